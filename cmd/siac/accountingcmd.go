@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"go.sia.tech/siad/node/api"
+)
+
+const (
+	// accountingFormatCSV prints the accounting information as CSV.
+	accountingFormatCSV = "csv"
+
+	// accountingFormatJSON prints the accounting information as JSON.
+	accountingFormatJSON = "json"
+
+	// accountingFormatTable prints the accounting information as a table.
+	accountingFormatTable = "table"
+)
+
+var (
+	accountingCmd = &cobra.Command{
+		Use:   "accounting",
+		Short: "Print the accounting information",
+		Long: `Print the accounting information of the node. By default the full
+persisted history and the current accounting information are printed. Use the
+--start and --end flags to limit the history to a range of Unix timestamps.
+
+The --format flag can be used to print the information as a table (default),
+csv or json. The csv and json formats report currency values in hastings.`,
+		Run: wrap(accountingcmd),
+	}
+)
+
+// accountingcmd is the handler for the command `siac accounting`.
+// It prints the accounting information.
+func accountingcmd() {
+	if accountingStart > accountingEnd {
+		die("start cannot be after end")
+	}
+	ag, err := httpClient.AccountingGet(accountingStart, accountingEnd)
+	if err != nil {
+		die("Could not get accounting information:", err)
+	}
+
+	switch accountingOutputFormat {
+	case accountingFormatCSV:
+		err = writeAccountingCSV(ag)
+	case accountingFormatJSON:
+		err = writeAccountingJSON(ag)
+	case accountingFormatTable:
+		err = writeAccountingTable(ag)
+	default:
+		die(fmt.Sprintf("Unknown format '%v', must be one of %v, %v or %v", accountingOutputFormat, accountingFormatTable, accountingFormatCSV, accountingFormatJSON))
+	}
+	if err != nil {
+		die("Could not print accounting information:", err)
+	}
+}
+
+// writeAccountingCSV writes the accounting information to stdout as CSV.
+func writeAccountingCSV(ag api.AccountingGET) error {
+	w := csv.NewWriter(os.Stdout)
+	err := w.Write([]string{"timestamp", "renter unspent unallocated", "renter withheld funds", "wallet confirmed siacoins", "wallet confirmed siafunds"})
+	if err != nil {
+		return err
+	}
+	for _, ai := range ag {
+		err = w.Write([]string{
+			strconv.FormatInt(ai.Timestamp, 10),
+			ai.Renter.UnspentUnallocated.String(),
+			ai.Renter.WithheldFunds.String(),
+			ai.Wallet.ConfirmedSiacoinBalance.String(),
+			ai.Wallet.ConfirmedSiafundBalance.String(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// writeAccountingJSON writes the accounting information to stdout as JSON.
+func writeAccountingJSON(ag api.AccountingGET) error {
+	js, err := json.MarshalIndent(ag, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(js))
+	return nil
+}
+
+// writeAccountingTable writes the accounting information to stdout as a
+// human readable table.
+func writeAccountingTable(ag api.AccountingGET) error {
+	if len(ag) == 0 {
+		fmt.Println("No accounting information in the requested range.")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  Time\tUnspent Unallocated\tWithheld Funds\tSiacoin Balance\tSiafund Balance")
+	for _, ai := range ag {
+		fmt.Fprintf(w, "  %v\t%v\t%v\t%v\t%v\n",
+			time.Unix(ai.Timestamp, 0).Format("2006-01-02 15:04:05-0700"),
+			currencyUnits(ai.Renter.UnspentUnallocated),
+			currencyUnits(ai.Renter.WithheldFunds),
+			currencyUnits(ai.Wallet.ConfirmedSiacoinBalance),
+			ai.Wallet.ConfirmedSiafundBalance)
+	}
+	return w.Flush()
+}
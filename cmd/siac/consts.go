@@ -13,6 +13,14 @@ const (
 	// the download command gives up on finding a download in the download list.
 	RenterDownloadTimeout = time.Minute
 
+	// RenterUploadPackBatchSize is the maximum number of files which are sent
+	// to the renter within a single packed upload.
+	RenterUploadPackBatchSize = 1000
+
+	// WalletExportPageBlocks is the number of blocks whose transactions are
+	// requested at once when exporting the wallet history.
+	WalletExportPageBlocks = 1000
+
 	// SpeedEstimationWindow is the size of the window which we use to
 	// determine download speeds.
 	SpeedEstimationWindow = 60 * time.Second
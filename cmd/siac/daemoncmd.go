@@ -2,7 +2,9 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -19,6 +21,35 @@ var (
 		Run:   wrap(alertscmd),
 	}
 
+	alertsStreamCmd = &cobra.Command{
+		Use:   "stream",
+		Short: "Stream daemon alerts",
+		Long:  "Print alerts as they are registered and unregistered until interrupted.",
+		Run:   wrap(alertsstreamcmd),
+	}
+
+	alertsWebhooksCmd = &cobra.Command{
+		Use:   "webhooks",
+		Short: "View the alert webhooks",
+		Long:  "View the urls which are notified about registered and unregistered alerts.",
+		Run:   wrap(alertswebhookscmd),
+	}
+
+	alertsWebhooksAddCmd = &cobra.Command{
+		Use:   "add [url]",
+		Short: "Add an alert webhook",
+		Long: `Add a url which is notified about registered and unregistered alerts.
+Every event is sent as a json encoded POST request.`,
+		Run: wrap(alertswebhooksaddcmd),
+	}
+
+	alertsWebhooksRemoveCmd = &cobra.Command{
+		Use:   "remove [url]",
+		Short: "Remove an alert webhook",
+		Long:  "Remove an alert webhook.",
+		Run:   wrap(alertswebhooksremovecmd),
+	}
+
 	stopCmd = &cobra.Command{
 		Use:   "stop",
 		Short: "Stop the Sia daemon",
@@ -138,6 +169,61 @@ func alertscmd() {
 	}
 }
 
+// alertsstreamcmd prints alert events until the stream is closed.
+func alertsstreamcmd() {
+	stream, err := httpClient.DaemonAlertsStream()
+	if err != nil {
+		die("Could not stream daemon alerts:", err)
+	}
+	defer stream.Close()
+	for {
+		event, err := stream.Next()
+		if errors.Contains(err, io.EOF) {
+			return
+		} else if err != nil {
+			die("Could not read alert:", err)
+		}
+		fmt.Printf("%v %-12v %-8v %v: %v", event.Timestamp.Format(time.RFC3339), event.Event, event.Severity, event.Module, event.Msg)
+		if event.Cause != "" {
+			fmt.Printf(" (%v)", event.Cause)
+		}
+		fmt.Println()
+	}
+}
+
+// alertswebhookscmd prints the alert webhooks.
+func alertswebhookscmd() {
+	dawg, err := httpClient.DaemonAlertWebhooksGet()
+	if err != nil {
+		die("Could not get alert webhooks:", err)
+	}
+	if len(dawg.Webhooks) == 0 {
+		fmt.Println("No alert webhooks.")
+		return
+	}
+	for _, webhook := range dawg.Webhooks {
+		fmt.Println(webhook)
+	}
+}
+
+// alertswebhooksaddcmd adds an alert webhook.
+func alertswebhooksaddcmd(webhook string) {
+	err := httpClient.DaemonAlertWebhookAddPost(webhook)
+	if err != nil {
+		die("Could not add alert webhook:", err)
+	}
+	fmt.Println("Added alert webhook", webhook)
+}
+
+// alertswebhooksremovecmd removes an alert webhook.
+func alertswebhooksremovecmd(webhook string) {
+	err := httpClient.DaemonAlertWebhookRemovePost(webhook)
+	if err != nil {
+		die("Could not remove alert webhook:", err)
+	}
+	fmt.Println("Removed alert webhook", webhook)
+}
+
 // profilecmd displays the usage info for the command.
 func profilecmd(cmd *cobra.Command, args []string) {
 	_ = cmd.UsageFunc()(cmd)
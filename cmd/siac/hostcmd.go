@@ -5,8 +5,10 @@ import (
 	"math/big"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -107,10 +109,45 @@ Available output types:
 		Run:   wrap(hostfolderaddcmd),
 	}
 
+	hostDrainCmd = &cobra.Command{
+		Use:   "drain",
+		Short: "Show the state of the drain mode",
+		Long: `Show whether the host is draining for maintenance and how many programs and
+uploads are still in flight. The host is drained once no programs or uploads
+are in flight anymore.`,
+		Run: wrap(hostdraincmd),
+	}
+
+	hostDrainStartCmd = &cobra.Command{
+		Use:   "start",
+		Short: "Start draining the host for maintenance",
+		Long: `Start draining the host for maintenance. A draining host doesn't form or
+renew contracts and, with --reject-deposits, doesn't accept ephemeral account
+deposits. It keeps serving downloads and submitting storage proofs. The drain
+mode is advertised to renters in the host's settings and price table.`,
+		Run: wrap(hostdrainstartcmd),
+	}
+
+	hostDrainStopCmd = &cobra.Command{
+		Use:   "stop",
+		Short: "Stop draining the host",
+		Long:  "Stop draining the host so that it forms and renews contracts and accepts deposits again.",
+		Run:   wrap(hostdrainstopcmd),
+	}
+
 	hostFolderCmd = &cobra.Command{
 		Use:   "folder",
-		Short: "Add, remove, or resize a storage folder",
-		Long:  "Add, remove, or resize a storage folder.",
+		Short: "Add, remove, resize, or migrate a storage folder",
+		Long:  "Add, remove, resize, or migrate a storage folder.",
+	}
+
+	hostFolderMigrateCmd = &cobra.Command{
+		Use:   "migrate [from] [to]",
+		Short: "Migrate sectors between storage folders",
+		Long: `Migrate sectors from one storage folder to another in the background while
+the host stays online. All sectors are migrated unless the number of sectors is
+set with the sectors flag.`,
+		Run: wrap(hostfoldermigratecmd),
 	}
 
 	hostFolderRemoveCmd = &cobra.Command{
@@ -131,6 +168,154 @@ other storage folders.`,
 		Run: wrap(hostfolderresizecmd),
 	}
 
+	hostMigrationCmd = &cobra.Command{
+		Use:   "migration",
+		Short: "Show the status of the sector migrator",
+		Long: `Show the settings of the sector migrator and the queued migrations. The
+migrator moves sectors between storage folders in the background, either when
+requested with 'siac host folder migrate' or to rebalance the storage folders.`,
+		Run: wrap(hostmigrationcmd),
+	}
+
+	hostMigrationCancelCmd = &cobra.Command{
+		Use:   "cancel",
+		Short: "Cancel all queued migrations",
+		Long:  "Cancel all queued migrations. Sectors which were already migrated stay in their new storage folder.",
+		Run:   wrap(hostmigrationcancelcmd),
+	}
+
+	hostMigrationRatelimitCmd = &cobra.Command{
+		Use:   "ratelimit [speed]",
+		Short: "Set the rate limit of the sector migrator",
+		Long: `Set the maximum speed at which the sector migrator moves sectors in
+Bytes per second: B/s, KB/s, MB/s, GB/s, TB/s
+or
+Bits per second: Bps, Kbps, Mbps, Gbps, Tbps`,
+		Run: wrap(hostmigrationratelimitcmd),
+	}
+
+	hostMigrationRebalanceCmd = &cobra.Command{
+		Use:   "rebalance [true/false]",
+		Short: "Enable or disable rebalancing the storage folders",
+		Long: `Enable or disable rebalancing the storage folders. If enabled, sectors are
+migrated automatically from the fullest to the emptiest storage folder whenever
+their utilization differs by more than 10%.`,
+		Run: wrap(hostmigrationrebalancecmd),
+	}
+
+	hostPricingCmd = &cobra.Command{
+		Use:   "pricing",
+		Short: "Show the status of the pricing policy",
+		Long: `Show the pricing policy of the host and the inputs of its most recent price
+adjustment. If enabled, the pricing policy periodically adjusts the host's
+prices towards fiat targets depending on the utilization of the host.`,
+		Run: wrap(hostpricingcmd),
+	}
+
+	hostPricingDisableCmd = &cobra.Command{
+		Use:   "disable",
+		Short: "Disable the pricing policy",
+		Long:  "Disable the pricing policy. The host's prices keep their current values.",
+		Run:   wrap(hostpricingdisablecmd),
+	}
+
+	hostPricingEnableCmd = &cobra.Command{
+		Use:   "enable",
+		Short: "Enable the pricing policy",
+		Long:  "Enable the pricing policy. The policy requires an exchange rate.",
+		Run:   wrap(hostpricingenablecmd),
+	}
+
+	hostPricingSetCmd = &cobra.Command{
+		Use:   "set [param] [value]",
+		Short: "Modify the pricing policy",
+		Long: `Modify the pricing policy of the host.
+
+Available parameters:
+     exchangerate:    the exchange rate of siacoin, e.g. 0.01USD
+     deviation:       the maximum fraction by which the utilization moves prices
+                      away from their targets, between 0 and 1
+     smoothing:       the fraction by which prices move towards their targets
+                      at each adjustment, greater than 0 and at most 1
+     targetbandwidth: the bandwidth at which bandwidth prices match their
+                      targets, e.g. 10MB/s
+
+     storageprice:  fiat / TB / Month
+     collateral:    fiat / TB / Month
+     maxcollateral: fiat
+     downloadprice: fiat / TB
+     uploadprice:   fiat / TB
+
+The bounds of each price can be set by appending 'min' or 'max' to its name,
+e.g. storagepricemin, and are specified in the same units as in
+'siac host config'. A target of 0 leaves the price unmanaged and a max of 0
+leaves the price unbounded.
+
+Prices which are managed by the pricing policy are overwritten at its next
+adjustment, even if they were changed with 'siac host config'.`,
+		Run: wrap(hostpricingsetcmd),
+	}
+
+	hostRentersCmd = &cobra.Command{
+		Use:   "renters",
+		Short: "View the renter filter of the host",
+		Long:  "View the filter mode of the host's renter filter and the filtered renters.",
+		Run:   wrap(hostrenterscmd),
+	}
+
+	hostRentersSetFilterCmd = &cobra.Command{
+		Use:   "setfilter [filtermode] [renter] [renter] [renter]...",
+		Short: "Set the renter filter of the host",
+		Long: `Set the filter mode of the host's renter filter and specify renters.
+Renters which aren't allowed by the filter can't form or renew contracts, fund
+ephemeral accounts or pay for RPCs.
+        [filtermode] can be whitelist, blacklist, or disable.
+        [renter] is the renter public key of an existing contract.
+Renewals are checked against the key of the renewed contract, and with the
+whitelist enabled the key of the new contract is added to the whitelist.`,
+		Run: hostrenterssetfiltercmd,
+	}
+
+	hostScrubCmd = &cobra.Command{
+		Use:   "scrub",
+		Short: "Show the status of the sector scrubber",
+		Long: `Show the settings and progress of the sector scrubber and the corrupt
+sectors it found. The scrubber periodically re-reads the sectors of each
+storage folder to detect data which was corrupted on disk.`,
+		Run: wrap(hostscrubcmd),
+	}
+
+	hostScrubDisableCmd = &cobra.Command{
+		Use:   "disable",
+		Short: "Disable the sector scrubber",
+		Long:  "Disable the sector scrubber, stopping any scrub which is under way.",
+		Run:   wrap(hostscrubdisablecmd),
+	}
+
+	hostScrubEnableCmd = &cobra.Command{
+		Use:   "enable",
+		Short: "Enable the sector scrubber",
+		Long:  "Enable the sector scrubber.",
+		Run:   wrap(hostscrubenablecmd),
+	}
+
+	hostScrubRatelimitCmd = &cobra.Command{
+		Use:   "ratelimit [speed]",
+		Short: "Set the rate limit of the sector scrubber",
+		Long: `Set the maximum speed at which the sector scrubber reads from disk in
+Bytes per second: B/s, KB/s, MB/s, GB/s, TB/s
+or
+Bits per second: Bps, Kbps, Mbps, Gbps, Tbps`,
+		Run: wrap(hostscrubratelimitcmd),
+	}
+
+	hostScrubStartCmd = &cobra.Command{
+		Use:   "start",
+		Short: "Start scrubbing all storage folders",
+		Long:  "Start scrubbing all storage folders immediately instead of waiting for their next scheduled scrub.",
+		Run:   wrap(hostscrubstartcmd),
+	}
+
 	hostSectorCmd = &cobra.Command{
 		Use:   "sector",
 		Short: "Add or delete a sector (add not supported)",
@@ -311,6 +496,8 @@ RPC Stats:
 			nm.ErrorCalls, nm.UnrecognizedCalls, nm.DownloadCalls,
 			nm.RenewCalls, nm.ReviseCalls, nm.SettingsCalls,
 			nm.FormContractCalls)
+		printHostRPCMetrics("RHP3 RPC Stats", nm.RPCMetrics)
+		printHostRPCMetrics("MDM Instruction Stats", nm.InstructionMetrics)
 	} else {
 		fmt.Printf(`Host info:
 	Connectability Status: %v
@@ -366,6 +553,33 @@ RPC Stats:
 	}
 }
 
+// printHostRPCMetrics prints a table of RPC or MDM instruction metrics sorted by
+// name. The P95 latency is the upper bound of the histogram bucket which
+// contains the 95th percentile.
+func printHostRPCMetrics(title string, metrics map[string]modules.HostRPCMetrics) {
+	if len(metrics) == 0 {
+		return
+	}
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Printf("\n%v:\n", title)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "\tName\tCalls\tErrors\tData In\tData Out\tAvg Latency\tP95 Latency\n")
+	for _, name := range names {
+		m := metrics[name]
+		fmt.Fprintf(w, "\t%v\t%v\t%v\t%v\t%v\t%v\t<= %v\n", name, m.Calls, m.Errors,
+			modules.FilesizeUnits(m.BytesIn), modules.FilesizeUnits(m.BytesOut),
+			m.AverageLatency().Round(time.Microsecond), m.LatencyPercentile(0.95))
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer")
+	}
+}
+
 // hostconfigcmd is the handler for the command `siac host config [setting] [value]`.
 // Modifies host settings.
 func hostconfigcmd(param, value string) {
@@ -531,6 +745,19 @@ func hostfolderaddcmd(path, size string) {
 	fmt.Println("Added folder", path)
 }
 
+// hostfoldermigratecmd migrates sectors between folders of the host.
+func hostfoldermigratecmd(from, to string) {
+	err := httpClient.HostStorageFoldersMigratePost(abs(from), abs(to), hostFolderMigrateSectors)
+	if err != nil {
+		die("Could not migrate sectors:", err)
+	}
+	if hostFolderMigrateSectors == 0 {
+		fmt.Printf("Migrating all sectors from %v to %v\n", from, to)
+		return
+	}
+	fmt.Printf("Migrating %v sectors from %v to %v\n", hostFolderMigrateSectors, from, to)
+}
+
 // hostfolderremovecmd removes a folder from the host.
 func hostfolderremovecmd(path string) {
 	// Ask for confirm for dangerous --force flag
@@ -582,3 +809,450 @@ func hostsectordeletecmd(root string) {
 	}
 	fmt.Println("Deleted sector", root)
 }
+
+// hostscrubcmd shows the status of the sector scrubber.
+func hostscrubcmd() {
+	ssg, err := httpClient.HostStorageScrubGet()
+	if err != nil {
+		die("Could not get scrub status:", err)
+	}
+	status := "Disabled"
+	if ssg.Enabled {
+		status = "Enabled"
+	}
+	fmt.Printf(`Sector Scrubbing: %v
+Rate Limit:       %v
+`, status, ratelimitUnits(int64(ssg.RateLimit)))
+
+	if len(ssg.Folders) > 0 {
+		fmt.Println()
+		fmt.Println("Storage Folders:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  Path\tProgress\tCorrupt Sectors\tLast Scrub")
+		for _, sf := range ssg.Folders {
+			progress := "-"
+			if sf.Scrubbing {
+				progress = fmt.Sprintf("%v/%v sectors", sf.ScrubbedSectors, sf.TotalSectors)
+			}
+			lastScrub := "never"
+			if !sf.LastScrub.IsZero() {
+				lastScrub = sf.LastScrub.Format(time.RFC822)
+			}
+			fmt.Fprintf(w, "  %v\t%v\t%v\t%v\n", sf.Path, progress, sf.CorruptSectors, lastScrub)
+		}
+		if err := w.Flush(); err != nil {
+			die("failed to flush writer:", err)
+		}
+	}
+
+	if len(ssg.CorruptSectors) > 0 {
+		fmt.Println()
+		fmt.Println("Corrupt Sectors:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  Root\tFolder\tStorage Obligations")
+		for _, cs := range ssg.CorruptSectors {
+			obligations := make([]string, 0, len(cs.Obligations))
+			for _, id := range cs.Obligations {
+				obligations = append(obligations, id.String())
+			}
+			fmt.Fprintf(w, "  %v\t%v\t%v\n", cs.Root, cs.StorageFolder, strings.Join(obligations, ", "))
+		}
+		if err := w.Flush(); err != nil {
+			die("failed to flush writer:", err)
+		}
+	}
+}
+
+// hostscrubsettings sets the settings of the sector scrubber, keeping the
+// rate limit unless a new one is provided.
+func hostscrubsettings(enabled bool, rateLimit uint64) {
+	if rateLimit == 0 {
+		ssg, err := httpClient.HostStorageScrubGet()
+		if err != nil {
+			die("Could not get scrub status:", err)
+		}
+		rateLimit = ssg.RateLimit
+	}
+	err := httpClient.HostStorageScrubPost(enabled, rateLimit)
+	if err != nil {
+		die("Could not update scrub settings:", err)
+	}
+}
+
+// hostscrubdisablecmd disables the sector scrubber.
+func hostscrubdisablecmd() {
+	hostscrubsettings(false, 0)
+	fmt.Println("Sector scrubbing disabled")
+}
+
+// hostscrubenablecmd enables the sector scrubber.
+func hostscrubenablecmd() {
+	hostscrubsettings(true, 0)
+	fmt.Println("Sector scrubbing enabled")
+}
+
+// hostscrubratelimitcmd sets the rate limit of the sector scrubber.
+func hostscrubratelimitcmd(speed string) {
+	rateLimit, err := parseRatelimit(speed)
+	if err != nil {
+		die("Could not parse rate limit:", err)
+	}
+	if rateLimit <= 0 {
+		die("Rate limit must be greater than 0")
+	}
+	ssg, err := httpClient.HostStorageScrubGet()
+	if err != nil {
+		die("Could not get scrub status:", err)
+	}
+	hostscrubsettings(ssg.Enabled, uint64(rateLimit))
+	fmt.Println("Set the scrub rate limit to", ratelimitUnits(rateLimit))
+}
+
+// hostscrubstartcmd starts scrubbing all storage folders.
+func hostscrubstartcmd() {
+	err := httpClient.HostStorageScrubStartPost()
+	if err != nil {
+		die("Could not start scrub:", err)
+	}
+	fmt.Println("Started scrubbing all storage folders")
+}
+
+// hostmigrationcmd shows the status of the sector migrator.
+func hostmigrationcmd() {
+	sms, err := httpClient.HostStorageMigrationGet()
+	if err != nil {
+		die("Could not get migration status:", err)
+	}
+	sg, err := httpClient.HostStorageGet()
+	if err != nil {
+		die("Could not get storage folders:", err)
+	}
+	paths := make(map[uint16]string)
+	for _, sf := range sg.Folders {
+		paths[sf.Index] = sf.Path
+	}
+	rebalance := "Disabled"
+	if sms.Rebalance {
+		rebalance = "Enabled"
+	}
+	fmt.Printf(`Rebalancing: %v
+Rate Limit:  %v
+`, rebalance, ratelimitUnits(int64(sms.RateLimit)))
+
+	if len(sms.Migrations) > 0 {
+		fmt.Println()
+		fmt.Println("Migrations:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  From\tTo\tMigrated\tRemaining\tReason")
+		for _, m := range sms.Migrations {
+			remaining := fmt.Sprint(m.Sectors)
+			if m.All {
+				remaining = "all"
+			}
+			reason := "requested"
+			if m.Rebalance {
+				reason = "rebalance"
+			}
+			fmt.Fprintf(w, "  %v\t%v\t%v\t%v\t%v\n", paths[m.From], paths[m.To], m.Migrated, remaining, reason)
+		}
+		if err := w.Flush(); err != nil {
+			die("failed to flush writer:", err)
+		}
+	}
+}
+
+// hostmigrationcancelcmd cancels all queued sector migrations.
+func hostmigrationcancelcmd() {
+	err := httpClient.HostStorageMigrationCancelPost()
+	if err != nil {
+		die("Could not cancel migrations:", err)
+	}
+	fmt.Println("Cancelled all queued migrations")
+}
+
+// hostmigrationratelimitcmd sets the rate limit of the sector migrator.
+func hostmigrationratelimitcmd(speed string) {
+	rateLimit, err := parseRatelimit(speed)
+	if err != nil {
+		die("Could not parse rate limit:", err)
+	}
+	if rateLimit <= 0 {
+		die("Rate limit must be greater than 0")
+	}
+	sms, err := httpClient.HostStorageMigrationGet()
+	if err != nil {
+		die("Could not get migration status:", err)
+	}
+	err = httpClient.HostStorageMigrationPost(sms.Rebalance, uint64(rateLimit))
+	if err != nil {
+		die("Could not update migration settings:", err)
+	}
+	fmt.Println("Set the migration rate limit to", ratelimitUnits(rateLimit))
+}
+
+// hostmigrationrebalancecmd enables or disables rebalancing the storage
+// folders.
+func hostmigrationrebalancecmd(enabled string) {
+	rebalance, err := strconv.ParseBool(enabled)
+	if err != nil {
+		die("Could not parse rebalance:", err)
+	}
+	sms, err := httpClient.HostStorageMigrationGet()
+	if err != nil {
+		die("Could not get migration status:", err)
+	}
+	err = httpClient.HostStorageMigrationPost(rebalance, sms.RateLimit)
+	if err != nil {
+		die("Could not update migration settings:", err)
+	}
+	if rebalance {
+		fmt.Println("Rebalancing enabled")
+		return
+	}
+	fmt.Println("Rebalancing disabled")
+}
+
+// hostdraincmd shows the state of the drain mode.
+func hostdraincmd() {
+	hds, err := httpClient.HostDrainGet()
+	if err != nil {
+		die("Could not get drain status:", err)
+	}
+	fmt.Printf(`Draining:           %v
+Rejecting Deposits: %v
+In-Flight Programs: %v
+In-Flight Uploads:  %v
+Drained:            %v
+`, yesNo(hds.Draining), yesNo(hds.RejectDeposits), hds.InFlightPrograms, hds.InFlightUploads, yesNo(hds.Drained))
+}
+
+// hostdrainstartcmd starts draining the host.
+func hostdrainstartcmd() {
+	err := httpClient.HostDrainPost(true, hostDrainRejectDeposits)
+	if err != nil {
+		die("Could not start draining:", err)
+	}
+	fmt.Println("Started draining the host. Run 'siac host drain' to check whether it is drained.")
+}
+
+// hostdrainstopcmd stops draining the host.
+func hostdrainstopcmd() {
+	err := httpClient.HostDrainPost(false, false)
+	if err != nil {
+		die("Could not stop draining:", err)
+	}
+	fmt.Println("Stopped draining the host")
+}
+
+// pricingTarget returns the price target of the pricing policy with the
+// provided name, the unit of its bounds in bytes and the name of that unit.
+func pricingTarget(policy *modules.HostPricingPolicy, name string) (*modules.HostPriceTarget, types.Currency, string) {
+	switch name {
+	case "storageprice":
+		return &policy.StoragePrice, modules.BlockBytesPerMonthTerabyte, "/TB/Month"
+	case "collateral":
+		return &policy.Collateral, modules.BlockBytesPerMonthTerabyte, "/TB/Month"
+	case "maxcollateral":
+		return &policy.MaxCollateral, types.NewCurrency64(1), ""
+	case "downloadprice":
+		return &policy.DownloadPrice, modules.BytesPerTerabyte, "/TB"
+	case "uploadprice":
+		return &policy.UploadPrice, modules.BytesPerTerabyte, "/TB"
+	}
+	return nil, types.ZeroCurrency, ""
+}
+
+// hostpricingcmd shows the status of the pricing policy.
+func hostpricingcmd() {
+	hps, err := httpClient.HostPricingGet()
+	if err != nil {
+		die("Could not get pricing status:", err)
+	}
+	policy := hps.Policy
+	enabled := "Disabled"
+	if policy.Enabled {
+		enabled = "Enabled"
+	}
+	var rate, symbol string
+	if policy.ExchangeRate != nil {
+		rate, symbol = policy.ExchangeRate.String(), policy.ExchangeRate.Symbol()
+	}
+	targetBandwidth := "none"
+	if policy.TargetBandwidth > 0 {
+		targetBandwidth = ratelimitUnits(int64(policy.TargetBandwidth))
+	}
+	fmt.Printf(`Pricing Policy:   %v
+Exchange Rate:    %v
+Deviation:        %v
+Smoothing:        %v
+Target Bandwidth: %v
+`, enabled, rate, policy.Deviation, policy.Smoothing, targetBandwidth)
+
+	fmt.Println()
+	fmt.Println("Targets:")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  Price\tTarget\tMin\tMax")
+	for _, name := range []string{"storageprice", "collateral", "maxcollateral", "downloadprice", "uploadprice"} {
+		pt, unit, unitName := pricingTarget(&policy, name)
+		if pt.Target == 0 {
+			fmt.Fprintf(w, "  %v\tunmanaged\t\t\n", name)
+			continue
+		}
+		max := "none"
+		if !pt.Max.IsZero() {
+			max = pt.Max.Mul(unit).HumanString() + unitName
+		}
+		fmt.Fprintf(w, "  %v\t%v %v%v\t%v%v\t%v\n", name, pt.Target, symbol, unitName, pt.Min.Mul(unit).HumanString(), unitName, max)
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer:", err)
+	}
+
+	if hps.LastAdjustment.IsZero() {
+		return
+	}
+	fmt.Printf(`
+Last Adjustment:        %v
+Storage Utilization:    %.2f%%
+Collateral Utilization: %.2f%%
+Bandwidth:              %v
+`, hps.LastAdjustment.Format(time.RFC822), hps.StorageUtilization*100, hps.CollateralUtilization*100, ratelimitUnits(int64(hps.Bandwidth)))
+}
+
+// hostpricingdisablecmd disables the pricing policy.
+func hostpricingdisablecmd() {
+	hps, err := httpClient.HostPricingGet()
+	if err != nil {
+		die("Could not get pricing status:", err)
+	}
+	hps.Policy.Enabled = false
+	err = httpClient.HostPricingPost(hps.Policy)
+	if err != nil {
+		die("Could not disable the pricing policy:", err)
+	}
+	fmt.Println("Pricing policy disabled")
+}
+
+// hostpricingenablecmd enables the pricing policy.
+func hostpricingenablecmd() {
+	hps, err := httpClient.HostPricingGet()
+	if err != nil {
+		die("Could not get pricing status:", err)
+	}
+	hps.Policy.Enabled = true
+	err = httpClient.HostPricingPost(hps.Policy)
+	if err != nil {
+		die("Could not enable the pricing policy:", err)
+	}
+	fmt.Println("Pricing policy enabled")
+}
+
+// hostpricingsetcmd is the handler for the command `siac host pricing set
+// [param] [value]`. It modifies the pricing policy.
+func hostpricingsetcmd(param, value string) {
+	hps, err := httpClient.HostPricingGet()
+	if err != nil {
+		die("Could not get pricing status:", err)
+	}
+	policy := hps.Policy
+	switch param {
+	case "exchangerate":
+		policy.ExchangeRate, err = types.ParseExchangeRate(value)
+		if err != nil {
+			die("Could not parse "+param+":", err)
+		}
+	case "deviation", "smoothing":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			die("Could not parse "+param+":", err)
+		}
+		if param == "deviation" {
+			policy.Deviation = f
+		} else {
+			policy.Smoothing = f
+		}
+	case "targetbandwidth":
+		bandwidth, err := parseRatelimit(value)
+		if err != nil {
+			die("Could not parse "+param+":", err)
+		}
+		policy.TargetBandwidth = uint64(bandwidth)
+	default:
+		name := strings.TrimSuffix(strings.TrimSuffix(param, "min"), "max")
+		pt, unit, _ := pricingTarget(&policy, name)
+		if pt == nil {
+			die("Unknown pricing policy parameter:", param)
+		}
+		if name == param {
+			pt.Target, err = strconv.ParseFloat(value, 64)
+			if err != nil {
+				die("Could not parse "+param+":", err)
+			}
+			break
+		}
+		// convert the bounds from currency/unit to hastings/byte
+		hastings, err := types.ParseCurrency(value)
+		if err != nil {
+			die("Could not parse "+param+":", err)
+		}
+		i, _ := new(big.Int).SetString(hastings, 10)
+		bound := types.NewCurrency(i).Div(unit)
+		if strings.HasSuffix(param, "min") {
+			pt.Min = bound
+		} else {
+			pt.Max = bound
+		}
+	}
+
+	err = httpClient.HostPricingPost(policy)
+	if err != nil {
+		die("Could not update the pricing policy:", err)
+	}
+	fmt.Println("Pricing policy updated.")
+}
+
+// hostrenterscmd is the handler for the command `siac host renters`.
+func hostrenterscmd() {
+	hrfg, err := httpClient.HostRenterFilterGet()
+	if err != nil {
+		die("Could not get renter filter:", err)
+	}
+	fmt.Println()
+	fmt.Println("  Renter Filter Mode:", hrfg.FilterMode)
+	fmt.Println("  Renters:")
+	for _, renter := range hrfg.Renters {
+		fmt.Println("    ", renter)
+	}
+	fmt.Println()
+}
+
+// hostrenterssetfiltercmd is the handler for the command `siac host renters
+// setfilter`. It sets the renter filter mode (whitelist, blacklist, disable).
+func hostrenterssetfiltercmd(cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		_ = cmd.UsageFunc()(cmd)
+		os.Exit(exitCodeUsage)
+	}
+	if len(args) == 1 && args[0] != "disable" {
+		die("if only submitting filtermode it should be `disable`")
+	}
+	var fm modules.FilterMode
+	err := fm.FromString(args[0])
+	if err != nil {
+		die("Could not parse filtermode:", err)
+	}
+	var renters []types.SiaPublicKey
+	for _, arg := range args[1:] {
+		var renter types.SiaPublicKey
+		if err := renter.LoadString(arg); err != nil {
+			die("Could not parse renter public key:", err)
+		}
+		renters = append(renters, renter)
+	}
+
+	err = httpClient.HostRenterFilterPost(fm, renters)
+	if err != nil {
+		die("Could not set renter filter:", err)
+	}
+	fmt.Println("Successfully set the renter filter")
+}
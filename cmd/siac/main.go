@@ -23,6 +23,11 @@ var (
 
 	// Module Specific Flags
 	//
+	// Accounting Flags
+	accountingEnd          int64  // End of the accounting history range
+	accountingOutputFormat string // Output format of the accounting information
+	accountingStart        int64  // Start of the accounting history range
+
 	// Daemon Flags
 	daemonStackOutputFile  string // The file that the stack trace will be written to
 	daemonCPUProfile       bool   // Indicates that the CPU profile should be started
@@ -31,8 +36,10 @@ var (
 	daemonTraceProfile     bool   // Indicates that the Trace profile should be started
 
 	// Host Flags
-	hostContractOutputType string // output type for host contracts
-	hostFolderRemoveForce  bool   // force folder remove
+	hostContractOutputType   string // output type for host contracts
+	hostDrainRejectDeposits  bool   // reject ephemeral account deposits while draining
+	hostFolderMigrateSectors uint64 // number of sectors to migrate
+	hostFolderRemoveForce    bool   // force folder remove
 
 	// Renter Flags
 	dataPieces                string // the number of data pieces a file should be uploaded with
@@ -44,10 +51,13 @@ var (
 	renterDownloadRecursive   bool   // Downloads folders recursively.
 	renterDownloadRoot        bool   // Download path start from root instead of the UserFolder.
 	renterFuseMountAllowOther bool   // Mount fuse with 'AllowOther' set to true.
+	renterFuseMountReadOnly   bool   // Mount fuse with 'ReadOnly' set to true.
+	renterImportForce         bool   // Overwrite existing files when importing shared files.
 	renterListRecursive       bool   // List files of folder recursively.
 	renterListRoot            bool   // List path start from root instead of the UserFolder.
 	renterRenameRoot          bool   // Rename files relative to root instead of the UserFolder.
 	renterShowHistory         bool   // Show download history in addition to download queue.
+	renterUploadPack          bool   // Pack small files into shared chunks.
 
 	// Renter Allowance Flags
 	allowanceFunds       string // amount of money to be used within a period
@@ -67,6 +77,8 @@ var (
 	allowanceMaxStoragePrice           string // max allowed price to store data on a host
 	allowanceMaxUploadBandwidthPrice   string // max allowed price to upload data to a host
 
+	allowanceFundingAccount string // wallet account which funds the contracts
+
 	// Skykey Flags
 	skykeyID              string // ID used to identify a Skykey.
 	skykeyName            string // Name used to identify a Skykey.
@@ -84,6 +96,25 @@ var (
 	walletStartHeight    uint64 // Start height for transaction search.
 	walletEndHeight      uint64 // End height for transaction search.
 	walletTxnFeeIncluded bool   // include the fee in the balance being sent
+	walletSendInclude    string // comma separated outputs which have to be spent
+	walletSendExclude    string // comma separated outputs which must not be spent
+	walletSendStrategy   string // coin selection strategy
+	walletSendChange     string // address which receives the change
+	walletBumpMethod     string // fee bump method
+	walletBumpFee        string // fee per byte of the bumped transaction set
+	walletBroadcast      bool   // broadcast a finalized partially signed transaction
+	walletMultisigUnused bool   // skip the rescan when creating a multisig address
+	walletTxnLabel       string // only show transactions with this label
+	walletLabelTags      string // comma separated tags of a transaction
+	walletPayInterval    uint64 // blocks between recurring scheduled payments
+	walletReportStart    string // start of a fiat report
+	walletReportEnd      string // end of a fiat report
+	walletReportPeriod   string // period a fiat report is summarized by
+	walletFiatSymbol     string // currency in which transactions are valued
+	walletExportFormat   string // format of the exported wallet history
+	walletSendAccount    string // account which funds a transaction
+	walletTxnAccount     string // only show transactions of this account
+	walletTimelockUnused bool   // skip the rescan when adding a time-locked address
 )
 
 var (
@@ -288,6 +319,11 @@ func initCmds() *cobra.Command {
 	}
 
 	// create command tree (alphabetized by root command)
+	root.AddCommand(accountingCmd)
+	accountingCmd.Flags().Int64Var(&accountingStart, "start", 0, "Unix timestamp of the start of the accounting history")
+	accountingCmd.Flags().Int64Var(&accountingEnd, "end", math.MaxInt64, "Unix timestamp of the end of the accounting history")
+	accountingCmd.Flags().StringVar(&accountingOutputFormat, "format", accountingFormatTable, "Output format, one of table, csv or json")
+
 	root.AddCommand(consensusCmd)
 	root.AddCommand(jsonCmd)
 
@@ -296,10 +332,17 @@ func initCmds() *cobra.Command {
 	gatewayBlocklistCmd.AddCommand(gatewayBlocklistAppendCmd, gatewayBlocklistClearCmd, gatewayBlocklistRemoveCmd, gatewayBlocklistSetCmd)
 
 	root.AddCommand(hostCmd)
-	hostCmd.AddCommand(hostAnnounceCmd, hostConfigCmd, hostContractCmd, hostFolderCmd, hostSectorCmd)
-	hostFolderCmd.AddCommand(hostFolderAddCmd, hostFolderRemoveCmd, hostFolderResizeCmd)
+	hostCmd.AddCommand(hostAnnounceCmd, hostConfigCmd, hostContractCmd, hostDrainCmd, hostFolderCmd, hostMigrationCmd, hostPricingCmd, hostRentersCmd, hostScrubCmd, hostSectorCmd)
+	hostMigrationCmd.AddCommand(hostMigrationCancelCmd, hostMigrationRatelimitCmd, hostMigrationRebalanceCmd)
+	hostDrainCmd.AddCommand(hostDrainStartCmd, hostDrainStopCmd)
+	hostPricingCmd.AddCommand(hostPricingDisableCmd, hostPricingEnableCmd, hostPricingSetCmd)
+	hostRentersCmd.AddCommand(hostRentersSetFilterCmd)
+	hostScrubCmd.AddCommand(hostScrubDisableCmd, hostScrubEnableCmd, hostScrubRatelimitCmd, hostScrubStartCmd)
+	hostFolderCmd.AddCommand(hostFolderAddCmd, hostFolderMigrateCmd, hostFolderRemoveCmd, hostFolderResizeCmd)
 	hostSectorCmd.AddCommand(hostSectorDeleteCmd)
 	hostContractCmd.Flags().StringVarP(&hostContractOutputType, "type", "t", "value", "Select output type")
+	hostDrainStartCmd.Flags().BoolVarP(&hostDrainRejectDeposits, "reject-deposits", "r", false, "Reject ephemeral account deposits while draining")
+	hostFolderMigrateCmd.Flags().Uint64VarP(&hostFolderMigrateSectors, "sectors", "n", 0, "Number of sectors to migrate, all sectors if not set")
 	hostFolderRemoveCmd.Flags().BoolVarP(&hostFolderRemoveForce, "force", "f", false, "Force the removal of the folder and its data")
 
 	root.AddCommand(hostdbCmd)
@@ -314,12 +357,13 @@ func initCmds() *cobra.Command {
 		renterCleanCmd, renterContractsCmd, renterContractsRecoveryScanProgressCmd, renterDownloadCancelCmd,
 		renterDownloadsCmd, renterExportCmd, renterFilesDeleteCmd, renterFilesDownloadCmd,
 		renterFilesListCmd, renterFilesRenameCmd, renterFilesUnstuckCmd, renterFilesUploadCmd,
-		renterFuseCmd, renterLostCmd, renterPricesCmd, renterRatelimitCmd, renterSetAllowanceCmd,
-		renterSetLocalPathCmd, renterTriggerContractRecoveryScanCmd, renterUploadsCmd, renterWorkersCmd,
+		renterFuseCmd, renterImportCmd, renterLostCmd, renterPricesCmd, renterRatelimitCmd, renterRegistryCmd, renterSetAllowanceCmd,
+		renterSetLocalPathCmd, renterShareCmd, renterTriggerContractRecoveryScanCmd, renterUploadsCmd, renterWorkersCmd,
 		renterHealthSummaryCmd)
 	renterWorkersCmd.AddCommand(renterWorkersAccountsCmd, renterWorkersDownloadsCmd, renterWorkersPriceTableCmd, renterWorkersReadJobsCmd, renterWorkersHasSectorJobSCmd, renterWorkersUploadsCmd, renterWorkersReadRegistryCmd, renterWorkersUpdateRegistryCmd)
 
 	renterAllowanceCmd.AddCommand(renterAllowanceCancelCmd)
+	renterRegistryCmd.AddCommand(renterRegistryGetCmd, renterRegistrySetCmd, renterRegistrySubscribeCmd)
 	renterBubbleCmd.Flags().BoolVarP(&renterBubbleAll, "all", "A", false, "Bubble the entire directory tree")
 	renterContractsCmd.AddCommand(renterContractsViewCmd)
 	renterFilesUploadCmd.AddCommand(renterFilesUploadPauseCmd, renterFilesUploadResumeCmd)
@@ -334,7 +378,9 @@ func initCmds() *cobra.Command {
 	renterFilesListCmd.Flags().BoolVar(&renterListRoot, "root", false, "List files and folders from root instead of from the user home directory")
 	renterFilesUploadCmd.Flags().StringVar(&dataPieces, "data-pieces", "", "the number of data pieces a files should be uploaded with")
 	renterFilesUploadCmd.Flags().StringVar(&parityPieces, "parity-pieces", "", "the number of parity pieces a files should be uploaded with")
+	renterFilesUploadCmd.Flags().BoolVar(&renterUploadPack, "pack", false, "Pack small files into shared chunks")
 	renterExportCmd.AddCommand(renterExportContractTxnsCmd)
+	renterImportCmd.Flags().BoolVar(&renterImportForce, "force", false, "Overwrite an existing file at the path")
 	renterFilesRenameCmd.Flags().BoolVar(&renterRenameRoot, "root", false, "Rename files relative to root instead of the user homedir")
 
 	renterSetAllowanceCmd.Flags().StringVar(&allowanceFunds, "amount", "", "amount of money in allowance, specified in currency units")
@@ -351,12 +397,16 @@ func initCmds() *cobra.Command {
 	renterSetAllowanceCmd.Flags().StringVar(&allowanceMaxSectorAccessPrice, "max-sector-access-price", "", "the maximum price that the renter will pay to access a sector on a host")
 	renterSetAllowanceCmd.Flags().StringVar(&allowanceMaxStoragePrice, "max-storage-price", "", "the maximum price that the renter will pay to store data on a host")
 	renterSetAllowanceCmd.Flags().StringVar(&allowanceMaxUploadBandwidthPrice, "max-upload-bandwidth-price", "", "the maximum price that the renter will pay to upload data to a host")
+	renterSetAllowanceCmd.Flags().StringVar(&allowanceFundingAccount, "funding-account", "", "the wallet account which funds the contracts, an empty name uses the outputs outside of accounts")
 
 	renterFuseCmd.AddCommand(renterFuseMountCmd, renterFuseUnmountCmd)
 	renterFuseMountCmd.Flags().BoolVarP(&renterFuseMountAllowOther, "allow-other", "", false, "Allow users other than the user that mounted the fuse directory to access and use the fuse directory")
+	renterFuseMountCmd.Flags().BoolVarP(&renterFuseMountReadOnly, "read-only", "", false, "Mount the fuse directory in read-only mode")
 
 	// Daemon Commands
 	root.AddCommand(alertsCmd, globalRatelimitCmd, profileCmd, stackCmd, stopCmd, updateCmd, versionCmd)
+	alertsCmd.AddCommand(alertsStreamCmd, alertsWebhooksCmd)
+	alertsWebhooksCmd.AddCommand(alertsWebhooksAddCmd, alertsWebhooksRemoveCmd)
 	profileCmd.AddCommand(profileStartCmd, profileStopCmd)
 	profileStartCmd.Flags().BoolVarP(&daemonCPUProfile, "cpu", "c", false, "Start the CPU profile")
 	profileStartCmd.Flags().BoolVarP(&daemonMemoryProfile, "memory", "m", false, "Start the Memory profile")
@@ -373,20 +423,51 @@ func initCmds() *cobra.Command {
 	utilsVerifySeedCmd.Flags().StringVarP(&dictionaryLanguage, "language", "l", "english", "which dictionary you want to use")
 
 	root.AddCommand(walletCmd)
-	walletCmd.AddCommand(walletAddressCmd, walletAddressesCmd, walletBalanceCmd, walletBroadcastCmd, walletChangepasswordCmd,
-		walletInitCmd, walletInitSeedCmd, walletLoadCmd, walletLockCmd, walletSeedsCmd, walletSendCmd,
-		walletSignCmd, walletSweepCmd, walletTransactionsCmd, walletUnlockCmd)
+	walletCmd.AddCommand(walletAccountsCmd, walletAddressCmd, walletAddressesCmd, walletBalanceCmd, walletBroadcastCmd, walletBumpCmd, walletChangepasswordCmd,
+		walletExportCmd, walletInitCmd, walletInitSeedCmd, walletLabelCmd, walletLoadCmd, walletLockCmd, walletMultisigCmd, walletPartialCmd, walletRatesCmd,
+		walletReportCmd, walletScheduleCmd, walletSeedsCmd, walletSendCmd, walletSignCmd, walletSweepCmd, walletTimelockCmd, walletTransactionsCmd,
+		walletUnlockCmd)
+	walletAccountsCmd.AddCommand(walletAccountsAddCmd, walletAccountsAddressCmd, walletAccountsAssignCmd, walletAccountsRemoveCmd, walletAccountsSeedCmd,
+		walletAccountsShowCmd, walletAccountsUnassignCmd)
+	walletBumpCmd.Flags().StringVar(&walletBumpMethod, "method", "", "Fee bump method, either cpfp or replace")
+	walletBumpCmd.Flags().StringVar(&walletBumpFee, "fee-per-byte", "", "Fee per byte of the bumped transaction set, defaults to the maximum recommended fee")
+	walletExportCmd.Flags().StringVar(&walletExportFormat, "format", modules.ExportFormatCSV, "Export format, one of csv, json or ofx")
+	walletExportCmd.Flags().Uint64Var(&walletStartHeight, "startheight", 0, "Height of the block where the export should begin")
+	walletExportCmd.Flags().Uint64Var(&walletEndHeight, "endheight", math.MaxUint64, "Height of the block where the export should end")
 	walletInitCmd.Flags().BoolVarP(&initPassword, "password", "p", false, "Prompt for a custom password")
 	walletInitCmd.Flags().BoolVarP(&initForce, "force", "", false, "destroy the existing wallet and re-encrypt")
 	walletInitSeedCmd.Flags().BoolVarP(&initForce, "force", "", false, "destroy the existing wallet")
 	walletLoadCmd.AddCommand(walletLoad033xCmd, walletLoadSeedCmd, walletLoadSiagCmd)
+	walletLabelCmd.AddCommand(walletLabelAddressCmd, walletLabelTransactionCmd)
+	walletLabelTransactionCmd.Flags().StringVar(&walletLabelTags, "tags", "", "Comma separated tags of the transaction")
+	walletRatesCmd.AddCommand(walletRatesAddCmd, walletRatesImportCmd)
+	walletReportCmd.Flags().StringVar(&walletReportStart, "start", "", "Start of the report, a unix timestamp, date or RFC 3339 time")
+	walletReportCmd.Flags().StringVar(&walletReportEnd, "end", "", "End of the report, defaults to now")
+	walletReportCmd.Flags().StringVar(&walletReportPeriod, "period", "month", "Summarize the report by day, month or year")
+	walletScheduleCmd.AddCommand(walletScheduleAddCmd, walletScheduleRemoveCmd)
+	walletScheduleAddCmd.Flags().Uint64Var(&walletPayInterval, "interval", 0, "Repeat the payment every interval blocks")
+	walletMultisigCmd.AddCommand(walletMultisigCreateCmd, walletMultisigListCmd, walletMultisigPublicKeyCmd)
+	walletMultisigCreateCmd.Flags().BoolVarP(&walletMultisigUnused, "unused", "", false, "Skip the blockchain rescan, only use for addresses which never received coins")
+	walletPartialCmd.AddCommand(walletPartialCreateCmd, walletPartialFinalizeCmd, walletPartialMergeCmd, walletPartialSignCmd, walletPartialStatusCmd)
+	walletPartialCmd.PersistentFlags().BoolVarP(&walletRawTxn, "raw", "", false, "Encode partially signed transactions as base64 instead of JSON")
+	walletPartialFinalizeCmd.Flags().BoolVarP(&walletBroadcast, "broadcast", "", false, "Broadcast the finalized transaction")
 	walletSendCmd.AddCommand(walletSendSiacoinsCmd, walletSendSiafundsCmd)
 	walletSendSiacoinsCmd.Flags().BoolVarP(&walletTxnFeeIncluded, "fee-included", "", false, "Take the transaction fee out of the balance being submitted instead of the fee being additional")
+	walletSendSiacoinsCmd.Flags().StringVar(&walletSendInclude, "include", "", "Comma separated list of outputs to spend, no other outputs are spent")
+	walletSendSiacoinsCmd.Flags().StringVar(&walletSendExclude, "exclude", "", "Comma separated list of outputs which must not be spent")
+	walletSendSiacoinsCmd.Flags().StringVar(&walletSendStrategy, "strategy", "", "Coin selection strategy, one of largest-first, smallest-first, minimize-change or privacy")
+	walletSendSiacoinsCmd.Flags().StringVar(&walletSendChange, "change-address", "", "Address which receives the change instead of a new wallet address")
+	walletSendSiacoinsCmd.Flags().StringVar(&walletSendAccount, "account", "", "Account whose outputs are spent, the change is sent to a new address of the account")
+	walletTimelockCmd.AddCommand(walletTimelockAddCmd, walletTimelockAddressCmd, walletTimelockSendCmd)
+	walletTimelockAddCmd.Flags().BoolVar(&walletTimelockUnused, "unused", false, "Skip the blockchain rescan, only use for addresses which never received coins")
 	walletUnlockCmd.Flags().BoolVarP(&initPassword, "password", "p", false, "Display interactive password prompt even if SIA_WALLET_PASSWORD is set")
 	walletBroadcastCmd.Flags().BoolVarP(&walletRawTxn, "raw", "", false, "Decode transaction as base64 instead of JSON")
 	walletSignCmd.Flags().BoolVarP(&walletRawTxn, "raw", "", false, "Encode signed transaction as base64 instead of JSON")
 	walletTransactionsCmd.Flags().Uint64Var(&walletStartHeight, "startheight", 0, " Height of the block where transaction history should begin.")
 	walletTransactionsCmd.Flags().Uint64Var(&walletEndHeight, "endheight", math.MaxUint64, " Height of the block where transaction history should end.")
+	walletTransactionsCmd.Flags().StringVar(&walletTxnLabel, "label", "", "Only show transactions with this tag or which involve an address with this name")
+	walletTransactionsCmd.Flags().StringVar(&walletTxnAccount, "account", "", "Only show transactions which involve an address of this account")
+	walletTransactionsCmd.Flags().StringVar(&walletFiatSymbol, "symbol", "", "Show the value of transactions in this currency using the stored exchange rates")
 
 	return root
 }
@@ -16,6 +16,7 @@ import (
 	"gitlab.com/NebulousLabs/encoding"
 	"gitlab.com/NebulousLabs/errors"
 	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/modules"
 	"go.sia.tech/siad/types"
 )
 
@@ -325,31 +326,50 @@ func yesNo(b bool) string {
 // parseTxn decodes a transaction from s, which can be JSON, base64, or a path
 // to a file containing either encoding.
 func parseTxn(s string) (types.Transaction, error) {
+	var txn types.Transaction
+	if err := parseEncoded(s, "transaction", &txn); err != nil {
+		return types.Transaction{}, err
+	}
+	return txn, nil
+}
+
+// parsePartialTxn decodes a partially signed transaction in the same formats
+// as parseTxn.
+func parsePartialTxn(s string) (modules.PartiallySignedTransaction, error) {
+	var pst modules.PartiallySignedTransaction
+	if err := parseEncoded(s, "partially signed transaction", &pst); err != nil {
+		return modules.PartiallySignedTransaction{}, err
+	}
+	return pst, nil
+}
+
+// parseEncoded decodes an object which is either a JSON or base64-encoded
+// binary literal or a file containing either.
+func parseEncoded(s, name string, obj interface{}) error {
 	// first assume s is a file
-	txnBytes, err := ioutil.ReadFile(s)
+	objBytes, err := ioutil.ReadFile(s)
 	if os.IsNotExist(err) {
 		// assume s is a literal encoding
-		txnBytes = []byte(s)
+		objBytes = []byte(s)
 	} else if err != nil {
-		return types.Transaction{}, errors.New("could not read transaction file: " + err.Error())
+		return errors.New("could not read " + name + " file: " + err.Error())
 	}
-	// txnBytes now contains either s or the contents of the file, so it is
+	// objBytes now contains either s or the contents of the file, so it is
 	// either JSON or base64
-	var txn types.Transaction
-	if json.Valid(txnBytes) {
-		if err := json.Unmarshal(txnBytes, &txn); err != nil {
-			return types.Transaction{}, errors.New("could not decode JSON transaction: " + err.Error())
+	if json.Valid(objBytes) {
+		if err := json.Unmarshal(objBytes, obj); err != nil {
+			return errors.New("could not decode JSON " + name + ": " + err.Error())
 		}
 	} else {
-		bin, err := base64.StdEncoding.DecodeString(string(txnBytes))
+		bin, err := base64.StdEncoding.DecodeString(string(objBytes))
 		if err != nil {
-			return types.Transaction{}, errors.New("argument is not valid JSON, base64, or filepath")
+			return errors.New("argument is not valid JSON, base64, or filepath")
 		}
-		if err := encoding.Unmarshal(bin, &txn); err != nil {
-			return types.Transaction{}, errors.New("could not decode binary transaction: " + err.Error())
+		if err := encoding.Unmarshal(bin, obj); err != nil {
+			return errors.New("could not decode binary " + name + ": " + err.Error())
 		}
 	}
-	return txn, nil
+	return nil
 }
 
 // fmtDuration converts a time.Duration into a days,hours,minutes string
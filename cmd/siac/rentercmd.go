@@ -168,6 +168,14 @@ the command 'siac renter lost' to see the renter's lost files.`,
 		Run:     wrap(renterfilesrenamecmd),
 	}
 
+	renterImportCmd = &cobra.Command{
+		Use:   "import [descriptor] [sharingkey] [path]",
+		Short: "Import a file shared by another renter",
+		Long: `Import a file which was shared by another renter using 'siac renter share'. The
+file is downloaded from the hosts which store it and repaired onto the renter's own hosts.`,
+		Run: wrap(renterimportcmd),
+	}
+
 	renterFuseCmd = &cobra.Command{
 		Use:   "fuse",
 		Short: "Perform fuse actions.",
@@ -179,10 +187,9 @@ the command 'siac renter lost' to see the renter's lost files.`,
 		Use:   "mount [path] [siapath]",
 		Short: "Mount a Sia folder to your disk",
 		Long: `Mount a Sia folder to your disk. Applications will be able to see this folder
-as though it is a normal part of your filesystem.  Currently experimental, and
-read-only. When Sia is ready to support read-write fuse mounting, siac will be
-updated to mount in read-write mode as the default. If you must guarantee that
-read-only mode is used, you must use the API.`,
+as though it is a normal part of your filesystem.  Currently experimental. The
+folder is mounted in read-write mode unless the --read-only flag is set. Files
+written to the folder are buffered locally and uploaded when they are closed.`,
 		Run: wrap(renterfusemountcmd),
 	}
 
@@ -201,6 +208,14 @@ local path where the Sia folder is mounted.`,
 		Run:   wrap(rentersetlocalpathcmd),
 	}
 
+	renterShareCmd = &cobra.Command{
+		Use:   "share [path]",
+		Short: "Share a file with another renter",
+		Long: `Create a share descriptor for a file. The descriptor and the sharing key can be
+passed to 'siac renter import' by another renter to import the file.`,
+		Run: wrap(rentersharecmd),
+	}
+
 	renterFilesUnstuckCmd = &cobra.Command{
 		Use:   "unstuckall",
 		Short: "Set all files to unstuck",
@@ -212,7 +227,8 @@ local path where the Sia folder is mounted.`,
 		Use:   "upload [source] [path]",
 		Short: "Upload a file or folder",
 		Long: `Upload a file or folder to [path] on the Sia network. The --data-pieces and --parity-pieces
-flags can be used to set a custom redundancy for the file.`,
+flags can be used to set a custom redundancy for the file. The --pack flag packs files which
+are smaller than a sector into shared chunks to save storage on the hosts.`,
 		Run: wrap(renterfilesuploadcmd),
 	}
 
@@ -592,11 +608,17 @@ func renterallowancecmd() {
 		fmt.Printf("Warning: ignoring exchange rate - %s\n", err)
 	}
 
+	fundingAccount := allowance.FundingAccount
+	if fundingAccount == "" {
+		fundingAccount = "none"
+	}
+
 	fmt.Printf(`Allowance:
   Amount:               %v
   Period:               %v blocks
   Renew Window:         %v blocks
   Hosts:                %v
+  Funding Account:      %v
 
 Expectations for period:
   Expected Storage:     %v
@@ -612,7 +634,7 @@ Price Protections:
   MaxStoragePrice:           %v per TB per Month
   MaxUploadBandwidthPrice:   %v per TB
 `, currencyUnitsWithExchangeRate(allowance.Funds, rate), allowance.Period, allowance.RenewWindow,
-		allowance.Hosts,
+		allowance.Hosts, fundingAccount,
 		modules.FilesizeUnits(allowance.ExpectedStorage),
 		modules.FilesizeUnits(allowance.ExpectedUpload*uint64(allowance.Period)),
 		modules.FilesizeUnits(allowance.ExpectedDownload*uint64(allowance.Period)),
@@ -661,7 +683,7 @@ to be accessible after a short period of time.`)
 
 // rentersetallowancecmd is the handler for `siac renter setallowance`.
 // set the allowance or modify individual allowance fields.
-func rentersetallowancecmd(_ *cobra.Command, _ []string) {
+func rentersetallowancecmd(cmd *cobra.Command, _ []string) {
 	// Get the current period setting.
 	rg, err := httpClient.RenterGet()
 	if err != nil {
@@ -862,6 +884,11 @@ func rentersetallowancecmd(_ *cobra.Command, _ []string) {
 		req = req.WithMaxUploadBandwidthPrice(price)
 		changedFields++
 	}
+	// parse fundingaccount
+	if cmd.Flags().Changed("funding-account") {
+		req = req.WithFundingAccount(allowanceFundingAccount)
+		changedFields++
+	}
 
 	// check if any fields were updated.
 	if changedFields == 0 {
@@ -1852,6 +1879,21 @@ func renterfilesrenamecmd(path, newpath string) {
 	fmt.Printf("Renamed %s to %s\n", path, newpath)
 }
 
+// renterimportcmd is the handler for the command `siac renter import
+// [descriptor] [sharingkey] [path]`. It imports a file shared by another
+// renter.
+func renterimportcmd(descriptor, sharingKey, path string) {
+	siaPath, err := modules.NewSiaPath(path)
+	if err != nil {
+		die("Couldn't parse SiaPath:", err)
+	}
+	err = httpClient.RenterImportPost(siaPath, descriptor, sharingKey, renterImportForce)
+	if err != nil {
+		die("Could not import file:", err)
+	}
+	fmt.Printf("Imported shared file to %s\n", path)
+}
+
 // renterfusecmd displays the list of directories that are currently mounted via
 // fuse.
 func renterfusecmd() {
@@ -1909,7 +1951,7 @@ func renterfusemountcmd(path, siaPathStr string) {
 		}
 	}
 	opts := modules.MountOptions{
-		ReadOnly:   true,
+		ReadOnly:   renterFuseMountReadOnly,
 		AllowOther: renterFuseMountAllowOther,
 	}
 	err = httpClient.RenterFuseMount(path, siaPath, opts)
@@ -1946,6 +1988,24 @@ func rentersetlocalpathcmd(siapath, newlocalpath string) {
 	fmt.Printf("Updated %s localpath to %s\n", siapath, newlocalpath)
 }
 
+// rentersharecmd is the handler for the command `siac renter share [path]`. It
+// prints the share descriptor and sharing key of a file.
+func rentersharecmd(path string) {
+	siaPath, err := modules.NewSiaPath(path)
+	if err != nil {
+		die("Couldn't parse SiaPath:", err)
+	}
+	rsg, err := httpClient.RenterShareGet(siaPath)
+	if err != nil {
+		die("Could not share file:", err)
+	}
+	fmt.Printf(`Descriptor:  %v
+Sharing Key: %v
+
+Import the file using 'siac renter import [descriptor] [sharingkey] [path]'.
+`, rsg.Descriptor, rsg.SharingKey)
+}
+
 // renterfilesunstuckcmd is the handler for the command `siac renter
 // unstuckall`. Sets all files to unstuck.
 func renterfilesunstuckcmd() {
@@ -2019,7 +2079,7 @@ func renterfilesuploadcmd(source, path string) {
 
 	if stat.IsDir() {
 		// folder
-		var files []string
+		var files, packedFiles []string
 		err := filepath.Walk(source, func(path string, info os.FileInfo, err error) error {
 			if err != nil {
 				fmt.Println("Warning: skipping file:", err)
@@ -2028,15 +2088,40 @@ func renterfilesuploadcmd(source, path string) {
 			if info.IsDir() {
 				return nil
 			}
+			if renterUploadPack && uint64(info.Size()) <= modules.SectorSize {
+				packedFiles = append(packedFiles, path)
+				return nil
+			}
 			files = append(files, path)
 			return nil
 		})
 		if err != nil {
 			die("Could not read folder:", err)
-		} else if len(files) == 0 {
+		} else if len(files)+len(packedFiles) == 0 {
 			die("Nothing to upload.")
 		}
 		failed := 0
+		var packed []api.RenterUploadPackedFile
+		for _, file := range packedFiles {
+			fpath, _ := filepath.Rel(source, file)
+			fpath = filepath.Join(path, fpath)
+			packed = append(packed, api.RenterUploadPackedFile{
+				Source:  abs(file),
+				SiaPath: filepath.ToSlash(fpath),
+			})
+		}
+		for len(packed) > 0 {
+			batch := packed
+			if len(batch) > RenterUploadPackBatchSize {
+				batch = batch[:RenterUploadPackBatchSize]
+			}
+			packed = packed[len(batch):]
+			err = httpClient.RenterUploadPackedPost(batch, uint64(numDataPieces), uint64(numParityPieces), false)
+			if err != nil {
+				failed += len(batch)
+				fmt.Printf("Could not upload %d packed files: %v\n", len(batch), err)
+			}
+		}
 		for _, file := range files {
 			fpath, _ := filepath.Rel(source, file)
 			fpath = filepath.Join(path, fpath)
@@ -2052,7 +2137,8 @@ func renterfilesuploadcmd(source, path string) {
 				fmt.Printf("Could not upload file %s :%v\n", file, err)
 			}
 		}
-		fmt.Printf("\nUploaded %d of %d files into '%s'.\n", len(files)-failed, len(files), path)
+		total := len(files) + len(packedFiles)
+		fmt.Printf("\nUploaded %d of %d files into '%s'.\n", total-failed, total, path)
 	} else {
 		// single file
 		// Parse SiaPath.
@@ -2060,7 +2146,14 @@ func renterfilesuploadcmd(source, path string) {
 		if err != nil {
 			die("Couldn't parse SiaPath:", err)
 		}
-		err = httpClient.RenterUploadPost(abs(source), siaPath, uint64(numDataPieces), uint64(numParityPieces))
+		if renterUploadPack && uint64(stat.Size()) <= modules.SectorSize {
+			err = httpClient.RenterUploadPackedPost([]api.RenterUploadPackedFile{{
+				Source:  abs(source),
+				SiaPath: siaPath.String(),
+			}}, uint64(numDataPieces), uint64(numParityPieces), false)
+		} else {
+			err = httpClient.RenterUploadPost(abs(source), siaPath, uint64(numDataPieces), uint64(numParityPieces))
+		}
 		if err != nil {
 			die("Could not upload file:", err)
 		}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/modules/renter"
+	"go.sia.tech/siad/types"
+)
+
+var (
+	renterRegistryCmd = &cobra.Command{
+		Use:   "registry",
+		Short: "Read and update registry entries",
+		Long: `Read and update registry entries on the hosts the renter has contracts
+with. An entry is identified by the public key of its owner and a 32 byte data
+key, both encoded in hex.`,
+		// Run field not provided; registry requires a subcommand.
+	}
+
+	renterRegistryGetCmd = &cobra.Command{
+		Use:   "get [publickey] [datakey]",
+		Short: "Read a registry entry",
+		Long: `Read the registry entry with the highest revision number for the given
+public key (e.g. ed25519:<hex>) and data key.`,
+		Run: wrap(renterregistrygetcmd),
+	}
+
+	renterRegistrySetCmd = &cobra.Command{
+		Use:   "set [secretkey] [datakey] [data]",
+		Short: "Update a registry entry",
+		Long: `Update the registry entry for the given data key with the provided data.
+The entry is signed with the hex encoded ed25519 secret key and its revision
+number is set to one higher than the revision currently stored on the hosts.
+The data may be at most 113 bytes.`,
+		Run: wrap(renterregistrysetcmd),
+	}
+
+	renterRegistrySubscribeCmd = &cobra.Command{
+		Use:   "subscribe [publickey] [datakey]",
+		Short: "Print updates of a registry entry",
+		Long: `Subscribe to the registry entry for the given public key and data key
+and print it whenever its revision number increases. The command runs until
+interrupted.`,
+		Run: wrap(renterregistrysubscribecmd),
+	}
+)
+
+// renterregistrygetcmd is the handler for the command `siac renter registry
+// get [publickey] [datakey]`.
+func renterregistrygetcmd(pubKeyStr, dataKeyStr string) {
+	spk, dataKey := parseRegistryKey(pubKeyStr, dataKeyStr)
+	srv, err := httpClient.RenterRegistryRead(spk, dataKey)
+	if err != nil {
+		die("Could not read registry entry:", err)
+	}
+	printRegistryValue(srv)
+}
+
+// renterregistrysetcmd is the handler for the command `siac renter registry
+// set [secretkey] [datakey] [data]`.
+func renterregistrysetcmd(secretKeyStr, dataKeyStr, data string) {
+	// Parse the secret key.
+	skBytes, err := hex.DecodeString(secretKeyStr)
+	if err != nil {
+		die("Could not decode secret key:", err)
+	}
+	var sk crypto.SecretKey
+	if len(skBytes) != len(sk) {
+		die(fmt.Sprintf("Secret key must be %v bytes long", len(sk)))
+	}
+	copy(sk[:], skBytes)
+	spk := types.Ed25519PublicKey(sk.PublicKey())
+
+	// Parse the data key.
+	var dataKey crypto.Hash
+	err = dataKey.LoadString(dataKeyStr)
+	if err != nil {
+		die("Could not parse data key:", err)
+	}
+	if len(data) > modules.RegistryDataSize {
+		die(fmt.Sprintf("Data can't be larger than %v bytes", modules.RegistryDataSize))
+	}
+
+	// Determine the next revision number.
+	var revision uint64
+	srv, err := httpClient.RenterRegistryRead(spk, dataKey)
+	if err == nil {
+		revision = srv.Revision + 1
+	} else if !strings.Contains(err.Error(), renter.ErrRegistryEntryNotFound.Error()) &&
+		!strings.Contains(err.Error(), renter.ErrRegistryLookupTimeout.Error()) {
+		die("Could not read current registry entry:", err)
+	}
+
+	// Sign and update the entry.
+	srv = modules.NewRegistryValue(dataKey, []byte(data), revision).Sign(sk)
+	err = httpClient.RenterRegistryUpdate(spk, srv)
+	if err != nil {
+		die("Could not update registry entry:", err)
+	}
+	fmt.Printf("Updated registry entry for %v to revision %v\n", spk, revision)
+}
+
+// renterregistrysubscribecmd is the handler for the command `siac renter
+// registry subscribe [publickey] [datakey]`.
+func renterregistrysubscribecmd(pubKeyStr, dataKeyStr string) {
+	spk, dataKey := parseRegistryKey(pubKeyStr, dataKeyStr)
+	sub, err := httpClient.RenterRegistrySubscribe(spk, dataKey)
+	if err != nil {
+		die("Could not subscribe to registry entry:", err)
+	}
+	defer sub.Close()
+	for {
+		srv, err := sub.Next()
+		if err == io.EOF {
+			die("Subscription was closed by siad")
+		}
+		if err != nil {
+			die("Could not receive registry update:", err)
+		}
+		printRegistryValue(srv)
+		fmt.Println()
+	}
+}
+
+// parseRegistryKey parses the public key and data key of a registry entry.
+func parseRegistryKey(pubKeyStr, dataKeyStr string) (types.SiaPublicKey, crypto.Hash) {
+	var spk types.SiaPublicKey
+	err := spk.LoadString(pubKeyStr)
+	if err != nil {
+		die("Could not parse public key:", err)
+	}
+	var dataKey crypto.Hash
+	err = dataKey.LoadString(dataKeyStr)
+	if err != nil {
+		die("Could not parse data key:", err)
+	}
+	return spk, dataKey
+}
+
+// printRegistryValue prints a signed registry value.
+func printRegistryValue(srv modules.SignedRegistryValue) {
+	fmt.Printf("Revision:  %v\n", srv.Revision)
+	fmt.Printf("Data:      %v\n", hex.EncodeToString(srv.Data))
+	if utf8.Valid(srv.Data) {
+		fmt.Printf("Text:      %v\n", string(srv.Data))
+	}
+	fmt.Printf("Signature: %v\n", hex.EncodeToString(srv.Signature[:]))
+}
@@ -1,14 +1,18 @@
 package main
 
 import (
+	"bytes"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"math"
 	"math/big"
 	"os"
 	"strconv"
 	"strings"
 	"syscall"
+	"text/tabwriter"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -26,6 +30,68 @@ import (
 )
 
 var (
+	walletAccountsCmd = &cobra.Command{
+		Use:   "accounts",
+		Short: "List and manage wallet accounts",
+		Long: `List the accounts of the wallet and their confirmed balances.
+An account is a named set of wallet addresses with a separate balance. Sending
+from an account only spends its outputs and the change goes back to it. Outputs
+which don't belong to an account are spent when no account is selected.`,
+		Run: wrap(walletaccountscmd),
+	}
+
+	walletAccountsAddCmd = &cobra.Command{
+		Use:   "add [name]",
+		Short: "Create an account",
+		Long:  "Create an empty wallet account.",
+		Run:   wrap(walletaccountsaddcmd),
+	}
+
+	walletAccountsAddressCmd = &cobra.Command{
+		Use:   "address [name]",
+		Short: "Get a new address of an account",
+		Long:  "Generate a new address from the wallet's primary seed and assign it to the account.",
+		Run:   wrap(walletaccountsaddresscmd),
+	}
+
+	walletAccountsAssignCmd = &cobra.Command{
+		Use:   "assign [name] [address]...",
+		Short: "Assign addresses to an account",
+		Long: `Assign addresses of the wallet to an account. Addresses which are
+assigned to another account are moved to this account.`,
+		Run: walletaccountsassigncmd,
+	}
+
+	walletAccountsRemoveCmd = &cobra.Command{
+		Use:   "remove [name]",
+		Short: "Remove an account",
+		Long: `Remove an account. Its addresses are no longer assigned to an account and
+their outputs are spent like any other output of the wallet.`,
+		Run: wrap(walletaccountsremovecmd),
+	}
+
+	walletAccountsSeedCmd = &cobra.Command{
+		Use:   "seed [name]",
+		Short: "Assign a seed to an account",
+		Long: `Assign all addresses of an auxiliary seed to an account. The seed has to be
+loaded using 'siac wallet load seed' first.`,
+		Run: wrap(walletaccountsseedcmd),
+	}
+
+	walletAccountsShowCmd = &cobra.Command{
+		Use:   "show [name]",
+		Short: "Show the addresses of an account",
+		Long:  "Show the balance of an account and the addresses assigned to it.",
+		Run:   wrap(walletaccountsshowcmd),
+	}
+
+	walletAccountsUnassignCmd = &cobra.Command{
+		Use:   "unassign [name] [address]...",
+		Short: "Remove addresses from an account",
+		Long:  "Remove addresses from an account. Their outputs are spent like any other output of the wallet.",
+		Run:   walletaccountsunassigncmd,
+	}
+
 	walletAddressCmd = &cobra.Command{
 		Use:   "address",
 		Short: "Get a new wallet address",
@@ -55,6 +121,17 @@ be valid. txn may be either JSON, base64, or a file containing either.`,
 		Run: wrap(walletbroadcastcmd),
 	}
 
+	walletBumpCmd = &cobra.Command{
+		Use:   "bump [txid]",
+		Short: "Bump the fee of an unconfirmed transaction",
+		Long: `Raise the fee of the unconfirmed transaction set containing the transaction.
+The 'cpfp' method adds a child transaction which spends the change output of
+the set. The 'replace' method re-signs the set with a higher fee, which is only
+possible if the whole set was created by the wallet. If no method is supplied,
+'cpfp' is used if the set has a change output.`,
+		Run: wrap(walletbumpcmd),
+	}
+
 	walletChangepasswordCmd = &cobra.Command{
 		Use:   "change-password",
 		Short: "Change the wallet password",
@@ -81,6 +158,21 @@ The smallest unit of siacoins is the hasting. One siacoin is 10^24 hastings. Oth
 		Run: wrap(walletbalancecmd),
 	}
 
+	walletExportCmd = &cobra.Command{
+		Use:   "export",
+		Short: "Export the wallet's transaction history",
+		Long: `Export the confirmed transactions between --startheight and --endheight to
+stdout. Every transaction contains its confirmation height and time, the inputs
+and outputs which belong to the wallet's addresses, the miner fees paid by the
+wallet, the net siacoin value and the ids of related file contracts.
+
+The --format flag selects csv (default), json or ofx. The csv and json formats
+report currency values in hastings, ofx reports siacoins with the currency code
+XSC.`,
+		Example: "siac wallet export --format ofx > sia.ofx",
+		Run:     wrap(walletexportcmd),
+	}
+
 	walletInitCmd = &cobra.Command{
 		Use:   "init",
 		Short: "Initialize and encrypt a new wallet",
@@ -125,6 +217,30 @@ By default the wallet encryption / unlock password is the same as the generated
 		Run:     wrap(walletloadsiagcmd),
 	}
 
+	walletLabelCmd = &cobra.Command{
+		Use:   "label",
+		Short: "View and manage address and transaction labels",
+		Long: `List the names given to addresses and the notes and tags attached to
+transactions. Use the subcommands to change labels.`,
+		Run: wrap(walletlabelcmd),
+	}
+
+	walletLabelAddressCmd = &cobra.Command{
+		Use:   "address [address] [name]",
+		Short: "Name an address",
+		Long: `Set the name of an address of the wallet or of a counterparty. Omitting the
+name removes the label.`,
+		Run: walletlabeladdresscmd,
+	}
+
+	walletLabelTransactionCmd = &cobra.Command{
+		Use:   "transaction [txid] [note]",
+		Short: "Label a transaction",
+		Long: `Attach a note and tags to a transaction, replacing its existing label.
+Omitting both the note and the tags removes the label.`,
+		Run: walletlabeltransactioncmd,
+	}
+
 	walletLockCmd = &cobra.Command{
 		Use:   "lock",
 		Short: "Lock the wallet",
@@ -132,6 +248,73 @@ By default the wallet encryption / unlock password is the same as the generated
 		Run:   wrap(walletlockcmd),
 	}
 
+	walletRatesCmd = &cobra.Command{
+		Use:   "rates [symbol]",
+		Short: "View and manage historical exchange rates",
+		Long: `List the stored historical exchange rates of a currency, or of all currencies
+if no symbol is given. The wallet uses them to value transactions in fiat
+currencies.`,
+		Run: walletratescmd,
+	}
+
+	walletRatesAddCmd = &cobra.Command{
+		Use:   "add [time] [rate]",
+		Short: "Add an exchange rate",
+		Long: `Add the exchange rate of one siacoin at a point in time. The time is a unix
+timestamp, a date like 2021-03-01 or an RFC 3339 time. The rate includes the
+currency symbol, e.g. "0.012 USD".`,
+		Example: `siac wallet rates add 2021-03-01 "0.012 USD"`,
+		Run:     wrap(walletratesaddcmd),
+	}
+
+	walletRatesImportCmd = &cobra.Command{
+		Use:   "import [file] [symbol]",
+		Short: "Import exchange rates from a CSV file",
+		Long: `Import historical exchange rates of a currency from a CSV file with the
+columns time and rate. The time is a unix timestamp, a date like 2021-03-01 or
+an RFC 3339 time and the rate is the value of one siacoin. A header row is
+skipped.`,
+		Example: "siac wallet rates import sc-usd.csv USD",
+		Run:     wrap(walletratesimportcmd),
+	}
+
+	walletReportCmd = &cobra.Command{
+		Use:   "report [symbol]",
+		Short: "Report the fiat value of transactions",
+		Long: `Value the transactions confirmed in a time range using the stored exchange
+rates and summarize the received and sent value and the realized gains by day,
+month or year. Realized gains match sent siacoins with the siacoins received
+first. Siacoins received without a known exchange rate have a cost basis of
+zero.`,
+		Example: "siac wallet report USD --start 2021-01-01 --end 2022-01-01",
+		Run:     wrap(walletreportcmd),
+	}
+
+	walletScheduleCmd = &cobra.Command{
+		Use:   "schedule",
+		Short: "View and manage scheduled payments",
+		Long: `List the payments which the wallet sends at a block height or repeatedly
+every few blocks. Failed payments are retried on every block and raise an
+alert.`,
+		Run: wrap(walletschedulecmd),
+	}
+
+	walletScheduleAddCmd = &cobra.Command{
+		Use:   "add [amount] [dest] [height]",
+		Short: "Schedule a payment",
+		Long: `Send amount siacoins to dest once the blockchain reaches height. The amount
+is in the form XXXXUU, where U is a unit, e.g. 10SC. If an interval is set, the
+payment is repeated every interval blocks. A month is roughly 4320 blocks.`,
+		Run: wrap(walletscheduleaddcmd),
+	}
+
+	walletScheduleRemoveCmd = &cobra.Command{
+		Use:   "remove [id]",
+		Short: "Cancel a scheduled payment",
+		Long:  "Cancel and remove a scheduled payment.",
+		Run:   wrap(walletscheduleremovecmd),
+	}
+
 	walletSeedsCmd = &cobra.Command{
 		Use:   "seeds",
 		Short: "View information about your seeds",
@@ -139,6 +322,94 @@ By default the wallet encryption / unlock password is the same as the generated
 		Run:   wrap(walletseedscmd),
 	}
 
+	walletMultisigCmd = &cobra.Command{
+		Use:   "multisig",
+		Short: "Manage multisig addresses",
+		Long: `Create and list M-of-N multisig addresses which contain a key of the wallet.
+The wallet tracks the balance and transactions of its multisig addresses and
+adds its signatures when signing transactions which spend from them.`,
+		// Run field is not set, as the multisig command itself is not a valid
+		// command. A subcommand must be provided.
+	}
+
+	walletMultisigCreateCmd = &cobra.Command{
+		Use:   "create [required] [publickey]...",
+		Short: "Create a multisig address",
+		Long: `Create a multisig address which requires 'required' signatures of the
+public keys. At least one of the public keys has to belong to the wallet. All
+parties need to use the same order of public keys to create the same address.
+Public keys are in the form ed25519:<hex>, use 'siac wallet multisig publickey'
+to get a public key of the wallet.`,
+		Run: walletmultisigcreatecmd,
+	}
+
+	walletMultisigListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List multisig addresses",
+		Long:  "List the multisig addresses of the wallet and their confirmed balances.",
+		Run:   wrap(walletmultisiglistcmd),
+	}
+
+	walletMultisigPublicKeyCmd = &cobra.Command{
+		Use:   "publickey",
+		Short: "Get a new public key of the wallet",
+		Long:  "Generate a new wallet address and print its public key to share it with the other parties of a multisig address.",
+		Run:   wrap(walletmultisigpublickeycmd),
+	}
+
+	walletPartialCmd = &cobra.Command{
+		Use:   "partial",
+		Short: "Coordinate partially signed transactions",
+		Long: `Create, sign, merge and finalize partially signed transactions. A partially
+signed transaction holds a transaction, its parents, the unlock conditions of
+every input and the signatures collected so far. It can be passed between the
+signers of a multisig transaction until enough signatures are collected.
+
+Partially signed transactions may be either JSON, base64, or a file containing
+either. They are printed as JSON unless --raw is supplied.`,
+		// Run field is not set, as the partial command itself is not a valid
+		// command. A subcommand must be provided.
+	}
+
+	walletPartialCreateCmd = &cobra.Command{
+		Use:   "create [txn] [parents]",
+		Short: "Create a partially signed transaction",
+		Long: `Create a partially signed transaction from a transaction and its optional
+unconfirmed parents. Signatures which are already part of the transaction are
+kept.`,
+		Run: walletpartialcreatecmd,
+	}
+
+	walletPartialFinalizeCmd = &cobra.Command{
+		Use:   "finalize [partialtxn]",
+		Short: "Finalize a partially signed transaction",
+		Long: `Create the signed transaction from a partially signed transaction with enough
+signatures. The transaction is broadcast if --broadcast is supplied and printed
+otherwise.`,
+		Run: wrap(walletpartialfinalizecmd),
+	}
+
+	walletPartialMergeCmd = &cobra.Command{
+		Use:   "merge [partialtxn] [partialtxn]...",
+		Short: "Merge partially signed transactions",
+		Long:  "Merge the signatures of partially signed versions of the same transaction.",
+		Run:   walletpartialmergecmd,
+	}
+
+	walletPartialSignCmd = &cobra.Command{
+		Use:   "sign [partialtxn]",
+		Short: "Sign a partially signed transaction",
+		Long:  "Add all signatures which the wallet can create to a partially signed transaction.",
+		Run:   wrap(walletpartialsigncmd),
+	}
+
+	walletPartialStatusCmd = &cobra.Command{
+		Use:   "status [partialtxn]",
+		Short: "Show the signatures of a partially signed transaction",
+		Long:  "Show how many signatures are required and collected for every input.",
+		Run:   wrap(walletpartialstatuscmd),
+	}
+
 	walletSendCmd = &cobra.Command{
 		Use:   "send",
 		Short: "Send either siacoins or siafunds to an address",
@@ -189,11 +460,48 @@ will be sent to your wallet.`,
 		Run: wrap(walletsweepcmd),
 	}
 
+	walletTimelockCmd = &cobra.Command{
+		Use:   "timelock",
+		Short: "Manage time-locked addresses",
+		Long: `List the time-locked addresses of the wallet and the outputs which are
+pending unlock. Outputs of a time-locked address can't be spent before its
+unlock height. Afterwards they are spent like any other output of the wallet.`,
+		Run: wrap(wallettimelockcmd),
+	}
+
+	walletTimelockAddCmd = &cobra.Command{
+		Use:   "add [publickey] [height]",
+		Short: "Add a time-locked address",
+		Long: `Add the time-locked address of a public key of the wallet, e.g. to receive a
+payment which was sent using 'siac wallet timelock send'. The blockchain is
+rescanned unless --unused is set.`,
+		Run: wrap(wallettimelockaddcmd),
+	}
+
+	walletTimelockAddressCmd = &cobra.Command{
+		Use:   "address [height]",
+		Short: "Get a new time-locked address",
+		Long:  "Generate a new address of the wallet whose outputs can't be spent before 'height'.",
+		Run:   wrap(wallettimelockaddresscmd),
+	}
+
+	walletTimelockSendCmd = &cobra.Command{
+		Use:   "send [amount] [publickey] [height]",
+		Short: "Send siacoins which unlock at a height",
+		Long: `Send siacoins to a public key. The recipient can't spend them before 'height'.
+The public key is in the form ed25519:<hex>, the recipient can get one using
+'siac wallet multisig publickey'. 'amount' can be specified in units, e.g.
+1.23KS.`,
+		Run: wrap(wallettimelocksendcmd),
+	}
+
 	walletTransactionsCmd = &cobra.Command{
 		Use:   "transactions",
 		Short: "View transactions",
-		Long:  "View transactions related to addresses spendable by the wallet, providing a net flow of siacoins and siafunds for each transaction",
-		Run:   wrap(wallettransactionscmd),
+		Long: `View transactions related to addresses spendable by the wallet, providing a net flow of siacoins and siafunds for each transaction.
+Notes and tags of transactions and the names of the involved addresses are
+shown below each transaction.`,
+		Run: wrap(wallettransactionscmd),
 	}
 
 	walletUnlockCmd = &cobra.Command{
@@ -236,6 +544,129 @@ func confirmPassword(prev string) error {
 	return nil
 }
 
+// walletaccountscmd lists the accounts of the wallet and their balances.
+func walletaccountscmd() {
+	wag, err := httpClient.WalletAccountsGet()
+	if err != nil {
+		die("Could not get accounts:", err)
+	}
+	wg, err := httpClient.WalletGet()
+	if err != nil {
+		die("Could not get wallet balance:", err)
+	}
+	// The remaining balance doesn't belong to an account.
+	siacoins, siafunds := wg.ConfirmedSiacoinBalance, wg.SiafundBalance
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Account\tAddresses\tSiacoins\tSiafunds")
+	for _, account := range wag.Accounts {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\n", account.Name, account.NumAddresses, currencyUnits(account.ConfirmedSiacoinBalance), account.ConfirmedSiafundBalance)
+		if siacoins.Cmp(account.ConfirmedSiacoinBalance) >= 0 {
+			siacoins = siacoins.Sub(account.ConfirmedSiacoinBalance)
+		}
+		if siafunds.Cmp(account.ConfirmedSiafundBalance) >= 0 {
+			siafunds = siafunds.Sub(account.ConfirmedSiafundBalance)
+		}
+	}
+	fmt.Fprintf(w, "(none)\t-\t%v\t%v\n", currencyUnits(siacoins), siafunds)
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer:", err)
+	}
+}
+
+// walletaccountsaddcmd creates an account.
+func walletaccountsaddcmd(name string) {
+	if err := httpClient.WalletAccountsAddPost(name); err != nil {
+		die("Could not create account:", err)
+	}
+	fmt.Printf("Created account %v\n", name)
+}
+
+// walletaccountsaddresscmd prints a new address of an account.
+func walletaccountsaddresscmd(name string) {
+	wag, err := httpClient.WalletAccountAddressGet(name)
+	if err != nil {
+		die("Could not generate new address:", err)
+	}
+	fmt.Printf("Created new address for account %v: %s\n", name, wag.Address)
+}
+
+// parseAddresses parses the addresses of the arguments.
+func parseAddresses(args []string) []types.UnlockHash {
+	addrs := make([]types.UnlockHash, 0, len(args))
+	for _, arg := range args {
+		var addr types.UnlockHash
+		if err := addr.LoadString(arg); err != nil {
+			die("Could not parse address:", err)
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs
+}
+
+// walletaccountsassigncmd assigns addresses to an account.
+func walletaccountsassigncmd(cmd *cobra.Command, args []string) {
+	if len(args) < 2 {
+		_ = cmd.UsageFunc()(cmd)
+		os.Exit(exitCodeUsage)
+	}
+	addrs := parseAddresses(args[1:])
+	if err := httpClient.WalletAccountAssignPost(args[0], addrs); err != nil {
+		die("Could not assign addresses:", err)
+	}
+	fmt.Printf("Assigned %v addresses to account %v\n", len(addrs), args[0])
+}
+
+// walletaccountsremovecmd removes an account.
+func walletaccountsremovecmd(name string) {
+	if err := httpClient.WalletAccountsRemovePost(name); err != nil {
+		die("Could not remove account:", err)
+	}
+	fmt.Printf("Removed account %v\n", name)
+}
+
+// walletaccountsseedcmd assigns the addresses of an auxiliary seed to an
+// account.
+func walletaccountsseedcmd(name string) {
+	seed, err := passwordPrompt("Seed: ")
+	if err != nil {
+		die("Reading seed failed:", err)
+	}
+	if err := httpClient.WalletAccountSeedPost(name, seed); err != nil {
+		die("Could not assign seed:", err)
+	}
+	fmt.Printf("Assigned seed to account %v\n", name)
+}
+
+// walletaccountsshowcmd prints the balance and the addresses of an account.
+func walletaccountsshowcmd(name string) {
+	wag, err := httpClient.WalletAccountGet(name)
+	if err != nil {
+		die("Could not get account:", err)
+	}
+	fmt.Printf(`Account:     %v
+Created at:  height %v
+Siacoins:    %v
+Siafunds:    %v SF
+Addresses:   %v
+`, wag.Name, wag.CreationHeight, currencyUnits(wag.ConfirmedSiacoinBalance), wag.ConfirmedSiafundBalance, wag.NumAddresses)
+	for _, addr := range wag.Addresses {
+		fmt.Println(addr)
+	}
+}
+
+// walletaccountsunassigncmd removes addresses from an account.
+func walletaccountsunassigncmd(cmd *cobra.Command, args []string) {
+	if len(args) < 2 {
+		_ = cmd.UsageFunc()(cmd)
+		os.Exit(exitCodeUsage)
+	}
+	addrs := parseAddresses(args[1:])
+	if err := httpClient.WalletAccountUnassignPost(args[0], addrs); err != nil {
+		die("Could not remove addresses:", err)
+	}
+	fmt.Printf("Removed %v addresses from account %v\n", len(addrs), args[0])
+}
+
 // walletaddresscmd fetches a new address from the wallet that will be able to
 // receive coins.
 func walletaddresscmd() {
@@ -276,6 +707,50 @@ func walletchangepasswordcmd() {
 	fmt.Println("Password changed successfully.")
 }
 
+// walletexportcmd exports the wallet's transaction history. The transactions
+// are requested in pages of WalletExportPageBlocks blocks.
+func walletexportcmd() {
+	wg, err := httpClient.WalletGet()
+	if err != nil {
+		die("Could not get wallet status:", err)
+	}
+	start, end := types.BlockHeight(walletStartHeight), types.BlockHeight(walletEndHeight)
+	if end > wg.Height {
+		end = wg.Height
+	}
+	if start > end {
+		die("startheight must not be greater than endheight or the wallet height")
+	}
+	txns := []modules.ExportTransaction{}
+	for pageStart := start; pageStart <= end; pageStart += WalletExportPageBlocks {
+		pageEnd := pageStart + WalletExportPageBlocks - 1
+		if pageEnd > end {
+			pageEnd = end
+		}
+		weg, err := httpClient.WalletExportGet(pageStart, pageEnd)
+		if err != nil {
+			die("Could not get transactions:", err)
+		}
+		txns = append(txns, weg.Transactions...)
+	}
+
+	switch walletExportFormat {
+	case modules.ExportFormatCSV:
+		err = api.WriteExportCSV(os.Stdout, txns)
+	case modules.ExportFormatJSON:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(txns)
+	case modules.ExportFormatOFX:
+		err = api.WriteExportOFX(os.Stdout, txns, wg.ConfirmedSiacoinBalance, types.CurrentTimestamp())
+	default:
+		die(fmt.Sprintf("Unknown format '%v', must be one of %v, %v or %v", walletExportFormat, modules.ExportFormatCSV, modules.ExportFormatJSON, modules.ExportFormatOFX))
+	}
+	if err != nil {
+		die("Could not export transactions:", err)
+	}
+}
+
 // walletinitcmd encrypts the wallet with the given password
 func walletinitcmd() {
 	var password string
@@ -369,6 +844,83 @@ func walletloadsiagcmd(keyfiles string) {
 	fmt.Println("Wallet loading successful.")
 }
 
+// walletlabelcmd lists the address and transaction labels of the wallet.
+func walletlabelcmd() {
+	wlg, err := httpClient.WalletLabelsGet()
+	if err != nil {
+		die("Could not get labels:", err)
+	}
+	if len(wlg.AddressLabels) == 0 && len(wlg.TransactionLabels) == 0 {
+		fmt.Println("No labels.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if len(wlg.AddressLabels) > 0 {
+		fmt.Fprintln(w, "Address\tName")
+		for _, al := range wlg.AddressLabels {
+			fmt.Fprintf(w, "%v\t%v\n", al.Address, al.Name)
+		}
+		fmt.Fprintln(w)
+	}
+	if len(wlg.TransactionLabels) > 0 {
+		fmt.Fprintln(w, "Transaction\tNote\tTags")
+		for _, tl := range wlg.TransactionLabels {
+			fmt.Fprintf(w, "%v\t%v\t%v\n", tl.TransactionID, tl.Note, strings.Join(tl.Tags, ", "))
+		}
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer:", err)
+	}
+}
+
+// walletlabeladdresscmd sets the name of an address.
+func walletlabeladdresscmd(cmd *cobra.Command, args []string) {
+	if len(args) < 1 || len(args) > 2 {
+		_ = cmd.UsageFunc()(cmd)
+		os.Exit(exitCodeUsage)
+	}
+	var addr types.UnlockHash
+	if err := addr.LoadString(args[0]); err != nil {
+		die("Could not parse address:", err)
+	}
+	var name string
+	if len(args) == 2 {
+		name = args[1]
+	}
+	err := httpClient.WalletLabelsPost([]modules.AddressLabel{{Address: addr, Name: name}}, nil)
+	if err != nil {
+		die("Could not set address label:", err)
+	}
+	if name == "" {
+		fmt.Println("Removed label of", addr)
+	} else {
+		fmt.Printf("Labelled %v as %q\n", addr, name)
+	}
+}
+
+// walletlabeltransactioncmd sets the note and tags of a transaction.
+func walletlabeltransactioncmd(cmd *cobra.Command, args []string) {
+	if len(args) < 1 || len(args) > 2 {
+		_ = cmd.UsageFunc()(cmd)
+		os.Exit(exitCodeUsage)
+	}
+	label := modules.TransactionLabel{}
+	if err := (*crypto.Hash)(&label.TransactionID).LoadString(args[0]); err != nil {
+		die("Could not parse transaction id:", err)
+	}
+	if len(args) == 2 {
+		label.Note = args[1]
+	}
+	if walletLabelTags != "" {
+		label.Tags = strings.Split(walletLabelTags, ",")
+	}
+	err := httpClient.WalletLabelsPost(nil, []modules.TransactionLabel{label})
+	if err != nil {
+		die("Could not set transaction label:", err)
+	}
+	fmt.Println("Updated label of", label.TransactionID)
+}
+
 // walletlockcmd locks the wallet
 func walletlockcmd() {
 	err := httpClient.WalletLockPost()
@@ -400,6 +952,198 @@ func walletseedscmd() {
 	}
 }
 
+// walletbumpcmd bumps the fee of an unconfirmed transaction.
+func walletbumpcmd(txidStr string) {
+	var txid types.TransactionID
+	if err := (*crypto.Hash)(&txid).LoadString(txidStr); err != nil {
+		die("Could not parse transaction id:", err)
+	}
+	var feePerByte types.Currency
+	if walletBumpFee != "" {
+		hastings, err := types.ParseCurrency(walletBumpFee)
+		if err != nil {
+			die("Could not parse fee:", err)
+		}
+		if _, err := fmt.Sscan(hastings, &feePerByte); err != nil {
+			die("Failed to parse fee", err)
+		}
+	}
+	wbp, err := httpClient.WalletBumpPost(txid, modules.FeeBumpMethod(walletBumpMethod), feePerByte)
+	if err != nil {
+		die("Could not bump transaction fee:", err)
+	}
+	fmt.Printf("Bumped fee of %v using %v\n", txid, wbp.Method)
+	for _, id := range wbp.TransactionIDs {
+		fmt.Println("  ", id)
+	}
+}
+
+// walletratescmd lists the stored exchange rates.
+func walletratescmd(cmd *cobra.Command, args []string) {
+	if len(args) > 1 {
+		_ = cmd.UsageFunc()(cmd)
+		os.Exit(exitCodeUsage)
+	}
+	var symbol string
+	if len(args) == 1 {
+		symbol = args[0]
+	}
+	werg, err := httpClient.WalletExchangeRatesGet(symbol)
+	if err != nil {
+		die("Could not get exchange rates:", err)
+	}
+	if len(werg.ExchangeRates) == 0 {
+		fmt.Println("No exchange rates.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Time\tRate")
+	for _, point := range werg.ExchangeRates {
+		fmt.Fprintf(w, "%v\t%v\n", time.Unix(int64(point.Timestamp), 0).UTC().Format(time.RFC3339), point.Rate)
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer:", err)
+	}
+}
+
+// walletratesaddcmd adds an exchange rate.
+func walletratesaddcmd(timeStr, rateStr string) {
+	ts, err := modules.ParseReportTime(timeStr)
+	if err != nil {
+		die("Could not parse time:", err)
+	}
+	rate, err := types.ParseExchangeRate(rateStr)
+	if err != nil || rate == nil {
+		die("Could not parse rate:", err)
+	}
+	err = httpClient.WalletExchangeRatesPost([]modules.ExchangeRatePoint{{Timestamp: ts, Rate: *rate}})
+	if err != nil {
+		die("Could not add exchange rate:", err)
+	}
+	fmt.Printf("Added exchange rate of %v at %v\n", rate, time.Unix(int64(ts), 0).UTC().Format(time.RFC3339))
+}
+
+// walletratesimportcmd imports exchange rates from a CSV file.
+func walletratesimportcmd(path, symbol string) {
+	csv, err := ioutil.ReadFile(path)
+	if err != nil {
+		die("Could not read file:", err)
+	}
+	points, err := modules.ParseExchangeRateCSV(bytes.NewReader(csv), symbol)
+	if err != nil {
+		die("Could not parse file:", err)
+	}
+	err = httpClient.WalletExchangeRatesCSVPost(string(csv), symbol)
+	if err != nil {
+		die("Could not import exchange rates:", err)
+	}
+	fmt.Printf("Imported %v exchange rates\n", len(points))
+}
+
+// walletreportcmd prints a fiat report of the wallet's transactions.
+func walletreportcmd(symbol string) {
+	var start types.Timestamp
+	end := types.CurrentTimestamp()
+	var err error
+	if walletReportStart != "" {
+		start, err = modules.ParseReportTime(walletReportStart)
+		if err != nil {
+			die("Could not parse start:", err)
+		}
+	}
+	if walletReportEnd != "" {
+		end, err = modules.ParseReportTime(walletReportEnd)
+		if err != nil {
+			die("Could not parse end:", err)
+		}
+	}
+	wrg, err := httpClient.WalletReportGet(symbol, start, end, modules.ReportPeriod(walletReportPeriod))
+	if err != nil {
+		die("Could not create report:", err)
+	}
+	if len(wrg.Transactions) == 0 {
+		fmt.Println("No transactions in this time range.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Period\tTransactions\tReceived\tSent\tReceived %[1]v\tSent %[1]v\tRealized Gain %[1]v\n", wrg.Symbol)
+	for _, p := range append(wrg.Periods, wrg.Total) {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\n", p.Period, p.Transactions, currencyUnits(p.Received), currencyUnits(p.Sent), p.FiatReceived, p.FiatSent, p.RealizedGain)
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer:", err)
+	}
+	if wrg.MissingRates > 0 {
+		fmt.Printf("\nNo exchange rate was known for %v transactions, their fiat values are not included.\n", wrg.MissingRates)
+	}
+}
+
+// walletschedulecmd lists the scheduled payments of the wallet.
+func walletschedulecmd() {
+	wsg, err := httpClient.WalletScheduleGet()
+	if err != nil {
+		die("Could not get scheduled payments:", err)
+	}
+	if len(wsg.ScheduledPayments) == 0 {
+		fmt.Println("No scheduled payments.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tAmount\tDestination\tNext Height\tInterval\tPayments\tStatus")
+	for _, sp := range wsg.ScheduledPayments {
+		next := fmt.Sprint(sp.NextHeight)
+		status := "scheduled"
+		if sp.Completed {
+			next = "-"
+			status = "completed"
+		} else if sp.LastError != "" {
+			status = "failed: " + sp.LastError
+		}
+		interval := "once"
+		if sp.Interval != 0 {
+			interval = fmt.Sprintf("%v blocks", sp.Interval)
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\n", sp.ID, currencyUnits(sp.Amount), sp.Destination, next, interval, sp.Payments, status)
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer:", err)
+	}
+}
+
+// walletscheduleaddcmd schedules a payment.
+func walletscheduleaddcmd(amount, dest, heightStr string) {
+	hastings, err := types.ParseCurrency(amount)
+	if err != nil {
+		die("Could not parse amount:", err)
+	}
+	var value types.Currency
+	if _, err := fmt.Sscan(hastings, &value); err != nil {
+		die("Failed to parse amount", err)
+	}
+	var addr types.UnlockHash
+	if err := addr.LoadString(dest); err != nil {
+		die("Failed to parse destination address", err)
+	}
+	height, err := strconv.ParseUint(heightStr, 10, 64)
+	if err != nil {
+		die("Could not parse height:", err)
+	}
+	wsp, err := httpClient.WalletSchedulePost(value, addr, types.BlockHeight(height), types.BlockHeight(walletPayInterval))
+	if err != nil {
+		die("Could not schedule payment:", err)
+	}
+	fmt.Println("Scheduled payment", wsp.ScheduledPayment.ID)
+}
+
+// walletscheduleremovecmd cancels a scheduled payment.
+func walletscheduleremovecmd(id string) {
+	err := httpClient.WalletScheduleIDRemovePost(modules.ScheduledPaymentID(id))
+	if err != nil {
+		die("Could not remove scheduled payment:", err)
+	}
+	fmt.Println("Removed scheduled payment", id)
+}
+
 // walletsendsiacoinscmd sends siacoins to a destination address.
 func walletsendsiacoinscmd(amount, dest string) {
 	hastings, err := types.ParseCurrency(amount)
@@ -414,13 +1158,49 @@ func walletsendsiacoinscmd(amount, dest string) {
 	if _, err := fmt.Sscan(dest, &hash); err != nil {
 		die("Failed to parse destination address", err)
 	}
-	_, err = httpClient.WalletSiacoinsPost(value, hash, walletTxnFeeIncluded)
+	cc, err := parseCoinControl()
+	if err != nil {
+		die("Could not parse coin control:", err)
+	}
+	_, err = httpClient.WalletSiacoinsCoinControlPost(value, hash, walletTxnFeeIncluded, cc)
 	if err != nil {
 		die("Could not send siacoins:", err)
 	}
 	fmt.Printf("Sent %s hastings to %s\n", hastings, dest)
 }
 
+// parseCoinControl parses the coin control flags of 'wallet send siacoins'.
+func parseCoinControl() (cc modules.CoinControl, err error) {
+	parseIDs := func(s string) ([]types.SiacoinOutputID, error) {
+		var ids []types.SiacoinOutputID
+		for _, idStr := range strings.Split(s, ",") {
+			if idStr = strings.TrimSpace(idStr); idStr == "" {
+				continue
+			}
+			var h crypto.Hash
+			if err := h.LoadString(idStr); err != nil {
+				return nil, errors.AddContext(err, "invalid output "+idStr)
+			}
+			ids = append(ids, types.SiacoinOutputID(h))
+		}
+		return ids, nil
+	}
+	if cc.Include, err = parseIDs(walletSendInclude); err != nil {
+		return modules.CoinControl{}, err
+	}
+	if cc.Exclude, err = parseIDs(walletSendExclude); err != nil {
+		return modules.CoinControl{}, err
+	}
+	cc.Strategy = modules.CoinSelectionStrategy(walletSendStrategy)
+	cc.Account = walletSendAccount
+	if walletSendChange != "" {
+		if err := cc.ChangeAddress.LoadString(walletSendChange); err != nil {
+			return modules.CoinControl{}, errors.AddContext(err, "invalid change address")
+		}
+	}
+	return cc, cc.Validate()
+}
+
 // walletsendsiafundscmd sends siafunds to a destination address.
 func walletsendsiafundscmd(amount, dest string) {
 	var value types.Currency
@@ -473,6 +1253,15 @@ Unlock the wallet to view balance
 		delta = "-" + currencyUnits(status.ConfirmedSiacoinBalance.Sub(unconfirmedBalance))
 	}
 
+	pendingUnlock := currencyUnits(status.PendingUnlockSiacoins)
+	if !status.PendingUnlockSiafunds.IsZero() {
+		pendingUnlock += fmt.Sprintf(", %v SF", status.PendingUnlockSiafunds)
+	}
+	multisig := currencyUnits(status.MultisigSiacoins)
+	if !status.MultisigSiafunds.IsZero() {
+		multisig += fmt.Sprintf(", %v SF", status.MultisigSiafunds)
+	}
+
 	fmt.Printf(`Wallet status:
 %s, Unlocked
 Height:              %v
@@ -481,11 +1270,13 @@ Unconfirmed Delta:   %v
 Exact:               %v H
 Siafunds:            %v SF
 Siafund Claims:      %v H
+Pending Unlock:      %v
+Multisig:            %v
 
 Estimated Fee:       %v / KB
 `, encStatus, status.Height, currencyUnits(status.ConfirmedSiacoinBalance), delta,
-		status.ConfirmedSiacoinBalance, status.SiafundBalance, status.SiacoinClaimBalance,
-		fees.Maximum.Mul64(1e3).HumanString())
+		status.ConfirmedSiacoinBalance, status.SiafundBalance, status.SiacoinClaimBalance, pendingUnlock,
+		multisig, fees.Maximum.Mul64(1e3).HumanString())
 }
 
 // walletbroadcastcmd broadcasts a transaction.
@@ -515,6 +1306,106 @@ func walletsweepcmd() {
 	fmt.Printf("Swept %v and %v SF from seed.\n", currencyUnits(swept.Coins), swept.Funds)
 }
 
+// wallettimelockcmd lists the time-locked addresses of the wallet and the
+// outputs which are pending unlock.
+func wallettimelockcmd() {
+	wtg, err := httpClient.WalletTimelockGet()
+	if err != nil {
+		die("Could not get time-locked addresses:", err)
+	}
+	if len(wtg.Addresses) == 0 {
+		fmt.Println("No time-locked addresses.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Address\tUnlock Height")
+	for _, addr := range wtg.Addresses {
+		fmt.Fprintf(w, "%v\t%v\n", addr.Address, addr.UnlockConditions.Timelock)
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer:", err)
+	}
+	if len(wtg.Outputs) == 0 {
+		return
+	}
+	fmt.Println()
+	fmt.Println("Pending unlock:")
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Output\tValue\tUnlock Height")
+	for _, to := range wtg.Outputs {
+		value := currencyUnits(to.Value)
+		if to.FundType == types.SpecifierSiafundOutput {
+			value = to.Value.String() + " SF"
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\n", to.ID, value, to.UnlockHeight)
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer:", err)
+	}
+}
+
+// wallettimelockaddcmd adds the time-locked address of a public key of the
+// wallet.
+func wallettimelockaddcmd(pkStr, heightStr string) {
+	var pk types.SiaPublicKey
+	if err := pk.LoadString(pkStr); err != nil {
+		die("Could not parse public key:", err)
+	}
+	height, err := strconv.ParseUint(heightStr, 10, 64)
+	if err != nil {
+		die("Could not parse unlock height:", err)
+	}
+	uc := types.UnlockConditions{
+		Timelock:           types.BlockHeight(height),
+		PublicKeys:         []types.SiaPublicKey{pk},
+		SignaturesRequired: 1,
+	}
+	wta, err := httpClient.WalletTimelockAddPost(uc, walletTimelockUnused)
+	if err != nil {
+		die("Could not add time-locked address:", err)
+	}
+	fmt.Printf("Added address %v which unlocks at height %v\n", wta.Address, height)
+}
+
+// wallettimelockaddresscmd generates a new time-locked address.
+func wallettimelockaddresscmd(heightStr string) {
+	height, err := strconv.ParseUint(heightStr, 10, 64)
+	if err != nil {
+		die("Could not parse unlock height:", err)
+	}
+	wta, err := httpClient.WalletTimelockPost(types.BlockHeight(height))
+	if err != nil {
+		die("Could not generate time-locked address:", err)
+	}
+	fmt.Printf("Created new address which unlocks at height %v: %v\n", height, wta.Address)
+}
+
+// wallettimelocksendcmd sends siacoins to a public key which can't be spent
+// before the unlock height.
+func wallettimelocksendcmd(amount, pkStr, heightStr string) {
+	hastings, err := types.ParseCurrency(amount)
+	if err != nil {
+		die("Could not parse amount:", err)
+	}
+	var value types.Currency
+	if _, err := fmt.Sscan(hastings, &value); err != nil {
+		die("Failed to parse amount", err)
+	}
+	var pk types.SiaPublicKey
+	if err := pk.LoadString(pkStr); err != nil {
+		die("Could not parse public key:", err)
+	}
+	height, err := strconv.ParseUint(heightStr, 10, 64)
+	if err != nil {
+		die("Could not parse unlock height:", err)
+	}
+	wsp, err := httpClient.WalletSiacoinsTimelockPost(value, pk, types.BlockHeight(height), modules.CoinControl{})
+	if err != nil {
+		die("Could not send siacoins:", err)
+	}
+	fmt.Printf("Sent %s hastings to %v which unlocks at height %v\n", hastings, wsp.UnlockConditions.UnlockHash(), height)
+}
+
 // walletsigncmd signs a transaction.
 func walletsigncmd(cmd *cobra.Command, args []string) {
 	if len(args) < 1 {
@@ -594,13 +1485,236 @@ func walletsigncmdoffline(txn *types.Transaction, toSign []crypto.Hash) {
 	close(done)
 }
 
+// walletmultisigcreatecmd creates a multisig address.
+func walletmultisigcreatecmd(cmd *cobra.Command, args []string) {
+	if len(args) < 2 {
+		_ = cmd.UsageFunc()(cmd)
+		os.Exit(exitCodeUsage)
+	}
+	required, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		die("Could not parse number of required signatures:", err)
+	}
+	var pks []types.SiaPublicKey
+	for _, arg := range args[1:] {
+		var pk types.SiaPublicKey
+		if err := pk.LoadString(arg); err != nil {
+			die("Could not parse public key", arg)
+		}
+		pks = append(pks, pk)
+	}
+	wmp, err := httpClient.WalletMultisigPost(pks, required, walletMultisigUnused)
+	if err != nil {
+		die("Could not create multisig address:", err)
+	}
+	fmt.Printf("Created %v-of-%v multisig address %v\n", required, len(pks), wmp.Address)
+}
+
+// walletmultisiglistcmd lists the multisig addresses of the wallet.
+func walletmultisiglistcmd() {
+	wmg, err := httpClient.WalletMultisigGet()
+	if err != nil {
+		die("Could not get multisig addresses:", err)
+	}
+	if len(wmg.Addresses) == 0 {
+		fmt.Println("No multisig addresses.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Address\tRequired\tSiacoins\tSiafunds")
+	for _, addr := range wmg.Addresses {
+		uc := addr.UnlockConditions
+		fmt.Fprintf(w, "%v\t%v-of-%v\t%v\t%v\n", addr.Address, uc.SignaturesRequired, len(uc.PublicKeys), currencyUnits(addr.SiacoinBalance), addr.SiafundBalance)
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer:", err)
+	}
+}
+
+// walletmultisigpublickeycmd prints a new public key of the wallet.
+func walletmultisigpublickeycmd() {
+	wag, err := httpClient.WalletAddressGet()
+	if err != nil {
+		die("Could not generate new address:", err)
+	}
+	wucg, err := httpClient.WalletUnlockConditionsGet(wag.Address)
+	if err != nil {
+		die("Could not get unlock conditions:", err)
+	}
+	fmt.Println(wucg.UnlockConditions.PublicKeys[0])
+}
+
+// printPartialTxn prints a partially signed transaction as JSON or base64.
+func printPartialTxn(pst modules.PartiallySignedTransaction) {
+	var err error
+	if walletRawTxn {
+		_, err = base64.NewEncoder(base64.StdEncoding, os.Stdout).Write(encoding.Marshal(pst))
+	} else {
+		err = json.NewEncoder(os.Stdout).Encode(pst)
+	}
+	if err != nil {
+		die("failed to encode partially signed transaction", err)
+	}
+	fmt.Println()
+}
+
+// walletpartialcreatecmd creates a partially signed transaction.
+func walletpartialcreatecmd(cmd *cobra.Command, args []string) {
+	if len(args) < 1 {
+		_ = cmd.UsageFunc()(cmd)
+		os.Exit(exitCodeUsage)
+	}
+	txn, err := parseTxn(args[0])
+	if err != nil {
+		die("Could not decode transaction:", err)
+	}
+	var parents []types.Transaction
+	for _, arg := range args[1:] {
+		parent, err := parseTxn(arg)
+		if err != nil {
+			die("Could not decode parent:", err)
+		}
+		parents = append(parents, parent)
+	}
+	wpp, err := httpClient.WalletPartialCreatePost(txn, parents)
+	if err != nil {
+		die("Could not create partially signed transaction:", err)
+	}
+	printPartialTxn(wpp.PartialTransaction)
+}
+
+// walletpartialfinalizecmd finalizes a partially signed transaction and
+// optionally broadcasts it.
+func walletpartialfinalizecmd(pstStr string) {
+	pst, err := parsePartialTxn(pstStr)
+	if err != nil {
+		die("Could not decode partially signed transaction:", err)
+	}
+	wpfp, err := httpClient.WalletPartialFinalizePost(pst, walletBroadcast)
+	if err != nil {
+		die("Could not finalize partially signed transaction:", err)
+	}
+	if walletBroadcast {
+		fmt.Println("Transaction has been broadcast successfully")
+		return
+	}
+	txn := wpfp.Transactions[len(wpfp.Transactions)-1]
+	if walletRawTxn {
+		_, err = base64.NewEncoder(base64.StdEncoding, os.Stdout).Write(encoding.Marshal(txn))
+	} else {
+		err = json.NewEncoder(os.Stdout).Encode(txn)
+	}
+	if err != nil {
+		die("failed to encode txn", err)
+	}
+	fmt.Println()
+}
+
+// walletpartialmergecmd merges partially signed transactions.
+func walletpartialmergecmd(cmd *cobra.Command, args []string) {
+	if len(args) < 2 {
+		_ = cmd.UsageFunc()(cmd)
+		os.Exit(exitCodeUsage)
+	}
+	var psts []modules.PartiallySignedTransaction
+	for _, arg := range args {
+		pst, err := parsePartialTxn(arg)
+		if err != nil {
+			die("Could not decode partially signed transaction:", err)
+		}
+		psts = append(psts, pst)
+	}
+	wpp, err := httpClient.WalletPartialMergePost(psts...)
+	if err != nil {
+		die("Could not merge partially signed transactions:", err)
+	}
+	printPartialTxn(wpp.PartialTransaction)
+}
+
+// walletpartialsigncmd signs a partially signed transaction.
+func walletpartialsigncmd(pstStr string) {
+	pst, err := parsePartialTxn(pstStr)
+	if err != nil {
+		die("Could not decode partially signed transaction:", err)
+	}
+	wpp, err := httpClient.WalletPartialSignPost(pst)
+	if err != nil {
+		die("Could not sign partially signed transaction:", err)
+	}
+	printPartialTxn(wpp.PartialTransaction)
+}
+
+// walletpartialstatuscmd prints the signing status of a partially signed
+// transaction.
+func walletpartialstatuscmd(pstStr string) {
+	pst, err := parsePartialTxn(pstStr)
+	if err != nil {
+		die("Could not decode partially signed transaction:", err)
+	}
+	wpp, err := httpClient.WalletPartialStatusPost(pst)
+	if err != nil {
+		die("Could not get status of partially signed transaction:", err)
+	}
+	fmt.Println("Transaction:", pst.ID())
+	fmt.Println("Complete:   ", wpp.Complete)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\nInput\tRequired\tCollected\tSigners")
+	for _, input := range wpp.Inputs {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\n", input.ParentID, input.Required, input.Collected, input.Signers)
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer:", err)
+	}
+}
+
 // wallettransactionscmd lists all of the transactions related to the wallet,
 // providing a net flow of siacoins and siafunds for each.
 func wallettransactionscmd() {
-	wtg, err := httpClient.WalletTransactionsGet(types.BlockHeight(walletStartHeight), types.BlockHeight(walletEndHeight))
+	wtg, err := httpClient.WalletTransactionsLabelGet(types.BlockHeight(walletStartHeight), types.BlockHeight(walletEndHeight), walletTxnLabel)
 	if err != nil {
 		die("Could not fetch transaction history:", err)
 	}
+	names := make(map[types.UnlockHash]string)
+	for _, al := range wtg.AddressLabels {
+		names[al.Address] = al.Name
+	}
+	labels := make(map[types.TransactionID]modules.TransactionLabel)
+	for _, tl := range wtg.TransactionLabels {
+		labels[tl.TransactionID] = tl
+	}
+	fiat := make(map[types.TransactionID]modules.FiatTransaction)
+	if walletFiatSymbol != "" {
+		wrg, err := httpClient.WalletReportGet(walletFiatSymbol, 0, math.MaxUint64, modules.ReportPeriodMonth)
+		if err != nil {
+			die("Could not fetch fiat values:", err)
+		}
+		for _, ft := range wrg.Transactions {
+			fiat[ft.TransactionID] = ft
+		}
+	}
+	if walletTxnAccount != "" {
+		wag, err := httpClient.WalletAccountGet(walletTxnAccount)
+		if err != nil {
+			die("Could not fetch account:", err)
+		}
+		isAccount := make(map[types.UnlockHash]struct{})
+		for _, addr := range wag.Addresses {
+			isAccount[addr] = struct{}{}
+		}
+		filter := func(pts []modules.ProcessedTransaction) (filtered []modules.ProcessedTransaction) {
+			for _, pt := range pts {
+				for _, addr := range pt.Addresses() {
+					if _, exists := isAccount[addr]; exists {
+						filtered = append(filtered, pt)
+						break
+					}
+				}
+			}
+			return filtered
+		}
+		wtg.ConfirmedTransactions = filter(wtg.ConfirmedTransactions)
+		wtg.UnconfirmedTransactions = filter(wtg.UnconfirmedTransactions)
+	}
 	cg, err := httpClient.ConsensusGet()
 	if err != nil {
 		die("Could not fetch consensus information:", err)
@@ -650,6 +1764,50 @@ func wallettransactionscmd() {
 		} else {
 			fmt.Printf("-%14v SF\n", outgoingSiafunds.Sub(incomingSiafunds))
 		}
+		printTxnLabels(txn.ProcessedTransaction, labels, names)
+		if ft, exists := fiat[txn.TransactionID]; exists {
+			printFiatValue(ft)
+		}
+	}
+}
+
+// printFiatValue prints the fiat value of a transaction and the realized gain
+// of sent siacoins.
+func printFiatValue(ft modules.FiatTransaction) {
+	if ft.Rate == nil {
+		fmt.Printf("%24v unknown, no exchange rate\n", "value:")
+		return
+	}
+	if ft.FiatSent != "" {
+		fmt.Printf("%24v -%v %v at %v, realized gain %v %v\n", "value:", ft.FiatSent, ft.Rate.Symbol(), ft.Rate, ft.RealizedGain, ft.Rate.Symbol())
+	} else {
+		fmt.Printf("%24v %v %v at %v\n", "value:", ft.FiatReceived, ft.Rate.Symbol(), ft.Rate)
+	}
+}
+
+// printTxnLabels prints the label of a transaction and the names of the
+// addresses involved in it.
+func printTxnLabels(pt modules.ProcessedTransaction, labels map[types.TransactionID]modules.TransactionLabel, names map[types.UnlockHash]string) {
+	if tl, exists := labels[pt.TransactionID]; exists {
+		if tl.Note != "" {
+			fmt.Printf("%24v %v\n", "note:", tl.Note)
+		}
+		if len(tl.Tags) > 0 {
+			fmt.Printf("%24v %v\n", "tags:", strings.Join(tl.Tags, ", "))
+		}
+	}
+	var addrNames []string
+	seen := make(map[types.UnlockHash]struct{})
+	for _, addr := range pt.Addresses() {
+		name, exists := names[addr]
+		if _, done := seen[addr]; !exists || done {
+			continue
+		}
+		seen[addr] = struct{}{}
+		addrNames = append(addrNames, name)
+	}
+	if len(addrNames) > 0 {
+		fmt.Printf("%24v %v\n", "addresses:", strings.Join(addrNames, ", "))
 	}
 }
 
@@ -44,6 +44,7 @@ type Config struct {
 		RequiredUserAgent string
 		AuthenticateAPI   bool
 		TempPassword      bool
+		EnableMetrics     bool
 
 		Profile    string
 		ProfileDir string
@@ -206,6 +207,7 @@ func main() {
 	root.Flags().BoolVarP(&globalConfig.Siad.AuthenticateAPI, "authenticate-api", "", true, "enable API password protection")
 	root.Flags().BoolVarP(&globalConfig.Siad.TempPassword, "temp-password", "", false, "enter a temporary API password during startup")
 	root.Flags().BoolVarP(&globalConfig.Siad.AllowAPIBind, "disable-api-security", "", false, "allow siad to listen on a non-localhost address (DANGEROUS)")
+	root.Flags().BoolVarP(&globalConfig.Siad.EnableMetrics, "enable-metrics", "", false, "serve Prometheus metrics at /metrics")
 
 	// If globalConfig.Siad.SiaDir is not set, use the environment variable provided.
 	if globalConfig.Siad.SiaDir == "" {
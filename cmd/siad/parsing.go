@@ -48,5 +48,6 @@ func parseModules(config Config) node.NodeParams {
 	params.SiaMuxTCPAddress = config.Siad.SiaMuxTCPAddr
 	params.SiaMuxWSAddress = config.Siad.SiaMuxWSAddr
 	params.Dir = config.Siad.SiaDir
+	params.EnableMetrics = config.Siad.EnableMetrics
 	return params
 }
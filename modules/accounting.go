@@ -21,6 +21,10 @@ type (
 
 		Renter RenterAccounting `json:"renter"`
 		Wallet WalletAccounting `json:"wallet"`
+
+		// Timestamp is the Unix timestamp of when the accounting information
+		// was recorded.
+		Timestamp int64 `json:"timestamp"`
 	}
 
 	// RenterAccounting contains the accounting information related to the Renter
@@ -53,6 +57,10 @@ type Accounting interface {
 	// Accounting returns the current accounting information
 	Accounting() (AccountingInfo, error)
 
+	// History returns the persisted accounting information that was recorded
+	// between the start and end Unix timestamps, inclusive.
+	History(start, end int64) ([]AccountingInfo, error)
+
 	// Close closes the accounting module
 	Close() error
 }
@@ -19,6 +19,10 @@ var (
 
 	// errNilWallet is the error returned when the wallet is nil
 	errNilWallet = errors.New("wallet cannot be nil")
+
+	// errInvalidRange is the error returned when the requested history range
+	// ends before it starts
+	errInvalidRange = errors.New("start time cannot be after end time")
 )
 
 // Accounting contains the information needed for providing accounting
@@ -31,6 +35,10 @@ type Accounting struct {
 	staticWallet modules.Wallet
 
 	// Accounting module settings
+	//
+	// history contains all persisted entries in the order they were written to
+	// disk. persistence is the most recent accounting information.
+	history          []persistence
 	persistence      persistence
 	staticPersistDir string
 
@@ -101,6 +109,32 @@ func (a *Accounting) Accounting() (modules.AccountingInfo, error) {
 	return ai, nil
 }
 
+// History returns the persisted accounting information that was recorded
+// between the start and end Unix timestamps, inclusive.
+func (a *Accounting) History(start, end int64) ([]modules.AccountingInfo, error) {
+	err := a.staticTG.Add()
+	if err != nil {
+		return nil, err
+	}
+	defer a.staticTG.Done()
+
+	// Check the range
+	if start > end {
+		return nil, errInvalidRange
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var ais []modules.AccountingInfo
+	for _, p := range a.history {
+		if p.Timestamp < start || p.Timestamp > end {
+			continue
+		}
+		ais = append(ais, p.accountingInfo())
+	}
+	return ais, nil
+}
+
 // Close closes the accounting module
 //
 // NOTE: It will not call close on any of the modules it is tracking. Those
@@ -111,7 +145,9 @@ func (a *Accounting) Close() error {
 
 // callUpdateAccounting updates the accounting information
 func (a *Accounting) callUpdateAccounting() (modules.AccountingInfo, error) {
-	var ai modules.AccountingInfo
+	ai := modules.AccountingInfo{
+		Timestamp: time.Now().Unix(),
+	}
 
 	// Get Renter information
 	//
@@ -140,7 +176,7 @@ func (a *Accounting) callUpdateAccounting() (modules.AccountingInfo, error) {
 		a.mu.Lock()
 		a.persistence.Renter = ai.Renter
 		a.persistence.Wallet = ai.Wallet
-		a.persistence.Timestamp = time.Now().Unix()
+		a.persistence.Timestamp = ai.Timestamp
 		a.mu.Unlock()
 	}
 	return ai, err
@@ -1,9 +1,11 @@
 package accounting
 
 import (
+	"math"
 	"reflect"
 	"testing"
 
+	"gitlab.com/NebulousLabs/errors"
 	"go.sia.tech/siad/modules"
 	"go.sia.tech/siad/siatest/dependencies"
 )
@@ -17,6 +19,7 @@ func TestAccounting(t *testing.T) {
 
 	// Specific Methods
 	t.Run("Accounting", testAccounting)
+	t.Run("History", testHistory)
 	t.Run("NewCustomAccounting", testNewCustomAccounting)
 }
 
@@ -51,8 +54,9 @@ func testAccounting(t *testing.T) {
 	}
 	// Check for a returned value
 	expected := modules.AccountingInfo{
-		Renter: ai.Renter,
-		Wallet: ai.Wallet,
+		Renter:    ai.Renter,
+		Wallet:    ai.Wallet,
+		Timestamp: ai.Timestamp,
 	}
 	if !reflect.DeepEqual(ai, expected) {
 		t.Error("accounting information is incorrect")
@@ -65,6 +69,10 @@ func testAccounting(t *testing.T) {
 	if reflect.DeepEqual(ai.Wallet, modules.WalletAccounting{}) {
 		t.Error("wallet accounting information is empty")
 	}
+	// Check timestamp explicitly
+	if ai.Timestamp == 0 {
+		t.Error("timestamp not set")
+	}
 
 	// Persistence should have been updated
 	a.mu.Lock()
@@ -87,6 +95,84 @@ func testAccounting(t *testing.T) {
 	}
 }
 
+// testHistory probes the History method
+func testHistory(t *testing.T) {
+	// Create new accounting
+	testDir := accountingTestDir(t.Name())
+	h, m, r, w, _ := testingParams()
+	a, err := NewCustomAccounting(h, m, r, w, testDir, &dependencies.AccountingDisablePersistLoop{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// History should initially be empty
+	history, err := a.History(0, math.MaxInt64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 0 {
+		t.Fatalf("expected empty history, got %v entries", len(history))
+	}
+
+	// An invalid range should return an error
+	_, err = a.History(1, 0)
+	if !errors.Contains(err, errInvalidRange) {
+		t.Fatalf("expected %v, got %v", errInvalidRange, err)
+	}
+
+	// Persist a few entries
+	numEntries := 3
+	for i := 0; i < numEntries; i++ {
+		err = a.managedUpdateAndPersistAccounting()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Close and reload the accounting to verify the history is loaded from disk
+	err = a.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err = NewCustomAccounting(h, m, r, w, testDir, &dependencies.AccountingDisablePersistLoop{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err = a.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// All entries should be returned for the full range
+	history, err = a.History(0, math.MaxInt64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != numEntries {
+		t.Fatalf("expected %v entries, got %v", numEntries, len(history))
+	}
+	for _, ai := range history {
+		if ai.Timestamp == 0 {
+			t.Error("timestamp not set")
+		}
+		if reflect.DeepEqual(ai.Wallet, modules.WalletAccounting{}) {
+			t.Error("wallet accounting information is empty")
+		}
+	}
+
+	// No entries should be returned for a range in the future
+	last := history[len(history)-1].Timestamp
+	history, err = a.History(last+1, math.MaxInt64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 0 {
+		t.Fatalf("expected no entries, got %v", len(history))
+	}
+}
+
 // testNewCustomAccounting probes the NewCustomAccounting function
 func testNewCustomAccounting(t *testing.T) {
 	// checkNew is a helper function to check NewCustomAccounting
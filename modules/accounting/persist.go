@@ -137,7 +137,8 @@ func (a *Accounting) initPersist() error {
 		return errors.AddContext(err, "unable to unmarshal persistence")
 	}
 
-	// Keep the last persist entry in memory
+	// Keep the persisted history in memory
+	a.history = persistence
 	if len(persistence) > 0 {
 		a.persistence = persistence[len(persistence)-1]
 	}
@@ -175,9 +176,23 @@ func (a *Accounting) managedUpdateAndPersistAccounting() error {
 		return err
 	}
 
+	// Add the persisted entry to the history
+	a.mu.Lock()
+	a.history = append(a.history, p)
+	a.mu.Unlock()
 	return nil
 }
 
+// accountingInfo returns the accounting information contained in the
+// persistence.
+func (p persistence) accountingInfo() modules.AccountingInfo {
+	return modules.AccountingInfo{
+		Renter:    p.Renter,
+		Wallet:    p.Wallet,
+		Timestamp: p.Timestamp,
+	}
+}
+
 // marshalPersistence marshals the persistence.
 func marshalPersistence(p persistence) ([]byte, error) {
 	// Marshal the persistence
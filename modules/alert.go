@@ -53,6 +53,10 @@ const (
 	// registered if the host has insufficient collateral budget left to form or
 	// renew a contract
 	AlertIDHostInsufficientCollateral = "host-insufficient-collateral"
+	// AlertIDHostCorruptSectors is the id of the alert that is registered if
+	// the sector scrubber found corrupt sectors which are part of the host's
+	// storage obligations
+	AlertIDHostCorruptSectors = "host-corrupt-sectors"
 )
 
 // AlertIDSiafileLowRedundancy uses a Siafile's UID to create a unique AlertID
@@ -61,6 +65,12 @@ func AlertIDSiafileLowRedundancy(uid string) AlertID {
 	return AlertID(fmt.Sprintf("low-redundancy:%v", uid))
 }
 
+// AlertIDScheduledPaymentFailed uses the id of a scheduled payment to create
+// a unique AlertID for a failed payment.
+func AlertIDScheduledPaymentFailed(id string) AlertID {
+	return AlertID(fmt.Sprintf("scheduled-payment-failed:%v", id))
+}
+
 type (
 	// Alerter is the interface implemented by all top-level modules. It's an
 	// interface that allows for asking a module about potential issues.
@@ -178,6 +178,19 @@ var (
 	// BytesPerTerabyte is the conversion rate between bytes and terabytes.
 	BytesPerTerabyte = types.NewCurrency64(1e12)
 
+	// HostRPCLatencyBuckets are the upper bounds of the buckets of the latency
+	// histograms in the HostRPCMetrics.
+	HostRPCLatencyBuckets = []time.Duration{
+		10 * time.Millisecond,
+		50 * time.Millisecond,
+		100 * time.Millisecond,
+		250 * time.Millisecond,
+		500 * time.Millisecond,
+		time.Second,
+		5 * time.Second,
+		30 * time.Second,
+	}
+
 	// MaxBaseRPCPriceVsBandwidth is the max ratio for sane pricing between the
 	// MinBaseRPCPrice and the MinDownloadBandwidthPrice. This ensures that 1
 	// million base RPC charges are at most 1% of the cost to download 4TB. This
@@ -292,7 +305,9 @@ type (
 	}
 
 	// HostNetworkMetrics reports the quantity of each type of RPC call that
-	// has been made to the host.
+	// has been made to the host. RPCMetrics and InstructionMetrics contain
+	// detailed metrics for the RHP3 RPCs and the MDM instructions, keyed by
+	// their specifier.
 	HostNetworkMetrics struct {
 		DownloadCalls     uint64 `json:"downloadcalls"`
 		ErrorCalls        uint64 `json:"errorcalls"`
@@ -301,6 +316,80 @@ type (
 		ReviseCalls       uint64 `json:"revisecalls"`
 		SettingsCalls     uint64 `json:"settingscalls"`
 		UnrecognizedCalls uint64 `json:"unrecognizedcalls"`
+
+		RPCMetrics         map[string]HostRPCMetrics `json:"rpcmetrics"`
+		InstructionMetrics map[string]HostRPCMetrics `json:"instructionmetrics"`
+	}
+
+	// HostDrainStatus contains the state of the host's drain mode. While
+	// draining, the host doesn't form or renew contracts and, if
+	// RejectDeposits is set, doesn't accept ephemeral account deposits. It
+	// keeps serving downloads and submitting storage proofs. Drained is set
+	// once no RHP3 programs and no RHP2 uploads are in flight anymore.
+	HostDrainStatus struct {
+		Draining         bool   `json:"draining"`
+		RejectDeposits   bool   `json:"rejectdeposits"`
+		InFlightPrograms uint64 `json:"inflightprograms"`
+		InFlightUploads  uint64 `json:"inflightuploads"`
+		Drained          bool   `json:"drained"`
+	}
+
+	// HostPriceTarget is the target of a price managed by the host's pricing
+	// policy. The target is denominated in the fiat currency of the policy's
+	// exchange rate, per TB per month for the storage price and collateral,
+	// per TB for the bandwidth prices and per contract for the max
+	// collateral. Min and Max bound the price in the units of the
+	// corresponding internal setting. A Target of 0 leaves the price
+	// unmanaged, a Max of 0 leaves it unbounded.
+	HostPriceTarget struct {
+		Target float64        `json:"target"`
+		Min    types.Currency `json:"min"`
+		Max    types.Currency `json:"max"`
+	}
+
+	// HostPricingPolicy configures the automatic adjustment of the host's
+	// prices. The prices are converted from their fiat targets using the
+	// exchange rate and then adjusted by up to Deviation depending on the
+	// storage utilization, the collateral budget utilization and the
+	// bandwidth usage relative to TargetBandwidth. Smoothing is the fraction
+	// of the difference between the current and the adjusted price which is
+	// applied per adjustment.
+	HostPricingPolicy struct {
+		Enabled         bool                `json:"enabled"`
+		ExchangeRate    *types.ExchangeRate `json:"exchangerate"`
+		Deviation       float64             `json:"deviation"`
+		Smoothing       float64             `json:"smoothing"`
+		TargetBandwidth uint64              `json:"targetbandwidth"`
+
+		StoragePrice  HostPriceTarget `json:"storageprice"`
+		Collateral    HostPriceTarget `json:"collateral"`
+		MaxCollateral HostPriceTarget `json:"maxcollateral"`
+		DownloadPrice HostPriceTarget `json:"downloadprice"`
+		UploadPrice   HostPriceTarget `json:"uploadprice"`
+	}
+
+	// HostPricingStatus contains the host's pricing policy and the inputs of
+	// its most recent price adjustment. Bandwidth is the average number of
+	// bytes per second transferred by the host since the previous adjustment.
+	HostPricingStatus struct {
+		Policy                HostPricingPolicy `json:"policy"`
+		LastAdjustment        time.Time         `json:"lastadjustment"`
+		StorageUtilization    float64           `json:"storageutilization"`
+		CollateralUtilization float64           `json:"collateralutilization"`
+		Bandwidth             uint64            `json:"bandwidth"`
+	}
+
+	// HostRPCMetrics contains the metrics of a single RPC or MDM instruction.
+	// The i-th entry of the LatencyHistogram counts the calls which took at
+	// most HostRPCLatencyBuckets[i]. The last entry counts the calls which
+	// took longer than the largest bucket.
+	HostRPCMetrics struct {
+		Calls            uint64        `json:"calls"`
+		Errors           uint64        `json:"errors"`
+		BytesIn          uint64        `json:"bytesin"`
+		BytesOut         uint64        `json:"bytesout"`
+		LatencyHistogram []uint64      `json:"latencyhistogram"`
+		TotalLatency     time.Duration `json:"totallatency"`
 	}
 
 	// StorageObligation contains information about a storage obligation that
@@ -315,6 +404,7 @@ type (
 		PotentialDownloadRevenue types.Currency       `json:"potentialdownloadrevenue"`
 		PotentialStorageRevenue  types.Currency       `json:"potentialstoragerevenue"`
 		PotentialUploadRevenue   types.Currency       `json:"potentialuploadrevenue"`
+		RenterPublicKey          types.SiaPublicKey   `json:"renterpublickey"`
 		RiskedCollateral         types.Currency       `json:"riskedcollateral"`
 		SectorRootsCount         uint64               `json:"sectorrootscount"`
 		TransactionFeesAdded     types.Currency       `json:"transactionfeesadded"`
@@ -393,10 +483,20 @@ type (
 		// The host needs to be able to shut down.
 		Close() error
 
+		// CancelSectorMigrations cancels all queued sector migrations.
+		// Sectors which were already migrated stay in their new storage
+		// folder.
+		CancelSectorMigrations() error
+
 		// ConnectabilityStatus returns the connectability status of the host,
 		// that is, if it can connect to itself on the configured NetAddress.
 		ConnectabilityStatus() HostConnectabilityStatus
 
+		// CorruptSectors returns the sectors which the sector scrubber found
+		// to be corrupted on disk, together with the unresolved storage
+		// obligations that contain them.
+		CorruptSectors() ([]CorruptSector, error)
+
 		// DeleteSector deletes a sector, meaning that the host will be
 		// unable to upload that sector and be unable to provide a storage
 		// proof on that sector. DeleteSector is for removing the data
@@ -405,6 +505,9 @@ type (
 		// requests to remove data.
 		DeleteSector(sectorRoot crypto.Hash) error
 
+		// DrainStatus returns the state of the host's drain mode.
+		DrainStatus() HostDrainStatus
+
 		// ExternalSettings returns the settings of the host as seen by an
 		// untrusted node querying the host for settings.
 		ExternalSettings() HostExternalSettings
@@ -419,6 +522,15 @@ type (
 		// potentially private or sensitive information.
 		InternalSettings() HostInternalSettings
 
+		// MigrateSectors queues a migration of sectors from one storage
+		// folder to another, which is performed in the background. If
+		// numSectors is 0, all sectors of the source folder are migrated.
+		MigrateSectors(from, to uint16, numSectors uint64) error
+
+		// MigrationStatus returns the settings of the sector migrator and the
+		// queued migrations.
+		MigrationStatus() StorageMigrationStatus
+
 		// NetworkMetrics returns information on the types of RPC calls that
 		// have been made to the host.
 		NetworkMetrics() HostNetworkMetrics
@@ -435,6 +547,10 @@ type (
 		// show the correct values.
 		PruneStaleStorageObligations() error
 
+		// PricingStatus returns the host's pricing policy and the inputs of
+		// its most recent price adjustment.
+		PricingStatus() HostPricingStatus
+
 		// PublicKey returns the public key of the host.
 		PublicKey() types.SiaPublicKey
 
@@ -464,6 +580,10 @@ type (
 		// operation will be completed, meaning that data will be lost.
 		RemoveStorageFolder(index uint16, force bool) error
 
+		// RenterFilter returns the filter mode of the host's renter filter
+		// and the renters it applies to.
+		RenterFilter() (FilterMode, []types.SiaPublicKey)
+
 		// ResetStorageFolderHealth will reset the health statistics on a
 		// storage folder.
 		ResetStorageFolderHealth(index uint16) error
@@ -479,9 +599,41 @@ type (
 		// and the resize operation completed, meaning that data will be lost.
 		ResizeStorageFolder(index uint16, newSize uint64, force bool) error
 
+		// ScrubStatus returns the settings and the progress of the sector
+		// scrubber.
+		ScrubStatus() StorageScrubStatus
+
 		// SetInternalSettings sets the hosting parameters of the host.
 		SetInternalSettings(HostInternalSettings) error
 
+		// SetDrain enables or disables the host's drain mode. While draining,
+		// the host doesn't form or renew contracts and, if rejectDeposits is
+		// set, doesn't accept ephemeral account deposits.
+		SetDrain(draining, rejectDeposits bool) error
+
+		// SetMigrationSettings enables or disables the automatic rebalancing
+		// of the storage folders and sets the maximum number of bytes per
+		// second moved by the sector migrator.
+		SetMigrationSettings(rebalance bool, rateLimit uint64) error
+
+		// SetPricingPolicy sets the policy which automatically adjusts the
+		// host's prices.
+		SetPricingPolicy(HostPricingPolicy) error
+
+		// SetRenterFilter sets the filter mode of the host's renter filter
+		// and the renters it applies to. Renters which aren't allowed by the
+		// filter can't form or renew contracts, fund ephemeral accounts or
+		// pay for RPCs.
+		SetRenterFilter(fm FilterMode, renters []types.SiaPublicKey) error
+
+		// SetScrubSettings enables or disables the sector scrubber and sets
+		// the maximum number of bytes per second it reads from disk.
+		SetScrubSettings(enabled bool, rateLimit uint64) error
+
+		// StartScrub starts scrubbing all storage folders immediately instead
+		// of waiting for their next scheduled scrub.
+		StartScrub() error
+
 		// StorageObligation returns the storage obligation matching the id or
 		// an error if it does not exist
 		StorageObligation(obligationID types.FileContractID) (StorageObligation, error)
@@ -500,6 +652,35 @@ type (
 	}
 )
 
+// AverageLatency returns the average latency of the calls.
+func (m HostRPCMetrics) AverageLatency() time.Duration {
+	if m.Calls == 0 {
+		return 0
+	}
+	return m.TotalLatency / time.Duration(m.Calls)
+}
+
+// LatencyPercentile returns an upper bound for the latency of the provided
+// percentage of calls. If the percentile falls into the last entry of the
+// histogram, the largest bucket is returned.
+func (m HostRPCMetrics) LatencyPercentile(p float64) time.Duration {
+	var total uint64
+	for _, n := range m.LatencyHistogram {
+		total += n
+	}
+	if total == 0 {
+		return 0
+	}
+	var count uint64
+	for i, n := range m.LatencyHistogram {
+		count += n
+		if float64(count) >= p*float64(total) && i < len(HostRPCLatencyBuckets) {
+			return HostRPCLatencyBuckets[i]
+		}
+	}
+	return HostRPCLatencyBuckets[len(HostRPCLatencyBuckets)-1]
+}
+
 // MaxBaseRPCPrice returns the maximum value for the MinBaseRPCPrice based on
 // the MinDownloadBandwidthPrice
 func (his HostInternalSettings) MaxBaseRPCPrice() types.Currency {
@@ -860,11 +860,17 @@ func (am *accountManager) threadedPruneExpiredAccounts() {
 
 			// Expire accounts that have been inactive for too long. Keep track
 			// of the indexes that got expired.
-			expired := am.managedExpireAccounts(accountExpiryTimeout)
+			expired, ids := am.managedExpireAccounts(accountExpiryTimeout)
 			if len(expired) == 0 {
 				return
 			}
 
+			// Forget the renters which funded the expired accounts
+			err := am.h.staticRenterFilter.managedPruneAccounts(ids)
+			if err != nil {
+				am.h.log.Println(errors.AddContext(err, "prune renters of expired accounts failed"))
+			}
+
 			// Batch delete the expired accounts on disk
 			deleted, err := am.staticAccountsPersister.callBatchDeleteAccount(expired)
 			if err != nil {
@@ -920,8 +926,8 @@ func (am *accountManager) staticWaitForWithdrawalResult(commitResultChan chan er
 }
 
 // managedExpireAccounts will expire accounts where the lastTxnTime exceeds the
-// given threshold.
-func (am *accountManager) managedExpireAccounts(threshold int64) []uint32 {
+// given threshold. It returns the indexes and the ids of the expired accounts.
+func (am *accountManager) managedExpireAccounts(threshold int64) ([]uint32, []modules.AccountID) {
 	am.mu.Lock()
 	defer am.mu.Unlock()
 
@@ -929,6 +935,7 @@ func (am *accountManager) managedExpireAccounts(threshold int64) []uint32 {
 	force := am.h.dependencies.Disrupt("expireEphemeralAccounts")
 
 	var deleted []uint32
+	var ids []modules.AccountID
 	now := time.Now().Unix()
 	for id, acc := range am.accounts {
 		if force || now-acc.lastTxnTime > threshold {
@@ -939,9 +946,10 @@ func (am *accountManager) managedExpireAccounts(threshold int64) []uint32 {
 			}
 			delete(am.accounts, id)
 			deleted = append(deleted, acc.index)
+			ids = append(ids, id)
 		}
 	}
-	return deleted
+	return deleted, ids
 }
 
 // callAccountBalance will return the balance of an account.
@@ -12,6 +12,10 @@ const (
 	// AlertMSGHostInsufficientCollateral indicates that a host has insufficient
 	// collateral budget remaining
 	AlertMSGHostInsufficientCollateral = "host has insufficient collateral budget"
+
+	// AlertMSGHostCorruptSectors indicates that the sector scrubber found
+	// sectors which are corrupted on disk
+	AlertMSGHostCorruptSectors = "corrupt sectors detected"
 )
 
 const (
@@ -34,6 +38,14 @@ const (
 	// maxObligationLockTimeout is the maximum amount of time the host will wait
 	// to lock a storage obligation.
 	maxObligationLockTimeout = 10 * time.Minute
+
+	// defaultPricingDeviation is the default maximum deviation of the prices
+	// managed by the pricing policy from their targets.
+	defaultPricingDeviation = 0.25
+
+	// defaultPricingSmoothing is the default fraction of a price change that
+	// the pricing policy applies per adjustment.
+	defaultPricingSmoothing = 0.5
 )
 
 var (
@@ -61,6 +73,22 @@ var (
 		Testing:  time.Second * 90,
 	}).(time.Duration)
 
+	// corruptSectorsCheckFrequency defines how often the host checks whether
+	// the sector scrubber found corrupt sectors.
+	corruptSectorsCheckFrequency = build.Select(build.Var{
+		Standard: time.Minute * 10,
+		Dev:      time.Minute,
+		Testing:  time.Second,
+	}).(time.Duration)
+
+	// pricingAdjustmentFrequency defines how often the pricing policy adjusts
+	// the host's prices.
+	pricingAdjustmentFrequency = build.Select(build.Var{
+		Standard: time.Hour,
+		Dev:      time.Minute,
+		Testing:  time.Second,
+	}).(time.Duration)
+
 	// defaultCollateralBudget defines the maximum number of siacoins that the
 	// host is going to allocate towards collateral. The number has been chosen
 	// as a number that is large, but not so large that someone would be
@@ -140,3 +140,53 @@ var (
 		Testing:  time.Second * 8,
 	}).(time.Duration)
 )
+
+var (
+	// defaultScrubRateLimit is the default maximum number of bytes per second
+	// that the scrubber reads from the storage folders.
+	defaultScrubRateLimit = build.Select(build.Var{
+		Dev:      uint64(1 << 26), // 64 MiB/s
+		Standard: uint64(1 << 24), // 16 MiB/s
+		Testing:  uint64(1 << 22), // 4 MiB/s
+	}).(uint64)
+
+	// scrubCheckInterval specifies how often the scrubber checks whether a
+	// storage folder is due to be scrubbed.
+	scrubCheckInterval = build.Select(build.Var{
+		Dev:      time.Minute,
+		Standard: time.Hour,
+		Testing:  time.Second,
+	}).(time.Duration)
+
+	// scrubInterval specifies the amount of time between two scrubs of the
+	// same storage folder.
+	scrubInterval = build.Select(build.Var{
+		Dev:      time.Hour * 24,
+		Standard: time.Hour * 24 * 30,
+		Testing:  time.Hour,
+	}).(time.Duration)
+)
+
+var (
+	// defaultMigrationRateLimit is the default maximum number of bytes per
+	// second that the migrator moves between storage folders.
+	defaultMigrationRateLimit = build.Select(build.Var{
+		Dev:      uint64(1 << 26), // 64 MiB/s
+		Standard: uint64(1 << 25), // 32 MiB/s
+		Testing:  uint64(1 << 22), // 4 MiB/s
+	}).(uint64)
+
+	// migrationCheckInterval specifies how often the migrator retries the
+	// queued migrations and checks whether the storage folders need to be
+	// rebalanced.
+	migrationCheckInterval = build.Select(build.Var{
+		Dev:      time.Minute,
+		Standard: time.Minute * 10,
+		Testing:  time.Second,
+	}).(time.Duration)
+
+	// rebalanceThreshold is the difference in utilization between the fullest
+	// and the emptiest storage folder above which the rebalance policy
+	// migrates sectors between them.
+	rebalanceThreshold = 0.1
+)
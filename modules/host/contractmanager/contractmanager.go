@@ -93,6 +93,24 @@ type ContractManager struct {
 	// or modified.
 	lockedSectors map[sectorID]*sectorLock
 
+	// corruptSectors contains the sectors which the scrubber found to be
+	// corrupted on disk. scrubDisabled and scrubRateLimit are the persistent
+	// settings of the scrubber, scrubWake is used to wake the scrubber when
+	// its settings change or a scrub is requested.
+	corruptSectors map[sectorID]struct{}
+	scrubDisabled  bool
+	scrubRateLimit uint64
+	scrubWake      chan struct{}
+
+	// migrations are the queued sector migrations, which are performed in
+	// order by the migrator. rebalance and migrationRateLimit are the
+	// persistent settings of the migrator, migrationWake is used to wake the
+	// migrator when a migration is queued or its settings change.
+	migrations         []*sectorMigration
+	rebalance          bool
+	migrationRateLimit uint64
+	migrationWake      chan struct{}
+
 	// Utilities.
 	dependencies  modules.Dependencies
 	staticAlerter *modules.GenericAlerter
@@ -116,6 +134,11 @@ func newContractManager(dependencies modules.Dependencies, persistDir string) (_
 
 		lockedSectors: make(map[sectorID]*sectorLock),
 
+		corruptSectors: make(map[sectorID]struct{}),
+		scrubWake:      make(chan struct{}, 1),
+
+		migrationWake: make(chan struct{}, 1),
+
 		dependencies: dependencies,
 		persistDir:   persistDir,
 
@@ -224,6 +247,14 @@ func newContractManager(dependencies modules.Dependencies, persistDir string) (_
 	// and adds them if they are discovered.
 	go cm.threadedFolderRecheck()
 
+	// Spin up the thread that periodically scrubs the sectors of the storage
+	// folders.
+	go cm.threadedScrub()
+
+	// Spin up the thread that performs the queued sector migrations and
+	// rebalances the storage folders.
+	go cm.threadedMigrate()
+
 	// Simulate an error to make sure the cleanup code is triggered correctly.
 	if cm.dependencies.Disrupt("erroredStartup") {
 		err = errors.New("startup disrupted")
@@ -0,0 +1,388 @@
+package contractmanager
+
+import (
+	"errors"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"go.sia.tech/siad/modules"
+)
+
+var (
+	// errMigrationSameFolder is returned if a migration is requested whose
+	// source and destination are the same storage folder.
+	errMigrationSameFolder = errors.New("cannot migrate sectors to the storage folder they are stored in")
+)
+
+type (
+	// sectorMigration is a queued migration of sectors from one storage
+	// folder to another. Migrations are saved as part of the settings, which
+	// allows them to resume after a restart. The sector moves themselves go
+	// through the WAL like any other sector update.
+	sectorMigration struct {
+		From      uint16
+		To        uint16
+		All       bool
+		Sectors   uint64
+		Migrated  uint64
+		Rebalance bool
+	}
+
+	// migrationSector is a sector which is queued to be migrated.
+	migrationSector struct {
+		id    sectorID
+		index uint32
+	}
+)
+
+// capacity returns the number of sectors that fit into the storage folder.
+func (sf *storageFolder) capacity() uint64 {
+	return uint64(len(sf.usage)) * storageFolderGranularity
+}
+
+// utilization returns the fraction of the storage folder which is in use.
+func (sf *storageFolder) utilization() float64 {
+	if sf.capacity() == 0 {
+		return 1
+	}
+	return float64(sf.sectors) / float64(sf.capacity())
+}
+
+// migrationRate returns the effective rate limit of the migrator in bytes per
+// second.
+func (cm *ContractManager) migrationRate() uint64 {
+	if cm.migrationRateLimit == 0 {
+		return defaultMigrationRateLimit
+	}
+	return cm.migrationRateLimit
+}
+
+// wakeMigrator wakes the migrator without blocking if it's already awake.
+func (cm *ContractManager) wakeMigrator() {
+	select {
+	case cm.migrationWake <- struct{}{}:
+	default:
+	}
+}
+
+// removeMigration removes a migration from the queue.
+func (cm *ContractManager) removeMigration(m *sectorMigration) {
+	for i := range cm.migrations {
+		if cm.migrations[i] == m {
+			cm.migrations = append(cm.migrations[:i:i], cm.migrations[i+1:]...)
+			return
+		}
+	}
+}
+
+// removeFolderMigrations removes the queued migrations from or to the storage
+// folder with the provided index.
+func (cm *ContractManager) removeFolderMigrations(index uint16) {
+	var migrations []*sectorMigration
+	for _, m := range cm.migrations {
+		if m.From != index && m.To != index {
+			migrations = append(migrations, m)
+		}
+	}
+	cm.migrations = migrations
+}
+
+// managedQueueRebalance queues a migration from the fullest to the emptiest
+// available storage folder if the rebalance policy is enabled, no other
+// migration is queued and the utilization of the folders differs by more than
+// the rebalanceThreshold. Enough sectors are migrated for one of the folders to
+// reach the average utilization of all folders.
+func (cm *ContractManager) managedQueueRebalance() {
+	cm.wal.mu.Lock()
+	defer cm.wal.mu.Unlock()
+	if !cm.rebalance || len(cm.migrations) > 0 {
+		return
+	}
+
+	var fullest, emptiest *storageFolder
+	var totalSectors, totalCapacity uint64
+	for _, sf := range cm.availableStorageFolders() {
+		if sf.capacity() == 0 {
+			continue
+		}
+		totalSectors += sf.sectors
+		totalCapacity += sf.capacity()
+		if fullest == nil || sf.utilization() > fullest.utilization() {
+			fullest = sf
+		}
+		if emptiest == nil || sf.utilization() < emptiest.utilization() {
+			emptiest = sf
+		}
+	}
+	if fullest == nil || fullest == emptiest || fullest.utilization()-emptiest.utilization() <= rebalanceThreshold {
+		return
+	}
+
+	average := float64(totalSectors) / float64(totalCapacity)
+	var excess, deficit uint64
+	if target := uint64(average * float64(fullest.capacity())); fullest.sectors > target {
+		excess = fullest.sectors - target
+	}
+	if target := uint64(average * float64(emptiest.capacity())); target > emptiest.sectors {
+		deficit = target - emptiest.sectors
+	}
+	numSectors := excess
+	if deficit < numSectors {
+		numSectors = deficit
+	}
+	if numSectors == 0 {
+		return
+	}
+	cm.migrations = append(cm.migrations, &sectorMigration{
+		From:      fullest.index,
+		To:        emptiest.index,
+		Sectors:   numSectors,
+		Rebalance: true,
+	})
+	cm.log.Printf("Rebalancing %v sectors from storage folder %v to %v\n", numSectors, fullest.path, emptiest.path)
+}
+
+// managedMigrate migrates a batch of sectors of the first queued migration.
+// true is returned if progress was made and managedMigrate should be called
+// again, false if the migration was interrupted or there is nothing to
+// migrate.
+func (cm *ContractManager) managedMigrate() bool {
+	cm.wal.mu.Lock()
+	if len(cm.migrations) == 0 {
+		cm.wal.mu.Unlock()
+		return false
+	}
+	m := cm.migrations[0]
+	from, exists1 := cm.storageFolders[m.From]
+	to, exists2 := cm.storageFolders[m.To]
+	if !exists1 || !exists2 {
+		cm.removeMigration(m)
+		cm.wal.mu.Unlock()
+		cm.log.Printf("WARN: dropping migration from storage folder %v to %v, the storage folder doesn't exist\n", m.From, m.To)
+		return true
+	}
+	if atomic.LoadUint64(&from.atomicUnavailable) == 1 || atomic.LoadUint64(&to.atomicUnavailable) == 1 {
+		cm.wal.mu.Unlock()
+		return false
+	}
+
+	// Collect the sectors of the source folder, highest index first so that
+	// the folder can be shrunk without moving the remaining sectors.
+	var sectors []migrationSector
+	for id, sl := range cm.sectorLocations {
+		if sl.storageFolder == m.From {
+			sectors = append(sectors, migrationSector{id: id, index: sl.index})
+		}
+	}
+	cm.wal.mu.Unlock()
+	sort.Slice(sectors, func(i, j int) bool {
+		return sectors[i].index > sectors[j].index
+	})
+	if !m.All && uint64(len(sectors)) > m.Sectors {
+		sectors = sectors[:m.Sectors]
+	}
+	if len(sectors) == 0 {
+		cm.wal.mu.Lock()
+		cm.removeMigration(m)
+		cm.wal.mu.Unlock()
+		cm.log.Printf("Migrated %v sectors from storage folder %v to %v\n", m.Migrated, from.path, to.path)
+		return true
+	}
+
+	var moved, failed int
+	for _, ms := range sectors {
+		start := time.Now()
+		cm.wal.mu.Lock()
+		queued := len(cm.migrations) > 0 && cm.migrations[0] == m
+		rate := cm.migrationRate()
+		sl, exists := cm.sectorLocations[ms.id]
+		cm.wal.mu.Unlock()
+		if !queued {
+			// The migration was cancelled.
+			return true
+		}
+		if atomic.LoadUint64(&from.atomicUnavailable) == 1 || atomic.LoadUint64(&to.atomicUnavailable) == 1 {
+			return false
+		}
+		if !exists || sl.storageFolder != m.From {
+			// The sector was removed or moved since the batch was collected.
+			continue
+		}
+
+		// The source folder is locked while it's being shrunk or removed,
+		// which moves its sectors anyway. Try again later.
+		if !from.mu.TryRLock() {
+			return false
+		}
+		err := cm.wal.managedMoveSectorTo(ms.id, []*storageFolder{to})
+		from.mu.RUnlock()
+		if err != nil && err.Error() == modules.V1420HostOutOfStorageErrString {
+			cm.wal.mu.Lock()
+			cm.removeMigration(m)
+			cm.wal.mu.Unlock()
+			cm.log.Printf("WARN: dropping migration from storage folder %v to %v after migrating %v sectors, the destination is full or unavailable\n", from.path, to.path, m.Migrated)
+			return true
+		} else if err != nil {
+			failed++
+			cm.log.Printf("WARN: unable to migrate sector %v of storage folder %v: %v\n", ms.index, from.path, err)
+		} else {
+			moved++
+			cm.wal.mu.Lock()
+			m.Migrated++
+			if !m.All {
+				m.Sectors--
+			}
+			cm.wal.mu.Unlock()
+		}
+
+		// Wait until moving the sector is within the rate limit.
+		wait := time.Duration(modules.SectorSize*uint64(time.Second)/rate) - time.Since(start)
+		select {
+		case <-cm.tg.StopChan():
+			return false
+		case <-time.After(wait):
+		}
+	}
+
+	// Retry later if none of the sectors could be migrated.
+	return moved > 0 || failed == 0
+}
+
+// threadedMigrate performs the queued sector migrations in the background and
+// queues new migrations according to the rebalance policy.
+func (cm *ContractManager) threadedMigrate() {
+	// Don't spawn the loop if 'noMigrate' disruption is set.
+	if cm.dependencies.Disrupt("noMigrate") {
+		return
+	}
+
+	for {
+		select {
+		case <-cm.tg.StopChan():
+			return
+		case <-cm.migrationWake:
+		case <-time.After(migrationCheckInterval):
+		}
+
+		// Migrate sectors until the queue is empty or the migrator is
+		// interrupted.
+		func() {
+			err := cm.tg.Add()
+			if err != nil {
+				return
+			}
+			defer cm.tg.Done()
+			for {
+				cm.managedQueueRebalance()
+				if !cm.managedMigrate() {
+					return
+				}
+			}
+		}()
+	}
+}
+
+// CancelSectorMigrations cancels all queued sector migrations. Sectors which
+// were already migrated stay in their new storage folder. If the rebalance
+// policy is enabled, it will queue new migrations when necessary.
+func (cm *ContractManager) CancelSectorMigrations() error {
+	err := cm.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer cm.tg.Done()
+	cm.wal.mu.Lock()
+	cm.migrations = nil
+	syncChan := cm.wal.syncChan
+	cm.wal.mu.Unlock()
+
+	// Block until the migrations have been removed on disk.
+	<-syncChan
+	return nil
+}
+
+// MigrateSectors queues a migration of sectors from one storage folder to
+// another, which is performed in the background. If numSectors is 0, all
+// sectors of the source folder are migrated.
+func (cm *ContractManager) MigrateSectors(from, to uint16, numSectors uint64) error {
+	err := cm.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer cm.tg.Done()
+	if from == to {
+		return errMigrationSameFolder
+	}
+
+	cm.wal.mu.Lock()
+	_, exists1 := cm.storageFolders[from]
+	_, exists2 := cm.storageFolders[to]
+	if !exists1 || !exists2 {
+		cm.wal.mu.Unlock()
+		return errStorageFolderNotFound
+	}
+	cm.migrations = append(cm.migrations, &sectorMigration{
+		From:    from,
+		To:      to,
+		All:     numSectors == 0,
+		Sectors: numSectors,
+	})
+	syncChan := cm.wal.syncChan
+	cm.wal.mu.Unlock()
+
+	// Block until the migration has been written to disk.
+	<-syncChan
+	cm.wakeMigrator()
+	return nil
+}
+
+// MigrationStatus returns the settings of the sector migrator and the queued
+// migrations.
+func (cm *ContractManager) MigrationStatus() modules.StorageMigrationStatus {
+	err := cm.tg.Add()
+	if err != nil {
+		return modules.StorageMigrationStatus{}
+	}
+	defer cm.tg.Done()
+	cm.wal.mu.Lock()
+	defer cm.wal.mu.Unlock()
+
+	status := modules.StorageMigrationStatus{
+		Rebalance:  cm.rebalance,
+		RateLimit:  cm.migrationRate(),
+		Migrations: make([]modules.StorageFolderMigration, 0, len(cm.migrations)),
+	}
+	for _, m := range cm.migrations {
+		status.Migrations = append(status.Migrations, modules.StorageFolderMigration{
+			From:      m.From,
+			To:        m.To,
+			All:       m.All,
+			Sectors:   m.Sectors,
+			Migrated:  m.Migrated,
+			Rebalance: m.Rebalance,
+		})
+	}
+	return status
+}
+
+// SetMigrationSettings enables or disables the rebalance policy and sets the
+// maximum number of bytes per second moved by the migrator. A rate limit of 0
+// restores the default rate limit.
+func (cm *ContractManager) SetMigrationSettings(rebalance bool, rateLimit uint64) error {
+	err := cm.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer cm.tg.Done()
+	cm.wal.mu.Lock()
+	cm.rebalance = rebalance
+	cm.migrationRateLimit = rateLimit
+	syncChan := cm.wal.syncChan
+	cm.wal.mu.Unlock()
+
+	// Block until the settings have been written to disk.
+	<-syncChan
+	cm.wakeMigrator()
+	return nil
+}
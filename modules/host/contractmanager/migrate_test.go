@@ -0,0 +1,271 @@
+package contractmanager
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+)
+
+// dependencyNoMigrate prevents the migrator from running in the contract
+// manager.
+type dependencyNoMigrate struct {
+	modules.ProductionDependencies
+}
+
+// Disrupt prevents the migrator from running in the contract manager.
+func (*dependencyNoMigrate) Disrupt(s string) bool {
+	return s == "noMigrate"
+}
+
+// folderSectors returns the number of sectors stored in each storage folder of
+// the contract manager, keyed by the index of the folder.
+func folderSectors(cm *ContractManager) map[uint16]uint64 {
+	sectors := make(map[uint16]uint64)
+	for _, sf := range cm.StorageFolders() {
+		sectors[sf.Index] = (sf.Capacity - sf.CapacityRemaining) / modules.SectorSize
+	}
+	return sectors
+}
+
+// TestMigrateSectors checks that sectors can be migrated between storage
+// folders and that queued migrations resume after a restart.
+func TestMigrateSectors(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cmt, err := newMockedContractManagerTester(&dependencyNoMigrate{}, t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := cmt.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Add a storage folder with some sectors, then add a second storage
+	// folder so that all sectors are stored in the first one.
+	storageFolderOne := filepath.Join(cmt.persistDir, "storageFolderOne")
+	storageFolderTwo := filepath.Join(cmt.persistDir, "storageFolderTwo")
+	for _, dir := range []string{storageFolderOne, storageFolderTwo} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cmt.cm.AddStorageFolder(storageFolderOne, modules.SectorSize*64); err != nil {
+		t.Fatal(err)
+	}
+	roots := make([]crypto.Hash, 10)
+	datas := make([][]byte, 10)
+	var wg sync.WaitGroup
+	for i := range roots {
+		roots[i], datas[i] = randSector()
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := cmt.cm.AddSector(roots[i], datas[i]); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if err := cmt.cm.AddStorageFolder(storageFolderTwo, modules.SectorSize*64); err != nil {
+		t.Fatal(err)
+	}
+	sfs := cmt.cm.StorageFolders()
+	indexOne, indexTwo := sfs[0].Index, sfs[1].Index
+	if sfs[0].Path != storageFolderOne {
+		indexOne, indexTwo = indexTwo, indexOne
+	}
+
+	// Invalid migrations should be rejected.
+	if err := cmt.cm.MigrateSectors(indexOne, indexOne, 0); !errors.Is(err, errMigrationSameFolder) {
+		t.Fatal("expected errMigrationSameFolder, got", err)
+	}
+	if err := cmt.cm.MigrateSectors(indexOne, indexOne+indexTwo+1, 0); !errors.Is(err, errStorageFolderNotFound) {
+		t.Fatal("expected errStorageFolderNotFound, got", err)
+	}
+
+	// Queue two migrations while the migrator isn't running.
+	if err := cmt.cm.MigrateSectors(indexOne, indexTwo, 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmt.cm.MigrateSectors(indexTwo, indexOne, 0); err != nil {
+		t.Fatal(err)
+	}
+	status := cmt.cm.MigrationStatus()
+	if len(status.Migrations) != 2 || status.Rebalance || status.RateLimit != defaultMigrationRateLimit {
+		t.Fatal("wrong migration status", status)
+	}
+	m := status.Migrations[0]
+	if m.From != indexOne || m.To != indexTwo || m.All || m.Sectors != 3 || m.Migrated != 0 {
+		t.Fatal("wrong migration", m)
+	}
+	if !status.Migrations[1].All {
+		t.Fatal("wrong migration", status.Migrations[1])
+	}
+
+	// Cancelling should remove both migrations.
+	if err := cmt.cm.CancelSectorMigrations(); err != nil {
+		t.Fatal(err)
+	}
+	if status := cmt.cm.MigrationStatus(); len(status.Migrations) != 0 {
+		t.Fatal("migrations weren't cancelled", status.Migrations)
+	}
+
+	// Queue a migration of 3 sectors and restart the contract manager with
+	// the migrator enabled. The migration should resume.
+	if err := cmt.cm.MigrateSectors(indexOne, indexTwo, 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmt.cm.Close(); err != nil {
+		t.Fatal(err)
+	}
+	cmt.cm, err = New(filepath.Join(cmt.persistDir, modules.ContractManagerDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		if len(cmt.cm.MigrationStatus().Migrations) != 0 {
+			return errors.New("migration hasn't finished yet")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sectors := folderSectors(cmt.cm); sectors[indexOne] != 7 || sectors[indexTwo] != 3 {
+		t.Fatal("wrong number of sectors migrated", sectors)
+	}
+
+	// Migrate the remaining sectors.
+	if err := cmt.cm.MigrateSectors(indexOne, indexTwo, 0); err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		if len(cmt.cm.MigrationStatus().Migrations) != 0 {
+			return errors.New("migration hasn't finished yet")
+		}
+		if sectors := folderSectors(cmt.cm); sectors[indexOne] != 0 || sectors[indexTwo] != 10 {
+			return fmt.Errorf("wrong number of sectors migrated: %v", sectors)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// All sectors should still be readable.
+	for i, root := range roots {
+		data, err := cmt.cm.ReadSector(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if crypto.MerkleRoot(data) != crypto.MerkleRoot(datas[i]) {
+			t.Fatal("sector data doesn't match after migration")
+		}
+	}
+}
+
+// TestRebalance checks that the rebalance policy evens out the utilization of
+// the storage folders.
+func TestRebalance(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cmt, err := newContractManagerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := cmt.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Fill up a storage folder and then add two more storage folders, one of
+	// them twice as large as the first one.
+	storageFolderOne := filepath.Join(cmt.persistDir, "storageFolderOne")
+	storageFolderTwo := filepath.Join(cmt.persistDir, "storageFolderTwo")
+	storageFolderThree := filepath.Join(cmt.persistDir, "storageFolderThree")
+	for _, dir := range []string{storageFolderOne, storageFolderTwo, storageFolderThree} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cmt.cm.AddStorageFolder(storageFolderOne, modules.SectorSize*64); err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 64; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			root, data := randSector()
+			if err := cmt.cm.AddSector(root, data); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := cmt.cm.AddStorageFolder(storageFolderTwo, modules.SectorSize*64); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmt.cm.AddStorageFolder(storageFolderThree, modules.SectorSize*128); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing should happen while the rebalance policy is disabled.
+	time.Sleep(2 * migrationCheckInterval)
+	if status := cmt.cm.MigrationStatus(); len(status.Migrations) != 0 {
+		t.Fatal("migration was queued without rebalancing", status.Migrations)
+	}
+
+	// Enable the rebalance policy and wait until the folders are balanced.
+	if err := cmt.cm.SetMigrationSettings(true, 1<<30); err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		if len(cmt.cm.MigrationStatus().Migrations) != 0 {
+			return errors.New("rebalance hasn't finished yet")
+		}
+		var sizes []uint64
+		var sectors []uint64
+		for _, sf := range cmt.cm.StorageFolders() {
+			sizes = append(sizes, sf.Capacity/modules.SectorSize)
+			sectors = append(sectors, (sf.Capacity-sf.CapacityRemaining)/modules.SectorSize)
+		}
+		for i := range sizes {
+			utilization := float64(sectors[i]) / float64(sizes[i])
+			if utilization < 0.25-rebalanceThreshold || utilization > 0.25+rebalanceThreshold {
+				return fmt.Errorf("storage folders aren't balanced: %v of %v", sectors, sizes)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The settings should persist across restarts.
+	if err := cmt.cm.Close(); err != nil {
+		t.Fatal(err)
+	}
+	cmt.cm, err = New(filepath.Join(cmt.persistDir, modules.ContractManagerDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := cmt.cm.MigrationStatus(); !status.Rebalance || status.RateLimit != 1<<30 {
+		t.Fatal("migration settings weren't persisted", status)
+	}
+}
@@ -1,10 +1,13 @@
 package contractmanager
 
 import (
+	"bytes"
 	"encoding/binary"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync/atomic"
+	"time"
 
 	"gitlab.com/NebulousLabs/errors"
 	"gitlab.com/NebulousLabs/fastrand"
@@ -18,9 +21,10 @@ type (
 	// savedStorageFolder contains fields that are saved automatically to disk
 	// for each storage folder.
 	savedStorageFolder struct {
-		Index uint16
-		Path  string
-		Usage []uint64
+		Index     uint16
+		Path      string
+		Usage     []uint64
+		LastScrub time.Time
 	}
 
 	// savedSettings contains fields that are saved atomically to disk inside
@@ -28,15 +32,31 @@ type (
 	savedSettings struct {
 		SectorSalt     crypto.Hash
 		StorageFolders []savedStorageFolder
+
+		// ScrubDisabled and ScrubRateLimit are the settings of the sector
+		// scrubber. A rate limit of 0 indicates the default rate limit.
+		// CorruptSectors are the sectors which the scrubber found to be
+		// corrupted, sorted by id.
+		ScrubDisabled  bool
+		ScrubRateLimit uint64
+		CorruptSectors []sectorID
+
+		// Migrations are the queued sector migrations. Rebalance and
+		// MigrationRateLimit are the settings of the migrator, a rate limit
+		// of 0 indicates the default rate limit.
+		Migrations         []sectorMigration
+		Rebalance          bool
+		MigrationRateLimit uint64
 	}
 )
 
 // savedStorageFolder returns the persistent version of the storage folder.
 func (sf *storageFolder) savedStorageFolder() savedStorageFolder {
 	ssf := savedStorageFolder{
-		Index: sf.index,
-		Path:  sf.path,
-		Usage: make([]uint64, len(sf.usage)),
+		Index:     sf.index,
+		Path:      sf.path,
+		Usage:     make([]uint64, len(sf.usage)),
+		LastScrub: sf.lastScrub,
 	}
 	copy(ssf.Usage, sf.usage)
 	return ssf
@@ -73,11 +93,23 @@ func (cm *ContractManager) loadSettings() error {
 
 	// Copy the saved settings into the contract manager.
 	cm.sectorSalt = ss.SectorSalt
+	cm.scrubDisabled = ss.ScrubDisabled
+	cm.scrubRateLimit = ss.ScrubRateLimit
+	for _, id := range ss.CorruptSectors {
+		cm.corruptSectors[id] = struct{}{}
+	}
+	cm.rebalance = ss.Rebalance
+	cm.migrationRateLimit = ss.MigrationRateLimit
+	for i := range ss.Migrations {
+		m := ss.Migrations[i]
+		cm.migrations = append(cm.migrations, &m)
+	}
 	for i := range ss.StorageFolders {
 		sf := new(storageFolder)
 		sf.index = ss.StorageFolders[i].Index
 		sf.path = ss.StorageFolders[i].Path
 		sf.usage = ss.StorageFolders[i].Usage
+		sf.lastScrub = ss.StorageFolders[i].LastScrub
 		sf.metadataFile, err = cm.dependencies.OpenFile(filepath.Join(ss.StorageFolders[i].Path, metadataFile), os.O_RDWR, 0700)
 		if err != nil {
 			// Mark the folder as unavailable and log an error.
@@ -146,8 +178,22 @@ func (cm *ContractManager) loadSectorLocations(sf *storageFolder) {
 // easily-serializable form.
 func (cm *ContractManager) savedSettings() savedSettings {
 	ss := savedSettings{
-		SectorSalt: cm.sectorSalt,
+		SectorSalt:     cm.sectorSalt,
+		ScrubDisabled:  cm.scrubDisabled,
+		ScrubRateLimit: cm.scrubRateLimit,
+
+		Rebalance:          cm.rebalance,
+		MigrationRateLimit: cm.migrationRateLimit,
+	}
+	for _, m := range cm.migrations {
+		ss.Migrations = append(ss.Migrations, *m)
+	}
+	for id := range cm.corruptSectors {
+		ss.CorruptSectors = append(ss.CorruptSectors, id)
 	}
+	sort.Slice(ss.CorruptSectors, func(i, j int) bool {
+		return bytes.Compare(ss.CorruptSectors[i][:], ss.CorruptSectors[j][:]) < 0
+	})
 	for _, sf := range cm.storageFolders {
 		// Unset all of the usage bits in the storage folder for the queued sectors.
 		for _, sectorIndex := range sf.availableSectors {
@@ -0,0 +1,315 @@
+package contractmanager
+
+import (
+	"errors"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+)
+
+var (
+	// errScrubDisabled is returned if a scrub is requested while the scrubber
+	// is disabled.
+	errScrubDisabled = errors.New("sector scrubbing is disabled")
+)
+
+// scrubSector is a sector which is queued to be scrubbed.
+type scrubSector struct {
+	id    sectorID
+	index uint32
+}
+
+// scrubRate returns the effective rate limit of the scrubber in bytes per
+// second.
+func (cm *ContractManager) scrubRate() uint64 {
+	if cm.scrubRateLimit == 0 {
+		return defaultScrubRateLimit
+	}
+	return cm.scrubRateLimit
+}
+
+// wakeScrubber wakes the scrubber without blocking if it's already awake.
+func (cm *ContractManager) wakeScrubber() {
+	select {
+	case cm.scrubWake <- struct{}{}:
+	default:
+	}
+}
+
+// corruptSectorCount returns the number of corrupt sectors which are stored
+// in each storage folder.
+func (cm *ContractManager) corruptSectorCount() map[uint16]uint64 {
+	counts := make(map[uint16]uint64)
+	for id := range cm.corruptSectors {
+		if sl, exists := cm.sectorLocations[id]; exists {
+			counts[sl.storageFolder]++
+		}
+	}
+	return counts
+}
+
+// managedNextScrubFolder returns the available storage folder which is due to
+// be scrubbed, preferring folders for which a scrub was requested and then the
+// folders which haven't been scrubbed for the longest time. nil is returned if
+// no folder needs to be scrubbed or if the scrubber is disabled.
+func (cm *ContractManager) managedNextScrubFolder() *storageFolder {
+	cm.wal.mu.Lock()
+	defer cm.wal.mu.Unlock()
+	if cm.scrubDisabled {
+		return nil
+	}
+	var next *storageFolder
+	for _, sf := range cm.storageFolders {
+		if atomic.LoadUint64(&sf.atomicUnavailable) == 1 {
+			continue
+		}
+		if !sf.scrubRequested && time.Since(sf.lastScrub) < scrubInterval {
+			continue
+		}
+		if next == nil || (sf.scrubRequested && !next.scrubRequested) ||
+			(sf.scrubRequested == next.scrubRequested && sf.lastScrub.Before(next.lastScrub)) {
+			next = sf
+		}
+	}
+	return next
+}
+
+// managedScrubSector reads a sector from the storage folder and compares the
+// Merkle root of its data against the id of the sector. Sectors which don't
+// match are added to the corrupt sectors of the contract manager.
+func (cm *ContractManager) managedScrubSector(sf *storageFolder, ss scrubSector) {
+	cm.wal.managedLockSector(ss.id)
+	defer cm.wal.managedUnlockSector(ss.id)
+
+	// Skip the sector if it was removed or moved since the scrub started.
+	cm.wal.mu.Lock()
+	sl, exists := cm.sectorLocations[ss.id]
+	current := cm.storageFolders[sf.index] == sf
+	cm.wal.mu.Unlock()
+	if !exists || !current || sl.storageFolder != sf.index || sl.index != ss.index {
+		return
+	}
+
+	sectorData, err := readSector(sf.sectorFile, ss.index)
+	if err != nil {
+		atomic.AddUint64(&sf.atomicFailedReads, 1)
+		cm.log.Printf("WARN: unable to read sector %v of storage folder %v during scrub: %v\n", ss.index, sf.path, err)
+		return
+	}
+	atomic.AddUint64(&sf.atomicSuccessfulReads, 1)
+	corrupt := cm.managedSectorID(crypto.MerkleRoot(sectorData)) != ss.id
+
+	cm.wal.mu.Lock()
+	if !corrupt {
+		delete(cm.corruptSectors, ss.id)
+		cm.wal.mu.Unlock()
+		return
+	}
+	if _, exists := cm.corruptSectors[ss.id]; exists {
+		cm.wal.mu.Unlock()
+		return
+	}
+	cm.log.Printf("ERROR: sector %v of storage folder %v is corrupt\n", ss.index, sf.path)
+	cm.corruptSectors[ss.id] = struct{}{}
+	syncChan := cm.wal.syncChan
+	cm.wal.mu.Unlock()
+
+	// Block until the corrupt sector has been written to disk.
+	<-syncChan
+}
+
+// managedScrubFolder scrubs all sectors of a storage folder, reading at most
+// the rate limit of the scrubber. false is returned if the scrub was
+// interrupted before all sectors were scrubbed.
+func (cm *ContractManager) managedScrubFolder(sf *storageFolder) bool {
+	// Collect the sectors of the folder, sorted by index so that the sector
+	// file is read sequentially.
+	cm.wal.mu.Lock()
+	var sectors []scrubSector
+	for id, sl := range cm.sectorLocations {
+		if sl.storageFolder == sf.index {
+			sectors = append(sectors, scrubSector{id: id, index: sl.index})
+		}
+	}
+	sf.scrubbedSectors = 0
+	sf.scrubTotal = uint64(len(sectors))
+	cm.wal.mu.Unlock()
+	sort.Slice(sectors, func(i, j int) bool {
+		return sectors[i].index < sectors[j].index
+	})
+	defer func() {
+		cm.wal.mu.Lock()
+		sf.scrubbedSectors = 0
+		sf.scrubTotal = 0
+		cm.wal.mu.Unlock()
+	}()
+
+	for _, ss := range sectors {
+		start := time.Now()
+		cm.wal.mu.Lock()
+		disabled := cm.scrubDisabled
+		rate := cm.scrubRate()
+		cm.wal.mu.Unlock()
+		if disabled || atomic.LoadUint64(&sf.atomicUnavailable) == 1 {
+			return false
+		}
+
+		cm.managedScrubSector(sf, ss)
+		cm.wal.mu.Lock()
+		sf.scrubbedSectors++
+		cm.wal.mu.Unlock()
+
+		// Wait until reading the sector is within the rate limit.
+		wait := time.Duration(modules.SectorSize*uint64(time.Second)/rate) - time.Since(start)
+		select {
+		case <-cm.tg.StopChan():
+			return false
+		case <-time.After(wait):
+		}
+	}
+
+	cm.wal.mu.Lock()
+	sf.lastScrub = time.Now()
+	sf.scrubRequested = false
+	corrupt := cm.corruptSectorCount()[sf.index]
+	cm.wal.mu.Unlock()
+	cm.log.Printf("Scrubbed %v sectors of storage folder %v, %v corrupt sectors found\n", len(sectors), sf.path, corrupt)
+	return true
+}
+
+// threadedScrub periodically scrubs the sectors of the storage folders to
+// detect data which was corrupted on disk. Corruption would otherwise only be
+// noticed when a renter downloads the sector or when a storage proof fails.
+func (cm *ContractManager) threadedScrub() {
+	// Don't spawn the loop if 'noScrub' disruption is set.
+	if cm.dependencies.Disrupt("noScrub") {
+		return
+	}
+
+	for {
+		select {
+		case <-cm.tg.StopChan():
+			return
+		case <-cm.scrubWake:
+		case <-time.After(scrubCheckInterval):
+		}
+
+		// Scrub the folders which are due until the scrubber is interrupted.
+		func() {
+			err := cm.tg.Add()
+			if err != nil {
+				return
+			}
+			defer cm.tg.Done()
+			for sf := cm.managedNextScrubFolder(); sf != nil; sf = cm.managedNextScrubFolder() {
+				if !cm.managedScrubFolder(sf) {
+					return
+				}
+			}
+		}()
+	}
+}
+
+// FilterCorruptSectors returns the roots of the input which belong to sectors
+// that the scrubber found to be corrupted on disk, mapped to the index of the
+// storage folder of the sector.
+func (cm *ContractManager) FilterCorruptSectors(sectorRoots []crypto.Hash) map[crypto.Hash]uint16 {
+	corrupt := make(map[crypto.Hash]uint16)
+	ids := make([]sectorID, len(sectorRoots))
+	for i, root := range sectorRoots {
+		ids[i] = cm.managedSectorID(root)
+	}
+
+	cm.wal.mu.Lock()
+	defer cm.wal.mu.Unlock()
+	if len(cm.corruptSectors) == 0 {
+		return corrupt
+	}
+	for i, id := range ids {
+		if _, exists := cm.corruptSectors[id]; !exists {
+			continue
+		}
+		if sl, exists := cm.sectorLocations[id]; exists {
+			corrupt[sectorRoots[i]] = sl.storageFolder
+		}
+	}
+	return corrupt
+}
+
+// ScrubStatus returns the settings and the progress of the sector scrubber.
+func (cm *ContractManager) ScrubStatus() modules.StorageScrubStatus {
+	err := cm.tg.Add()
+	if err != nil {
+		return modules.StorageScrubStatus{}
+	}
+	defer cm.tg.Done()
+	cm.wal.mu.Lock()
+	defer cm.wal.mu.Unlock()
+
+	corrupt := cm.corruptSectorCount()
+	status := modules.StorageScrubStatus{
+		Enabled:   !cm.scrubDisabled,
+		RateLimit: cm.scrubRate(),
+		Folders:   make([]modules.StorageFolderScrubStatus, 0, len(cm.storageFolders)),
+	}
+	for _, sf := range cm.storageFolders {
+		status.Folders = append(status.Folders, modules.StorageFolderScrubStatus{
+			Index:           sf.index,
+			Path:            sf.path,
+			Scrubbing:       sf.scrubTotal > 0,
+			ScrubbedSectors: sf.scrubbedSectors,
+			TotalSectors:    sf.scrubTotal,
+			CorruptSectors:  corrupt[sf.index],
+			LastScrub:       sf.lastScrub,
+		})
+	}
+	sort.Slice(status.Folders, func(i, j int) bool {
+		return status.Folders[i].Index < status.Folders[j].Index
+	})
+	return status
+}
+
+// SetScrubSettings enables or disables the sector scrubber and sets the
+// maximum number of bytes per second it reads from disk. A rate limit of 0
+// restores the default rate limit.
+func (cm *ContractManager) SetScrubSettings(enabled bool, rateLimit uint64) error {
+	err := cm.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer cm.tg.Done()
+	cm.wal.mu.Lock()
+	cm.scrubDisabled = !enabled
+	cm.scrubRateLimit = rateLimit
+	syncChan := cm.wal.syncChan
+	cm.wal.mu.Unlock()
+	cm.wakeScrubber()
+
+	// Block until the settings have been written to disk.
+	<-syncChan
+	return nil
+}
+
+// StartScrub starts scrubbing all storage folders immediately instead of
+// waiting for their next scheduled scrub.
+func (cm *ContractManager) StartScrub() error {
+	err := cm.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer cm.tg.Done()
+	cm.wal.mu.Lock()
+	if cm.scrubDisabled {
+		cm.wal.mu.Unlock()
+		return errScrubDisabled
+	}
+	for _, sf := range cm.storageFolders {
+		sf.scrubRequested = true
+	}
+	cm.wal.mu.Unlock()
+	cm.wakeScrubber()
+	return nil
+}
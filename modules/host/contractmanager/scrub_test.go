@@ -0,0 +1,145 @@
+package contractmanager
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gitlab.com/NebulousLabs/fastrand"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+)
+
+// TestScrub checks that the scrubber detects sectors which were corrupted on
+// disk and that its settings are persisted.
+func TestScrub(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cmt, err := newContractManagerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := cmt.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Add a storage folder and two sectors.
+	storageFolderDir := filepath.Join(cmt.persistDir, "storageFolderOne")
+	err = os.MkdirAll(storageFolderDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cmt.cm.AddStorageFolder(storageFolderDir, modules.SectorSize*64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root1, data1 := randSector()
+	root2, data2 := randSector()
+	if err := cmt.cm.AddSector(root1, data1); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmt.cm.AddSector(root2, data2); err != nil {
+		t.Fatal(err)
+	}
+
+	// The new folder shouldn't need to be scrubbed.
+	status := cmt.cm.ScrubStatus()
+	if !status.Enabled || status.RateLimit != defaultScrubRateLimit {
+		t.Fatal("wrong scrub settings", status)
+	}
+	if len(status.Folders) != 1 || status.Folders[0].LastScrub.IsZero() {
+		t.Fatal("wrong scrub status", status.Folders)
+	}
+	lastScrub := status.Folders[0].LastScrub
+
+	// Corrupt the first sector on disk.
+	cmt.cm.wal.mu.Lock()
+	sl := cmt.cm.sectorLocations[cmt.cm.managedSectorID(root1)]
+	cmt.cm.wal.mu.Unlock()
+	f, err := os.OpenFile(filepath.Join(storageFolderDir, sectorFile), os.O_RDWR, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteAt(fastrand.Bytes(64), int64(uint64(sl.index)*modules.SectorSize)); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Scrub the folder and wait until the corrupt sector was found.
+	if err := cmt.cm.StartScrub(); err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		sf := cmt.cm.ScrubStatus().Folders[0]
+		if !sf.LastScrub.After(lastScrub) {
+			return errors.New("folder hasn't been scrubbed yet")
+		}
+		if sf.CorruptSectors != 1 {
+			return errors.New("expected 1 corrupt sector")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	corrupt := cmt.cm.FilterCorruptSectors([]crypto.Hash{root1, root2})
+	if len(corrupt) != 1 {
+		t.Fatal("expected 1 corrupt sector, got", len(corrupt))
+	}
+	if folder, exists := corrupt[root1]; !exists || folder != status.Folders[0].Index {
+		t.Fatal("corrupt sector wasn't reported", corrupt)
+	}
+
+	// The corrupt sector should be remembered after a restart.
+	if err := cmt.cm.Close(); err != nil {
+		t.Fatal(err)
+	}
+	cmt.cm, err = New(filepath.Join(cmt.persistDir, modules.ContractManagerDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if corrupt := cmt.cm.FilterCorruptSectors([]crypto.Hash{root1, root2}); len(corrupt) != 1 {
+		t.Fatal("corrupt sector wasn't persisted", corrupt)
+	}
+
+	// Deleting the sector should remove it from the corrupt sectors.
+	if err := cmt.cm.DeleteSector(root1); err != nil {
+		t.Fatal(err)
+	}
+	if corrupt := cmt.cm.FilterCorruptSectors([]crypto.Hash{root1, root2}); len(corrupt) != 0 {
+		t.Fatal("expected no corrupt sectors, got", corrupt)
+	}
+
+	// Disable the scrubber. The settings should persist across restarts.
+	if err := cmt.cm.SetScrubSettings(false, 1<<20); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmt.cm.StartScrub(); !errors.Is(err, errScrubDisabled) {
+		t.Fatal("expected errScrubDisabled, got", err)
+	}
+	lastScrub = cmt.cm.ScrubStatus().Folders[0].LastScrub
+	if err := cmt.cm.Close(); err != nil {
+		t.Fatal(err)
+	}
+	cmt.cm, err = New(filepath.Join(cmt.persistDir, modules.ContractManagerDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	status = cmt.cm.ScrubStatus()
+	if status.Enabled || status.RateLimit != 1<<20 {
+		t.Fatal("scrub settings weren't persisted", status)
+	}
+	if !status.Folders[0].LastScrub.Equal(lastScrub) {
+		t.Fatal("last scrub wasn't persisted", status.Folders[0].LastScrub, lastScrub)
+	}
+}
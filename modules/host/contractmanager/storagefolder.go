@@ -114,6 +114,15 @@ type storageFolder struct {
 	// makes it easy to do delayed-syncing.
 	metadataFile modules.File
 	sectorFile   modules.File
+
+	// lastScrub is the time at which the scrubber last finished scrubbing the
+	// folder and is saved to disk. scrubRequested indicates that the folder
+	// should be scrubbed regardless of lastScrub. scrubbedSectors and
+	// scrubTotal track the progress of a scrub which is under way.
+	lastScrub       time.Time
+	scrubRequested  bool
+	scrubbedSectors uint64
+	scrubTotal      uint64
 }
 
 // mostSignificantBit returns the index of the most significant bit of an input
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"gitlab.com/NebulousLabs/fastrand"
 
@@ -313,9 +314,10 @@ func (wal *writeAheadLog) commitAddStorageFolder(ssf savedStorageFolder) {
 	}
 
 	sf = &storageFolder{
-		index: ssf.Index,
-		path:  ssf.Path,
-		usage: ssf.Usage,
+		index:     ssf.Index,
+		path:      ssf.Path,
+		usage:     ssf.Usage,
+		lastScrub: ssf.LastScrub,
 
 		availableSectors: make(map[sectorID]uint32),
 	}
@@ -369,9 +371,12 @@ func (cm *ContractManager) AddStorageFolder(path string, size uint64) error {
 	}
 
 	// Create a storage folder object and add it to the WAL.
+	// The folder is empty and doesn't need to be scrubbed until the next
+	// scrub interval has passed.
 	newSF := &storageFolder{
-		path:  path,
-		usage: make([]uint64, sectors/64),
+		path:      path,
+		usage:     make([]uint64, sectors/64),
+		lastScrub: time.Now(),
 
 		availableSectors: make(map[sectorID]uint32),
 	}
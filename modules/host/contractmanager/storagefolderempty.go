@@ -19,6 +19,15 @@ var (
 // managedMoveSector will move a sector from its current storage folder to
 // another.
 func (wal *writeAheadLog) managedMoveSector(id sectorID) error {
+	wal.mu.Lock()
+	storageFolders := wal.cm.availableStorageFolders()
+	wal.mu.Unlock()
+	return wal.managedMoveSectorTo(id, storageFolders)
+}
+
+// managedMoveSectorTo will move a sector from its current storage folder to
+// one of the provided storage folders.
+func (wal *writeAheadLog) managedMoveSectorTo(id sectorID, storageFolders []*storageFolder) error {
 	wal.managedLockSector(id)
 	defer wal.managedUnlockSector(id)
 
@@ -49,9 +58,6 @@ func (wal *writeAheadLog) managedMoveSector(id sectorID) error {
 	}
 
 	// Place the sector into its new folder and add the atomic move to the WAL.
-	wal.mu.Lock()
-	storageFolders := wal.cm.availableStorageFolders()
-	wal.mu.Unlock()
 	for len(storageFolders) >= 1 {
 		var storageFolderIndex int
 		err := func() error {
@@ -21,6 +21,8 @@ func (wal *writeAheadLog) commitStorageFolderRemoval(sfr storageFolderRemoval) {
 	if exists {
 		delete(wal.cm.storageFolders, sfr.Index)
 	}
+	// Drop the migrations from or to the storage folder.
+	wal.cm.removeFolderMigrations(sfr.Index)
 	if exists && sf.metadataFile != nil {
 		err := sf.metadataFile.Close()
 		if err != nil {
@@ -0,0 +1,69 @@
+package host
+
+import (
+	"sync/atomic"
+
+	"go.sia.tech/siad/modules"
+)
+
+var (
+	// ErrHostDraining is returned if a renter tries to fund an ephemeral
+	// account while the host is draining and rejecting deposits.
+	ErrHostDraining = ErrorCommunication("host is draining for maintenance and not accepting deposits")
+)
+
+// managedRejectsDeposits returns whether the host is draining and rejecting
+// ephemeral account deposits.
+func (h *Host) managedRejectsDeposits() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.draining && h.drainRejectDeposits
+}
+
+// DrainStatus returns the state of the host's drain mode and the number of
+// RHP3 programs and RHP2 uploads which are still in flight.
+func (h *Host) DrainStatus() modules.HostDrainStatus {
+	h.mu.RLock()
+	draining, rejectDeposits := h.draining, h.drainRejectDeposits
+	h.mu.RUnlock()
+	programs := atomic.LoadUint64(&h.atomicInFlightPrograms)
+	uploads := atomic.LoadUint64(&h.atomicInFlightUploads)
+	return modules.HostDrainStatus{
+		Draining:         draining,
+		RejectDeposits:   rejectDeposits,
+		InFlightPrograms: programs,
+		InFlightUploads:  uploads,
+		Drained:          draining && programs == 0 && uploads == 0,
+	}
+}
+
+// SetDrain enables or disables the host's drain mode. While draining, the host
+// reports that it isn't accepting contracts, refuses to form or renew
+// contracts and, if rejectDeposits is set, refuses ephemeral account deposits.
+// Downloads and storage proofs are unaffected. The state is advertised in the
+// external settings and the price table.
+func (h *Host) SetDrain(draining, rejectDeposits bool) error {
+	err := h.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer h.tg.Done()
+
+	h.mu.Lock()
+	h.draining = draining
+	h.drainRejectDeposits = draining && rejectDeposits
+	err = h.saveSync()
+	h.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if draining {
+		h.log.Println("Host started draining, rejecting deposits:", rejectDeposits)
+	} else {
+		h.log.Println("Host stopped draining")
+	}
+
+	// Update the price table to advertise the new state.
+	h.managedUpdatePriceTable()
+	return nil
+}
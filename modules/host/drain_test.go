@@ -0,0 +1,126 @@
+package host
+
+import (
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// TestDrain checks that a draining host stops accepting contracts and,
+// optionally, ephemeral account deposits, that it advertises its state and
+// reports the programs and uploads in flight, and that the drain mode is
+// persisted.
+func TestDrain(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	// setup renter host pair
+	pair, err := newRenterHostPair(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := pair.Close()
+		if err != nil {
+			t.Error(err)
+		}
+	}()
+	ht := pair.staticHT
+	amount := types.SiacoinPrecision
+
+	// make sure the host accepts contracts
+	is := ht.host.InternalSettings()
+	is.AcceptingContracts = true
+	if err := ht.host.SetInternalSettings(is); err != nil {
+		t.Fatal(err)
+	}
+
+	// the host shouldn't be draining by default
+	if status := ht.host.DrainStatus(); status != (modules.HostDrainStatus{}) {
+		t.Fatal("unexpected drain status", status)
+	}
+	if es := ht.host.ExternalSettings(); !es.AcceptingContracts || es.Draining {
+		t.Fatal("unexpected external settings", es.AcceptingContracts, es.Draining)
+	}
+
+	// start draining without rejecting deposits
+	if err := ht.host.SetDrain(true, false); err != nil {
+		t.Fatal(err)
+	}
+	if es := ht.host.ExternalSettings(); es.AcceptingContracts || !es.Draining {
+		t.Fatal("draining host should not accept contracts", es.AcceptingContracts, es.Draining)
+	}
+	if err := pair.managedUpdatePriceTable(true); err != nil {
+		t.Fatal(err)
+	}
+	if !pair.managedPriceTable().Draining {
+		t.Fatal("price table should advertise the drain mode")
+	}
+	if _, err := pair.managedFundEphemeralAccount(amount, false); err != nil {
+		t.Fatal(err)
+	}
+	status := ht.host.DrainStatus()
+	if !status.Draining || status.RejectDeposits || status.InFlightPrograms != 0 || status.InFlightUploads != 0 || !status.Drained {
+		t.Fatal("unexpected drain status", status)
+	}
+
+	// the host isn't drained while a program or an upload is in flight
+	atomic.AddUint64(&ht.host.atomicInFlightPrograms, 1)
+	if status := ht.host.DrainStatus(); status.InFlightPrograms != 1 || status.Drained {
+		t.Fatal("unexpected drain status", status)
+	}
+	atomic.AddUint64(&ht.host.atomicInFlightPrograms, ^uint64(0))
+	atomic.AddUint64(&ht.host.atomicInFlightUploads, 1)
+	if status := ht.host.DrainStatus(); status.InFlightUploads != 1 || status.Drained {
+		t.Fatal("unexpected drain status", status)
+	}
+	atomic.AddUint64(&ht.host.atomicInFlightUploads, ^uint64(0))
+
+	// reject deposits, funding the account should fail but paying for an RPC
+	// by ephemeral account should still work
+	if err := ht.host.SetDrain(true, true); err != nil {
+		t.Fatal(err)
+	}
+	_, err = pair.managedFundEphemeralAccount(amount, false)
+	if err == nil || !strings.Contains(err.Error(), ErrHostDraining.Error()) {
+		t.Fatal("expected ErrHostDraining, got", err)
+	}
+	cost := pair.managedPriceTable().AccountBalanceCost
+	balance, err := pair.managedAccountBalance(false, cost, pair.staticAccountID, pair.staticAccountID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance.IsZero() {
+		t.Fatal("expected a funded account")
+	}
+
+	// the drain mode should be persisted
+	err = ht.host.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host, err = New(ht.cs, ht.gateway, ht.tpool, ht.wallet, ht.mux, "localhost:0", filepath.Join(ht.persistDir, modules.HostDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := ht.host.DrainStatus(); !status.Draining || !status.RejectDeposits {
+		t.Fatal("drain mode wasn't persisted", status)
+	}
+
+	// stop draining, rejecting deposits requires draining
+	if err := ht.host.SetDrain(false, true); err != nil {
+		t.Fatal(err)
+	}
+	if status := ht.host.DrainStatus(); status.Draining || status.RejectDeposits || status.Drained {
+		t.Fatal("unexpected drain status", status)
+	}
+	if es := ht.host.ExternalSettings(); !es.AcceptingContracts || es.Draining {
+		t.Fatal("unexpected external settings", es.AcceptingContracts, es.Draining)
+	}
+}
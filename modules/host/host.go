@@ -147,6 +147,13 @@ type Host struct {
 	atomicInternalErrors      uint64
 	atomicNormalErrors        uint64
 
+	// atomicInFlightPrograms is the number of programs the host is currently
+	// executing and atomicInFlightUploads is the number of RHP2 uploads it is
+	// currently processing. They are reported by the drain mode and not
+	// persistent.
+	atomicInFlightPrograms uint64
+	atomicInFlightUploads  uint64
+
 	// Dependencies.
 	cs            modules.ConsensusSet
 	g             modules.Gateway
@@ -194,6 +201,26 @@ type Host struct {
 	// of such conditions are congestion, load, liquidity, etc.
 	staticPriceTables *hostPrices
 
+	// staticRPCMetrics collects the metrics of the RHP3 RPCs and the MDM
+	// instructions.
+	staticRPCMetrics *rpcMetrics
+
+	// staticRenterFilter decides which renters are allowed to use the host.
+	staticRenterFilter *renterFilter
+
+	// The pricing policy periodically adjusts the host's prices. pricingMu
+	// serializes the adjustments, the other fields are protected by mu.
+	pricingPolicy        modules.HostPricingPolicy
+	pricingStatus        modules.HostPricingStatus
+	pricingBandwidth     uint64
+	pricingBandwidthTime time.Time
+	pricingMu            sync.Mutex
+
+	// The drain mode stops the host from forming and renewing contracts and
+	// optionally from accepting ephemeral account deposits.
+	draining            bool
+	drainRejectDeposits bool
+
 	// Fields related to RHP3 bandwidhth.
 	atomicStreamUpload   uint64
 	atomicStreamDownload uint64
@@ -382,6 +409,9 @@ func (h *Host) managedUpdatePriceTable() {
 		RegistryEntriesLeft:  h.staticRegistry.Cap() - h.staticRegistry.Len(),
 		RegistryEntriesTotal: h.staticRegistry.Cap(),
 
+		// Drain mode.
+		Draining: hes.Draining,
+
 		// Subscription related fields.
 		SubscriptionMemoryCost:       types.NewCurrency64(1),
 		SubscriptionNotificationCost: types.NewCurrency64(1),
@@ -456,7 +486,13 @@ func newHost(dependencies modules.Dependencies, smDeps modules.Dependencies, cs
 			},
 		},
 		staticRegistrySubscriptions: newRegistrySubscriptions(),
+		staticRPCMetrics:            newRPCMetrics(),
+		staticRenterFilter:          newRenterFilter(persistDir),
 		persistDir:                  persistDir,
+		pricingPolicy: modules.HostPricingPolicy{
+			Deviation: defaultPricingDeviation,
+			Smoothing: defaultPricingSmoothing,
+		},
 	}
 
 	// Create MDM.
@@ -511,6 +547,10 @@ func newHost(dependencies modules.Dependencies, smDeps modules.Dependencies, cs
 	if err != nil {
 		return nil, err
 	}
+	err = h.staticRenterFilter.managedLoadAccounts()
+	if err != nil {
+		return nil, errors.AddContext(err, "could not load the renters of the ephemeral accounts")
+	}
 	h.tg.AfterStop(func() {
 		err := h.saveSync()
 		if err != nil {
@@ -561,6 +601,12 @@ func newHost(dependencies modules.Dependencies, smDeps modules.Dependencies, cs
 	// Ensure the expired RPC tables get pruned as to not leak memory
 	go h.threadedPruneExpiredPriceTables()
 
+	// Alert the user about corrupt sectors found by the sector scrubber.
+	go h.threadedCheckCorruptSectors()
+
+	// Adjust the prices according to the pricing policy.
+	go h.threadedAdjustPrices()
+
 	return h, nil
 }
 
@@ -680,7 +726,13 @@ func (h *Host) SetInternalSettings(settings modules.HostInternalSettings) error
 	// reflects the updated settings.
 	defer h.managedUpdatePriceTable()
 	defer h.mu.Unlock()
+	return h.setInternalSettings(settings)
+}
 
+// setInternalSettings validates and applies the host's internal settings and
+// saves them to disk. The caller is responsible for updating the price table
+// afterwards. It must be called while holding h.mu.
+func (h *Host) setInternalSettings(settings modules.HostInternalSettings) (err error) {
 	// The host should not be accepting file contracts if it does not have an
 	// unlock hash.
 	if settings.AcceptingContracts {
@@ -46,6 +46,8 @@ type Output struct {
 	// FailureRefund is the amount of money that gets refunded should the
 	// program execution fail.
 	FailureRefund types.Currency
+	// DataRead is the number of bytes of program data the instruction read.
+	DataRead uint64
 }
 
 // output is the type returned by all instructions when being executed.
@@ -46,4 +46,8 @@ func TestInstructionHasSector(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	// The instruction should have read the sector root from the program data.
+	if outputs[0].DataRead != crypto.HashSize {
+		t.Fatal("wrong amount of program data read", outputs[0].DataRead)
+	}
 }
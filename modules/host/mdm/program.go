@@ -234,6 +234,9 @@ func (p *program) executeInstructions(ctx context.Context, fcSize uint64, fcRoot
 			return ErrInterrupted
 		default:
 		}
+		// Remember how much data was read before the instruction to figure
+		// out how much it read itself.
+		bytesRead := p.staticData.managedBytesRead()
 		// Increment collateral first.
 		collateral := i.Collateral()
 		err := p.addCollateral(collateral)
@@ -280,6 +283,7 @@ func (p *program) executeInstructions(ctx context.Context, fcSize uint64, fcRoot
 			ExecutionCost:        p.executionCost,
 			AdditionalCollateral: p.additionalCollateral,
 			FailureRefund:        p.failureRefund,
+			DataRead:             p.staticData.managedBytesRead() - bytesRead,
 		}
 		// Abort if the last output contained an error.
 		if output.Error != nil {
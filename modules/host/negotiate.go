@@ -331,6 +331,13 @@ func (h *Host) managedFinalizeContract(args finalizeContractArgs) ([]types.Trans
 		return nil, types.TransactionSignature{}, types.FileContractID{}, err
 	}
 
+	// Carry the approval of the renter over to the renewed contract.
+	if args.renewedSO != nil {
+		if oldRevision, err := args.renewedSO.recentRevision(); err == nil {
+			h.managedRenewedContractRenter(renterKey(oldRevision), types.Ed25519PublicKey(renterPK))
+		}
+	}
+
 	// Get the host's transaction signatures from the builder.
 	var hostTxnSignatures []types.TransactionSignature
 	_, _, _, txnSigIndices := builder.ViewAdded()
@@ -236,6 +236,11 @@ func (h *Host) managedVerifyNewContract(txnSet []types.Transaction, renterPK cry
 	if len(txnSet[len(txnSet)-1].FileContracts) < 1 {
 		return extendErr("transaction without file contract: ", ErrEmptyObject)
 	}
+	// Check that the renter is allowed to form contracts with the host. The
+	// renter is identified by the key of the new contract.
+	if err := h.staticRenterFilter.managedCheckRenters(types.Ed25519PublicKey(renterPK)); err != nil {
+		return err
+	}
 
 	h.mu.RLock()
 	blockHeight := h.blockHeight
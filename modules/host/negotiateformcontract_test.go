@@ -41,8 +41,9 @@ func TestVerifyNewContract(t *testing.T) {
 		settings: modules.HostInternalSettings{
 			CollateralBudget: types.SiacoinPrecision,
 		},
-		staticAlerter: modules.NewAlerter("test"),
-		tpool:         ht.tpool,
+		staticAlerter:      modules.NewAlerter("test"),
+		staticRenterFilter: newRenterFilter(ht.persistDir),
+		tpool:              ht.tpool,
 	}
 	curr := []types.Transaction{
 		{
@@ -115,6 +115,16 @@ func (h *Host) managedVerifyRenewedContract(so storageObligation, txnSet []types
 	if len(txnSet[len(txnSet)-1].FileContracts) < 1 {
 		return types.Currency{}, extendErr("transaction without file contract: ", ErrEmptyObject)
 	}
+	// Check that the renter of the old contract is allowed to renew contracts
+	// with the host. The key of the new contract isn't checked since renters
+	// usually derive a new key for every contract.
+	oldRevision, err := so.recentRevision()
+	if err != nil {
+		return types.Currency{}, extendErr("could not get the latest revision: ", err)
+	}
+	if err := h.staticRenterFilter.managedCheckRenters(renterKey(oldRevision)); err != nil {
+		return types.Currency{}, err
+	}
 
 	_, maxFee := h.tpool.FeeEstimation()
 	h.mu.Lock()
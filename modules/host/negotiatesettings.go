@@ -48,6 +48,10 @@ func (h *Host) externalSettings(maxFeeEstimation types.Currency) modules.HostExt
 	if unlocked, err := h.wallet.Unlocked(); err != nil || !unlocked {
 		acceptingContracts = false
 	}
+	// If the host is draining, report that it is not accepting contracts.
+	if h.draining {
+		acceptingContracts = false
+	}
 	// If the host's wallet cannot afford to put MaxCollateral coins into a
 	// contract, reduce its advertised MaxCollateral.
 	maxCollateral := h.settings.MaxCollateral
@@ -99,6 +103,8 @@ func (h *Host) externalSettings(maxFeeEstimation types.Currency) modules.HostExt
 		Version:        modules.RHPVersion,
 
 		SiaMuxPort: port,
+
+		Draining: h.draining,
 	}
 }
 
@@ -349,6 +349,10 @@ func (h *Host) threadedHandleConn(conn net.Conn) {
 func (h *Host) threadedHandleStream(stream siamux.Stream) {
 	// close the stream when the method terminates
 	var cleanup afterCloseFn
+	var rpcID types.Specifier
+	var rpcStart time.Time
+	var rpcErr error
+	recordMetrics := false
 	defer func() {
 		if h.dependencies.Disrupt("DisableStreamClose") {
 			return
@@ -363,6 +367,11 @@ func (h *Host) threadedHandleStream(stream siamux.Stream) {
 		atomic.AddUint64(&h.atomicStreamUpload, l.Uploaded())
 		atomic.AddUint64(&h.atomicStreamDownload, l.Downloaded())
 
+		// Update the metrics of the RPC.
+		if recordMetrics {
+			h.staticRPCMetrics.managedRecordRPC(rpcID, rpcErr != nil, l.Downloaded(), l.Uploaded(), time.Since(rpcStart))
+		}
+
 		// Call rpc specific cleanup if necessary.
 		if cleanup != nil {
 			cleanup()
@@ -395,7 +404,6 @@ func (h *Host) threadedHandleStream(stream siamux.Stream) {
 	}
 
 	// read the RPC id
-	var rpcID types.Specifier
 	err = modules.RPCRead(stream, &rpcID)
 	if err != nil {
 		err = errors.AddContext(err, "Failed to read RPC id")
@@ -406,6 +414,9 @@ func (h *Host) threadedHandleStream(stream siamux.Stream) {
 		return
 	}
 
+	// Only known RPCs are recorded in the metrics.
+	rpcStart = time.Now()
+	recordMetrics = true
 	switch rpcID {
 	case modules.RPCAccountBalance:
 		err = h.managedRPCAccountBalance(stream)
@@ -425,6 +436,7 @@ func (h *Host) threadedHandleStream(stream siamux.Stream) {
 		h.log.Debugf("WARN: incoming stream %v requested unknown RPC \"%v\"", stream.RemoteAddr().String(), rpcID)
 		err = errors.New(fmt.Sprintf("Unrecognized RPC id %v", rpcID))
 		atomic.AddUint64(&h.atomicUnrecognizedCalls, 1)
+		recordMetrics = false
 	}
 
 	if err != nil {
@@ -432,6 +444,7 @@ func (h *Host) threadedHandleStream(stream siamux.Stream) {
 		atomic.AddUint64(&h.atomicErroredCalls, 1)
 		h.managedLogError(err)
 	}
+	rpcErr = err
 }
 
 // threadedListen listens for incoming RPCs and spawns an appropriate handler for each.
@@ -473,6 +486,7 @@ func (h *Host) NetAddress() modules.NetAddress {
 // NetworkMetrics returns information about the types of rpc calls that have
 // been made to the host.
 func (h *Host) NetworkMetrics() modules.HostNetworkMetrics {
+	rpcMetrics, instructionMetrics := h.staticRPCMetrics.managedSnapshot()
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 	return modules.HostNetworkMetrics{
@@ -483,5 +497,8 @@ func (h *Host) NetworkMetrics() modules.HostNetworkMetrics {
 		ReviseCalls:       atomic.LoadUint64(&h.atomicReviseCalls),
 		SettingsCalls:     atomic.LoadUint64(&h.atomicSettingsCalls),
 		UnrecognizedCalls: atomic.LoadUint64(&h.atomicUnrecognizedCalls),
+
+		RPCMetrics:         rpcMetrics,
+		InstructionMetrics: instructionMetrics,
 	}
 }
@@ -160,6 +160,10 @@ func (h *Host) managedRPCLoopUnlock(s *rpcSession) error {
 // managedRPCLoopWrite reads an upload request and responds with a signature
 // for the new revision.
 func (h *Host) managedRPCLoopWrite(s *rpcSession) error {
+	// Track the upload until the RPC returns.
+	atomic.AddUint64(&h.atomicInFlightUploads, 1)
+	defer atomic.AddUint64(&h.atomicInFlightUploads, ^uint64(0))
+
 	s.extendDeadline(modules.NegotiateFileContractRevisionTime)
 	// Read the request.
 	var req modules.LoopWriteRequest
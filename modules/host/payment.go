@@ -43,6 +43,11 @@ func (h *Host) staticPayByEphemeralAccount(stream siamux.Stream, bh types.BlockH
 		return nil, errors.AddContext(err, "Could not read PayByEphemeralAccountRequest")
 	}
 
+	// check whether the renter which funded the account is allowed to pay
+	if err := h.staticRenterFilter.managedCheckAccount(req.Message.Account); err != nil {
+		return nil, errors.AddContext(err, "Renter is not allowed to pay")
+	}
+
 	// process the request
 	if err := h.staticAccountManager.callWithdraw(&req.Message, req.Signature, req.Priority, bh); err != nil {
 		return nil, errors.AddContext(err, "Withdraw failed")
@@ -93,6 +98,11 @@ func (h *Host) managedPayByContract(stream siamux.Stream, bh types.BlockHeight)
 	if err != nil {
 		return nil, errors.AddContext(err, "Could not find the most recent revision")
 	}
+
+	// check whether the renter is allowed to pay
+	if err := h.staticRenterFilter.managedCheckRenters(renterKey(currentRevision)); err != nil {
+		return nil, errors.AddContext(err, "Renter is not allowed to pay")
+	}
 	paymentRevision := revisionFromRequest(currentRevision, pbcr)
 
 	// verify the payment revision
@@ -152,6 +162,11 @@ func (h *Host) managedFundAccount(stream siamux.Stream, request modules.FundAcco
 		return types.ZeroCurrency, errors.New("can't provide a refund account on a fund account rpc")
 	}
 
+	// check whether the host accepts deposits
+	if h.managedRejectsDeposits() {
+		return types.ZeroCurrency, ErrHostDraining
+	}
+
 	// lock the storage obligation
 	h.managedLockStorageObligation(fcid)
 	defer h.managedUnlockStorageObligation(fcid)
@@ -170,6 +185,12 @@ func (h *Host) managedFundAccount(stream siamux.Stream, request modules.FundAcco
 	if err != nil {
 		return types.ZeroCurrency, errors.AddContext(err, "Could not get the latest revision")
 	}
+
+	// check whether the renter is allowed to fund the account
+	renter := renterKey(currentRevision)
+	if err := h.staticRenterFilter.managedCheckRenters(renter); err != nil {
+		return types.ZeroCurrency, errors.AddContext(err, "Renter is not allowed to fund the account")
+	}
 	paymentRevision := revisionFromRequest(currentRevision, pbcr)
 
 	// verify the payment revision
@@ -202,6 +223,10 @@ func (h *Host) managedFundAccount(stream siamux.Stream, request modules.FundAcco
 	// fsynced we'll close this so the account manager can properly lower the
 	// host's outstanding risk induced by the (immediate) deposit.
 	syncChan := make(chan struct{})
+	err = h.staticRenterFilter.managedFundedAccount(request.Account, renter)
+	if err != nil {
+		return types.ZeroCurrency, errors.AddContext(err, "Could not record the renter of the account")
+	}
 	err = h.staticAccountManager.callDeposit(request.Account, deposit, syncChan)
 	if err != nil {
 		return types.ZeroCurrency, errors.AddContext(err, "Could not deposit funds")
@@ -30,10 +30,22 @@ type persistence struct {
 	SecretKey        crypto.SecretKey             `json:"secretkey"`
 	Settings         modules.HostInternalSettings `json:"settings"`
 	UnlockHash       types.UnlockHash             `json:"unlockhash"`
+
+	// Renter Filter.
+	RenterFilterMode modules.FilterMode   `json:"renterfiltermode"`
+	RenterFilter     []types.SiaPublicKey `json:"renterfilter"`
+
+	// Pricing Policy.
+	PricingPolicy modules.HostPricingPolicy `json:"pricingpolicy"`
+
+	// Drain Mode.
+	Draining            bool `json:"draining"`
+	DrainRejectDeposits bool `json:"drainrejectdeposits"`
 }
 
 // persistData returns the data in the Host that will be saved to disk.
 func (h *Host) persistData() persistence {
+	fm, renters := h.staticRenterFilter.managedFilter()
 	return persistence{
 		// Consensus Tracking.
 		BlockHeight:  h.blockHeight,
@@ -48,6 +60,17 @@ func (h *Host) persistData() persistence {
 		SecretKey:        h.secretKey,
 		Settings:         h.settings,
 		UnlockHash:       h.unlockHash,
+
+		// Renter Filter.
+		RenterFilterMode: fm,
+		RenterFilter:     renters,
+
+		// Pricing Policy.
+		PricingPolicy: h.pricingPolicy,
+
+		// Drain Mode.
+		Draining:            h.draining,
+		DrainRejectDeposits: h.drainRejectDeposits,
 	}
 }
 
@@ -121,6 +144,24 @@ func (h *Host) loadPersistObject(p *persistence) {
 		h.settings.NetAddress = ""
 	}
 	h.unlockHash = p.UnlockHash
+
+	// Copy over the renter filter. Hosts which were persisted without a
+	// renter filter don't filter renters.
+	if p.RenterFilterMode != modules.HostDBFilterError {
+		if err := h.staticRenterFilter.managedSetFilter(p.RenterFilterMode, p.RenterFilter); err != nil {
+			h.log.Printf("WARN: renter filter loaded from persist is invalid: %v", err)
+		}
+	}
+
+	// Copy over the pricing policy. Hosts which were persisted without a
+	// pricing policy keep the default policy.
+	if p.PricingPolicy.Smoothing != 0 {
+		h.pricingPolicy = p.PricingPolicy
+	}
+
+	// Copy over the drain mode.
+	h.draining = p.Draining
+	h.drainRejectDeposits = p.DrainRejectDeposits
 }
 
 // initDB will check that the database has been initialized and if not, will
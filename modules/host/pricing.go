@@ -0,0 +1,272 @@
+package host
+
+import (
+	"math"
+	"math/big"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+var (
+	// errPricingDeviation is returned if the deviation of a pricing policy is
+	// out of bounds.
+	errPricingDeviation = errors.New("the deviation of the pricing policy must be at least 0 and less than 1")
+
+	// errPricingNoExchangeRate is returned if a pricing policy is enabled
+	// without a positive exchange rate.
+	errPricingNoExchangeRate = errors.New("the pricing policy requires an exchange rate greater than zero")
+
+	// errPricingSmoothing is returned if the smoothing of a pricing policy is
+	// out of bounds.
+	errPricingSmoothing = errors.New("the smoothing of the pricing policy must be greater than 0 and at most 1")
+
+	// errPricingTarget is returned if a price target of a pricing policy is
+	// invalid.
+	errPricingTarget = errors.New("price targets can't be negative and their max can't be lower than their min")
+)
+
+// managedPrice is a price in the host's internal settings which is managed by
+// the pricing policy.
+type managedPrice struct {
+	name       string
+	target     modules.HostPriceTarget
+	price      *types.Currency
+	unit       types.Currency
+	unitName   string
+	multiplier float64
+}
+
+// validatePricingPolicy checks that the pricing policy is valid.
+func validatePricingPolicy(policy modules.HostPricingPolicy) error {
+	if !(policy.Deviation >= 0 && policy.Deviation < 1) {
+		return errPricingDeviation
+	}
+	if !(policy.Smoothing > 0 && policy.Smoothing <= 1) {
+		return errPricingSmoothing
+	}
+	targets := []modules.HostPriceTarget{policy.StoragePrice, policy.Collateral, policy.MaxCollateral, policy.DownloadPrice, policy.UploadPrice}
+	for _, pt := range targets {
+		if !(pt.Target >= 0) || math.IsInf(pt.Target, 0) || (!pt.Max.IsZero() && pt.Max.Cmp(pt.Min) < 0) {
+			return errPricingTarget
+		}
+	}
+	if policy.Enabled && (policy.ExchangeRate == nil || policy.ExchangeRate.Rat().Sign() <= 0) {
+		return errPricingNoExchangeRate
+	}
+	return nil
+}
+
+// utilizationMultiplier maps a utilization between 0 and 1 to a price
+// multiplier between 1-deviation and 1+deviation.
+func utilizationMultiplier(utilization, deviation float64) float64 {
+	utilization = math.Max(0, math.Min(1, utilization))
+	return 1 + deviation*(2*utilization-1)
+}
+
+// adjustPrice returns the price after moving it towards the target of the
+// pricing policy. The target is converted from fiat to hastings per unit using
+// the exchange rate, scaled by the multiplier and clamped to the bounds of the
+// target. The price then moves by the smoothing fraction of the difference.
+func adjustPrice(price types.Currency, pt modules.HostPriceTarget, rate *big.Rat, unit types.Currency, multiplier, smoothing float64) types.Currency {
+	desired := new(big.Rat).SetFloat64(pt.Target * multiplier)
+	desired.Quo(desired, rate)
+	desired.Mul(desired, new(big.Rat).SetInt(types.SiacoinPrecision.Big()))
+	desired.Quo(desired, new(big.Rat).SetInt(unit.Big()))
+	if min := new(big.Rat).SetInt(pt.Min.Big()); desired.Cmp(min) < 0 {
+		desired = min
+	}
+	if max := new(big.Rat).SetInt(pt.Max.Big()); !pt.Max.IsZero() && desired.Cmp(max) > 0 {
+		desired = max
+	}
+
+	adjusted := new(big.Rat).SetInt(price.Big())
+	diff := new(big.Rat).Sub(desired, adjusted)
+	adjusted.Add(adjusted, diff.Mul(diff, new(big.Rat).SetFloat64(smoothing)))
+	return types.NewCurrency(new(big.Int).Quo(adjusted.Num(), adjusted.Denom()))
+}
+
+// managedAdjustPrices adjusts the prices of the host according to its pricing
+// policy and applies them through the same validation as SetInternalSettings.
+// Only the managed prices are changed. Every change is logged.
+func (h *Host) managedAdjustPrices() error {
+	h.pricingMu.Lock()
+	defer h.pricingMu.Unlock()
+
+	h.mu.RLock()
+	policy := h.pricingPolicy
+	collateralBudget := h.settings.CollateralBudget
+	lockedCollateral := h.financialMetrics.LockedStorageCollateral
+	lastBandwidth := h.pricingBandwidth
+	lastBandwidthTime := h.pricingBandwidthTime
+	h.mu.RUnlock()
+	if !policy.Enabled {
+		return nil
+	}
+
+	// Compute the utilization of the storage folders.
+	var capacity, capacityRemaining uint64
+	for _, sf := range h.StorageFolders() {
+		capacity += sf.Capacity
+		capacityRemaining += sf.CapacityRemaining
+	}
+	var storageUtilization float64
+	if capacity > 0 {
+		storageUtilization = float64(capacity-capacityRemaining) / float64(capacity)
+	}
+
+	// Compute the utilization of the collateral budget.
+	collateralUtilization := 1.0
+	if !collateralBudget.IsZero() {
+		collateralUtilization, _ = new(big.Rat).SetFrac(lockedCollateral.Big(), collateralBudget.Big()).Float64()
+	}
+
+	// Compute the bandwidth usage since the previous adjustment. The
+	// bandwidth prices aren't adjusted for the usage until it was measured.
+	sent, received, _, err := h.BandwidthCounters()
+	if err != nil {
+		return errors.AddContext(err, "unable to get the bandwidth usage")
+	}
+	now := time.Now()
+	totalBandwidth := sent + received
+	var bandwidth uint64
+	bandwidthUtilization := 0.5
+	if elapsed := now.Sub(lastBandwidthTime).Seconds(); !lastBandwidthTime.IsZero() && elapsed > 0 && totalBandwidth >= lastBandwidth {
+		bandwidth = uint64(float64(totalBandwidth-lastBandwidth) / elapsed)
+		if policy.TargetBandwidth > 0 {
+			bandwidthUtilization = float64(bandwidth) / float64(2*policy.TargetBandwidth)
+		}
+	}
+
+	// Adjust the managed prices. The host offers less collateral the more of
+	// its collateral budget is locked. The prices are applied to the current
+	// settings under the same lock they are read with, so that concurrent
+	// changes to other settings aren't reverted.
+	storageMultiplier := utilizationMultiplier(storageUtilization, policy.Deviation)
+	collateralMultiplier := utilizationMultiplier(1-collateralUtilization, policy.Deviation)
+	bandwidthMultiplier := utilizationMultiplier(bandwidthUtilization, policy.Deviation)
+
+	h.mu.Lock()
+	h.pricingBandwidth = totalBandwidth
+	h.pricingBandwidthTime = now
+	h.pricingStatus = modules.HostPricingStatus{
+		LastAdjustment:        now,
+		StorageUtilization:    storageUtilization,
+		CollateralUtilization: collateralUtilization,
+		Bandwidth:             bandwidth,
+	}
+	policy = h.pricingPolicy
+	if !policy.Enabled {
+		h.mu.Unlock()
+		return nil
+	}
+	// A persisted policy might predate the current validation rules.
+	if err := validatePricingPolicy(policy); err != nil {
+		h.mu.Unlock()
+		return err
+	}
+	rate := policy.ExchangeRate.Rat()
+	newSettings := h.settings
+	prices := []managedPrice{
+		{"storage price", policy.StoragePrice, &newSettings.MinStoragePrice, modules.BlockBytesPerMonthTerabyte, "/TB/month", storageMultiplier},
+		{"collateral", policy.Collateral, &newSettings.Collateral, modules.BlockBytesPerMonthTerabyte, "/TB/month", collateralMultiplier},
+		{"max collateral", policy.MaxCollateral, &newSettings.MaxCollateral, types.NewCurrency64(1), "", collateralMultiplier},
+		{"download price", policy.DownloadPrice, &newSettings.MinDownloadBandwidthPrice, modules.BytesPerTerabyte, "/TB", bandwidthMultiplier},
+		{"upload price", policy.UploadPrice, &newSettings.MinUploadBandwidthPrice, modules.BytesPerTerabyte, "/TB", bandwidthMultiplier},
+	}
+	var changed bool
+	for _, mp := range prices {
+		if mp.target.Target == 0 {
+			continue
+		}
+		price := adjustPrice(*mp.price, mp.target, rate, mp.unit, mp.multiplier, policy.Smoothing)
+		if price.Equals(*mp.price) {
+			continue
+		}
+		h.log.Printf("Pricing policy changed the %v from %v%v to %v%v\n", mp.name, mp.price.Mul(mp.unit).HumanString(), mp.unitName, price.Mul(mp.unit).HumanString(), mp.unitName)
+		*mp.price = price
+		changed = true
+	}
+
+	// Lowering the download price might violate the ratio restrictions of the
+	// base RPC price and the sector access price.
+	if maxBaseRPCPrice := newSettings.MaxBaseRPCPrice(); newSettings.MinBaseRPCPrice.Cmp(maxBaseRPCPrice) > 0 {
+		h.log.Printf("Pricing policy changed the base RPC price from %v to %v\n", newSettings.MinBaseRPCPrice.HumanString(), maxBaseRPCPrice.HumanString())
+		newSettings.MinBaseRPCPrice = maxBaseRPCPrice
+		changed = true
+	}
+	if maxSectorAccessPrice := newSettings.MaxSectorAccessPrice(); newSettings.MinSectorAccessPrice.Cmp(maxSectorAccessPrice) > 0 {
+		h.log.Printf("Pricing policy changed the sector access price from %v to %v\n", newSettings.MinSectorAccessPrice.HumanString(), maxSectorAccessPrice.HumanString())
+		newSettings.MinSectorAccessPrice = maxSectorAccessPrice
+		changed = true
+	}
+	if !changed {
+		h.mu.Unlock()
+		return nil
+	}
+	err = h.setInternalSettings(newSettings)
+	h.mu.Unlock()
+
+	// Update the price table to reflect the new prices.
+	h.managedUpdatePriceTable()
+	return err
+}
+
+// threadedAdjustPrices periodically adjusts the host's prices according to
+// its pricing policy.
+func (h *Host) threadedAdjustPrices() {
+	for {
+		func() {
+			if err := h.tg.Add(); err != nil {
+				return
+			}
+			defer h.tg.Done()
+			if err := h.managedAdjustPrices(); err != nil {
+				h.log.Println("WARN: unable to adjust prices:", err)
+			}
+		}()
+
+		// Block until next cycle.
+		select {
+		case <-h.tg.StopChan():
+			return
+		case <-time.After(pricingAdjustmentFrequency):
+			continue
+		}
+	}
+}
+
+// PricingStatus returns the host's pricing policy and the inputs of its most
+// recent price adjustment.
+func (h *Host) PricingStatus() modules.HostPricingStatus {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	status := h.pricingStatus
+	status.Policy = h.pricingPolicy
+	return status
+}
+
+// SetPricingPolicy sets the policy which automatically adjusts the host's
+// prices. If the policy is enabled, the prices are adjusted immediately.
+func (h *Host) SetPricingPolicy(policy modules.HostPricingPolicy) error {
+	err := h.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer h.tg.Done()
+	if err := validatePricingPolicy(policy); err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	h.pricingPolicy = policy
+	err = h.saveSync()
+	h.mu.Unlock()
+	if err != nil {
+		return errors.AddContext(err, "unable to save the pricing policy")
+	}
+	return errors.AddContext(h.managedAdjustPrices(), "unable to adjust prices")
+}
@@ -0,0 +1,175 @@
+package host
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// TestPricingPolicy checks that the pricing policy adjusts the host's prices
+// towards their targets and that the policy is persisted.
+func TestPricingPolicy(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := ht.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// The pricing policy should be disabled by default.
+	policy := ht.host.PricingStatus().Policy
+	if policy.Enabled || policy.Deviation != defaultPricingDeviation || policy.Smoothing != defaultPricingSmoothing {
+		t.Fatal("unexpected default pricing policy", policy)
+	}
+
+	// Invalid policies should be rejected.
+	rate, err := types.ParseExchangeRate("0.01USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := modules.HostPricingPolicy{
+		Enabled:      true,
+		ExchangeRate: rate,
+		Smoothing:    1,
+		StoragePrice: modules.HostPriceTarget{Target: 0.5},
+	}
+	invalid := func(modify func(p *modules.HostPricingPolicy), expected error) {
+		t.Helper()
+		p := valid
+		modify(&p)
+		if err := ht.host.SetPricingPolicy(p); !errors.Contains(err, expected) {
+			t.Fatalf("expected %v, got %v", expected, err)
+		}
+	}
+	invalid(func(p *modules.HostPricingPolicy) { p.ExchangeRate = nil }, errPricingNoExchangeRate)
+	invalid(func(p *modules.HostPricingPolicy) { p.ExchangeRate = &types.ExchangeRate{} }, errPricingNoExchangeRate)
+	var zeroRate types.ExchangeRate
+	if err := json.Unmarshal([]byte(`""`), &zeroRate); err != nil {
+		t.Fatal(err)
+	}
+	invalid(func(p *modules.HostPricingPolicy) { p.ExchangeRate = &zeroRate }, errPricingNoExchangeRate)
+	invalid(func(p *modules.HostPricingPolicy) { p.Deviation = 1 }, errPricingDeviation)
+	invalid(func(p *modules.HostPricingPolicy) { p.Smoothing = 0 }, errPricingSmoothing)
+	invalid(func(p *modules.HostPricingPolicy) { p.Collateral.Target = -1 }, errPricingTarget)
+	invalid(func(p *modules.HostPricingPolicy) {
+		p.StoragePrice.Min = types.SiacoinPrecision
+		p.StoragePrice.Max = types.NewCurrency64(1)
+	}, errPricingTarget)
+
+	// Enable the policy without deviation and smoothing. The prices should
+	// match their targets at an exchange rate of 0.01 USD/SC.
+	policy = valid
+	policy.Collateral.Target = 1
+	policy.MaxCollateral.Target = 10
+	policy.DownloadPrice.Target = 0.25
+	if err := ht.host.SetPricingPolicy(policy); err != nil {
+		t.Fatal(err)
+	}
+	settings := ht.host.InternalSettings()
+	if !settings.MinStoragePrice.Equals(types.SiacoinPrecision.Mul64(50).Div(modules.BlockBytesPerMonthTerabyte)) {
+		t.Fatal("wrong storage price", settings.MinStoragePrice)
+	}
+	if !settings.Collateral.Equals(types.SiacoinPrecision.Mul64(100).Div(modules.BlockBytesPerMonthTerabyte)) {
+		t.Fatal("wrong collateral", settings.Collateral)
+	}
+	if !settings.MaxCollateral.Equals(types.SiacoinPrecision.Mul64(1000)) {
+		t.Fatal("wrong max collateral", settings.MaxCollateral)
+	}
+	if !settings.MinDownloadBandwidthPrice.Equals(types.SiacoinPrecision.Mul64(25).Div(modules.BytesPerTerabyte)) {
+		t.Fatal("wrong download price", settings.MinDownloadBandwidthPrice)
+	}
+	if !settings.MinUploadBandwidthPrice.Equals(modules.DefaultUploadBandwidthPrice) {
+		t.Fatal("unmanaged upload price was changed", settings.MinUploadBandwidthPrice)
+	}
+	if status := ht.host.PricingStatus(); status.LastAdjustment.IsZero() || status.StorageUtilization != 0 || status.CollateralUtilization != 0 {
+		t.Fatal("unexpected pricing status", status)
+	}
+
+	// With smoothing, the storage price should move part of the way towards
+	// its target.
+	oldPrice := settings.MinStoragePrice
+	target := types.SiacoinPrecision.Mul64(100).Div(modules.BlockBytesPerMonthTerabyte)
+	policy.StoragePrice.Target = 1
+	policy.Smoothing = 0.5
+	if err := ht.host.SetPricingPolicy(policy); err != nil {
+		t.Fatal(err)
+	}
+	price := ht.host.InternalSettings().MinStoragePrice
+	if price.Cmp(oldPrice) <= 0 || price.Cmp(target) >= 0 {
+		t.Fatal("storage price didn't move part of the way towards its target", price)
+	}
+
+	// The storage price should be bounded.
+	policy.Smoothing = 1
+	policy.StoragePrice.Max = oldPrice
+	if err := ht.host.SetPricingPolicy(policy); err != nil {
+		t.Fatal(err)
+	}
+	if price := ht.host.InternalSettings().MinStoragePrice; !price.Equals(oldPrice) {
+		t.Fatal("storage price isn't bounded", price)
+	}
+
+	// Adjusting the prices should only change the managed prices.
+	is := ht.host.InternalSettings()
+	is.MaxDuration++
+	if err := ht.host.SetInternalSettings(is); err != nil {
+		t.Fatal(err)
+	}
+	if err := ht.host.managedAdjustPrices(); err != nil {
+		t.Fatal(err)
+	}
+	if maxDuration := ht.host.InternalSettings().MaxDuration; maxDuration != is.MaxDuration {
+		t.Fatal("unmanaged setting was reverted", maxDuration)
+	}
+
+	// The host's storage and collateral budget are unused, so the storage
+	// price should be lowered and the collateral raised by the deviation.
+	policy.StoragePrice = modules.HostPriceTarget{Target: 0.5}
+	policy.Deviation = 0.5
+	if err := ht.host.SetPricingPolicy(policy); err != nil {
+		t.Fatal(err)
+	}
+	settings = ht.host.InternalSettings()
+	if !settings.MinStoragePrice.Equals(types.SiacoinPrecision.Mul64(25).Div(modules.BlockBytesPerMonthTerabyte)) {
+		t.Fatal("wrong storage price", settings.MinStoragePrice)
+	}
+	if !settings.Collateral.Equals(types.SiacoinPrecision.Mul64(150).Div(modules.BlockBytesPerMonthTerabyte)) {
+		t.Fatal("wrong collateral", settings.Collateral)
+	}
+
+	// Disabling the policy should leave the prices untouched.
+	policy.Enabled = false
+	policy.StoragePrice.Target = 1
+	if err := ht.host.SetPricingPolicy(policy); err != nil {
+		t.Fatal(err)
+	}
+	if price := ht.host.InternalSettings().MinStoragePrice; !price.Equals(settings.MinStoragePrice) {
+		t.Fatal("disabled policy changed the storage price", price)
+	}
+
+	// The policy should be persisted.
+	err = ht.host.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host, err = New(ht.cs, ht.gateway, ht.tpool, ht.wallet, ht.mux, "localhost:0", filepath.Join(ht.persistDir, modules.HostDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := ht.host.PricingStatus().Policy
+	if loaded.Enabled || loaded.ExchangeRate == nil || loaded.ExchangeRate.String() != rate.String() || loaded.Deviation != 0.5 || loaded.StoragePrice.Target != 1 || loaded.MaxCollateral.Target != 10 {
+		t.Fatal("pricing policy wasn't persisted", loaded)
+	}
+}
@@ -0,0 +1,290 @@
+package host
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/persist"
+	"go.sia.tech/siad/types"
+)
+
+const (
+	// accountRentersFile is the name of the file which maps the ephemeral
+	// accounts of the host to the renters which funded them.
+	accountRentersFile = "accountrenters.json"
+)
+
+var (
+	// ErrRenterFiltered is returned if a renter which isn't allowed by the
+	// renter filter of the host tries to form or renew a contract, fund an
+	// ephemeral account or execute a program.
+	ErrRenterFiltered = ErrorCommunication("host is not accepting contracts or payments from this renter")
+
+	// errFilterModeError is returned if the renter filter is set to the error
+	// filter mode.
+	errFilterModeError = errors.New("cannot set renter filter mode, provided filter mode is an error")
+
+	// errWhitelistWithoutRenters is returned if the whitelist is enabled
+	// without any renters.
+	errWhitelistWithoutRenters = errors.New("cannot enable whitelist without renters")
+
+	// accountRentersMetadata is the header of the account renters file.
+	accountRentersMetadata = persist.Metadata{
+		Header:  "Sia Host Account Renters",
+		Version: "1.0",
+	}
+)
+
+type (
+	// renterFilter decides which renters are allowed to use the host.
+	// Renters are identified by the public key in the unlock conditions of
+	// their contracts. Since renters usually derive a new key for every
+	// contract, renewals are only checked against the key of the renewed
+	// contract and a whitelisted key is carried over to the new contract.
+	// Payments by ephemeral account are attributed to the renter whose
+	// contract last funded the account. This mapping is persisted next to the
+	// accounts and pruned when the accounts expire.
+	renterFilter struct {
+		accounts map[modules.AccountID]types.SiaPublicKey
+		mode     modules.FilterMode
+		renters  map[string]types.SiaPublicKey
+		mu       sync.Mutex
+
+		staticPersistPath string
+	}
+
+	// accountRenter is the persisted renter of an ephemeral account.
+	accountRenter struct {
+		Account types.SiaPublicKey `json:"account"`
+		Renter  types.SiaPublicKey `json:"renter"`
+	}
+)
+
+// newRenterFilter creates a new renterFilter which allows all renters and
+// persists the renters of the ephemeral accounts in persistDir.
+func newRenterFilter(persistDir string) *renterFilter {
+	return &renterFilter{
+		accounts:          make(map[modules.AccountID]types.SiaPublicKey),
+		mode:              modules.HostDBDisableFilter,
+		renters:           make(map[string]types.SiaPublicKey),
+		staticPersistPath: filepath.Join(persistDir, accountRentersFile),
+	}
+}
+
+// renterKey returns the public key of the renter from the unlock conditions of
+// a file contract revision.
+func renterKey(rev types.FileContractRevision) types.SiaPublicKey {
+	if len(rev.UnlockConditions.PublicKeys) == 0 {
+		return types.SiaPublicKey{}
+	}
+	return rev.UnlockConditions.PublicKeys[0]
+}
+
+// allowed returns whether the filter allows the renter with the provided key.
+// It must be called while holding the lock.
+func (rf *renterFilter) allowed(key string) bool {
+	_, listed := rf.renters[key]
+	switch rf.mode {
+	case modules.HostDBActivateBlacklist:
+		return !listed
+	case modules.HostDBActiveWhitelist:
+		return listed
+	default:
+		return true
+	}
+}
+
+// managedCheckRenters returns ErrRenterFiltered if any of the provided renters
+// isn't allowed to use the host.
+func (rf *renterFilter) managedCheckRenters(renters ...types.SiaPublicKey) error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	for _, renter := range renters {
+		if !rf.allowed(renter.String()) {
+			return ErrRenterFiltered
+		}
+	}
+	return nil
+}
+
+// managedRenewedContract carries the approval of the renter of a renewed
+// contract over to the key of the new contract. It returns whether the renters
+// of the filter changed.
+func (rf *renterFilter) managedRenewedContract(oldRenter, newRenter types.SiaPublicKey) bool {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	if rf.mode != modules.HostDBActiveWhitelist {
+		return false
+	}
+	if _, listed := rf.renters[oldRenter.String()]; !listed {
+		return false
+	}
+	if _, listed := rf.renters[newRenter.String()]; listed {
+		return false
+	}
+	rf.renters[newRenter.String()] = newRenter
+	return true
+}
+
+// managedCheckAccount returns ErrRenterFiltered if the renter which funded the
+// ephemeral account isn't allowed to use the host. Accounts without a known
+// renter are only rejected while the whitelist is active.
+func (rf *renterFilter) managedCheckAccount(id modules.AccountID) error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	renter, exists := rf.accounts[id]
+	if !exists && rf.mode == modules.HostDBActiveWhitelist {
+		return ErrRenterFiltered
+	}
+	if exists && !rf.allowed(renter.String()) {
+		return ErrRenterFiltered
+	}
+	return nil
+}
+
+// managedFundedAccount records and persists the renter which funded the
+// ephemeral account.
+func (rf *renterFilter) managedFundedAccount(id modules.AccountID, renter types.SiaPublicKey) error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	if current, exists := rf.accounts[id]; exists && current.Equals(renter) {
+		return nil
+	}
+	rf.accounts[id] = renter
+	return rf.saveAccounts()
+}
+
+// managedPruneAccounts removes the renters of the provided ephemeral accounts,
+// which have expired.
+func (rf *renterFilter) managedPruneAccounts(ids []modules.AccountID) error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	var pruned bool
+	for _, id := range ids {
+		if _, exists := rf.accounts[id]; exists {
+			delete(rf.accounts, id)
+			pruned = true
+		}
+	}
+	if !pruned {
+		return nil
+	}
+	return rf.saveAccounts()
+}
+
+// managedLoadAccounts loads the renters of the ephemeral accounts from disk.
+func (rf *renterFilter) managedLoadAccounts() error {
+	var ars []accountRenter
+	err := persist.LoadJSON(accountRentersMetadata, &ars, rf.staticPersistPath)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	for _, ar := range ars {
+		var id modules.AccountID
+		id.FromSPK(ar.Account)
+		rf.accounts[id] = ar.Renter
+	}
+	return nil
+}
+
+// saveAccounts persists the renters of the ephemeral accounts. It must be
+// called while holding the lock.
+func (rf *renterFilter) saveAccounts() error {
+	ars := make([]accountRenter, 0, len(rf.accounts))
+	for id, renter := range rf.accounts {
+		ars = append(ars, accountRenter{
+			Account: id.SPK(),
+			Renter:  renter,
+		})
+	}
+	return persist.SaveJSON(accountRentersMetadata, ars, rf.staticPersistPath)
+}
+
+// managedFilter returns the filter mode and the renters of the filter.
+func (rf *renterFilter) managedFilter() (modules.FilterMode, []types.SiaPublicKey) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	renters := make([]types.SiaPublicKey, 0, len(rf.renters))
+	for _, renter := range rf.renters {
+		renters = append(renters, renter)
+	}
+	return rf.mode, renters
+}
+
+// managedSetFilter sets the filter mode and the renters of the filter.
+func (rf *renterFilter) managedSetFilter(fm modules.FilterMode, renters []types.SiaPublicKey) error {
+	if fm == modules.HostDBFilterError {
+		return errFilterModeError
+	}
+	if fm == modules.HostDBActiveWhitelist && len(renters) == 0 {
+		return errWhitelistWithoutRenters
+	}
+	filtered := make(map[string]types.SiaPublicKey)
+	if fm != modules.HostDBDisableFilter {
+		for _, renter := range renters {
+			filtered[renter.String()] = renter
+		}
+	}
+
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	rf.mode = fm
+	rf.renters = filtered
+	return nil
+}
+
+// RenterFilter returns the filter mode of the host's renter filter and the
+// renters it applies to.
+func (h *Host) RenterFilter() (modules.FilterMode, []types.SiaPublicKey) {
+	return h.staticRenterFilter.managedFilter()
+}
+
+// managedRenewedContractRenter carries the approval of the renter of a renewed
+// contract over to the renter key of the new contract and persists the renter
+// filter if it changed.
+func (h *Host) managedRenewedContractRenter(oldRenter, newRenter types.SiaPublicKey) {
+	if !h.staticRenterFilter.managedRenewedContract(oldRenter, newRenter) {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := h.saveSync(); err != nil {
+		h.log.Println("WARN: failed to persist the renter filter after a renewal:", err)
+	}
+}
+
+// SetRenterFilter sets the filter mode of the host's renter filter and the
+// renters it applies to. With the whitelist enabled, only the provided renters
+// can form and renew contracts, fund ephemeral accounts and execute programs on
+// the host. With the blacklist enabled, the provided renters can't.
+//
+// Renters are identified by the renter key in the unlock conditions of their
+// contracts, which is reported as the renter public key of the host's
+// contracts. Renewing a contract only requires the key of the renewed contract
+// to be allowed, and while the whitelist is active the key of the new contract
+// is added to it. Renters which derive a new key for every contract, like the
+// siad renter, can therefore keep renewing but can't form new contracts with
+// an active whitelist unless the key of the new contract is listed.
+func (h *Host) SetRenterFilter(fm modules.FilterMode, renters []types.SiaPublicKey) error {
+	err := h.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer h.tg.Done()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := h.staticRenterFilter.managedSetFilter(fm, renters); err != nil {
+		return err
+	}
+	return h.saveSync()
+}
@@ -0,0 +1,179 @@
+package host
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// TestRenterFilter checks that renters which aren't allowed by the renter
+// filter can't form contracts, fund ephemeral accounts or pay the host, and
+// that the filter is persisted.
+func TestRenterFilter(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	// setup renter host pair
+	pair, err := newRenterHostPair(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := pair.Close()
+		if err != nil {
+			t.Error(err)
+		}
+	}()
+	ht := pair.staticHT
+	_, pk := crypto.GenerateKeyPair()
+	otherPK := types.Ed25519PublicKey(pk)
+
+	// the renter filter should be disabled by default
+	if fm, renters := ht.host.RenterFilter(); fm != modules.HostDBDisableFilter || len(renters) != 0 {
+		t.Fatal("unexpected renter filter", fm, renters)
+	}
+
+	// fund the account and pay using the account and the contract
+	amount := types.SiacoinPrecision
+	if _, err := pair.managedFundEphemeralAccount(amount, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := pair.managedUpdatePriceTable(false); err != nil {
+		t.Fatal(err)
+	}
+	if err := pair.managedUpdatePriceTable(true); err != nil {
+		t.Fatal(err)
+	}
+
+	// invalid filters should be rejected
+	if err := ht.host.SetRenterFilter(modules.HostDBFilterError, nil); !errors.Contains(err, errFilterModeError) {
+		t.Fatal("expected errFilterModeError, got", err)
+	}
+	if err := ht.host.SetRenterFilter(modules.HostDBActiveWhitelist, nil); !errors.Contains(err, errWhitelistWithoutRenters) {
+		t.Fatal("expected errWhitelistWithoutRenters, got", err)
+	}
+
+	// helpers to check whether the renter is filtered, the RPC errors are
+	// received as strings
+	txnSet := []types.Transaction{{FileContracts: []types.FileContract{{}}}}
+	var renterPK crypto.PublicKey
+	copy(renterPK[:], pair.staticRenterPK.Key)
+	isFiltered := func(err error) bool {
+		return err != nil && strings.Contains(err.Error(), ErrRenterFiltered.Error())
+	}
+	assertFiltered := func(filtered bool) {
+		t.Helper()
+		_, err := pair.managedFundEphemeralAccount(amount, false)
+		if filtered != isFiltered(err) {
+			t.Fatal("unexpected error funding the account", err)
+		}
+		err = pair.managedUpdatePriceTable(false)
+		if filtered != isFiltered(err) {
+			t.Fatal("unexpected error paying by ephemeral account", err)
+		}
+		err = pair.managedUpdatePriceTable(true)
+		if filtered != isFiltered(err) {
+			t.Fatal("unexpected error paying by contract", err)
+		}
+		err = ht.host.managedVerifyNewContract(txnSet, renterPK, ht.host.ExternalSettings())
+		if filtered != errors.Contains(err, ErrRenterFiltered) {
+			t.Fatal("unexpected error forming a contract", err)
+		}
+	}
+
+	// blacklist the renter
+	if err := ht.host.SetRenterFilter(modules.HostDBActivateBlacklist, []types.SiaPublicKey{pair.staticRenterPK}); err != nil {
+		t.Fatal(err)
+	}
+	assertFiltered(true)
+
+	// whitelist another renter
+	if err := ht.host.SetRenterFilter(modules.HostDBActiveWhitelist, []types.SiaPublicKey{otherPK}); err != nil {
+		t.Fatal(err)
+	}
+	assertFiltered(true)
+
+	// whitelist the renter
+	if err := ht.host.SetRenterFilter(modules.HostDBActiveWhitelist, []types.SiaPublicKey{otherPK, pair.staticRenterPK}); err != nil {
+		t.Fatal(err)
+	}
+	assertFiltered(false)
+
+	// accounts without a known renter are rejected while the whitelist is
+	// active
+	var unknown modules.AccountID
+	unknown.FromSPK(otherPK)
+	if err := ht.host.staticRenterFilter.managedCheckAccount(unknown); !errors.Contains(err, ErrRenterFiltered) {
+		t.Fatal("expected ErrRenterFiltered, got", err)
+	}
+
+	// renewing a contract of a whitelisted renter should whitelist the key of
+	// the new contract, but only if the renewed contract's key is listed
+	_, pk = crypto.GenerateKeyPair()
+	renewedPK := types.Ed25519PublicKey(pk)
+	_, pk = crypto.GenerateKeyPair()
+	unlistedPK := types.Ed25519PublicKey(pk)
+	ht.host.managedRenewedContractRenter(unlistedPK, renewedPK)
+	if err := ht.host.staticRenterFilter.managedCheckRenters(renewedPK); !errors.Contains(err, ErrRenterFiltered) {
+		t.Fatal("approval of an unlisted renter was carried over", err)
+	}
+	ht.host.managedRenewedContractRenter(pair.staticRenterPK, renewedPK)
+	if err := ht.host.staticRenterFilter.managedCheckRenters(renewedPK); err != nil {
+		t.Fatal("approval wasn't carried over to the renewed contract", err)
+	}
+
+	// the filter should be persisted
+	err = ht.host.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host, err = New(ht.cs, ht.gateway, ht.tpool, ht.wallet, ht.mux, "localhost:0", filepath.Join(ht.persistDir, modules.HostDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fm, renters := ht.host.RenterFilter()
+	if fm != modules.HostDBActiveWhitelist || len(renters) != 3 {
+		t.Fatal("renter filter wasn't persisted", fm, renters)
+	}
+
+	// the renter of the account should be persisted, so paying by ephemeral
+	// account is rejected once the renter is blacklisted
+	if err := ht.host.SetRenterFilter(modules.HostDBActivateBlacklist, []types.SiaPublicKey{pair.staticRenterPK}); err != nil {
+		t.Fatal(err)
+	}
+	rf := ht.host.staticRenterFilter
+	if err := rf.managedCheckAccount(pair.staticAccountID); !errors.Contains(err, ErrRenterFiltered) {
+		t.Fatal("renter of the account wasn't persisted", err)
+	}
+	if err := rf.managedCheckAccount(unknown); err != nil {
+		t.Fatal("unknown account should be allowed by the blacklist", err)
+	}
+
+	// the renters of expired accounts should be pruned
+	if err := rf.managedPruneAccounts([]modules.AccountID{pair.staticAccountID}); err != nil {
+		t.Fatal(err)
+	}
+	rf = newRenterFilter(filepath.Join(ht.persistDir, modules.HostDir))
+	if err := rf.managedLoadAccounts(); err != nil {
+		t.Fatal(err)
+	}
+	if len(rf.accounts) != 0 {
+		t.Fatal("renter of the expired account wasn't pruned", rf.accounts)
+	}
+
+	// disabling the filter should allow all renters
+	if err := ht.host.SetRenterFilter(modules.HostDBDisableFilter, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := ht.host.staticRenterFilter.managedCheckRenters(otherPK, pair.staticRenterPK); err != nil {
+		t.Fatal(err)
+	}
+}
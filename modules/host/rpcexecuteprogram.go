@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"sync/atomic"
 	"time"
 
 	"gitlab.com/NebulousLabs/errors"
@@ -19,6 +20,10 @@ import (
 
 // managedRPCExecuteProgram handles incoming ExecuteProgram RPCs.
 func (h *Host) managedRPCExecuteProgram(stream siamux.Stream) error {
+	// Track the program until the RPC returns.
+	atomic.AddUint64(&h.atomicInFlightPrograms, 1)
+	defer atomic.AddUint64(&h.atomicInFlightPrograms, ^uint64(0))
+
 	// read the price table
 	pt, err := h.staticReadPriceTableID(stream)
 	if err != nil {
@@ -86,6 +91,12 @@ func (h *Host) managedRPCExecuteProgram(stream siamux.Stream) error {
 		if err != nil {
 			return errors.AddContext(err, fmt.Sprintf("failed to get storage obligation snapshot for contract %v", fcid))
 		}
+		// Check that the renter of the contract is allowed to execute
+		// programs on it.
+		err = h.staticRenterFilter.managedCheckRenters(renterKey(sos.RecentRevision()))
+		if err != nil {
+			return errors.AddContext(err, fmt.Sprintf("renter of contract %v is not allowed to execute programs", fcid))
+		}
 	}
 
 	// Get the remaining unallocated collateral.
@@ -138,9 +149,11 @@ func (h *Host) managedRPCExecuteProgram(stream siamux.Stream) error {
 		return errors.AddContext(err, "Failed to write cancellation token")
 	}
 
-	// Handle outputs.
+	// Handle outputs. The instructions are executed in order, so the latency
+	// of an instruction is the time between its output and the previous one.
 	executionFailed := false
 	numOutputs := 0
+	lastOutput := time.Now()
 	var output mdm.Output
 	for output = range outputs {
 		// Remember number of returned outputs.
@@ -152,6 +165,11 @@ func (h *Host) managedRPCExecuteProgram(stream siamux.Stream) error {
 			continue // continue to drain the channel
 		}
 
+		// Update the metrics of the instruction.
+		now := time.Now()
+		h.staticRPCMetrics.managedRecordInstruction(types.Specifier(program[numOutputs-1].Specifier), output.Error != nil, output.DataRead, uint64(len(output.Output)), now.Sub(lastOutput))
+		lastOutput = now
+
 		// Sanity check output when error occurred
 		if executionFailed && len(output.Output) > 0 {
 			err = fmt.Errorf("output.Error != nil but len(output.Output) == %v", len(output.Output))
@@ -513,6 +513,24 @@ func TestExecuteHasSectorProgram(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	// The RPC and the instruction should show up in the network metrics. The
+	// RPC is recorded after the stream is closed so we retry.
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		nm := ht.host.NetworkMetrics()
+		rpc := nm.RPCMetrics[modules.RPCExecuteProgram.String()]
+		if rpc.Calls == 0 || rpc.BytesIn == 0 || rpc.BytesOut == 0 {
+			return fmt.Errorf("ExecuteProgram RPC wasn't recorded: %+v", rpc)
+		}
+		instruction := nm.InstructionMetrics[types.Specifier(modules.SpecifierHasSector).String()]
+		if instruction.Calls != 1 || instruction.Errors != 0 {
+			return fmt.Errorf("HasSector instruction wasn't recorded: %+v", instruction)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	// Execute program again. This time pay for 1 less byte of bandwidth. This
 	// should fail.
 	program, data = pb.Program()
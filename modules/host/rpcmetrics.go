@@ -0,0 +1,86 @@
+package host
+
+import (
+	"sync"
+	"time"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// rpcMetrics collects the metrics of the RHP3 RPCs and the MDM instructions
+// executed by the host. The metrics are not persisted.
+type rpcMetrics struct {
+	instructions map[types.Specifier]*modules.HostRPCMetrics
+	rpcs         map[types.Specifier]*modules.HostRPCMetrics
+	mu           sync.Mutex
+}
+
+// newRPCMetrics creates a new rpcMetrics object.
+func newRPCMetrics() *rpcMetrics {
+	return &rpcMetrics{
+		instructions: make(map[types.Specifier]*modules.HostRPCMetrics),
+		rpcs:         make(map[types.Specifier]*modules.HostRPCMetrics),
+	}
+}
+
+// record adds a call to the metrics of the provided specifier.
+func record(metrics map[types.Specifier]*modules.HostRPCMetrics, id types.Specifier, failed bool, bytesIn, bytesOut uint64, latency time.Duration) {
+	m, exists := metrics[id]
+	if !exists {
+		m = &modules.HostRPCMetrics{
+			LatencyHistogram: make([]uint64, len(modules.HostRPCLatencyBuckets)+1),
+		}
+		metrics[id] = m
+	}
+	m.Calls++
+	if failed {
+		m.Errors++
+	}
+	m.BytesIn += bytesIn
+	m.BytesOut += bytesOut
+	m.TotalLatency += latency
+
+	bucket := len(modules.HostRPCLatencyBuckets)
+	for i, upperBound := range modules.HostRPCLatencyBuckets {
+		if latency <= upperBound {
+			bucket = i
+			break
+		}
+	}
+	m.LatencyHistogram[bucket]++
+}
+
+// snapshot returns a copy of the provided metrics keyed by the string
+// representation of their specifier.
+func snapshot(metrics map[types.Specifier]*modules.HostRPCMetrics) map[string]modules.HostRPCMetrics {
+	s := make(map[string]modules.HostRPCMetrics, len(metrics))
+	for id, m := range metrics {
+		c := *m
+		c.LatencyHistogram = append([]uint64(nil), m.LatencyHistogram...)
+		s[id.String()] = c
+	}
+	return s
+}
+
+// managedRecordInstruction adds an executed MDM instruction to the metrics.
+// The incoming bandwidth of an instruction is the program data it read.
+func (rm *rpcMetrics) managedRecordInstruction(id types.Specifier, failed bool, bytesIn, bytesOut uint64, latency time.Duration) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	record(rm.instructions, id, failed, bytesIn, bytesOut, latency)
+}
+
+// managedRecordRPC adds a handled RPC to the metrics.
+func (rm *rpcMetrics) managedRecordRPC(id types.Specifier, failed bool, bytesIn, bytesOut uint64, latency time.Duration) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	record(rm.rpcs, id, failed, bytesIn, bytesOut, latency)
+}
+
+// managedSnapshot returns a copy of the RPC and instruction metrics.
+func (rm *rpcMetrics) managedSnapshot() (rpcs, instructions map[string]modules.HostRPCMetrics) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	return snapshot(rm.rpcs), snapshot(rm.instructions)
+}
@@ -0,0 +1,68 @@
+package host
+
+import (
+	"testing"
+	"time"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// TestRPCMetrics is a unit test for recording RPC and instruction metrics.
+func TestRPCMetrics(t *testing.T) {
+	t.Parallel()
+
+	rm := newRPCMetrics()
+	id := types.NewSpecifier("TestRPC")
+	numBuckets := len(modules.HostRPCLatencyBuckets) + 1
+
+	// Record a fast successful call, a slow failed call and a call which
+	// exceeds the largest bucket.
+	rm.managedRecordRPC(id, false, 10, 20, time.Millisecond)
+	rm.managedRecordRPC(id, true, 1, 2, modules.HostRPCLatencyBuckets[2])
+	rm.managedRecordRPC(id, false, 0, 0, time.Hour)
+	rm.managedRecordInstruction(id, true, 3, 5, time.Millisecond)
+
+	rpcs, instructions := rm.managedSnapshot()
+	m, exists := rpcs[id.String()]
+	if !exists {
+		t.Fatal("rpc wasn't recorded")
+	}
+	if m.Calls != 3 || m.Errors != 1 {
+		t.Fatal("wrong number of calls or errors", m.Calls, m.Errors)
+	}
+	if m.BytesIn != 11 || m.BytesOut != 22 {
+		t.Fatal("wrong bandwidth", m.BytesIn, m.BytesOut)
+	}
+	if m.TotalLatency != time.Millisecond+modules.HostRPCLatencyBuckets[2]+time.Hour {
+		t.Fatal("wrong total latency", m.TotalLatency)
+	}
+	if len(m.LatencyHistogram) != numBuckets {
+		t.Fatal("wrong number of buckets", len(m.LatencyHistogram))
+	}
+	expected := make([]uint64, numBuckets)
+	expected[0] = 1
+	expected[2] = 1
+	expected[numBuckets-1] = 1
+	for i := range expected {
+		if m.LatencyHistogram[i] != expected[i] {
+			t.Fatalf("wrong histogram %v != %v", m.LatencyHistogram, expected)
+		}
+	}
+	if m.LatencyPercentile(0.5) != modules.HostRPCLatencyBuckets[2] {
+		t.Fatal("wrong median latency", m.LatencyPercentile(0.5))
+	}
+
+	// The instruction should be tracked separately.
+	i := instructions[id.String()]
+	if i.Calls != 1 || i.Errors != 1 || i.BytesIn != 3 || i.BytesOut != 5 {
+		t.Fatalf("wrong instruction metrics %+v", i)
+	}
+
+	// Modifying the snapshot shouldn't modify the metrics.
+	m.LatencyHistogram[0] = 100
+	rpcs, _ = rm.managedSnapshot()
+	if rpcs[id.String()].LatencyHistogram[0] != 1 {
+		t.Fatal("snapshot wasn't a deep copy")
+	}
+}
@@ -57,7 +57,7 @@ func (h *Host) managedRPCRenewContract(stream siamux.Stream) error {
 	hsk := h.secretKey
 	contractPrice := pt.ContractPrice
 	is := h.settings // internal settings
-	ac := is.AcceptingContracts
+	ac := is.AcceptingContracts && !h.draining
 	lockedCollateral := h.financialMetrics.LockedStorageCollateral
 	unlockHash := h.unlockHash
 	h.mu.RUnlock()
@@ -118,6 +118,14 @@ func (h *Host) managedRPCRenewContract(stream siamux.Stream) error {
 		return errors.AddContext(err, "managedRPCRenewContract: host is not accepting a renewal")
 	}
 
+	// Check if the renter is allowed to renew the contract. Only the key of the
+	// renewed contract is checked since renters usually derive a new key for
+	// every contract.
+	err = h.staticRenterFilter.managedCheckRenters(renterKey(currentRevision))
+	if err != nil {
+		return errors.AddContext(err, "managedRPCRenewContract: renter is not allowed to renew")
+	}
+
 	// Verify the final revision against the current revision. We use the
 	// ZeroCurrency here since the basePrice will cover the renewal rpc cost.
 	// That way the new contract pays for the renewal instead of the old one.
@@ -0,0 +1,131 @@
+package host
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gitlab.com/NebulousLabs/bolt"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// managedCorruptSectors returns the corrupt sectors of the storage manager
+// which are part of unresolved storage obligations, sorted by root.
+func (h *Host) managedCorruptSectors() ([]modules.CorruptSector, error) {
+	// Avoid reading the storage obligations if the scrubber didn't find any
+	// corrupt sectors.
+	var numCorrupt uint64
+	for _, sf := range h.StorageManager.ScrubStatus().Folders {
+		numCorrupt += sf.CorruptSectors
+	}
+	if numCorrupt == 0 {
+		return []modules.CorruptSector{}, nil
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	corrupt := make(map[crypto.Hash]*modules.CorruptSector)
+	err := h.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketStorageObligations).ForEach(func(_, soBytes []byte) error {
+			var so storageObligation
+			if err := json.Unmarshal(soBytes, &so); err != nil {
+				return build.ExtendErr("unable to unmarshal storage obligation:", err)
+			}
+			if so.ObligationStatus != obligationUnresolved {
+				return nil
+			}
+			for root, folder := range h.StorageManager.FilterCorruptSectors(so.SectorRoots) {
+				cs, exists := corrupt[root]
+				if !exists {
+					cs = &modules.CorruptSector{
+						Root:          root,
+						StorageFolder: folder,
+					}
+					corrupt[root] = cs
+				}
+				cs.Obligations = append(cs.Obligations, so.id())
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sectors := make([]modules.CorruptSector, 0, len(corrupt))
+	for _, cs := range corrupt {
+		sectors = append(sectors, *cs)
+	}
+	sort.Slice(sectors, func(i, j int) bool {
+		return bytes.Compare(sectors[i].Root[:], sectors[j].Root[:]) < 0
+	})
+	return sectors, nil
+}
+
+// managedUpdateCorruptSectorsAlert registers an alert listing the storage
+// obligations which are affected by corrupt sectors, or unregisters it if
+// there are none.
+func (h *Host) managedUpdateCorruptSectorsAlert() {
+	sectors, err := h.managedCorruptSectors()
+	if err != nil {
+		h.log.Println("Unable to check for corrupt sectors:", err)
+		return
+	}
+	if len(sectors) == 0 {
+		h.staticAlerter.UnregisterAlert(modules.AlertIDHostCorruptSectors)
+		return
+	}
+
+	seen := make(map[types.FileContractID]struct{})
+	var obligations []string
+	for _, cs := range sectors {
+		for _, id := range cs.Obligations {
+			if _, exists := seen[id]; !exists {
+				seen[id] = struct{}{}
+				obligations = append(obligations, id.String())
+			}
+		}
+	}
+	cause := fmt.Sprintf("%v corrupt sectors affect %v storage obligations: %v", len(sectors), len(obligations), strings.Join(obligations, ", "))
+	h.staticAlerter.RegisterAlert(modules.AlertIDHostCorruptSectors, AlertMSGHostCorruptSectors, cause, modules.SeverityCritical)
+}
+
+// threadedCheckCorruptSectors periodically checks whether the sector scrubber
+// found corrupt sectors and updates the corresponding alert.
+func (h *Host) threadedCheckCorruptSectors() {
+	for {
+		func() {
+			if err := h.tg.Add(); err != nil {
+				return
+			}
+			defer h.tg.Done()
+			h.managedUpdateCorruptSectorsAlert()
+		}()
+
+		// Block until next cycle.
+		select {
+		case <-h.tg.StopChan():
+			return
+		case <-time.After(corruptSectorsCheckFrequency):
+			continue
+		}
+	}
+}
+
+// CorruptSectors returns the sectors which the sector scrubber found to be
+// corrupted on disk, together with the unresolved storage obligations that
+// contain them.
+func (h *Host) CorruptSectors() ([]modules.CorruptSector, error) {
+	if err := h.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer h.tg.Done()
+	return h.managedCorruptSectors()
+}
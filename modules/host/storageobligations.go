@@ -334,6 +334,10 @@ func (sos StorageObligationSnapshot) UnallocatedCollateral() types.Currency {
 // module
 func (so *storageObligation) StorageObligation() modules.StorageObligation {
 	valid, missed := so.payouts()
+	var renterPK types.SiaPublicKey
+	if rev, err := so.recentRevision(); err == nil {
+		renterPK = renterKey(rev)
+	}
 	return modules.StorageObligation{
 		ContractCost:             so.ContractCost,
 		DataSize:                 so.fileSize(),
@@ -344,6 +348,7 @@ func (so *storageObligation) StorageObligation() modules.StorageObligation {
 		PotentialDownloadRevenue: so.PotentialDownloadRevenue,
 		PotentialStorageRevenue:  so.PotentialStorageRevenue,
 		PotentialUploadRevenue:   so.PotentialUploadRevenue,
+		RenterPublicKey:          renterPK,
 		RiskedCollateral:         so.RiskedCollateral,
 		SectorRootsCount:         uint64(len(so.SectorRoots)),
 		TransactionFeesAdded:     so.TransactionFeesAdded,
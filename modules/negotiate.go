@@ -343,6 +343,10 @@ type (
 		Version        string `json:"version"`
 
 		SiaMuxPort string `json:"siamuxport"`
+
+		// Draining indicates that the host is draining for maintenance. A
+		// draining host doesn't accept new contracts or renewals.
+		Draining bool `json:"draining"`
 	}
 
 	// HostOldExternalSettings are the pre-v1.4.0 host settings.
@@ -0,0 +1,267 @@
+package modules
+
+import (
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/types"
+)
+
+var (
+	// ErrIncompleteTransaction is returned when finalizing a partially signed
+	// transaction which is missing signatures.
+	ErrIncompleteTransaction = errors.New("partially signed transaction is missing signatures")
+
+	// ErrInvalidPartialSignature is returned when a partially signed
+	// transaction contains a signature which doesn't verify.
+	ErrInvalidPartialSignature = errors.New("partially signed transaction contains an invalid signature")
+
+	// ErrPartialTransactionMismatch is returned when merging partially signed
+	// transactions which don't sign the same transaction.
+	ErrPartialTransactionMismatch = errors.New("partially signed transactions sign different transactions")
+)
+
+type (
+	// PartiallySignedTransaction is a portable container for a transaction
+	// which needs to be signed by multiple parties. It holds the unsigned
+	// transaction, its unconfirmed parents and the signatures collected so
+	// far for every input. Signers sign the whole transaction, which makes
+	// their signatures independent of each other so that containers signed
+	// by different parties can be merged.
+	PartiallySignedTransaction struct {
+		Transaction types.Transaction      `json:"transaction"`
+		Parents     []types.Transaction    `json:"parents"`
+		Inputs      []PartiallySignedInput `json:"inputs"`
+	}
+
+	// PartiallySignedInput contains the unlock conditions of an input of a
+	// partially signed transaction and the signatures collected for it.
+	PartiallySignedInput struct {
+		ParentID         crypto.Hash                  `json:"parentid"`
+		UnlockConditions types.UnlockConditions       `json:"unlockconditions"`
+		Signatures       []types.TransactionSignature `json:"signatures"`
+	}
+
+	// PartiallySignedInputStatus describes how many signatures are required
+	// for an input and which public keys signed it so far.
+	PartiallySignedInputStatus struct {
+		ParentID  crypto.Hash `json:"parentid"`
+		Required  uint64      `json:"required"`
+		Collected uint64      `json:"collected"`
+		Signers   []uint64    `json:"signers"`
+	}
+)
+
+// NewPartiallySignedTransaction creates a partially signed transaction from a
+// transaction and its parents. Signatures which are already part of the
+// transaction are kept as collected signatures.
+func NewPartiallySignedTransaction(txn types.Transaction, parents []types.Transaction) (PartiallySignedTransaction, error) {
+	pst := PartiallySignedTransaction{
+		Transaction: txn,
+		Parents:     parents,
+	}
+	pst.Transaction.TransactionSignatures = nil
+	inputs := make(map[crypto.Hash]int)
+	addInput := func(parentID crypto.Hash, uc types.UnlockConditions) error {
+		if _, exists := inputs[parentID]; exists {
+			return types.ErrDoubleSpend
+		}
+		inputs[parentID] = len(pst.Inputs)
+		pst.Inputs = append(pst.Inputs, PartiallySignedInput{
+			ParentID:         parentID,
+			UnlockConditions: uc,
+		})
+		return nil
+	}
+	for _, sci := range txn.SiacoinInputs {
+		if err := addInput(crypto.Hash(sci.ParentID), sci.UnlockConditions); err != nil {
+			return PartiallySignedTransaction{}, err
+		}
+	}
+	for _, fcr := range txn.FileContractRevisions {
+		if err := addInput(crypto.Hash(fcr.ParentID), fcr.UnlockConditions); err != nil {
+			return PartiallySignedTransaction{}, err
+		}
+	}
+	for _, sfi := range txn.SiafundInputs {
+		if err := addInput(crypto.Hash(sfi.ParentID), sfi.UnlockConditions); err != nil {
+			return PartiallySignedTransaction{}, err
+		}
+	}
+	for _, sig := range txn.TransactionSignatures {
+		i, exists := inputs[sig.ParentID]
+		if !exists {
+			return PartiallySignedTransaction{}, types.ErrFrivolousSignature
+		}
+		pst.Inputs[i].addSignature(sig)
+	}
+	return pst, nil
+}
+
+// addSignature adds a signature to the input unless the public key already
+// signed it.
+func (psi *PartiallySignedInput) addSignature(sig types.TransactionSignature) bool {
+	for _, existing := range psi.Signatures {
+		if existing.PublicKeyIndex == sig.PublicKeyIndex {
+			return false
+		}
+	}
+	psi.Signatures = append(psi.Signatures, sig)
+	return true
+}
+
+// verifySignature checks that the signature was created by the public key it
+// references and covers the whole transaction at the provided height.
+func (pst PartiallySignedTransaction) verifySignature(input PartiallySignedInput, sig types.TransactionSignature, height types.BlockHeight) error {
+	if sig.ParentID != input.ParentID || sig.PublicKeyIndex >= uint64(len(input.UnlockConditions.PublicKeys)) {
+		return ErrInvalidPartialSignature
+	}
+	if !sig.CoveredFields.WholeTransaction || sig.Timelock > height {
+		return ErrInvalidPartialSignature
+	}
+	pk := input.UnlockConditions.PublicKeys[sig.PublicKeyIndex]
+	if pk.Algorithm != types.SignatureEd25519 {
+		return ErrInvalidPartialSignature
+	}
+	txn := pst.Transaction
+	txn.TransactionSignatures = []types.TransactionSignature{sig}
+	var edPK crypto.PublicKey
+	copy(edPK[:], pk.Key)
+	var edSig crypto.Signature
+	copy(edSig[:], sig.Signature)
+	if err := crypto.VerifyHash(txn.SigHash(0, height), edPK, edSig); err != nil {
+		return errors.Compose(err, ErrInvalidPartialSignature)
+	}
+	return nil
+}
+
+// complete returns whether the input has enough signatures.
+func (psi PartiallySignedInput) complete() bool {
+	return uint64(len(psi.Signatures)) >= psi.UnlockConditions.SignaturesRequired
+}
+
+// ID returns the id of the transaction which is being signed.
+func (pst PartiallySignedTransaction) ID() types.TransactionID {
+	return pst.Transaction.ID()
+}
+
+// Complete returns whether all inputs of the transaction have enough
+// signatures.
+func (pst PartiallySignedTransaction) Complete() bool {
+	for _, input := range pst.Inputs {
+		if !input.complete() {
+			return false
+		}
+	}
+	return true
+}
+
+// Status returns the signing status of every input.
+func (pst PartiallySignedTransaction) Status() []PartiallySignedInputStatus {
+	status := make([]PartiallySignedInputStatus, 0, len(pst.Inputs))
+	for _, input := range pst.Inputs {
+		s := PartiallySignedInputStatus{
+			ParentID:  input.ParentID,
+			Required:  input.UnlockConditions.SignaturesRequired,
+			Collected: uint64(len(input.Signatures)),
+			Signers:   make([]uint64, 0, len(input.Signatures)),
+		}
+		for _, sig := range input.Signatures {
+			s.Signers = append(s.Signers, sig.PublicKeyIndex)
+		}
+		status = append(status, s)
+	}
+	return status
+}
+
+// Merge adds the signatures collected by another partially signed version of
+// the same transaction. Every signature is verified at the provided height
+// and the merge is rejected if one of them is invalid.
+func (pst *PartiallySignedTransaction) Merge(other PartiallySignedTransaction, height types.BlockHeight) error {
+	if pst.ID() != other.ID() || len(pst.Inputs) != len(other.Inputs) {
+		return ErrPartialTransactionMismatch
+	}
+	for i := range pst.Inputs {
+		if pst.Inputs[i].ParentID != other.Inputs[i].ParentID {
+			return ErrPartialTransactionMismatch
+		}
+		for _, sig := range other.Inputs[i].Signatures {
+			if err := pst.verifySignature(pst.Inputs[i], sig, height); err != nil {
+				return err
+			}
+		}
+	}
+	for i := range pst.Inputs {
+		for _, sig := range other.Inputs[i].Signatures {
+			pst.Inputs[i].addSignature(sig)
+		}
+	}
+	return nil
+}
+
+// Sign adds a signature for every public key of the inputs that still require
+// signatures which can be signed by 'sign'. 'sign' has the same semantics as
+// Wallet.SignTransaction and returns ErrUnknownSigningKey for keys it doesn't
+// own. The signatures are verified at the provided height, which should be
+// the height used by 'sign'. The number of added signatures is returned.
+func (pst *PartiallySignedTransaction) Sign(sign func(txn *types.Transaction, toSign []crypto.Hash) error, height types.BlockHeight) (added int, err error) {
+	for i := range pst.Inputs {
+		input := &pst.Inputs[i]
+		for j, pk := range input.UnlockConditions.PublicKeys {
+			if input.complete() {
+				break
+			}
+			if pk.Algorithm != types.SignatureEd25519 {
+				continue
+			}
+			// Sign a copy of the transaction which only contains the new
+			// signature. The signature covers the whole transaction except
+			// for the other signatures.
+			txn := pst.Transaction
+			txn.TransactionSignatures = []types.TransactionSignature{{
+				ParentID:       input.ParentID,
+				CoveredFields:  types.FullCoveredFields,
+				PublicKeyIndex: uint64(j),
+			}}
+			err := sign(&txn, []crypto.Hash{input.ParentID})
+			if errors.Contains(err, ErrUnknownSigningKey) {
+				continue
+			} else if err != nil {
+				return added, err
+			}
+			sig := txn.TransactionSignatures[0]
+			if err := pst.verifySignature(*input, sig, height); err != nil {
+				return added, err
+			}
+			if input.addSignature(sig) {
+				added++
+			}
+		}
+	}
+	return added, nil
+}
+
+// Finalize returns the parents and the fully signed transaction. Only the
+// required number of signatures which are valid at the provided height is
+// added for each input.
+func (pst PartiallySignedTransaction) Finalize(height types.BlockHeight) ([]types.Transaction, error) {
+	txn := pst.Transaction
+	txn.TransactionSignatures = nil
+	for _, input := range pst.Inputs {
+		var valid uint64
+		for _, sig := range input.Signatures {
+			if valid == input.UnlockConditions.SignaturesRequired {
+				break
+			}
+			if pst.verifySignature(input, sig, height) != nil {
+				continue
+			}
+			txn.TransactionSignatures = append(txn.TransactionSignatures, sig)
+			valid++
+		}
+		if valid < input.UnlockConditions.SignaturesRequired {
+			return nil, ErrIncompleteTransaction
+		}
+	}
+	return append(append([]types.Transaction(nil), pst.Parents...), txn), nil
+}
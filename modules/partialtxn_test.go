@@ -0,0 +1,153 @@
+package modules
+
+import (
+	"bytes"
+	"testing"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/types"
+)
+
+// TestPartiallySignedTransaction tests signing a 2-of-3 multisig transaction
+// by two parties and merging their signatures.
+func TestPartiallySignedTransaction(t *testing.T) {
+	// Create the keys of the three parties.
+	var sks []crypto.SecretKey
+	uc := types.UnlockConditions{SignaturesRequired: 2}
+	for i := 0; i < 3; i++ {
+		sk, pk := crypto.GenerateKeyPair()
+		sks = append(sks, sk)
+		uc.PublicKeys = append(uc.PublicKeys, types.Ed25519PublicKey(pk))
+	}
+	txn := types.Transaction{
+		SiacoinInputs: []types.SiacoinInput{{
+			ParentID:         types.SiacoinOutputID{1},
+			UnlockConditions: uc,
+		}},
+		SiacoinOutputs: []types.SiacoinOutput{{
+			Value:      types.SiacoinPrecision,
+			UnlockHash: types.UnlockHash{2},
+		}},
+	}
+
+	// signer creates a function which signs like Wallet.SignTransaction using
+	// a single key.
+	signer := func(sk crypto.SecretKey) func(*types.Transaction, []crypto.Hash) error {
+		return func(txn *types.Transaction, toSign []crypto.Hash) error {
+			pk := sk.PublicKey()
+			for i, sig := range txn.TransactionSignatures {
+				if !bytes.Equal(uc.PublicKeys[sig.PublicKeyIndex].Key, pk[:]) {
+					return ErrUnknownSigningKey
+				}
+				encodedSig := crypto.SignHash(txn.SigHash(i, 0), sk)
+				txn.TransactionSignatures[i].Signature = encodedSig[:]
+			}
+			return nil
+		}
+	}
+
+	pst1, err := NewPartiallySignedTransaction(txn, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pst2, err := NewPartiallySignedTransaction(txn, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Sign with the first and the last key.
+	if added, err := pst1.Sign(signer(sks[0]), 0); err != nil || added != 1 {
+		t.Fatal("expected one signature", added, err)
+	}
+	if added, err := pst1.Sign(signer(sks[0]), 0); err != nil || added != 0 {
+		t.Fatal("key signed twice", added, err)
+	}
+	if added, err := pst2.Sign(signer(sks[2]), 0); err != nil || added != 1 {
+		t.Fatal("expected one signature", added, err)
+	}
+	if pst1.Complete() || pst2.Complete() {
+		t.Fatal("transaction shouldn't be complete")
+	}
+	if _, err := pst1.Finalize(0); !errors.Contains(err, ErrIncompleteTransaction) {
+		t.Fatal("expected ErrIncompleteTransaction, got", err)
+	}
+
+	// Errors other than unknown keys should be returned.
+	pst4 := pst1
+	pst4.Inputs = append([]PartiallySignedInput(nil), pst1.Inputs...)
+	failing := func(*types.Transaction, []crypto.Hash) error { return ErrLockedWallet }
+	if _, err := pst4.Sign(failing, 0); !errors.Contains(err, ErrLockedWallet) {
+		t.Fatal("expected ErrLockedWallet, got", err)
+	}
+
+	// Invalid signatures should be rejected.
+	forging := func(txn *types.Transaction, _ []crypto.Hash) error {
+		txn.TransactionSignatures[0].Signature = make([]byte, crypto.SignatureSize)
+		return nil
+	}
+	if _, err := pst4.Sign(forging, 0); !errors.Contains(err, ErrInvalidPartialSignature) {
+		t.Fatal("expected ErrInvalidPartialSignature, got", err)
+	}
+	forged := pst2
+	forged.Inputs = []PartiallySignedInput{pst2.Inputs[0]}
+	forged.Inputs[0].Signatures = []types.TransactionSignature{pst2.Inputs[0].Signatures[0]}
+	forged.Inputs[0].Signatures[0].Signature = make([]byte, crypto.SignatureSize)
+	if err := pst4.Merge(forged, 0); !errors.Contains(err, ErrInvalidPartialSignature) {
+		t.Fatal("expected ErrInvalidPartialSignature, got", err)
+	}
+
+	// Finalizing should skip invalid signatures.
+	pst4.Inputs[0].Signatures = append(forged.Inputs[0].Signatures, pst1.Inputs[0].Signatures...)
+	if !pst4.Complete() {
+		t.Fatal("transaction should be complete")
+	}
+	if _, err := pst4.Finalize(0); !errors.Contains(err, ErrIncompleteTransaction) {
+		t.Fatal("expected ErrIncompleteTransaction, got", err)
+	}
+
+	// Merge the signatures.
+	if err := pst1.Merge(pst2, 0); err != nil {
+		t.Fatal(err)
+	}
+	if !pst1.Complete() {
+		t.Fatal("transaction should be complete")
+	}
+	status := pst1.Status()
+	if len(status) != 1 || status[0].Required != 2 || status[0].Collected != 2 {
+		t.Fatal("wrong status", status)
+	}
+	txns, err := pst1.Finalize(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txns) != 1 || txns[0].ID() != txn.ID() {
+		t.Fatal("wrong transaction")
+	}
+	if err := txns[0].StandaloneValid(0); err != nil {
+		t.Fatal(err)
+	}
+
+	// Finalized transactions can be turned into partially signed transactions
+	// again.
+	pst3, err := NewPartiallySignedTransaction(txns[0], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pst3.Complete() {
+		t.Fatal("signatures weren't kept")
+	}
+
+	// Partially signed transactions of different transactions can't be
+	// merged.
+	otherTxn := txn
+	otherTxn.SiacoinOutputs = []types.SiacoinOutput{{Value: types.SiacoinPrecision.Mul64(2)}}
+	other, err := NewPartiallySignedTransaction(otherTxn, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pst1.Merge(other, 0); !errors.Contains(err, ErrPartialTransactionMismatch) {
+		t.Fatal("expected ErrPartialTransactionMismatch, got", err)
+	}
+}
@@ -279,6 +279,11 @@ type Allowance struct {
 	// period.
 	MaxPeriodChurn uint64 `json:"maxperiodchurn"`
 
+	// FundingAccount is the name of the wallet account which funds the
+	// contracts and receives their refunds. If it is empty, the contracts
+	// are funded by the outputs which don't belong to an account.
+	FundingAccount string `json:"fundingaccount"`
+
 	// The following fields provide price gouging protection for the user. By
 	// setting a particular maximum price for each mechanism that a host can use
 	// to charge users, the workers know to avoid hosts that go outside of the
@@ -435,6 +440,25 @@ type FileUploadParams struct {
 	CipherKey crypto.CipherKey
 }
 
+// PackedUploadParams contains the information used by the Renter to pack
+// multiple small files into shared chunks and upload them.
+type PackedUploadParams struct {
+	Files       []PackedFileParams
+	ErasureCode ErasureCoder
+	Force       bool
+
+	// CipherType is the encryption method used for the packs. If it is left
+	// blank, the renter will use the default encryption method.
+	CipherType crypto.CipherType
+}
+
+// PackedFileParams contains the source and destination of a single file of a
+// packed upload.
+type PackedFileParams struct {
+	Source  string
+	SiaPath SiaPath
+}
+
 // FileInfo provides information about a file.
 type FileInfo struct {
 	AccessTime       time.Time         `json:"accesstime"`
@@ -1055,6 +1079,10 @@ type Renter interface {
 	// MemoryStatus returns the current status of the memory manager
 	MemoryStatus() (MemoryStatus, error)
 
+	// NewRegistrySubscriber creates a new registry subscriber which calls
+	// notifyFunc whenever one of the entries it is subscribed to is updated.
+	NewRegistrySubscriber(notifyFunc func(RPCRegistrySubscriptionNotificationEntryUpdate)) (RegistrySubscriber, error)
+
 	// Mount mounts a FUSE filesystem at mountPoint, making the contents of sp
 	// available via the local filesystem.
 	Mount(mountPoint string, sp SiaPath, opts MountOptions) error
@@ -1141,6 +1169,10 @@ type Renter interface {
 	// Host provides the DB entry and score breakdown for the requested host.
 	Host(pk types.SiaPublicKey) (HostDBEntry, bool, error)
 
+	// ImportSharedFile imports a file which was shared by another renter
+	// using its share descriptor and sharing key.
+	ImportSharedFile(siaPath SiaPath, descriptor, sharingKey []byte, force bool) error
+
 	// InitialScanComplete returns a boolean indicating if the initial scan of the
 	// hostdb is completed.
 	InitialScanComplete() (bool, error)
@@ -1175,6 +1207,11 @@ type Renter interface {
 	// SetSettings sets the Renter's settings.
 	SetSettings(RenterSettings) error
 
+	// ShareFile creates a share descriptor for a file which allows another
+	// renter to download it. The descriptor is encrypted with the returned
+	// sharing key.
+	ShareFile(siaPath SiaPath) (descriptor, sharingKey []byte, err error)
+
 	// SetFileTrackingPath sets the on-disk location of an uploaded file to a
 	// new value. Useful if files need to be moved on disk.
 	SetFileTrackingPath(siaPath SiaPath, newPath string) error
@@ -1198,6 +1235,10 @@ type Renter interface {
 	// Upload uploads a file using the input parameters.
 	Upload(FileUploadParams) error
 
+	// UploadPacked packs multiple small files into shared chunks and uploads
+	// them.
+	UploadPacked(PackedUploadParams) error
+
 	// UploadStreamFromReader reads from the provided reader until io.EOF is
 	// reached and upload the data to the Sia network.
 	UploadStreamFromReader(up FileUploadParams, reader io.Reader) error
@@ -1233,6 +1274,21 @@ type Streamer interface {
 	io.Closer
 }
 
+// RegistrySubscriber is the interface implemented by the Renter's registry
+// subscriber type which allows for receiving notifications about updated
+// registry entries.
+type RegistrySubscriber interface {
+	// Subscribe subscribes to the provided entries. The latest known values
+	// of the entries are passed to the notify func of the subscriber once the
+	// subscriptions are established.
+	Subscribe(requests ...RPCRegistrySubscriptionRequest) error
+
+	// Unsubscribe unsubscribes from the provided entries.
+	Unsubscribe(requests ...RPCRegistrySubscriptionRequest)
+
+	io.Closer
+}
+
 // SkyfileStreamer is the interface implemented by the Renter's skyfile type
 // which allows for streaming files uploaded to the Sia network.
 type SkyfileStreamer interface {
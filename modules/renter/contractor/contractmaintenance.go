@@ -345,6 +345,16 @@ func (c *Contractor) managedFindMinAllowedHostScores() (types.Currency, types.Cu
 	return minScoreGFR, minScoreGFU, nil
 }
 
+// managedRefundAddress returns a new wallet address which receives the refund
+// of a contract. If the allowance has a funding account, the address is
+// assigned to the account.
+func (c *Contractor) managedRefundAddress(allowance modules.Allowance) (types.UnlockConditions, error) {
+	if allowance.FundingAccount == "" {
+		return c.wallet.NextAddress()
+	}
+	return c.wallet.AccountAddress(allowance.FundingAccount)
+}
+
 // managedNewContract negotiates an initial file contract with the specified
 // host, saves it, and returns it.
 func (c *Contractor) managedNewContract(host modules.HostDBEntry, contractFunding types.Currency, endHeight types.BlockHeight) (_ types.Currency, _ modules.RenterContract, err error) {
@@ -379,7 +389,7 @@ func (c *Contractor) managedNewContract(host modules.HostDBEntry, contractFundin
 	}
 
 	// get an address to use for negotiation
-	uc, err := c.wallet.NextAddress()
+	uc, err := c.managedRefundAddress(allowance)
 	if err != nil {
 		return types.ZeroCurrency, modules.RenterContract{}, err
 	}
@@ -620,6 +630,7 @@ func (c *Contractor) managedRenew(id types.FileContractID, hpk types.SiaPublicKe
 		return modules.RenterContract{}, errors.New("called managedRenew but allowance isn't set")
 	}
 	period := c.allowance.Period
+	allowance := c.allowance
 	c.mu.Unlock()
 
 	if !ok {
@@ -644,7 +655,7 @@ func (c *Contractor) managedRenew(id types.FileContractID, hpk types.SiaPublicKe
 	}
 
 	// get an address to use for negotiation
-	uc, err := c.wallet.NextAddress()
+	uc, err := c.managedRefundAddress(allowance)
 	if err != nil {
 		return modules.RenterContract{}, err
 	}
@@ -684,7 +695,7 @@ func (c *Contractor) managedRenew(id types.FileContractID, hpk types.SiaPublicKe
 	if err != nil {
 		return modules.RenterContract{}, err
 	}
-	err = txnBuilder.FundSiacoins(params.Funding)
+	err = txnBuilder.FundSiacoinsCoinControl(params.Funding, modules.CoinControl{Account: params.Allowance.FundingAccount})
 	if err != nil {
 		txnBuilder.Drop() // return unused outputs to wallet
 		return modules.RenterContract{}, err
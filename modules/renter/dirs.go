@@ -7,6 +7,7 @@ import (
 
 	"gitlab.com/NebulousLabs/errors"
 	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/modules/renter/filesystem"
 )
 
 // CreateDir creates a directory for the renter
@@ -26,7 +27,38 @@ func (r *Renter) DeleteDir(siaPath modules.SiaPath) error {
 		return err
 	}
 	defer r.tg.Done()
-	return r.staticFileSystem.DeleteDir(siaPath)
+
+	// Remember the packs of the packed files within the directory.
+	var mu sync.Mutex
+	var siaPaths []modules.SiaPath
+	flf := func(fi modules.FileInfo) {
+		mu.Lock()
+		siaPaths = append(siaPaths, fi.SiaPath)
+		mu.Unlock()
+	}
+	err := r.staticFileSystem.CachedList(siaPath, true, flf, func(modules.DirectoryInfo) {})
+	if err != nil && !errors.Contains(err, filesystem.ErrNotExist) {
+		return errors.AddContext(err, "unable to list files of directory")
+	}
+	var packs []modules.SiaPath
+	for _, fileSiaPath := range siaPaths {
+		if packSiaPath, packed := r.managedPackSiaPath(fileSiaPath); packed {
+			packs = append(packs, packSiaPath)
+		}
+	}
+
+	err = r.staticFileSystem.DeleteDir(siaPath)
+	if err != nil {
+		return err
+	}
+
+	// Delete the packs whose last files were deleted.
+	for _, packSiaPath := range packs {
+		if err := r.managedReleasePack(packSiaPath); err != nil {
+			r.log.Printf("WARN: Unable to release pack %v of deleted directory %v: %v", packSiaPath, siaPath, err)
+		}
+	}
+	return nil
 }
 
 // DirList lists the directories in a siadir
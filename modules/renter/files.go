@@ -15,12 +15,22 @@ func (r *Renter) DeleteFile(siaPath modules.SiaPath) error {
 	}
 	defer r.tg.Done()
 
+	// Remember the pack of a packed file before deleting it.
+	packSiaPath, packed := r.managedPackSiaPath(siaPath)
+
 	// Perform the delete operation.
 	err = r.staticFileSystem.DeleteFile(siaPath)
 	if err != nil {
 		return errors.AddContext(err, "unable to delete siafile from filesystem")
 	}
 
+	// Delete the pack if this was its last file.
+	if packed {
+		if err := r.managedReleasePack(packSiaPath); err != nil {
+			r.log.Printf("WARN: Unable to release pack %v of deleted siafile %v: %v", packSiaPath, siaPath, err)
+		}
+	}
+
 	// Update the filesystem metadata.
 	//
 	// TODO: This is incorrect, should be running the metadata update call on a
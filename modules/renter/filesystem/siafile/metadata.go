@@ -60,6 +60,11 @@ type (
 		PartialChunks       []PartialChunkInfo `json:"partialchunks"`       // information about the partial chunk.
 		HasPartialChunk     bool               `json:"haspartialchunk"`     // indicates whether this file is supposed to have a partial chunk or not
 
+		// Fields for packed files
+		PackSiaPath  string `json:"packsiapath"`  // siapath of the pack the file's data is stored in
+		PackedOffset uint64 `json:"packedoffset"` // offset of the file's data within the pack's chunk
+		PackedFiles  uint64 `json:"packedfiles"`  // number of packed files whose data is stored in this pack
+
 		// The following fields are the usual unix timestamps of files.
 		ModTime    time.Time `json:"modtime"`    // time of last content modification
 		ChangeTime time.Time `json:"changetime"` // time of last metadata modification
@@ -237,6 +242,29 @@ func (sf *SiaFile) LocalPath() string {
 	return sf.staticMetadata.LocalPath
 }
 
+// PackSiaPath returns the siapath of the pack the file's data is stored in. It
+// is empty if the file isn't packed.
+func (sf *SiaFile) PackSiaPath() string {
+	sf.mu.RLock()
+	defer sf.mu.RUnlock()
+	return sf.staticMetadata.PackSiaPath
+}
+
+// PackedOffset returns the offset of the file's data within its pack.
+func (sf *SiaFile) PackedOffset() uint64 {
+	sf.mu.RLock()
+	defer sf.mu.RUnlock()
+	return sf.staticMetadata.PackedOffset
+}
+
+// PackedFiles returns the number of packed files whose data is stored in the
+// file. It is zero if the file isn't a pack.
+func (sf *SiaFile) PackedFiles() uint64 {
+	sf.mu.RLock()
+	defer sf.mu.RUnlock()
+	return sf.staticMetadata.PackedFiles
+}
+
 // MasterKey returns the masterkey used to encrypt the file.
 func (sf *SiaFile) MasterKey() crypto.CipherKey {
 	return sf.staticMasterKey()
@@ -311,6 +339,9 @@ func (md Metadata) backup() (b Metadata) {
 	b.LocalPath = md.LocalPath
 	b.DisablePartialChunk = md.DisablePartialChunk
 	b.HasPartialChunk = md.HasPartialChunk
+	b.PackSiaPath = md.PackSiaPath
+	b.PackedOffset = md.PackedOffset
+	b.PackedFiles = md.PackedFiles
 	b.ModTime = md.ModTime
 	b.ChangeTime = md.ChangeTime
 	b.AccessTime = md.AccessTime
@@ -363,6 +394,9 @@ func (md *Metadata) restore(b Metadata) {
 	md.DisablePartialChunk = b.DisablePartialChunk
 	md.PartialChunks = b.PartialChunks
 	md.HasPartialChunk = b.HasPartialChunk
+	md.PackSiaPath = b.PackSiaPath
+	md.PackedOffset = b.PackedOffset
+	md.PackedFiles = b.PackedFiles
 	md.ModTime = b.ModTime
 	md.ChangeTime = b.ChangeTime
 	md.AccessTime = b.AccessTime
@@ -530,6 +564,83 @@ func (sf *SiaFile) SetLocalPath(path string) (err error) {
 	return sf.createAndApplyTransaction(updates...)
 }
 
+// SetPacked marks the file as packed. The file's data is stored at the
+// provided offset within the single chunk of the pack at packSiaPath.
+func (sf *SiaFile) SetPacked(packSiaPath string, offset uint64) (err error) {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+	// backup the changed metadata before changing it. Revert the change on
+	// error.
+	defer func(backup Metadata) {
+		if err != nil {
+			sf.staticMetadata.restore(backup)
+		}
+	}(sf.staticMetadata.backup())
+
+	sf.staticMetadata.PackSiaPath = packSiaPath
+	sf.staticMetadata.PackedOffset = offset
+
+	// Save changes to metadata to disk.
+	updates, err := sf.saveMetadataUpdates()
+	if err != nil {
+		return err
+	}
+	return sf.createAndApplyTransaction(updates...)
+}
+
+// SetPackedFiles sets the number of packed files whose data is stored in the
+// file.
+func (sf *SiaFile) SetPackedFiles(n uint64) (err error) {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+	// backup the changed metadata before changing it. Revert the change on
+	// error.
+	defer func(backup Metadata) {
+		if err != nil {
+			sf.staticMetadata.restore(backup)
+		}
+	}(sf.staticMetadata.backup())
+
+	sf.staticMetadata.PackedFiles = n
+
+	// Save changes to metadata to disk.
+	updates, err := sf.saveMetadataUpdates()
+	if err != nil {
+		return err
+	}
+	return sf.createAndApplyTransaction(updates...)
+}
+
+// ReleasePackedFile decrements the number of packed files whose data is stored
+// in the file and returns the number of remaining files.
+func (sf *SiaFile) ReleasePackedFile() (_ uint64, err error) {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+	if sf.staticMetadata.PackedFiles == 0 {
+		return 0, nil
+	}
+	// backup the changed metadata before changing it. Revert the change on
+	// error.
+	defer func(backup Metadata) {
+		if err != nil {
+			sf.staticMetadata.restore(backup)
+		}
+	}(sf.staticMetadata.backup())
+
+	sf.staticMetadata.PackedFiles--
+
+	// Save changes to metadata to disk.
+	updates, err := sf.saveMetadataUpdates()
+	if err != nil {
+		return 0, err
+	}
+	err = sf.createAndApplyTransaction(updates...)
+	if err != nil {
+		return 0, err
+	}
+	return sf.staticMetadata.PackedFiles, nil
+}
+
 // Size returns the file's size.
 func (sf *SiaFile) Size() uint64 {
 	sf.mu.RLock()
@@ -112,6 +112,9 @@ func TestBackupRestoreMetadata(t *testing.T) {
 		sf.staticMetadata.LocalPath = string(fastrand.Bytes(100))
 		sf.staticMetadata.DisablePartialChunk = !sf.staticMetadata.DisablePartialChunk
 		sf.staticMetadata.HasPartialChunk = !sf.staticMetadata.HasPartialChunk
+		sf.staticMetadata.PackSiaPath = string(fastrand.Bytes(100))
+		sf.staticMetadata.PackedOffset = fastrand.Uint64n(100)
+		sf.staticMetadata.PackedFiles = fastrand.Uint64n(100)
 		sf.staticMetadata.PartialChunks = nil
 		if fastrand.Intn(2) == 0 { // 50% chance to be not nil
 			sf.staticMetadata.PartialChunks = make([]PartialChunkInfo, fastrand.Intn(10))
@@ -150,3 +153,97 @@ func TestBackupRestoreMetadata(t *testing.T) {
 		t.Fatalf("metadata wasn't restored successfully %v %v", mdBefore, sf.staticMetadata)
 	}
 }
+
+// TestSetPacked tests marking a SiaFile as packed.
+func TestSetPacked(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	siaFilePath, _, source, rc, sk, _, _, fileMode := newTestFileParams(1, false)
+	sf, wal, _ := customTestFileAndWAL(siaFilePath, source, rc, sk, 100, 1, fileMode)
+	if sf.PackSiaPath() != "" || sf.PackedOffset() != 0 {
+		t.Fatal("new file shouldn't be packed")
+	}
+
+	// Mark the file as packed.
+	packSiaPath := modules.PackFolder.String() + "/pack"
+	offset := sf.ChunkSize() - 100
+	if err := sf.SetPacked(packSiaPath, offset); err != nil {
+		t.Fatal(err)
+	}
+	if sf.PackSiaPath() != packSiaPath || sf.PackedOffset() != offset {
+		t.Fatal("file wasn't marked as packed", sf.PackSiaPath(), sf.PackedOffset())
+	}
+
+	// The change should be persisted.
+	sf, err := LoadSiaFile(sf.siaFilePath, wal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sf.PackSiaPath() != packSiaPath || sf.PackedOffset() != offset {
+		t.Fatal("packed fields weren't persisted", sf.PackSiaPath(), sf.PackedOffset())
+	}
+
+	// Offsets of a snapshot should be relative to the pack.
+	snap, err := sf.Snapshot(modules.RandomSiaPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.PackedOffset() != offset {
+		t.Fatal("wrong packed offset", snap.PackedOffset())
+	}
+	chunkIndex, off := snap.ChunkIndexByOffset(10)
+	if chunkIndex != 0 || off != offset+10 {
+		t.Fatal("wrong chunk index or offset", chunkIndex, off)
+	}
+	chunkIndex, off = snap.ChunkIndexByOffset(100)
+	if chunkIndex != 1 || off != 0 {
+		t.Fatal("wrong chunk index or offset", chunkIndex, off)
+	}
+}
+
+// TestReleasePackedFile tests counting the packed files of a pack.
+func TestReleasePackedFile(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	siaFilePath, _, source, rc, sk, _, _, fileMode := newTestFileParams(1, false)
+	sf, wal, _ := customTestFileAndWAL(siaFilePath, source, rc, sk, 100, 1, fileMode)
+	if sf.PackedFiles() != 0 {
+		t.Fatal("new file shouldn't be a pack")
+	}
+	if err := sf.SetPackedFiles(2); err != nil {
+		t.Fatal(err)
+	}
+	remaining, err := sf.ReleasePackedFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if remaining != 1 {
+		t.Fatal("wrong number of remaining files", remaining)
+	}
+
+	// The change should be persisted.
+	sf, err = LoadSiaFile(sf.siaFilePath, wal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sf.PackedFiles() != 1 {
+		t.Fatal("packed files weren't persisted", sf.PackedFiles())
+	}
+
+	// The count shouldn't drop below zero.
+	for i := 0; i < 2; i++ {
+		remaining, err = sf.ReleasePackedFile()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if remaining != 0 {
+			t.Fatal("wrong number of remaining files", remaining)
+		}
+	}
+}
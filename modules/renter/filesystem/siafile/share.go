@@ -0,0 +1,197 @@
+package siafile
+
+// share.go contains the code for sharing SiaFiles with other renters. A share
+// descriptor contains everything another renter needs to download a file
+// using its own contracts: the host keys and merkle roots of the file's pieces,
+// the erasure code parameters and the master key of the file. Share
+// descriptors are encrypted with the file's sharing key which is derived from
+// its master key.
+
+import (
+	"fmt"
+	"os"
+
+	"gitlab.com/NebulousLabs/encoding"
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+var (
+	// ErrInvalidShareDescriptor is returned if a share descriptor can't be
+	// decrypted or contains invalid data.
+	ErrInvalidShareDescriptor = errors.New("invalid share descriptor")
+
+	// SharingKeyType is the type of key used to encrypt share descriptors.
+	SharingKeyType = crypto.TypeTwofish
+
+	// sharingKeySpecifier is used to derive the sharing key of a file from its
+	// master key.
+	sharingKeySpecifier = types.NewSpecifier("SharingKey")
+)
+
+type (
+	// SharedFile is the decrypted content of a share descriptor.
+	SharedFile struct {
+		FileSize          uint64
+		Mode              uint32
+		ErasureCodeType   [4]byte
+		ErasureCodeParams [8]byte
+		MasterKeyType     crypto.CipherType
+		MasterKey         []byte
+		HostKeys          []types.SiaPublicKey
+		Chunks            []SharedChunk
+	}
+
+	// SharedChunk contains the pieces of a chunk of a shared file.
+	SharedChunk struct {
+		Pieces []SharedPiece
+	}
+
+	// SharedPiece is a single piece of a shared file. HostIndex is the index
+	// of the host's key within the file's HostKeys.
+	SharedPiece struct {
+		PieceIndex uint32
+		HostIndex  uint32
+		MerkleRoot crypto.Hash
+	}
+)
+
+// deriveSharingKey derives the sharing key of a file from its master key.
+func deriveSharingKey(masterKey crypto.CipherKey) (crypto.CipherKey, error) {
+	entropy := crypto.HashAll(sharingKeySpecifier, masterKey.Key())
+	sk, err := crypto.NewSiaKey(SharingKeyType, entropy[:])
+	if err != nil {
+		return nil, errors.AddContext(err, "failed to derive sharing key")
+	}
+	return sk, nil
+}
+
+// SharingKey returns the key which is used to encrypt the file's share
+// descriptors. Files which were created before sharing keys were added derive
+// their key on demand.
+func (sf *SiaFile) SharingKey() (crypto.CipherKey, error) {
+	if len(sf.staticMetadata.StaticSharingKey) == 0 {
+		return deriveSharingKey(sf.staticMasterKey())
+	}
+	sk, err := crypto.NewSiaKey(sf.staticMetadata.StaticSharingKeyType, sf.staticMetadata.StaticSharingKey)
+	if err != nil {
+		// This should never happen since the sharing key is derived when the
+		// SiaFile is created.
+		build.Critical("failed to create sharing key of siafile", err)
+		return nil, errors.AddContext(err, "failed to create sharing key of siafile")
+	}
+	return sk, nil
+}
+
+// ShareDescriptor creates a share descriptor for the file which is encrypted
+// with the file's sharing key.
+func (sf *SiaFile) ShareDescriptor() (_ []byte, err error) {
+	sf.mu.RLock()
+	defer sf.mu.RUnlock()
+
+	if sf.deleted {
+		return nil, errors.AddContext(ErrDeleted, "can't share deleted file")
+	}
+	if len(sf.staticMetadata.PartialChunks) > 0 {
+		return nil, errors.New("can't share file with partial chunks")
+	}
+	if sf.staticMetadata.PackSiaPath != "" {
+		return nil, errors.New("can't share packed file")
+	}
+	shared := SharedFile{
+		FileSize:          uint64(sf.staticMetadata.FileSize),
+		Mode:              uint32(sf.staticMetadata.Mode),
+		ErasureCodeType:   sf.staticMetadata.StaticErasureCodeType,
+		ErasureCodeParams: sf.staticMetadata.StaticErasureCodeParams,
+		MasterKeyType:     sf.staticMetadata.StaticMasterKeyType,
+		MasterKey:         sf.staticMetadata.StaticMasterKey,
+		Chunks:            make([]SharedChunk, 0, sf.numChunks),
+	}
+	// Only the hosts which actually store pieces of the file are shared.
+	hostIndices := make(map[uint32]uint32)
+	err = sf.iterateChunksReadonly(func(chunk chunk) error {
+		var sc SharedChunk
+		for pieceIndex, pieceSet := range chunk.Pieces {
+			for _, piece := range pieceSet {
+				hostIndex, exists := hostIndices[piece.HostTableOffset]
+				if !exists {
+					hostIndex = uint32(len(shared.HostKeys))
+					hostIndices[piece.HostTableOffset] = hostIndex
+					shared.HostKeys = append(shared.HostKeys, sf.hostKey(piece.HostTableOffset).PublicKey)
+				}
+				sc.Pieces = append(sc.Pieces, SharedPiece{
+					PieceIndex: uint32(pieceIndex),
+					HostIndex:  hostIndex,
+					MerkleRoot: piece.MerkleRoot,
+				})
+			}
+		}
+		shared.Chunks = append(shared.Chunks, sc)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.AddContext(err, "failed to read chunks")
+	}
+	sk, err := sf.SharingKey()
+	if err != nil {
+		return nil, err
+	}
+	return sk.EncryptBytes(encoding.Marshal(shared)), nil
+}
+
+// DecryptShareDescriptor decrypts a share descriptor using the provided
+// sharing key and validates its content.
+func DecryptShareDescriptor(descriptor []byte, sharingKey crypto.CipherKey) (SharedFile, error) {
+	plaintext, err := sharingKey.DecryptBytes(descriptor)
+	if err != nil {
+		return SharedFile{}, errors.Compose(ErrInvalidShareDescriptor, err)
+	}
+	var shared SharedFile
+	if err := encoding.Unmarshal(plaintext, &shared); err != nil {
+		return SharedFile{}, errors.Compose(ErrInvalidShareDescriptor, err)
+	}
+	// Validate the content.
+	ec, err := shared.ErasureCode()
+	if err != nil {
+		return SharedFile{}, errors.Compose(ErrInvalidShareDescriptor, err)
+	}
+	mk, err := shared.CipherKey()
+	if err != nil {
+		return SharedFile{}, errors.Compose(ErrInvalidShareDescriptor, err)
+	}
+	chunkSize := (modules.SectorSize - mk.Type().Overhead()) * uint64(ec.MinPieces())
+	numChunks := shared.FileSize / chunkSize
+	if shared.FileSize%chunkSize != 0 {
+		numChunks++
+	}
+	if uint64(len(shared.Chunks)) != numChunks {
+		return SharedFile{}, errors.AddContext(ErrInvalidShareDescriptor, fmt.Sprintf("expected %v chunks but got %v", numChunks, len(shared.Chunks)))
+	}
+	for _, chunk := range shared.Chunks {
+		for _, piece := range chunk.Pieces {
+			if piece.PieceIndex >= uint32(ec.NumPieces()) || piece.HostIndex >= uint32(len(shared.HostKeys)) {
+				return SharedFile{}, errors.AddContext(ErrInvalidShareDescriptor, "piece out of bounds")
+			}
+		}
+	}
+	return shared, nil
+}
+
+// CipherKey returns the master key of the shared file.
+func (s SharedFile) CipherKey() (crypto.CipherKey, error) {
+	return crypto.NewSiaKey(s.MasterKeyType, s.MasterKey)
+}
+
+// ErasureCode returns the erasure coder of the shared file.
+func (s SharedFile) ErasureCode() (modules.ErasureCoder, error) {
+	return unmarshalErasureCoder(s.ErasureCodeType, s.ErasureCodeParams)
+}
+
+// FileMode returns the mode of the shared file.
+func (s SharedFile) FileMode() os.FileMode {
+	return os.FileMode(s.Mode)
+}
@@ -0,0 +1,141 @@
+package siafile
+
+import (
+	"testing"
+
+	"gitlab.com/NebulousLabs/encoding"
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// TestShareDescriptor tests creating and decrypting share descriptors.
+func TestShareDescriptor(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	siaFilePath, _, source, rc, sk, fileSize, numChunks, fileMode := newTestFileParams(2, false)
+	sf, _, _ := customTestFileAndWAL(siaFilePath, source, rc, sk, fileSize, numChunks, fileMode)
+
+	// The sharing key should be set on creation and match the derived one.
+	if len(sf.staticMetadata.StaticSharingKey) == 0 {
+		t.Fatal("sharing key wasn't set")
+	}
+	sharingKey, err := sf.SharingKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sharingKey.Type() != SharingKeyType {
+		t.Fatal("wrong sharing key type", sharingKey.Type())
+	}
+	derived, err := deriveSharingKey(sf.staticMasterKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(derived.Key()) != string(sharingKey.Key()) {
+		t.Fatal("sharing key doesn't match derived key")
+	}
+
+	// Add pieces to the file. Some hosts store multiple pieces.
+	hosts := make([]types.SiaPublicKey, 3)
+	for i := range hosts {
+		hosts[i] = types.SiaPublicKey{Key: fastrand.Bytes(crypto.EntropySize)}
+	}
+	for chunkIndex := 0; chunkIndex < sf.numChunks; chunkIndex++ {
+		for pieceIndex := 0; pieceIndex < rc.NumPieces(); pieceIndex++ {
+			var mr crypto.Hash
+			fastrand.Read(mr[:])
+			host := hosts[fastrand.Intn(len(hosts))]
+			if err := sf.AddPiece(host, uint64(chunkIndex), uint64(pieceIndex), mr); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// Create a descriptor and decrypt it.
+	descriptor, err := sf.ShareDescriptor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	shared, err := DecryptShareDescriptor(descriptor, sharingKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shared.FileSize != sf.Size() || shared.FileMode() != sf.Mode() {
+		t.Fatal("wrong file size or mode", shared.FileSize, shared.FileMode())
+	}
+	if len(shared.HostKeys) > len(hosts) {
+		t.Fatal("host keys weren't deduplicated", len(shared.HostKeys))
+	}
+	mk, err := shared.CipherKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(mk.Key()) != string(sk.Key()) {
+		t.Fatal("wrong master key")
+	}
+	ec, err := shared.ErasureCode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ec.Identifier() != rc.Identifier() {
+		t.Fatal("wrong erasure code", ec.Identifier())
+	}
+
+	// The pieces should match the pieces of the file.
+	if len(shared.Chunks) != sf.numChunks {
+		t.Fatal("wrong number of chunks", len(shared.Chunks))
+	}
+	for chunkIndex, chunk := range shared.Chunks {
+		pieces, err := sf.Pieces(uint64(chunkIndex))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(chunk.Pieces) != rc.NumPieces() {
+			t.Fatal("wrong number of pieces", len(chunk.Pieces))
+		}
+		for _, piece := range chunk.Pieces {
+			expected := pieces[piece.PieceIndex][0]
+			if piece.MerkleRoot != expected.MerkleRoot || shared.HostKeys[piece.HostIndex].String() != expected.HostPubKey.String() {
+				t.Fatal("shared piece doesn't match piece of file")
+			}
+		}
+	}
+
+	// Decrypting with the wrong key should fail.
+	wrongKey := crypto.GenerateSiaKey(SharingKeyType)
+	_, err = DecryptShareDescriptor(descriptor, wrongKey)
+	if !errors.Contains(err, ErrInvalidShareDescriptor) {
+		t.Fatal("expected ErrInvalidShareDescriptor but got", err)
+	}
+
+	// A descriptor with a missing or an extra chunk should fail.
+	for _, chunks := range [][]SharedChunk{shared.Chunks[1:], append(shared.Chunks, SharedChunk{})} {
+		invalid := shared
+		invalid.Chunks = chunks
+		_, err = DecryptShareDescriptor(sharingKey.EncryptBytes(encoding.Marshal(invalid)), sharingKey)
+		if !errors.Contains(err, ErrInvalidShareDescriptor) {
+			t.Fatal("expected ErrInvalidShareDescriptor but got", err)
+		}
+	}
+
+	// A tampered descriptor should fail.
+	descriptor[fastrand.Intn(len(descriptor))]++
+	_, err = DecryptShareDescriptor(descriptor, sharingKey)
+	if !errors.Contains(err, ErrInvalidShareDescriptor) {
+		t.Fatal("expected ErrInvalidShareDescriptor but got", err)
+	}
+
+	// Packed files can't be shared.
+	if err := sf.SetPacked(modules.PackFolder.String()+"/pack", 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sf.ShareDescriptor(); err == nil {
+		t.Fatal("shouldn't be able to share packed file")
+	}
+}
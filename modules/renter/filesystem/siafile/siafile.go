@@ -198,6 +198,10 @@ func New(siaFilePath, source string, wal *writeaheadlog.WAL, erasureCode modules
 	numPieces := erasureCode.NumPieces()
 	zeroHealth := float64(1 + minPieces/(numPieces-minPieces))
 	repairSize := fileSize * uint64(numPieces/minPieces)
+	sharingKey, err := deriveSharingKey(masterKey)
+	if err != nil {
+		return nil, err
+	}
 	file := &SiaFile{
 		staticMetadata: Metadata{
 			AccessTime:              currentTime,
@@ -217,6 +221,8 @@ func New(siaFilePath, source string, wal *writeaheadlog.WAL, erasureCode modules
 			LocalPath:               source,
 			StaticMasterKey:         masterKey.Key(),
 			StaticMasterKeyType:     masterKey.Type(),
+			StaticSharingKey:        sharingKey.Key(),
+			StaticSharingKeyType:    sharingKey.Type(),
 			Mode:                    fileMode,
 			ModTime:                 currentTime,
 			staticErasureCode:       erasureCode,
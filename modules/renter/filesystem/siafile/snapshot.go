@@ -29,6 +29,7 @@ type (
 		staticSiaPath         modules.SiaPath
 		staticLocalPath       string
 		staticPartialChunks   []PartialChunkInfo
+		staticPackedOffset    uint64
 		staticUID             SiafileUID
 	}
 )
@@ -94,6 +95,13 @@ func (sf *SiaFile) SnapshotReader() (*SnapshotReader, error) {
 func (s *Snapshot) ChunkIndexByOffset(offset uint64) (chunkIndex uint64, off uint64) {
 	chunkIndex = offset / s.ChunkSize()
 	off = offset % s.ChunkSize()
+	// If the file is packed, its data starts at the packed offset within the
+	// pack's chunk.
+	if s.staticPackedOffset > 0 {
+		offset += s.staticPackedOffset
+		chunkIndex = offset / s.ChunkSize()
+		off = offset % s.ChunkSize()
+	}
 	// If the offset points within a partial chunk, we need to adjust our
 	// calculation to compensate for the potential offset within a combined chunk.
 	var totalOffset uint64
@@ -149,6 +157,11 @@ func (s *Snapshot) LocalPath() string {
 	return s.staticLocalPath
 }
 
+// PackedOffset returns the offset of the file's data within its pack.
+func (s *Snapshot) PackedOffset() uint64 {
+	return s.staticPackedOffset
+}
+
 // MasterKey returns the masterkey used to encrypt the file.
 func (s *Snapshot) MasterKey() crypto.CipherKey {
 	return s.staticMasterKey
@@ -280,6 +293,7 @@ func (sf *SiaFile) readlockSnapshot(sp modules.SiaPath, chunks []chunk) (*Snapsh
 	hasPartial := sf.staticMetadata.HasPartialChunk
 	pcs := sf.staticMetadata.PartialChunks
 	localPath := sf.staticMetadata.LocalPath
+	packedOffset := sf.staticMetadata.PackedOffset
 
 	return &Snapshot{
 		staticChunks:          exportedChunks,
@@ -293,6 +307,7 @@ func (sf *SiaFile) readlockSnapshot(sp modules.SiaPath, chunks []chunk) (*Snapsh
 		staticPubKeyTable:     pkt,
 		staticSiaPath:         sp,
 		staticLocalPath:       localPath,
+		staticPackedOffset:    packedOffset,
 		staticUID:             uid,
 	}, nil
 }
@@ -6,6 +6,7 @@ import (
 	"context"
 	"io"
 	"math"
+	"os"
 	"sync"
 	"sync/atomic"
 	"syscall"
@@ -17,6 +18,10 @@ import (
 	"go.sia.tech/siad/modules/renter/filesystem"
 )
 
+// fuseRenameNoReplace is the RENAME_NOREPLACE flag of renameat2(). Unlike
+// RENAME_EXCHANGE it is not exported by the fs package.
+const fuseRenameNoReplace = 0x1
+
 // fuseDirnode is a fuse node for the fs package that covers a siadir.
 //
 // NOTE: The fuseDirnode is _very_hot_ in that it gets hit rapidly and
@@ -38,6 +43,8 @@ type fuseDirnode struct {
 // NodeAccesser is necessary for telling certain programs that it is okay to
 // access the file.
 //
+// NodeCreater is necessary for creating new files in a writable mount.
+//
 // NodeFlusher is necessary for cleaning up resources such as the filesystem
 // node.
 //
@@ -46,29 +53,47 @@ type fuseDirnode struct {
 //
 // NodeLookuper is necessary to have files added to the filesystem tree.
 //
+// NodeMkdirer is necessary for creating new directories in a writable mount.
+//
 // NodeReaddirer is necessary to list the files in a directory.
 //
+// NodeRenamer is necessary for moving files and directories in a writable
+// mount.
+//
 // NodeStatfser is necessary to provide information about the filesystem that
 // contains the directory.
+//
+// NodeUnlinker is necessary for deleting files in a writable mount.
 var _ = (fs.NodeAccesser)((*fuseDirnode)(nil))
+var _ = (fs.NodeCreater)((*fuseDirnode)(nil))
 var _ = (fs.NodeFlusher)((*fuseDirnode)(nil))
 var _ = (fs.NodeGetattrer)((*fuseDirnode)(nil))
 var _ = (fs.NodeLookuper)((*fuseDirnode)(nil))
+var _ = (fs.NodeMkdirer)((*fuseDirnode)(nil))
 var _ = (fs.NodeReaddirer)((*fuseDirnode)(nil))
+var _ = (fs.NodeRenamer)((*fuseDirnode)(nil))
 var _ = (fs.NodeStatfser)((*fuseDirnode)(nil))
+var _ = (fs.NodeUnlinker)((*fuseDirnode)(nil))
 
 // fuseFilenode is a fuse node for the fs package that covers a siafile.
 //
 // Data is fetched using a download streamer. This download streamer needs to be
-// closed when the filehandle is released.
+// closed when the filehandle is released. Files which are opened for writing
+// use a fuseWriteHandle instead.
+//
+// NOTE: The fileNode is replaced when a write handle overwrites the file. It is
+// protected by its own lock to avoid blocking Getattr while a Read is in
+// progress.
 type fuseFilenode struct {
 	atomicClosed uint32
 
 	fs.Inode
 	staticFilesystem *fuseFS
-	staticFileNode   *filesystem.FileNode
 	stream           modules.Streamer
 	mu               sync.Mutex
+
+	fileNode *filesystem.FileNode
+	nodeMu   sync.RWMutex
 }
 
 // Ensure the file nodes satisfy the required interfaces.
@@ -81,18 +106,28 @@ type fuseFilenode struct {
 //
 // NodeGetattrer is necessary for providing the filesize to file browsers.
 //
-// NodeOpener is necessary for opening files to be read.
+// NodeOpener is necessary for opening files to be read or written.
 //
 // NodeReader is necessary for reading files.
 //
+// NodeReleaser is necessary for cleaning up the local buffer of a write
+// handle.
+//
+// NodeSetattrer is necessary for truncating files and changing their mode.
+//
 // NodeStatfser is necessary to provide information about the filesystem that
 // contains the file.
+//
+// NodeWriter is necessary for writing files.
 var _ = (fs.NodeAccesser)((*fuseFilenode)(nil))
 var _ = (fs.NodeFlusher)((*fuseFilenode)(nil))
 var _ = (fs.NodeGetattrer)((*fuseFilenode)(nil))
 var _ = (fs.NodeOpener)((*fuseFilenode)(nil))
 var _ = (fs.NodeReader)((*fuseFilenode)(nil))
+var _ = (fs.NodeReleaser)((*fuseFilenode)(nil))
+var _ = (fs.NodeSetattrer)((*fuseFilenode)(nil))
 var _ = (fs.NodeStatfser)((*fuseFilenode)(nil))
+var _ = (fs.NodeWriter)((*fuseFilenode)(nil))
 
 // fuseRoot is the root directory for a mounted fuse filesystem.
 type fuseFS struct {
@@ -107,12 +142,51 @@ type fuseFS struct {
 func errToStatus(err error) syscall.Errno {
 	if err == nil {
 		return syscall.F_OK
-	} else if errors.IsOSNotExist(err) {
+	} else if errors.IsOSNotExist(err) || errors.Contains(err, filesystem.ErrNotExist) {
 		return syscall.ENOENT
+	} else if errors.Contains(err, filesystem.ErrExists) {
+		return syscall.EEXIST
 	}
 	return syscall.EIO
 }
 
+// managedFileNode returns the current filesystem node of the file.
+func (ffn *fuseFilenode) managedFileNode() *filesystem.FileNode {
+	ffn.nodeMu.RLock()
+	defer ffn.nodeMu.RUnlock()
+	return ffn.fileNode
+}
+
+// managedSetFileNode replaces the filesystem node of the file after the file
+// was overwritten. The old node is closed unless it was closed by a call to
+// Flush already. The new node will be closed by the next call to Flush.
+func (ffn *fuseFilenode) managedSetFileNode(fileNode *filesystem.FileNode) error {
+	ffn.nodeMu.Lock()
+	defer ffn.nodeMu.Unlock()
+	oldNode := ffn.fileNode
+	ffn.fileNode = fileNode
+	if atomic.SwapUint32(&ffn.atomicClosed, 0) == 1 {
+		return nil
+	}
+	return oldNode.Close()
+}
+
+// managedCloseFileNode closes the filesystem node of the file unless it was
+// closed by a call to Flush already.
+func (ffn *fuseFilenode) managedCloseFileNode() error {
+	ffn.nodeMu.Lock()
+	defer ffn.nodeMu.Unlock()
+	if !atomic.CompareAndSwapUint32(&ffn.atomicClosed, 0, 1) {
+		return nil
+	}
+	return ffn.fileNode.Close()
+}
+
+// childSiaPath returns the siapath of a child of the directory.
+func (fdn *fuseDirnode) childSiaPath(name string) (modules.SiaPath, error) {
+	return fdn.staticFilesystem.renter.staticFileSystem.DirSiaPath(fdn.staticDirNode).Join(name)
+}
+
 // Access reports whether a directory can be accessed by the caller.
 func (fdn *fuseDirnode) Access(ctx context.Context, mask uint32) syscall.Errno {
 	// TODO: parse the mask and return a more correct value instead of always
@@ -127,6 +201,47 @@ func (ffn *fuseFilenode) Access(ctx context.Context, mask uint32) syscall.Errno
 	return syscall.F_OK
 }
 
+// Create creates a new empty file in the directory and opens it for writing.
+func (fdn *fuseDirnode) Create(ctx context.Context, name string, flags uint32, mode uint32, out *fuse.EntryOut) (*fs.Inode, fs.FileHandle, uint32, syscall.Errno) {
+	if fdn.staticFilesystem.options.ReadOnly {
+		return nil, nil, 0, syscall.EROFS
+	}
+	siaPath, err := fdn.childSiaPath(name)
+	if err != nil {
+		return nil, nil, 0, syscall.EINVAL
+	}
+	fileNode, err := fdn.staticFilesystem.managedNewEmptyFile(siaPath, os.FileMode(mode)&os.ModePerm)
+	if err != nil {
+		fdn.staticFilesystem.renter.log.Printf("Unable to create fuse file %v: %v", siaPath, err)
+		return nil, nil, 0, errToStatus(err)
+	}
+	fileInfo, err := fdn.staticFilesystem.renter.staticFileSystem.FileNodeInfo(fileNode)
+	if err != nil {
+		fdn.staticFilesystem.renter.log.Printf("Unable to fetch fileinfo on new file %v: %v", siaPath, err)
+		return nil, nil, 0, errToStatus(errors.Compose(err, fileNode.Close()))
+	}
+
+	// Convert the file to an inode and open a write handle for it. The file is
+	// empty, so there is nothing to upload until it is written to.
+	filenode := &fuseFilenode{
+		staticFilesystem: fdn.staticFilesystem,
+		fileNode:         fileNode,
+	}
+	attrs := fs.StableAttr{
+		Ino:  fileInfo.UID,
+		Mode: fuse.S_IFREG,
+	}
+	out.Ino = fileInfo.UID
+	out.Mode = uint32(fileInfo.Mode()) | fuse.S_IFREG
+	inode := fdn.NewInode(ctx, filenode, attrs)
+	fwh, err := filenode.managedOpenWriteHandle(false)
+	if err != nil {
+		fdn.staticFilesystem.renter.log.Printf("Unable to open new file %v for writing: %v", siaPath, err)
+		return nil, nil, 0, errToStatus(err)
+	}
+	return inode, fwh, 0, errToStatus(nil)
+}
+
 // Flush is called when a directory is being closed.
 func (fdn *fuseDirnode) Flush(ctx context.Context, fh fs.FileHandle) syscall.Errno {
 	var err error
@@ -139,12 +254,19 @@ func (fdn *fuseDirnode) Flush(ctx context.Context, fh fs.FileHandle) syscall.Err
 
 // Flush is called when a file is being closed.
 func (ffn *fuseFilenode) Flush(ctx context.Context, fh fs.FileHandle) syscall.Errno {
+	// Files which were opened for writing are uploaded when they are flushed.
+	if fwh, ok := fh.(*fuseWriteHandle); ok {
+		return errToStatus(fwh.managedFlush())
+	}
+
+	ffn.mu.Lock()
+	defer ffn.mu.Unlock()
+	ffn.nodeMu.Lock()
+	defer ffn.nodeMu.Unlock()
 	swapped := atomic.CompareAndSwapUint32(&ffn.atomicClosed, 0, 1)
 	if !swapped {
 		return errToStatus(nil)
 	}
-	ffn.mu.Lock()
-	defer ffn.mu.Unlock()
 
 	// If a stream was opened for the file, the stream must now be closed.
 	var streamErr error
@@ -155,10 +277,10 @@ func (ffn *fuseFilenode) Flush(ctx context.Context, fh fs.FileHandle) syscall.Er
 	}
 
 	// Check all of the errors.
-	closeErr := ffn.staticFileNode.Close()
+	closeErr := ffn.fileNode.Close()
 	err := errors.Compose(streamErr, closeErr)
 	if err != nil {
-		siaPath := ffn.staticFilesystem.renter.staticFileSystem.FileSiaPath(ffn.staticFileNode)
+		siaPath := ffn.staticFilesystem.renter.staticFileSystem.FileSiaPath(ffn.fileNode)
 		ffn.staticFilesystem.renter.log.Printf("error when flushing fuse file %v: %v", siaPath, err)
 		return errToStatus(err)
 	}
@@ -181,7 +303,7 @@ func (fdn *fuseDirnode) Lookup(ctx context.Context, name string, out *fuse.Entry
 		// Convert the file to an inode.
 		filenode := &fuseFilenode{
 			staticFilesystem: fdn.staticFilesystem,
-			staticFileNode:   fileNode,
+			fileNode:         fileNode,
 		}
 		attrs := fs.StableAttr{
 			Ino:  fileInfo.UID,
@@ -244,29 +366,93 @@ func (fdn *fuseDirnode) Getattr(ctx context.Context, fh fs.FileHandle, out *fuse
 // Getattr should try to minimize lock contention and should run very quickly if
 // possible.
 func (ffn *fuseFilenode) Getattr(ctx context.Context, fh fs.FileHandle, out *fuse.AttrOut) syscall.Errno {
-	fileInfo, err := ffn.staticFilesystem.renter.staticFileSystem.FileNodeInfo(ffn.staticFileNode)
+	fileInfo, err := ffn.staticFilesystem.renter.staticFileSystem.FileNodeInfo(ffn.managedFileNode())
 	if err != nil {
 		ffn.staticFilesystem.renter.log.Printf("Unable to fetch info from file: %v", err)
 	}
 
 	out.Size = fileInfo.Filesize
+	if fwh, ok := fh.(*fuseWriteHandle); ok {
+		// The size of a file that is being written is the size of its buffer.
+		size, err := fwh.managedSize()
+		if err != nil {
+			ffn.staticFilesystem.renter.log.Printf("Unable to fetch size of write buffer: %v", err)
+			return errToStatus(err)
+		}
+		out.Size = size
+	}
 	out.Mode = uint32(fileInfo.Mode()) | syscall.S_IFREG
 	out.Ino = fileInfo.UID
 	return errToStatus(nil)
 }
 
-// Open will open a streamer for the file.
+// Mkdir creates a new directory within the directory.
+func (fdn *fuseDirnode) Mkdir(ctx context.Context, name string, mode uint32, out *fuse.EntryOut) (*fs.Inode, syscall.Errno) {
+	if fdn.staticFilesystem.options.ReadOnly {
+		return nil, syscall.EROFS
+	}
+	siaPath, err := fdn.childSiaPath(name)
+	if err != nil {
+		return nil, syscall.EINVAL
+	}
+	err = fdn.staticFilesystem.renter.CreateDir(siaPath, os.FileMode(mode)&os.ModePerm)
+	if err != nil {
+		fdn.staticFilesystem.renter.log.Printf("Unable to create fuse directory %v: %v", siaPath, err)
+		return nil, errToStatus(err)
+	}
+	childDir, err := fdn.staticDirNode.Dir(name)
+	if err != nil {
+		fdn.staticFilesystem.renter.log.Printf("Unable to open new fuse directory %v: %v", siaPath, err)
+		return nil, errToStatus(err)
+	}
+	dirInfo, err := fdn.staticFilesystem.renter.staticFileSystem.DirNodeInfo(childDir)
+	if err != nil {
+		fdn.staticFilesystem.renter.log.Printf("Unable to fetch info from new directory %v: %v", siaPath, err)
+		return nil, errToStatus(errors.Compose(err, childDir.Close()))
+	}
+
+	// Convert the directory to an inode.
+	dirnode := &fuseDirnode{
+		staticDirNode:    childDir,
+		staticFilesystem: fdn.staticFilesystem,
+	}
+	attrs := fs.StableAttr{
+		Ino:  dirInfo.UID,
+		Mode: fuse.S_IFDIR,
+	}
+	out.Ino = dirInfo.UID
+	out.Mode = uint32(dirInfo.Mode())
+	inode := fdn.NewInode(ctx, dirnode, attrs)
+	return inode, errToStatus(nil)
+}
+
+// Open will open a streamer for the file. If the file is opened for writing, a
+// write handle is returned instead.
 //
 // TODO: Currently 'Open' returns '0' for the fuseFlags. I was unable to figure
 // out from the documentation what the flags are supposed to represent. So far,
 // this has not seemed to cause problems.
 func (ffn *fuseFilenode) Open(ctx context.Context, flags uint32) (fs.FileHandle, uint32, syscall.Errno) {
+	if flags&syscall.O_ACCMODE != syscall.O_RDONLY {
+		if ffn.staticFilesystem.options.ReadOnly {
+			return nil, 0, syscall.EROFS
+		}
+		fwh, err := ffn.managedOpenWriteHandle(flags&syscall.O_TRUNC != 0)
+		if err != nil {
+			siaPath := ffn.staticFilesystem.renter.staticFileSystem.FileSiaPath(ffn.managedFileNode())
+			ffn.staticFilesystem.renter.log.Printf("Unable to open file %v for writing: %v", siaPath, err)
+			return nil, 0, errToStatus(err)
+		}
+		return fwh, 0, errToStatus(nil)
+	}
+
 	ffn.mu.Lock()
 	defer ffn.mu.Unlock()
 
-	stream, err := ffn.staticFilesystem.renter.StreamerByNode(ffn.staticFileNode, false)
+	fileNode := ffn.managedFileNode()
+	stream, err := ffn.staticFilesystem.renter.StreamerByNode(fileNode, false)
 	if err != nil {
-		siaPath := ffn.staticFilesystem.renter.staticFileSystem.FileSiaPath(ffn.staticFileNode)
+		siaPath := ffn.staticFilesystem.renter.staticFileSystem.FileSiaPath(fileNode)
 		ffn.staticFilesystem.renter.log.Printf("Unable to get stream for file %v: %v", siaPath, err)
 		return nil, 0, errToStatus(err)
 	}
@@ -276,6 +462,16 @@ func (ffn *fuseFilenode) Open(ctx context.Context, flags uint32) (fs.FileHandle,
 
 // Read will read data from the file and place it in dest.
 func (ffn *fuseFilenode) Read(ctx context.Context, f fs.FileHandle, dest []byte, offset int64) (fuse.ReadResult, syscall.Errno) {
+	// Files which are opened for writing are read from their buffer.
+	if fwh, ok := f.(*fuseWriteHandle); ok {
+		n, err := fwh.managedReadAt(dest, offset)
+		if err != nil {
+			ffn.staticFilesystem.renter.log.Printf("Error reading from write buffer at offset %v: %v", offset, err)
+			return nil, errToStatus(err)
+		}
+		return fuse.ReadResultData(dest[:n]), errToStatus(nil)
+	}
+
 	// TODO: Right now only one call to Read from a file can be in effect at
 	// once, based on the way the streamer and the read call has been
 	// implemented. As the streamer gets updated to more readily support
@@ -286,7 +482,7 @@ func (ffn *fuseFilenode) Read(ctx context.Context, f fs.FileHandle, dest []byte,
 
 	_, err := ffn.stream.Seek(offset, io.SeekStart)
 	if err != nil {
-		siaPath := ffn.staticFilesystem.renter.staticFileSystem.FileSiaPath(ffn.staticFileNode)
+		siaPath := ffn.staticFilesystem.renter.staticFileSystem.FileSiaPath(ffn.managedFileNode())
 		ffn.staticFilesystem.renter.log.Printf("Error seeking to offset %v during call to Read in file %s: %v", offset, siaPath.String(), err)
 		return nil, errToStatus(err)
 	}
@@ -297,7 +493,7 @@ func (ffn *fuseFilenode) Read(ctx context.Context, f fs.FileHandle, dest []byte,
 	// often dropping parts of the tail of the file.
 	n, err := io.ReadFull(ffn.stream, dest)
 	if err != nil && !errors.Contains(err, io.EOF) && err != io.ErrUnexpectedEOF {
-		siaPath := ffn.staticFilesystem.renter.staticFileSystem.FileSiaPath(ffn.staticFileNode)
+		siaPath := ffn.staticFilesystem.renter.staticFileSystem.FileSiaPath(ffn.managedFileNode())
 		ffn.staticFilesystem.renter.log.Printf("Error reading from offset %v during call to Read in file %s: %v", offset, siaPath.String(), err)
 		return nil, errToStatus(err)
 	}
@@ -338,6 +534,99 @@ func (fdn *fuseDirnode) Readdir(ctx context.Context) (fs.DirStream, syscall.Errn
 	return fs.NewListDirStream(dirEntries), errToStatus(nil)
 }
 
+// Release is called when the last reference to a file handle is closed.
+func (ffn *fuseFilenode) Release(ctx context.Context, fh fs.FileHandle) syscall.Errno {
+	fwh, ok := fh.(*fuseWriteHandle)
+	if !ok {
+		return errToStatus(nil)
+	}
+	// Write handles aren't closed by Flush, so the filesystem node is closed
+	// here, whether the handle wrote to the file or not.
+	err := errors.Compose(fwh.managedRelease(), ffn.managedCloseFileNode())
+	if err != nil {
+		siaPath := ffn.staticFilesystem.renter.staticFileSystem.FileSiaPath(ffn.managedFileNode())
+		ffn.staticFilesystem.renter.log.Printf("error when releasing fuse file %v: %v", siaPath, err)
+	}
+	return errToStatus(err)
+}
+
+// Rename moves a file or directory within the directory to a new location. An
+// existing file at the new location is replaced unless RENAME_NOREPLACE is set.
+func (fdn *fuseDirnode) Rename(ctx context.Context, name string, newParent fs.InodeEmbedder, newName string, flags uint32) syscall.Errno {
+	if fdn.staticFilesystem.options.ReadOnly {
+		return syscall.EROFS
+	}
+	newParentDir, ok := newParent.(*fuseDirnode)
+	if !ok || flags&fs.RENAME_EXCHANGE != 0 {
+		return syscall.EINVAL
+	}
+	oldSiaPath, err1 := fdn.childSiaPath(name)
+	newSiaPath, err2 := newParentDir.childSiaPath(newName)
+	if errors.Compose(err1, err2) != nil {
+		return syscall.EINVAL
+	}
+
+	renter := fdn.staticFilesystem.renter
+	isFile, err := renter.staticFileSystem.FileExists(oldSiaPath)
+	if err != nil {
+		return errToStatus(err)
+	}
+	if !isFile {
+		err = renter.RenameDir(oldSiaPath, newSiaPath)
+		if err != nil {
+			renter.log.Printf("Unable to rename fuse directory %v to %v: %v", oldSiaPath, newSiaPath, err)
+		}
+		return errToStatus(err)
+	}
+
+	// Replace an existing file at the destination.
+	exists, err := renter.staticFileSystem.FileExists(newSiaPath)
+	if err != nil {
+		return errToStatus(err)
+	}
+	if exists && flags&fuseRenameNoReplace != 0 {
+		return syscall.EEXIST
+	} else if exists {
+		err = renter.DeleteFile(newSiaPath)
+		if err != nil {
+			renter.log.Printf("Unable to replace fuse file %v: %v", newSiaPath, err)
+			return errToStatus(err)
+		}
+	}
+	err = renter.RenameFile(oldSiaPath, newSiaPath)
+	if err != nil {
+		renter.log.Printf("Unable to rename fuse file %v to %v: %v", oldSiaPath, newSiaPath, err)
+	}
+	return errToStatus(err)
+}
+
+// Setattr changes the attributes of a file. Only the size and the mode of a
+// file can be changed, other attributes are ignored.
+func (ffn *fuseFilenode) Setattr(ctx context.Context, fh fs.FileHandle, in *fuse.SetAttrIn, out *fuse.AttrOut) syscall.Errno {
+	if ffn.staticFilesystem.options.ReadOnly {
+		return syscall.EROFS
+	}
+	// Truncate the file. If the file isn't opened for writing, a temporary
+	// write handle is used.
+	if size, ok := in.GetSize(); ok {
+		err := ffn.managedTruncate(fh, size)
+		if err != nil {
+			siaPath := ffn.staticFilesystem.renter.staticFileSystem.FileSiaPath(ffn.managedFileNode())
+			ffn.staticFilesystem.renter.log.Printf("Unable to truncate fuse file %v: %v", siaPath, err)
+			return errToStatus(err)
+		}
+	}
+	if mode, ok := in.GetMode(); ok {
+		err := ffn.managedFileNode().SetMode(os.FileMode(mode) & os.ModePerm)
+		if err != nil {
+			siaPath := ffn.staticFilesystem.renter.staticFileSystem.FileSiaPath(ffn.managedFileNode())
+			ffn.staticFilesystem.renter.log.Printf("Unable to set mode of fuse file %v: %v", siaPath, err)
+			return errToStatus(err)
+		}
+	}
+	return ffn.Getattr(ctx, fh, out)
+}
+
 // setStatfsOut is a method that will set the StatfsOut fields which are
 // consistent across the fuse filesystem.
 func (ffs *fuseFS) setStatfsOut(out *fuse.StatfsOut) error {
@@ -401,9 +690,41 @@ func (fdn *fuseDirnode) Statfs(ctx context.Context, out *fuse.StatfsOut) syscall
 func (ffn *fuseFilenode) Statfs(ctx context.Context, out *fuse.StatfsOut) syscall.Errno {
 	err := ffn.staticFilesystem.setStatfsOut(out)
 	if err != nil {
-		siaPath := ffn.staticFilesystem.renter.staticFileSystem.FileSiaPath(ffn.staticFileNode)
+		siaPath := ffn.staticFilesystem.renter.staticFileSystem.FileSiaPath(ffn.managedFileNode())
 		ffn.staticFilesystem.renter.log.Printf("Error fetching statfs for fuse file %v: %v", siaPath, err)
 		return errToStatus(err)
 	}
 	return errToStatus(nil)
 }
+
+// Unlink deletes a file within the directory.
+func (fdn *fuseDirnode) Unlink(ctx context.Context, name string) syscall.Errno {
+	if fdn.staticFilesystem.options.ReadOnly {
+		return syscall.EROFS
+	}
+	siaPath, err := fdn.childSiaPath(name)
+	if err != nil {
+		return syscall.EINVAL
+	}
+	err = fdn.staticFilesystem.renter.DeleteFile(siaPath)
+	if err != nil {
+		fdn.staticFilesystem.renter.log.Printf("Unable to delete fuse file %v: %v", siaPath, err)
+	}
+	return errToStatus(err)
+}
+
+// Write writes data to the local buffer of a file that was opened for writing.
+// The data is uploaded when the file is flushed.
+func (ffn *fuseFilenode) Write(ctx context.Context, fh fs.FileHandle, data []byte, offset int64) (uint32, syscall.Errno) {
+	fwh, ok := fh.(*fuseWriteHandle)
+	if !ok {
+		return 0, syscall.EBADF
+	}
+	n, err := fwh.managedWriteAt(data, offset)
+	if err != nil {
+		siaPath := ffn.staticFilesystem.renter.staticFileSystem.FileSiaPath(ffn.managedFileNode())
+		ffn.staticFilesystem.renter.log.Printf("Error writing to offset %v in file %s: %v", offset, siaPath.String(), err)
+		return uint32(n), errToStatus(err)
+	}
+	return uint32(n), errToStatus(nil)
+}
@@ -4,6 +4,8 @@ package renter
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"sync"
 
 	"github.com/hanwen/go-fuse/v2/fs"
@@ -30,6 +32,12 @@ func newFuseManager(r *Renter) renterFuseManager {
 		renter:      r,
 	}
 
+	// Remove any write buffers which were left behind by an unclean shutdown.
+	err := os.RemoveAll(filepath.Join(r.persistDir, fuseWriteBufferDir))
+	if err != nil {
+		r.log.Println("WARN: unable to remove fuse write buffers:", err)
+	}
+
 	// Close the fuse manager on shutdown.
 	r.tg.OnStop(func() error {
 		return fm.managedCloseFuseManager()
@@ -98,11 +106,6 @@ func (fm *fuseManager) Mount(mountPoint string, sp modules.SiaPath, opts modules
 		}
 	}()
 
-	// Enforce read-only requirements for now.
-	if !opts.ReadOnly {
-		return errors.New("fuse must be mounted as read-only")
-	}
-
 	// Get the mountpoint's root from the filesystem.
 	rootDirNode, err := fm.renter.staticFileSystem.OpenSiaDir(sp)
 	if err != nil {
@@ -131,10 +134,15 @@ func (fm *fuseManager) Mount(mountPoint string, sp modules.SiaPath, opts modules
 		return errors.New("there is already a sia fuse system mounted at " + mountPoint)
 	}
 
-	// Mount the filesystem.
+	// Mount the filesystem. Read-only mounts are also enforced by the kernel.
+	var mountOpts []string
+	if opts.ReadOnly {
+		mountOpts = append(mountOpts, "ro")
+	}
 	server, err := fs.Mount(mountPoint, filesystem.root, &fs.Options{
 		MountOptions: fuse.MountOptions{
 			AllowOther: opts.AllowOther,
+			Options:    mountOpts,
 			// Debug: true,
 		},
 	})
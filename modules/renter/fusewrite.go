@@ -0,0 +1,219 @@
+// +build linux darwin
+
+package renter
+
+// fusewrite.go contains the write support of writable fuse mounts. Files which
+// are opened for writing get a fuseWriteHandle which buffers all writes in a
+// local file. When the handle is flushed, the buffer is uploaded to the Sia
+// network using the upload streamer and replaces the previous version of the
+// file. The buffer is removed once the handle is released, so no local copy of
+// the file is kept around.
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/hanwen/go-fuse/v2/fs"
+	"gitlab.com/NebulousLabs/errors"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/modules/renter/filesystem"
+)
+
+const (
+	// fuseWriteBufferDir is the name of the directory within the renter's
+	// persist dir which contains the local buffers of files that are being
+	// written.
+	fuseWriteBufferDir = "fusebuffers"
+)
+
+// fuseWriteHandle is a file handle for a fuse file which was opened for
+// writing.
+type fuseWriteHandle struct {
+	buffer *os.File
+	dirty  bool
+	mu     sync.Mutex
+
+	staticFilenode *fuseFilenode
+}
+
+// managedNewEmptyFile creates a new empty siafile with the default erasure
+// code and opens it.
+func (ffs *fuseFS) managedNewEmptyFile(siaPath modules.SiaPath, mode os.FileMode) (*filesystem.FileNode, error) {
+	ec := modules.NewRSSubCodeDefault()
+	sk := crypto.GenerateSiaKey(crypto.TypeDefaultRenter)
+	err := ffs.renter.staticFileSystem.NewSiaFile(siaPath, "", ec, sk, 0, mode, false)
+	if err != nil {
+		return nil, errors.AddContext(err, "unable to create siafile")
+	}
+	return ffs.renter.staticFileSystem.OpenSiaFile(siaPath)
+}
+
+// managedOpenWriteHandle opens a write handle for the file. Unless the file is
+// truncated, its current content is downloaded into the buffer first so that
+// it can be modified.
+func (ffn *fuseFilenode) managedOpenWriteHandle(truncate bool) (_ *fuseWriteHandle, err error) {
+	r := ffn.staticFilesystem.renter
+	bufferDir := filepath.Join(r.persistDir, fuseWriteBufferDir)
+	if err := os.MkdirAll(bufferDir, 0700); err != nil {
+		return nil, errors.AddContext(err, "unable to create write buffer dir")
+	}
+	buffer, err := ioutil.TempFile(bufferDir, "")
+	if err != nil {
+		return nil, errors.AddContext(err, "unable to create write buffer")
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Compose(err, buffer.Close(), os.Remove(buffer.Name()))
+		}
+	}()
+
+	fileNode := ffn.managedFileNode()
+	if !truncate && fileNode.Size() > 0 {
+		stream, err := r.StreamerByNode(fileNode, false)
+		if err != nil {
+			return nil, errors.AddContext(err, "unable to open stream")
+		}
+		_, err = io.Copy(buffer, stream)
+		if err = errors.Compose(err, stream.Close()); err != nil {
+			return nil, errors.AddContext(err, "unable to download file into write buffer")
+		}
+	}
+	return &fuseWriteHandle{
+		buffer: buffer,
+		dirty:  truncate,
+
+		staticFilenode: ffn,
+	}, nil
+}
+
+// managedTruncate truncates the file to the provided size. If the file isn't
+// opened for writing, a temporary write handle is used.
+func (ffn *fuseFilenode) managedTruncate(fh fs.FileHandle, size uint64) (err error) {
+	if fwh, ok := fh.(*fuseWriteHandle); ok {
+		return fwh.managedTruncate(size)
+	}
+	fwh, err := ffn.managedOpenWriteHandle(size == 0)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Compose(err, fwh.managedRelease())
+	}()
+	return fwh.managedTruncate(size)
+}
+
+// flush streams the buffer to the Sia network if it was modified since the
+// last flush. The previous version of the file is replaced once the upload
+// streamer returns, at which point the new version is available for download.
+func (fwh *fuseWriteHandle) flush() error {
+	if !fwh.dirty {
+		return nil
+	}
+	ffn := fwh.staticFilenode
+	ffs := ffn.staticFilesystem
+	r := ffs.renter
+	oldNode := ffn.managedFileNode()
+	siaPath := r.staticFileSystem.FileSiaPath(oldNode)
+	mode := oldNode.Mode()
+	stat, err := fwh.buffer.Stat()
+	if err != nil {
+		return errors.AddContext(err, "unable to fetch size of write buffer")
+	}
+
+	var newNode *filesystem.FileNode
+	if stat.Size() == 0 {
+		// Empty files don't need to be uploaded, so they are created directly.
+		// This also works without any workers.
+		err = r.DeleteFile(siaPath)
+		if err != nil && !errors.Contains(err, filesystem.ErrNotExist) {
+			return errors.AddContext(err, "unable to delete previous version of file")
+		}
+		newNode, err = ffs.managedNewEmptyFile(siaPath, mode)
+		if err != nil {
+			return err
+		}
+	} else {
+		up := modules.FileUploadParams{
+			SiaPath:    siaPath,
+			CipherType: crypto.TypeDefaultRenter,
+			Force:      true,
+		}
+		err = r.UploadStreamFromReader(up, io.NewSectionReader(fwh.buffer, 0, stat.Size()))
+		if err != nil {
+			return errors.AddContext(err, "unable to upload write buffer")
+		}
+		newNode, err = r.staticFileSystem.OpenSiaFile(siaPath)
+		if err != nil {
+			return errors.AddContext(err, "unable to open uploaded file")
+		}
+		// Streamed files are created with the default mode.
+		if err := newNode.SetMode(mode); err != nil {
+			return errors.Compose(err, newNode.Close())
+		}
+	}
+	fwh.dirty = false
+	return ffn.managedSetFileNode(newNode)
+}
+
+// managedFlush uploads the buffer if it was modified since the last flush.
+func (fwh *fuseWriteHandle) managedFlush() error {
+	fwh.mu.Lock()
+	defer fwh.mu.Unlock()
+	return fwh.flush()
+}
+
+// managedReadAt reads from the buffer at the provided offset.
+func (fwh *fuseWriteHandle) managedReadAt(b []byte, off int64) (int, error) {
+	fwh.mu.Lock()
+	defer fwh.mu.Unlock()
+	n, err := fwh.buffer.ReadAt(b, off)
+	if errors.Contains(err, io.EOF) {
+		err = nil
+	}
+	return n, err
+}
+
+// managedRelease flushes the buffer one last time and deletes it.
+func (fwh *fuseWriteHandle) managedRelease() error {
+	fwh.mu.Lock()
+	defer fwh.mu.Unlock()
+	err := fwh.flush()
+	return errors.Compose(err, fwh.buffer.Close(), os.Remove(fwh.buffer.Name()))
+}
+
+// managedSize returns the size of the buffer.
+func (fwh *fuseWriteHandle) managedSize() (uint64, error) {
+	fwh.mu.Lock()
+	defer fwh.mu.Unlock()
+	stat, err := fwh.buffer.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return uint64(stat.Size()), nil
+}
+
+// managedTruncate truncates the buffer to the provided size.
+func (fwh *fuseWriteHandle) managedTruncate(size uint64) error {
+	fwh.mu.Lock()
+	defer fwh.mu.Unlock()
+	if err := fwh.buffer.Truncate(int64(size)); err != nil {
+		return err
+	}
+	fwh.dirty = true
+	return nil
+}
+
+// managedWriteAt writes to the buffer at the provided offset.
+func (fwh *fuseWriteHandle) managedWriteAt(b []byte, off int64) (int, error) {
+	fwh.mu.Lock()
+	defer fwh.mu.Unlock()
+	n, err := fwh.buffer.WriteAt(b, off)
+	if n > 0 {
+		fwh.dirty = true
+	}
+	return n, err
+}
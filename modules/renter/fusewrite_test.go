@@ -0,0 +1,110 @@
+// +build linux darwin
+
+package renter
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+	"testing"
+
+	"gitlab.com/NebulousLabs/fastrand"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/persist"
+	"go.sia.tech/siad/siatest/dependencies"
+)
+
+// TestFuseWriteHandle tests that a write handle stays dirty if flushing it
+// fails, that empty files are created without an upload, that releasing a
+// handle removes its buffer and closes the file's filesystem node and that no
+// local copy of the file is kept.
+func TestFuseWriteHandle(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	rt, err := newRenterTesterWithDependency(t.Name(), &dependencies.DependencyDisableRepairAndHealthLoops{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := rt.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	ffs := &fuseFS{renter: rt.renter}
+	siaPath := modules.RandomSiaPath()
+	fileNode, err := ffs.managedNewEmptyFile(siaPath, persist.DefaultDiskPermissionsTest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ffn := &fuseFilenode{
+		staticFilesystem: ffs,
+		fileNode:         fileNode,
+	}
+
+	// Write to the file. The renter has no workers, so streaming the buffer
+	// fails and the handle should stay dirty with its data intact.
+	fwh, err := ffn.managedOpenWriteHandle(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := fastrand.Bytes(100)
+	if _, err := fwh.managedWriteAt(data, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := fwh.managedFlush(); err == nil {
+		t.Fatal("expected flush to fail without workers")
+	}
+	if !fwh.dirty {
+		t.Fatal("handle should still be dirty after a failed flush")
+	}
+	b := make([]byte, len(data))
+	if _, err := fwh.managedReadAt(b, 0); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, data) {
+		t.Fatal("write buffer doesn't contain the written data")
+	}
+
+	// Truncating the file creates an empty file on flush, which works without
+	// workers and keeps the mode of the file.
+	if err := fwh.managedTruncate(0); err != nil {
+		t.Fatal(err)
+	}
+	if err := fwh.managedFlush(); err != nil {
+		t.Fatal(err)
+	}
+	fileNode = ffn.managedFileNode()
+	if fileNode.Size() != 0 || fileNode.LocalPath() != "" {
+		t.Fatal("unexpected file", fileNode.Size(), fileNode.LocalPath())
+	}
+	if fileNode.Mode() != persist.DefaultDiskPermissionsTest {
+		t.Fatal("wrong file mode", fileNode.Mode())
+	}
+
+	// Releasing the handle should remove its buffer and close the file node.
+	buffer := fwh.buffer.Name()
+	if errno := ffn.Release(context.Background(), fwh); errno != syscall.F_OK {
+		t.Fatal("unable to release write handle", errno)
+	}
+	if atomic.LoadUint32(&ffn.atomicClosed) != 1 {
+		t.Fatal("file node wasn't closed")
+	}
+	if _, err := os.Stat(buffer); !os.IsNotExist(err) {
+		t.Fatal("write buffer wasn't removed", err)
+	}
+	buffers, err := ioutil.ReadDir(filepath.Join(rt.renter.persistDir, fuseWriteBufferDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buffers) != 0 {
+		t.Fatal("write buffers weren't removed", len(buffers))
+	}
+}
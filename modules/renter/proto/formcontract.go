@@ -41,8 +41,8 @@ func (cs *ContractSet) FormContract(params modules.ContractParams, txnBuilder tr
 	if types.PostTax(startHeight, totalPayout).Cmp(hostPayout) < 0 {
 		return modules.RenterContract{}, nil, types.Transaction{}, nil, errors.New("not enough money to pay both siafund fee and also host payout")
 	}
-	// Fund the transaction.
-	err = txnBuilder.FundSiacoins(funding)
+	// Fund the transaction from the allowance's funding account.
+	err = txnBuilder.FundSiacoinsCoinControl(funding, modules.CoinControl{Account: allowance.FundingAccount})
 	if err != nil {
 		return modules.RenterContract{}, nil, types.Transaction{}, nil, err
 	}
@@ -20,6 +20,7 @@ type (
 		AddTransactionSignature(types.TransactionSignature) uint64
 		Copy() modules.TransactionBuilder
 		FundSiacoins(types.Currency) error
+		FundSiacoinsCoinControl(types.Currency, modules.CoinControl) error
 		Sign(bool) ([]types.Transaction, error)
 		UnconfirmedParents() ([]types.Transaction, error)
 		View() (types.Transaction, []types.Transaction)
@@ -0,0 +1,483 @@
+package renter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/modules"
+)
+
+var (
+	// registrySubscriptionNumWorkers is the number of workers a registry
+	// subscription is fanned out to.
+	registrySubscriptionNumWorkers = build.Select(build.Var{
+		Dev:      3,
+		Standard: 5,
+		Testing:  2,
+	}).(int)
+
+	// registrySubscriptionTimeout is the amount of time the subscription
+	// manager waits for the workers to establish a new subscription before
+	// giving up on the initial values.
+	registrySubscriptionTimeout = build.Select(build.Var{
+		Dev:      30 * time.Second,
+		Standard: time.Minute,
+		Testing:  10 * time.Second,
+	}).(time.Duration)
+
+	// registrySubscriptionRefreshInterval is the interval at which the
+	// subscription manager replaces workers which are no longer usable.
+	registrySubscriptionRefreshInterval = build.Select(build.Var{
+		Dev:      30 * time.Second,
+		Standard: 5 * time.Minute,
+		Testing:  5 * time.Second,
+	}).(time.Duration)
+)
+
+var (
+	// errNoRegistrySubscriptionWorkers is returned if there are no workers
+	// available for subscribing to the registry.
+	errNoRegistrySubscriptionWorkers = errors.New("no workers available for registry subscriptions")
+
+	// errRegistrySubscriberClosed is returned when trying to use a registry
+	// subscriber that was closed already.
+	errRegistrySubscriberClosed = errors.New("registry subscriber was closed")
+)
+
+type (
+	// registrySubscriptionManager keeps track of the renter's registry
+	// subscriptions. It fans every subscription out to multiple workers and
+	// forwards the deduplicated notifications to the subscribers.
+	registrySubscriptionManager struct {
+		// subscriptions contains all the entries that at least one subscriber
+		// is subscribed to.
+		subscriptions map[modules.RegistryEntryID]*registrySubscription
+
+		staticRenter *Renter
+		mu           sync.Mutex
+	}
+
+	// registrySubscription contains the information about a single
+	// subscribed entry.
+	registrySubscription struct {
+		staticRequest modules.RPCRegistrySubscriptionRequest
+
+		// latest is the entry with the highest revision number that was
+		// received from any worker.
+		latest *modules.SignedRegistryValue
+
+		// subscribers are all the subscribers that are interested in the
+		// entry.
+		subscribers map[*registrySubscriber]struct{}
+
+		// workers are the workers the subscription was fanned out to, indexed
+		// by their host's public key.
+		workers map[string]*worker
+	}
+
+	// registrySubscriber is a single subscriber of the registry subscription
+	// manager. It implements the modules.RegistrySubscriber interface.
+	registrySubscriber struct {
+		// closed and subscriptions are protected by the manager's mutex.
+		closed        bool
+		subscriptions map[modules.RegistryEntryID]modules.RPCRegistrySubscriptionRequest
+
+		// notified contains the highest revision that was passed to the
+		// notify func for every entry.
+		notified   map[modules.RegistryEntryID]uint64
+		notifiedMu sync.Mutex
+
+		staticManager    *registrySubscriptionManager
+		staticNotifyFunc func(modules.RPCRegistrySubscriptionNotificationEntryUpdate)
+	}
+)
+
+// newRegistrySubscriptionManager creates a new registry subscription manager.
+func newRegistrySubscriptionManager(r *Renter) *registrySubscriptionManager {
+	return &registrySubscriptionManager{
+		subscriptions: make(map[modules.RegistryEntryID]*registrySubscription),
+		staticRenter:  r,
+	}
+}
+
+// newRegistrySubscription creates a new subscription for the provided request.
+func newRegistrySubscription(req modules.RPCRegistrySubscriptionRequest) *registrySubscription {
+	return &registrySubscription{
+		staticRequest: req,
+		subscribers:   make(map[*registrySubscriber]struct{}),
+		workers:       make(map[string]*worker),
+	}
+}
+
+// NewRegistrySubscriber creates a new registry subscriber which calls
+// notifyFunc whenever one of the entries it is subscribed to is updated.
+func (r *Renter) NewRegistrySubscriber(notifyFunc func(modules.RPCRegistrySubscriptionNotificationEntryUpdate)) (modules.RegistrySubscriber, error) {
+	if err := r.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer r.tg.Done()
+	return &registrySubscriber{
+		subscriptions:    make(map[modules.RegistryEntryID]modules.RPCRegistrySubscriptionRequest),
+		notified:         make(map[modules.RegistryEntryID]uint64),
+		staticManager:    r.staticRegistrySubscriptions,
+		staticNotifyFunc: notifyFunc,
+	}, nil
+}
+
+// Close unsubscribes the subscriber from all of its entries. After calling
+// Close, the subscriber can no longer be used.
+func (rs *registrySubscriber) Close() error {
+	m := rs.staticManager
+	m.mu.Lock()
+	if rs.closed {
+		m.mu.Unlock()
+		return errRegistrySubscriberClosed
+	}
+	rs.closed = true
+	requests := make([]modules.RPCRegistrySubscriptionRequest, 0, len(rs.subscriptions))
+	for _, req := range rs.subscriptions {
+		requests = append(requests, req)
+	}
+	m.mu.Unlock()
+
+	m.managedUnsubscribe(rs, requests)
+	return nil
+}
+
+// Subscribe subscribes to the provided entries. Once the subscriptions are
+// established, the latest known values of the entries are passed to the
+// subscriber's notify func.
+func (rs *registrySubscriber) Subscribe(requests ...modules.RPCRegistrySubscriptionRequest) error {
+	m := rs.staticManager
+	r := m.staticRenter
+	if err := r.tg.Add(); err != nil {
+		return err
+	}
+	defer r.tg.Done()
+
+	// Add the subscriber to the subscriptions and remember the ones which
+	// haven't been fanned out to any workers yet.
+	m.mu.Lock()
+	if rs.closed {
+		m.mu.Unlock()
+		return errRegistrySubscriberClosed
+	}
+	var newSubs []*registrySubscription
+	for _, req := range requests {
+		eid := modules.DeriveRegistryEntryID(req.PubKey, req.Tweak)
+		sub, exists := m.subscriptions[eid]
+		if !exists {
+			sub = newRegistrySubscription(req)
+			m.subscriptions[eid] = sub
+			newSubs = append(newSubs, sub)
+		}
+		sub.subscribers[rs] = struct{}{}
+		rs.subscriptions[eid] = req
+	}
+	m.mu.Unlock()
+
+	// Fan the new subscriptions out to the workers.
+	if len(newSubs) > 0 {
+		err := m.managedFanOut(newSubs)
+		if err != nil {
+			m.managedUnsubscribe(rs, requests)
+			return errors.AddContext(err, "failed to subscribe to registry entries")
+		}
+	}
+
+	// Notify the subscriber about the latest known values.
+	m.mu.Lock()
+	var updates []modules.RPCRegistrySubscriptionNotificationEntryUpdate
+	for _, req := range requests {
+		sub, exists := m.subscriptions[modules.DeriveRegistryEntryID(req.PubKey, req.Tweak)]
+		if !exists || sub.latest == nil {
+			continue
+		}
+		updates = append(updates, modules.RPCRegistrySubscriptionNotificationEntryUpdate{
+			Entry:  *sub.latest,
+			PubKey: sub.staticRequest.PubKey,
+		})
+	}
+	m.mu.Unlock()
+	for _, update := range updates {
+		rs.managedNotify(update)
+	}
+	return nil
+}
+
+// Unsubscribe unsubscribes from the provided entries.
+func (rs *registrySubscriber) Unsubscribe(requests ...modules.RPCRegistrySubscriptionRequest) {
+	rs.staticManager.managedUnsubscribe(rs, requests)
+}
+
+// managedNotify passes an update to the subscriber's notify func unless the
+// subscriber was already notified about the same or a more recent revision.
+func (rs *registrySubscriber) managedNotify(update modules.RPCRegistrySubscriptionNotificationEntryUpdate) {
+	rs.notifiedMu.Lock()
+	defer rs.notifiedMu.Unlock()
+	eid := modules.DeriveRegistryEntryID(update.PubKey, update.Entry.Tweak)
+	revision, exists := rs.notified[eid]
+	if exists && revision >= update.Entry.Revision {
+		return
+	}
+	rs.notified[eid] = update.Entry.Revision
+	rs.staticNotifyFunc(update)
+}
+
+// threadedNotify notifies a subscriber about an update in a separate thread to
+// avoid blocking the worker that received the update.
+func (rs *registrySubscriber) threadedNotify(update modules.RPCRegistrySubscriptionNotificationEntryUpdate) {
+	r := rs.staticManager.staticRenter
+	if err := r.tg.Add(); err != nil {
+		return
+	}
+	defer r.tg.Done()
+	rs.managedNotify(update)
+}
+
+// managedBestWorkers returns up to n workers which support registry
+// subscriptions, sorted by their recent registry performance.
+func (m *registrySubscriptionManager) managedBestWorkers(n int) []*worker {
+	r := m.staticRenter
+	var workers []*worker
+	for _, w := range r.staticWorkerPool.callWorkers() {
+		// Skip workers which don't support subscriptions.
+		cache := w.staticCache()
+		if build.VersionCmp(cache.staticHostVersion, minSubscriptionVersion) < 0 {
+			continue
+		}
+		// Skip workers which are on cooldown.
+		if w.managedOnMaintenanceCooldown() {
+			continue
+		}
+		if _, onCooldown := w.staticSubscriptionInfo.managedOnCooldown(); onCooldown {
+			continue
+		}
+		// Check for price gouging.
+		pt := w.staticPriceTable().staticPriceTable
+		err := checkProjectDownloadGouging(pt, cache.staticRenterAllowance)
+		if err != nil {
+			r.log.Debugf("price gouging detected in worker %v, err: %v\n", w.staticHostPubKeyStr, err)
+			continue
+		}
+		workers = append(workers, w)
+	}
+
+	// Sort the workers by their expected registry read time. Workers without
+	// any historic data are sorted to the end.
+	jobTimes := make(map[*worker]time.Duration, len(workers))
+	for _, w := range workers {
+		jobTimes[w] = w.staticJobReadRegistryQueue.callExpectedJobTime()
+	}
+	sort.SliceStable(workers, func(i, j int) bool {
+		ti, tj := jobTimes[workers[i]], jobTimes[workers[j]]
+		if ti == 0 || tj == 0 {
+			return ti != 0 && tj == 0
+		}
+		return ti < tj
+	})
+	if len(workers) > n {
+		workers = workers[:n]
+	}
+	return workers
+}
+
+// managedFanOut subscribes the best workers to the provided subscriptions and
+// waits for them to be done or for registrySubscriptionTimeout to pass. An
+// error is returned if none of the workers could subscribe.
+func (m *registrySubscriptionManager) managedFanOut(subs []*registrySubscription) error {
+	r := m.staticRenter
+	workers := m.managedBestWorkers(registrySubscriptionNumWorkers)
+	if len(workers) == 0 {
+		return errNoRegistrySubscriptionWorkers
+	}
+
+	// Register the workers with the subscriptions before subscribing to make
+	// sure a concurrent unsubscribe reaches them.
+	m.mu.Lock()
+	var requests []modules.RPCRegistrySubscriptionRequest
+	for _, sub := range subs {
+		for _, w := range workers {
+			sub.workers[w.staticHostPubKeyStr] = w
+		}
+		requests = append(requests, sub.staticRequest)
+	}
+	m.mu.Unlock()
+
+	// Subscribe the workers. The initial values are forwarded to the manager
+	// by the workers themselves.
+	ctx, cancel := context.WithTimeout(r.tg.StopCtx(), registrySubscriptionTimeout)
+	defer cancel()
+	var wg sync.WaitGroup
+	var errsMu sync.Mutex
+	var errs []error
+	var failed []*worker
+	for _, w := range workers {
+		wg.Add(1)
+		go func(w *worker) {
+			defer wg.Done()
+			_, err := w.Subscribe(ctx, requests...)
+			if err != nil {
+				r.log.Debugf("worker %v failed to subscribe to registry entries: %v", w.staticHostPubKeyStr, err)
+				errsMu.Lock()
+				errs = append(errs, errors.AddContext(err, fmt.Sprintf("worker %v failed to subscribe", w.staticHostPubKeyStr)))
+				failed = append(failed, w)
+				errsMu.Unlock()
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	// Remove the workers which failed to subscribe from the subscriptions.
+	m.mu.Lock()
+	for _, sub := range subs {
+		for _, w := range failed {
+			delete(sub.workers, w.staticHostPubKeyStr)
+		}
+	}
+	m.mu.Unlock()
+	if len(failed) == len(workers) {
+		return errors.Compose(errs...)
+	}
+
+	// Unsubscribe the workers from subscriptions that were removed in the
+	// meantime.
+	m.mu.Lock()
+	var removed []modules.RPCRegistrySubscriptionRequest
+	for _, sub := range subs {
+		eid := modules.DeriveRegistryEntryID(sub.staticRequest.PubKey, sub.staticRequest.Tweak)
+		if _, exists := m.subscriptions[eid]; !exists {
+			removed = append(removed, sub.staticRequest)
+		}
+	}
+	m.mu.Unlock()
+	if len(removed) > 0 {
+		for _, w := range workers {
+			w.Unsubscribe(removed...)
+		}
+	}
+	return nil
+}
+
+// managedNotify is called by the workers whenever they receive a new value for
+// a subscribed entry. Updates with a revision number that is not higher than
+// the one of the latest known value are ignored.
+func (m *registrySubscriptionManager) managedNotify(updates ...modules.RPCRegistrySubscriptionNotificationEntryUpdate) {
+	for _, update := range updates {
+		m.mu.Lock()
+		sub, exists := m.subscriptions[modules.DeriveRegistryEntryID(update.PubKey, update.Entry.Tweak)]
+		if !exists || (sub.latest != nil && sub.latest.Revision >= update.Entry.Revision) {
+			m.mu.Unlock()
+			continue
+		}
+		entry := update.Entry
+		sub.latest = &entry
+		subscribers := make([]*registrySubscriber, 0, len(sub.subscribers))
+		for rs := range sub.subscribers {
+			subscribers = append(subscribers, rs)
+		}
+		m.mu.Unlock()
+
+		for _, rs := range subscribers {
+			go rs.threadedNotify(update)
+		}
+	}
+}
+
+// managedRefreshSubscriptions replaces workers of subscriptions which were
+// killed and fans the subscriptions out to new workers if necessary.
+func (m *registrySubscriptionManager) managedRefreshSubscriptions() {
+	r := m.staticRenter
+	workers := m.managedBestWorkers(registrySubscriptionNumWorkers)
+
+	m.mu.Lock()
+	toSubscribe := make(map[*worker][]modules.RPCRegistrySubscriptionRequest)
+	for _, sub := range m.subscriptions {
+		for hpk, w := range sub.workers {
+			if w.staticKilled() {
+				delete(sub.workers, hpk)
+			}
+		}
+		for _, w := range workers {
+			if len(sub.workers) >= registrySubscriptionNumWorkers {
+				break
+			}
+			if _, exists := sub.workers[w.staticHostPubKeyStr]; exists {
+				continue
+			}
+			sub.workers[w.staticHostPubKeyStr] = w
+			toSubscribe[w] = append(toSubscribe[w], sub.staticRequest)
+		}
+	}
+	m.mu.Unlock()
+
+	// Subscribe the new workers in the background. The values are forwarded
+	// to the manager by the workers.
+	for w, requests := range toSubscribe {
+		w, requests := w, requests
+		err := r.tg.Launch(func() {
+			ctx, cancel := context.WithTimeout(r.tg.StopCtx(), registrySubscriptionTimeout)
+			defer cancel()
+			_, err := w.Subscribe(ctx, requests...)
+			if err != nil {
+				r.log.Debugf("worker %v failed to subscribe to registry entries: %v", w.staticHostPubKeyStr, err)
+			}
+		})
+		if err != nil {
+			return // shutdown
+		}
+	}
+}
+
+// managedUnsubscribe removes a subscriber from the provided subscriptions and
+// unsubscribes the workers from entries that no subscriber is interested in
+// anymore.
+func (m *registrySubscriptionManager) managedUnsubscribe(rs *registrySubscriber, requests []modules.RPCRegistrySubscriptionRequest) {
+	m.mu.Lock()
+	toUnsubscribe := make(map[*worker][]modules.RPCRegistrySubscriptionRequest)
+	for _, req := range requests {
+		eid := modules.DeriveRegistryEntryID(req.PubKey, req.Tweak)
+		delete(rs.subscriptions, eid)
+		sub, exists := m.subscriptions[eid]
+		if !exists {
+			continue
+		}
+		delete(sub.subscribers, rs)
+		if len(sub.subscribers) > 0 {
+			continue
+		}
+		delete(m.subscriptions, eid)
+		for _, w := range sub.workers {
+			toUnsubscribe[w] = append(toUnsubscribe[w], sub.staticRequest)
+		}
+	}
+	m.mu.Unlock()
+
+	for w, requests := range toUnsubscribe {
+		w.Unsubscribe(requests...)
+	}
+}
+
+// threadedRefreshRegistrySubscriptions periodically refreshes the workers of
+// the registry subscriptions.
+func (m *registrySubscriptionManager) threadedRefreshRegistrySubscriptions() {
+	r := m.staticRenter
+	if err := r.tg.Add(); err != nil {
+		return
+	}
+	defer r.tg.Done()
+
+	for {
+		select {
+		case <-r.tg.StopChan():
+			return
+		case <-time.After(registrySubscriptionRefreshInterval):
+		}
+		m.managedRefreshSubscriptions()
+	}
+}
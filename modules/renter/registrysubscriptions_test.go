@@ -0,0 +1,224 @@
+package renter
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/modules"
+)
+
+// TestRegistrySubscriptionManager tests subscribing to a registry entry through
+// the renter's registry subscription manager.
+func TestRegistrySubscriptionManager(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	wt, err := newWorkerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := wt.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	r := wt.renter
+	m := r.staticRegistrySubscriptions
+
+	// Wait for the worker to be usable for subscriptions.
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		if len(m.managedBestWorkers(registrySubscriptionNumWorkers)) != 1 {
+			return fmt.Errorf("worker not ready")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Set an entry on the host.
+	rv, spk, sk := randomRegistryValue()
+	err = wt.UpdateRegistry(context.Background(), spk, rv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a subscriber which collects its notifications.
+	var notifications []modules.RPCRegistrySubscriptionNotificationEntryUpdate
+	var mu sync.Mutex
+	subscriber, err := r.NewRegistrySubscriber(func(update modules.RPCRegistrySubscriptionNotificationEntryUpdate) {
+		mu.Lock()
+		defer mu.Unlock()
+		notifications = append(notifications, update)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	numNotifications := func(n int) error {
+		return build.Retry(100, 100*time.Millisecond, func() error {
+			mu.Lock()
+			defer mu.Unlock()
+			if len(notifications) != n {
+				return fmt.Errorf("expected %v notifications but got %v", n, len(notifications))
+			}
+			return nil
+		})
+	}
+
+	// Subscribe to the entry. The initial value should be passed to the
+	// notify func.
+	req := modules.RPCRegistrySubscriptionRequest{
+		PubKey: spk,
+		Tweak:  rv.Tweak,
+	}
+	err = subscriber.Subscribe(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := numNotifications(1); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	if !reflect.DeepEqual(notifications[0].Entry, rv) || !notifications[0].PubKey.Equals(spk) {
+		t.Fatal("wrong notification", notifications[0])
+	}
+	mu.Unlock()
+
+	// The subscription should be fanned out to the worker.
+	m.mu.Lock()
+	eid := modules.DeriveRegistryEntryID(spk, rv.Tweak)
+	sub, exists := m.subscriptions[eid]
+	if !exists || len(sub.workers) != 1 || len(sub.subscribers) != 1 {
+		t.Fatal("subscription wasn't added correctly")
+	}
+	m.mu.Unlock()
+
+	// Notifying the manager about the same revision again should be ignored.
+	m.managedNotify(modules.RPCRegistrySubscriptionNotificationEntryUpdate{
+		Entry:  rv,
+		PubKey: spk,
+	})
+
+	// Update the entry on the host. The subscriber should be notified about
+	// the new revision exactly once.
+	rv.Revision++
+	rv = rv.Sign(sk)
+	err = wt.UpdateRegistry(context.Background(), spk, rv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := numNotifications(2); err != nil {
+		t.Fatal(err)
+	}
+	m.managedNotify(modules.RPCRegistrySubscriptionNotificationEntryUpdate{
+		Entry:  rv,
+		PubKey: spk,
+	})
+	time.Sleep(time.Second)
+	if err := numNotifications(2); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	if !reflect.DeepEqual(notifications[1].Entry, rv) {
+		t.Fatal("wrong notification", notifications[1])
+	}
+	mu.Unlock()
+
+	// Close the subscriber. The manager and the worker should no longer be
+	// subscribed to the entry.
+	err = subscriber.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.mu.Lock()
+	_, exists = m.subscriptions[eid]
+	m.mu.Unlock()
+	if exists {
+		t.Fatal("subscription wasn't removed")
+	}
+	subInfo := wt.staticSubscriptionInfo
+	subInfo.mu.Lock()
+	workerSub, exists := subInfo.subscriptions[eid]
+	subscribed := exists && workerSub.subscribe
+	subInfo.mu.Unlock()
+	if subscribed {
+		t.Fatal("worker is still subscribed")
+	}
+
+	// Using the closed subscriber should fail.
+	if err := subscriber.Subscribe(req); err != errRegistrySubscriberClosed {
+		t.Fatal("expected subscriber to be closed", err)
+	}
+}
+
+// dependencyDisableSubscriptionLoop prevents the workers from establishing
+// subscriptions with their hosts.
+type dependencyDisableSubscriptionLoop struct {
+	modules.ProductionDependencies
+}
+
+// Disrupt prevents the subscription loop from running.
+func (*dependencyDisableSubscriptionLoop) Disrupt(s string) bool {
+	return s == "DisableSubscriptionLoop"
+}
+
+// TestRegistrySubscriptionManagerFanOutFailure tests that subscribing fails
+// if none of the workers can subscribe to the entries.
+func TestRegistrySubscriptionManagerFanOutFailure(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	wt, err := newWorkerTesterCustomDependency(t.Name(), &dependencyDisableSubscriptionLoop{}, modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := wt.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	r := wt.renter
+	m := r.staticRegistrySubscriptions
+
+	// Wait for the worker to be usable for subscriptions.
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		if len(m.managedBestWorkers(registrySubscriptionNumWorkers)) != 1 {
+			return fmt.Errorf("worker not ready")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Subscribing should fail since the worker never subscribes.
+	subscriber, err := r.NewRegistrySubscriber(func(modules.RPCRegistrySubscriptionNotificationEntryUpdate) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rv, spk, _ := randomRegistryValue()
+	err = subscriber.Subscribe(modules.RPCRegistrySubscriptionRequest{
+		PubKey: spk,
+		Tweak:  rv.Tweak,
+	})
+	if err == nil {
+		t.Fatal("subscribing should fail if no worker could subscribe")
+	}
+
+	// The subscription should have been removed again.
+	m.mu.Lock()
+	_, exists := m.subscriptions[modules.DeriveRegistryEntryID(spk, rv.Tweak)]
+	m.mu.Unlock()
+	if exists {
+		t.Fatal("failed subscription wasn't removed")
+	}
+}
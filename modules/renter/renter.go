@@ -225,6 +225,9 @@ type Renter struct {
 	// read registry stats
 	staticRRS *readRegistryStats
 
+	// registry subscriptions
+	staticRegistrySubscriptions *registrySubscriptionManager
+
 	// Memory management
 	//
 	// registryMemoryManager is used for updating registry entries and reading
@@ -929,6 +932,7 @@ func renterBlockingStartup(g modules.Gateway, cs modules.ConsensusSet, tpool mod
 	r.staticStreamBufferSet = newStreamBufferSet(&r.tg)
 	r.staticUploadChunkDistributionQueue = newUploadChunkDistributionQueue(r)
 	r.staticRRS = newReadRegistryStats(ReadRegistryBackgroundTimeout, readRegistryStatsInterval, readRegistryStatsDecay, readRegistryStatsPercentile)
+	r.staticRegistrySubscriptions = newRegistrySubscriptionManager(r)
 	close(r.uploadHeap.pauseChan)
 
 	// Seed the rrs.
@@ -990,6 +994,10 @@ func renterBlockingStartup(g modules.Gateway, cs modules.ConsensusSet, tpool mod
 	r.managedUpdateRenterContractsAndUtilities()
 	go r.threadedUpdateRenterContractsAndUtilities()
 
+	// Spin up the thread which keeps the registry subscriptions fanned out to
+	// usable workers.
+	go r.staticRegistrySubscriptions.threadedRefreshRegistrySubscriptions()
+
 	// Spin up background threads which are not depending on the renter being
 	// up-to-date with consensus.
 	if !r.deps.Disrupt("DisableRepairAndHealthLoops") {
@@ -0,0 +1,115 @@
+package renter
+
+// sharing.go allows for sharing files with other renters. Sharing a file
+// creates a share descriptor which contains the host keys and merkle roots of
+// the file's pieces as well as the erasure code and the key required to
+// decrypt them. The descriptor is encrypted with the file's sharing key.
+// Another renter can import the file using the descriptor and the sharing key
+// and download it from the hosts it has contracts with. Imported files have no
+// local copy, so the repair loop will move them to the importing renter's own
+// hosts if they are not healthy.
+
+import (
+	"fmt"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/modules/renter/filesystem"
+	"go.sia.tech/siad/modules/renter/filesystem/siafile"
+)
+
+// ImportSharedFile imports a file which was shared by another renter.
+func (r *Renter) ImportSharedFile(siaPath modules.SiaPath, descriptor, sharingKey []byte, force bool) (err error) {
+	if err := r.tg.Add(); err != nil {
+		return err
+	}
+	defer r.tg.Done()
+
+	// Decrypt the descriptor.
+	sk, err := crypto.NewSiaKey(siafile.SharingKeyType, sharingKey)
+	if err != nil {
+		return errors.AddContext(err, "invalid sharing key")
+	}
+	shared, err := siafile.DecryptShareDescriptor(descriptor, sk)
+	if err != nil {
+		return err
+	}
+	ec, err := shared.ErasureCode()
+	if err != nil {
+		return err
+	}
+	mk, err := shared.CipherKey()
+	if err != nil {
+		return err
+	}
+
+	// Delete existing file if overwrite flag is set. Ignore ErrUnknownPath.
+	if force {
+		err := r.DeleteFile(siaPath)
+		if err != nil && !errors.Contains(err, filesystem.ErrNotExist) {
+			return errors.AddContext(err, "unable to delete existing file")
+		}
+	}
+
+	// Create the SiaFile and add the shared pieces.
+	err = r.staticFileSystem.NewSiaFile(siaPath, "", ec, mk, shared.FileSize, shared.FileMode(), true)
+	if err != nil {
+		return errors.AddContext(err, "could not create a new sia file")
+	}
+	entry, err := r.staticFileSystem.OpenSiaFile(siaPath)
+	if err != nil {
+		return errors.AddContext(err, "could not open the new sia file")
+	}
+	defer func() {
+		err = errors.Compose(err, entry.Close())
+	}()
+	if uint64(len(shared.Chunks)) > entry.NumChunks() {
+		if err := entry.GrowNumChunks(uint64(len(shared.Chunks))); err != nil {
+			return errors.AddContext(err, "unable to grow sia file")
+		}
+	}
+	for chunkIndex, chunk := range shared.Chunks {
+		for _, piece := range chunk.Pieces {
+			err := entry.AddPiece(shared.HostKeys[piece.HostIndex], uint64(chunkIndex), uint64(piece.PieceIndex), piece.MerkleRoot)
+			if err != nil {
+				return errors.AddContext(err, fmt.Sprintf("unable to add piece to chunk %v", chunkIndex))
+			}
+		}
+	}
+
+	// Queue a bubble to update the health of the file's directory.
+	dirSiaPath, err := siaPath.Dir()
+	if err != nil {
+		return err
+	}
+	_ = r.staticBubbleScheduler.callQueueBubble(dirSiaPath)
+	return nil
+}
+
+// ShareFile creates an encrypted share descriptor for a file and returns it
+// together with the key to decrypt it.
+func (r *Renter) ShareFile(siaPath modules.SiaPath) (_, _ []byte, err error) {
+	if err := r.tg.Add(); err != nil {
+		return nil, nil, err
+	}
+	defer r.tg.Done()
+
+	entry, err := r.staticFileSystem.OpenSiaFile(siaPath)
+	if err != nil {
+		return nil, nil, errors.AddContext(err, "unable to open file")
+	}
+	defer func() {
+		err = errors.Compose(err, entry.Close())
+	}()
+	descriptor, err := entry.ShareDescriptor()
+	if err != nil {
+		return nil, nil, errors.AddContext(err, "unable to create share descriptor")
+	}
+	sk, err := entry.SharingKey()
+	if err != nil {
+		return nil, nil, errors.AddContext(err, "unable to get sharing key")
+	}
+	return descriptor, sk.Key(), nil
+}
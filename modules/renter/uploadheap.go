@@ -625,6 +625,15 @@ func (r *Renter) managedBuildUnfinishedChunk(entry *filesystem.FileNode, chunkIn
 // finish would then close the Entry and consequentially impact the remaining
 // chunks.
 func (r *Renter) managedBuildUnfinishedChunks(entry *filesystem.FileNode, hosts map[string]struct{}, target repairTarget, offline, goodForRenew map[string]bool, mm *memoryManager) []*unfinishedUploadChunk {
+	// Packed files are repaired through their pack. Instead of building chunks
+	// for them, they are synced with their pack.
+	if entry.PackSiaPath() != "" {
+		if err := r.managedSyncPackedFile(entry); err != nil {
+			r.log.Printf("WARN: unable to sync packed file %v with its pack: %v", entry.SiaFilePath(), err)
+		}
+		return nil
+	}
+
 	// If we don't have enough workers for the file, don't repair it right now.
 	minPieces := entry.ErasureCode().MinPieces()
 	r.staticWorkerPool.mu.RLock()
@@ -0,0 +1,441 @@
+package renter
+
+// uploadpacked.go implements packed uploads. Uploading a small file as a
+// regular siafile wastes most of a chunk on the hosts, since every chunk is
+// padded to its full size. Instead, multiple small files are packed into the
+// sectors of a single chunk which is uploaded as a regular siafile, the pack,
+// within the modules.PackFolder. Every packed file gets its own siafile which
+// shares the encryption key and the pieces of its pack and remembers the
+// offset of its data within the pack's chunk. That way packed files can be
+// downloaded and streamed like any other file.
+//
+// Packed files are not repaired on their own. Instead the pack is repaired like
+// any other siafile and the packed files copy the new pieces of their pack
+// whenever the repair code picks them up.
+//
+// Every pack keeps track of the number of files packed into it. Deleting a
+// packed file decrements that number and once the last file of a pack is
+// deleted, the pack is deleted as well.
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/modules/renter/filesystem"
+	"go.sia.tech/siad/persist"
+	"go.sia.tech/siad/types"
+)
+
+var (
+	// errNoPackedFiles is returned if a packed upload doesn't contain any
+	// files.
+	errNoPackedFiles = errors.New("no files to pack")
+
+	// errPackedFileChanged is returned if a file changes while it is being
+	// packed.
+	errPackedFileChanged = errors.New("file changed while being packed")
+)
+
+type (
+	// packedFile is a file which is uploaded as part of a pack.
+	packedFile struct {
+		staticSource  string
+		staticSiaPath modules.SiaPath
+		staticSize    uint64
+		staticMode    os.FileMode
+
+		// offset is the offset of the file's data within the pack's chunk.
+		offset uint64
+	}
+
+	// packReader is an io.Reader which reads the data of a pack from the
+	// files that are packed into it. Gaps between the files are filled with
+	// zeros.
+	packReader struct {
+		files  []*packedFile
+		offset uint64
+
+		// current is the file which is currently being read.
+		current *os.File
+	}
+)
+
+// newPackReader creates a new reader for a pack made up of the provided
+// files.
+func newPackReader(files []*packedFile) *packReader {
+	sorted := append([]*packedFile{}, files...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].offset < sorted[j].offset
+	})
+	return &packReader{
+		files: sorted,
+	}
+}
+
+// Close closes the file that is currently being read.
+func (pr *packReader) Close() error {
+	if pr.current == nil {
+		return nil
+	}
+	err := pr.current.Close()
+	pr.current = nil
+	return err
+}
+
+// Read implements the io.Reader interface.
+func (pr *packReader) Read(b []byte) (int, error) {
+	if len(pr.files) == 0 {
+		return 0, io.EOF
+	}
+	next := pr.files[0]
+
+	// If the next file doesn't start yet, fill the gap with zeros.
+	if pr.offset < next.offset {
+		n := next.offset - pr.offset
+		if n > uint64(len(b)) {
+			n = uint64(len(b))
+		}
+		for i := range b[:n] {
+			b[i] = 0
+		}
+		pr.offset += n
+		return int(n), nil
+	}
+
+	// Open the file if necessary.
+	if pr.current == nil {
+		f, err := os.Open(next.staticSource)
+		if err != nil {
+			return 0, errors.AddContext(err, "unable to open packed file")
+		}
+		pr.current = f
+	}
+
+	// Read the remaining data of the file.
+	remaining := next.offset + next.staticSize - pr.offset
+	if remaining < uint64(len(b)) {
+		b = b[:remaining]
+	}
+	n, err := pr.current.Read(b)
+	pr.offset += uint64(n)
+	if errors.Contains(err, io.EOF) && uint64(n) < remaining {
+		return n, errors.AddContext(errPackedFileChanged, next.staticSource)
+	} else if err != nil && !errors.Contains(err, io.EOF) {
+		return n, err
+	}
+
+	// If the file was read completely, move on to the next one.
+	if uint64(n) == remaining {
+		pr.files = pr.files[1:]
+		if err := pr.Close(); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// UploadPacked packs the provided files into as few chunks as possible and
+// uploads them. Every file still gets its own siafile at the provided siapath.
+func (r *Renter) UploadPacked(up modules.PackedUploadParams) error {
+	if err := r.tg.Add(); err != nil {
+		return err
+	}
+	defer r.tg.Done()
+
+	if len(up.Files) == 0 {
+		return errNoPackedFiles
+	}
+
+	// Fill in any missing upload params with sensible defaults.
+	if up.ErasureCode == nil {
+		up.ErasureCode = modules.NewRSSubCodeDefault()
+	}
+	var ct crypto.CipherType
+	if up.CipherType == ct {
+		up.CipherType = crypto.TypeDefaultRenter
+	}
+
+	// Check that we have contracts to upload to.
+	numContracts := len(r.hostContractor.Contracts())
+	requiredContracts := (up.ErasureCode.NumPieces() + up.ErasureCode.MinPieces()) / 2
+	if numContracts < requiredContracts && build.Release != "testing" {
+		return fmt.Errorf("not enough contracts to upload file: got %v, needed %v", numContracts, requiredContracts)
+	}
+
+	// Figure out how many sectors fit into a single pack.
+	chunkSize := (modules.SectorSize - up.CipherType.Overhead()) * uint64(up.ErasureCode.MinPieces())
+	sectorsPerPack := chunkSize / modules.SectorSize
+	if sectorsPerPack == 0 {
+		return errors.New("chunks of the provided erasure code are too small for packing")
+	}
+
+	// Check the files.
+	files := make([]*packedFile, 0, len(up.Files))
+	sizes := make(map[string]uint64)
+	siaPaths := make(map[modules.SiaPath]struct{})
+	for i, file := range up.Files {
+		if _, exists := siaPaths[file.SiaPath]; exists {
+			return fmt.Errorf("siapath %v was provided more than once", file.SiaPath)
+		}
+		siaPaths[file.SiaPath] = struct{}{}
+
+		fi, err := os.Stat(file.Source)
+		if err != nil {
+			return errors.AddContext(err, "unable to stat input file")
+		}
+		if fi.IsDir() {
+			return errors.AddContext(ErrUploadDirectory, file.Source)
+		}
+		if uint64(fi.Size()) > modules.SectorSize {
+			return errors.AddContext(modules.ErrSizeTooLarge, file.Source)
+		}
+		if !up.Force {
+			exists, err := r.staticFileSystem.FileExists(file.SiaPath)
+			if err != nil {
+				return errors.AddContext(err, "unable to check if file exists")
+			}
+			if exists {
+				return errors.AddContext(filesystem.ErrExists, file.SiaPath.String())
+			}
+		}
+		files = append(files, &packedFile{
+			staticSource:  file.Source,
+			staticSiaPath: file.SiaPath,
+			staticSize:    uint64(fi.Size()),
+			staticMode:    fi.Mode(),
+		})
+		// Zero-byte files don't need to be packed.
+		if fi.Size() > 0 {
+			sizes[strconv.Itoa(i)] = uint64(fi.Size())
+		}
+	}
+
+	// Pack the files into sectors and group the sectors into packs.
+	placements, numSectors, err := modules.PackFiles(sizes)
+	if err != nil {
+		return errors.AddContext(err, "unable to pack files")
+	}
+	packs := make([][]*packedFile, (numSectors+sectorsPerPack-1)/sectorsPerPack)
+	for _, placement := range placements {
+		i, err := strconv.Atoi(placement.FileID)
+		if err != nil {
+			return errors.AddContext(err, "invalid file id")
+		}
+		file := files[i]
+		file.offset = (placement.SectorIndex%sectorsPerPack)*modules.SectorSize + placement.SectorOffset
+		packIndex := placement.SectorIndex / sectorsPerPack
+		packs[packIndex] = append(packs[packIndex], file)
+	}
+
+	// Upload the packs.
+	for _, pack := range packs {
+		err := r.managedUploadPack(pack, up.ErasureCode, up.CipherType, up.Force)
+		if err != nil {
+			return errors.AddContext(err, "unable to upload pack")
+		}
+	}
+
+	// Create the zero-byte files.
+	dirs := make(map[modules.SiaPath]struct{})
+	for _, file := range files {
+		dirSiaPath, err := file.staticSiaPath.Dir()
+		if err != nil {
+			return err
+		}
+		dirs[dirSiaPath] = struct{}{}
+		if file.staticSize > 0 {
+			continue
+		}
+		err = r.managedNewPackedSiaFile(file, up.ErasureCode, crypto.GenerateSiaKey(up.CipherType), up.Force)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Bubble the directories of the packed files to update their health.
+	for dir := range dirs {
+		_ = r.staticBubbleScheduler.callQueueBubble(dir)
+	}
+	return nil
+}
+
+// managedNewPackedSiaFile creates the siafile for a packed file. If force is
+// true, an existing file at the same siapath will be replaced.
+func (r *Renter) managedNewPackedSiaFile(file *packedFile, ec modules.ErasureCoder, cipherKey crypto.CipherKey, force bool) error {
+	if force {
+		err := r.DeleteFile(file.staticSiaPath)
+		if err != nil && !errors.Contains(err, filesystem.ErrNotExist) {
+			return errors.AddContext(err, "unable to delete existing file")
+		}
+	}
+	err := r.staticFileSystem.NewSiaFile(file.staticSiaPath, file.staticSource, ec, cipherKey, file.staticSize, file.staticMode, true)
+	return errors.AddContext(err, "could not create a new sia file")
+}
+
+// managedUploadPack uploads a single pack made up of the provided files and
+// creates the siafiles of the files.
+func (r *Renter) managedUploadPack(files []*packedFile, ec modules.ErasureCoder, ct crypto.CipherType, force bool) (err error) {
+	packSiaPath, err := modules.PackFolder.Join(persist.UID())
+	if err != nil {
+		return err
+	}
+	pr := newPackReader(files)
+	defer func() {
+		err = errors.Compose(err, pr.Close())
+	}()
+	pack, err := r.callUploadStreamFromReader(modules.FileUploadParams{
+		SiaPath:     packSiaPath,
+		ErasureCode: ec,
+		CipherKey:   crypto.GenerateSiaKey(ct),
+
+		// A pack is a single chunk which is usually not full. The packed
+		// files reference the pieces of that chunk, so it must be uploaded
+		// as a regular chunk instead of being turned into a partial chunk.
+		DisablePartialChunk: true,
+	}, pr)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Compose(err, pack.Close())
+	}()
+	_ = r.staticBubbleScheduler.callQueueBubble(modules.PackFolder)
+
+	// Remember the number of files in the pack to know when it can be deleted.
+	err = pack.SetPackedFiles(uint64(len(files)))
+	if err != nil {
+		return errors.AddContext(err, "unable to set number of packed files")
+	}
+
+	// Create the siafiles of the packed files. They use the same key as the
+	// pack and start out with the pieces that are already uploaded.
+	pieces, err := pack.Pieces(0)
+	if err != nil {
+		return errors.AddContext(err, "unable to get pieces of pack")
+	}
+	for _, file := range files {
+		err := r.managedNewPackedSiaFile(file, pack.ErasureCode(), pack.MasterKey(), force)
+		if err != nil {
+			return err
+		}
+		entry, err := r.staticFileSystem.OpenSiaFile(file.staticSiaPath)
+		if err != nil {
+			return errors.AddContext(err, "could not open the new sia file")
+		}
+		err = entry.SetPacked(packSiaPath.String(), file.offset)
+		for pieceIndex, pieceSet := range pieces {
+			for _, piece := range pieceSet {
+				err = errors.Compose(err, entry.AddPiece(piece.HostPubKey, 0, uint64(pieceIndex), piece.MerkleRoot))
+			}
+		}
+		err = errors.Compose(err, entry.Close())
+		if err != nil {
+			return errors.AddContext(err, "unable to initialize packed file")
+		}
+	}
+	return nil
+}
+
+// managedPackSiaPath returns the siapath of the pack of the file at siaPath
+// and whether the file is packed at all.
+func (r *Renter) managedPackSiaPath(siaPath modules.SiaPath) (modules.SiaPath, bool) {
+	entry, err := r.staticFileSystem.OpenSiaFile(siaPath)
+	if err != nil {
+		return modules.SiaPath{}, false
+	}
+	pack := entry.PackSiaPath()
+	if err := entry.Close(); err != nil || pack == "" {
+		return modules.SiaPath{}, false
+	}
+	var packSiaPath modules.SiaPath
+	if err := packSiaPath.LoadString(pack); err != nil {
+		return modules.SiaPath{}, false
+	}
+	return packSiaPath, true
+}
+
+// managedReleasePack is called after a packed file was deleted. It decrements
+// the number of files in the file's pack and deletes the pack if it was the
+// last one.
+func (r *Renter) managedReleasePack(packSiaPath modules.SiaPath) error {
+	pack, err := r.staticFileSystem.OpenSiaFile(packSiaPath)
+	if errors.Contains(err, filesystem.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return errors.AddContext(err, "unable to open pack")
+	}
+	remaining, err := pack.ReleasePackedFile()
+	err = errors.Compose(err, pack.Close())
+	if err != nil {
+		return errors.AddContext(err, "unable to release packed file")
+	}
+	if remaining > 0 {
+		return nil
+	}
+	err = r.DeleteFile(packSiaPath)
+	if err != nil && !errors.Contains(err, filesystem.ErrNotExist) {
+		return errors.AddContext(err, "unable to delete pack")
+	}
+	return nil
+}
+
+// managedSyncPackedFile adds the pieces of a packed file's pack to the packed
+// file which it doesn't know about yet. It also copies the stuck status of the
+// pack.
+func (r *Renter) managedSyncPackedFile(entry *filesystem.FileNode) (err error) {
+	var packSiaPath modules.SiaPath
+	if err := packSiaPath.LoadString(entry.PackSiaPath()); err != nil {
+		return errors.AddContext(err, "invalid pack siapath")
+	}
+	pack, err := r.staticFileSystem.OpenSiaFile(packSiaPath)
+	if err != nil {
+		return errors.AddContext(err, "unable to open pack")
+	}
+	defer func() {
+		err = errors.Compose(err, pack.Close())
+	}()
+	packPieces, err := pack.Pieces(0)
+	if err != nil {
+		return errors.AddContext(err, "unable to get pieces of pack")
+	}
+	pieces, err := entry.Pieces(0)
+	if err != nil {
+		return errors.AddContext(err, "unable to get pieces of packed file")
+	}
+	for pieceIndex, pieceSet := range packPieces {
+		known := make(map[string]struct{})
+		if pieceIndex < len(pieces) {
+			for _, piece := range pieces[pieceIndex] {
+				known[pieceKey(piece.HostPubKey, piece.MerkleRoot)] = struct{}{}
+			}
+		}
+		for _, piece := range pieceSet {
+			if _, exists := known[pieceKey(piece.HostPubKey, piece.MerkleRoot)]; exists {
+				continue
+			}
+			err := entry.AddPiece(piece.HostPubKey, 0, uint64(pieceIndex), piece.MerkleRoot)
+			if err != nil {
+				return errors.AddContext(err, "unable to add piece to packed file")
+			}
+		}
+	}
+	stuck, err := pack.StuckChunkByIndex(0)
+	if err != nil {
+		return errors.AddContext(err, "unable to get stuck status of pack")
+	}
+	return entry.SetStuck(0, stuck)
+}
+
+// pieceKey returns a key which uniquely identifies a piece stored on a host.
+func pieceKey(hpk types.SiaPublicKey, root crypto.Hash) string {
+	return hpk.String() + root.String()
+}
@@ -0,0 +1,207 @@
+package renter
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/persist"
+	"go.sia.tech/siad/siatest/dependencies"
+	"go.sia.tech/siad/types"
+)
+
+// TestPackReader tests reading the data of a pack from its files.
+func TestPackReader(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	testDir := build.TempDir("renter", t.Name())
+	if err := os.MkdirAll(testDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	// Create some files and place them within the pack with gaps in between.
+	var files []*packedFile
+	var datas [][]byte
+	offset := uint64(10)
+	for i := 0; i < 3; i++ {
+		data := fastrand.Bytes(100 + fastrand.Intn(100))
+		path := filepath.Join(testDir, fmt.Sprint(i))
+		if err := ioutil.WriteFile(path, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, &packedFile{
+			staticSource: path,
+			staticSize:   uint64(len(data)),
+			offset:       offset,
+		})
+		datas = append(datas, data)
+		offset += uint64(len(data)) + uint64(fastrand.Intn(50))
+	}
+
+	// Build the expected pack. The files are passed to the reader in reverse
+	// order to make sure they are sorted by their offset.
+	expected := make([]byte, files[len(files)-1].offset+files[len(files)-1].staticSize)
+	reversed := make([]*packedFile, 0, len(files))
+	for i := len(files) - 1; i >= 0; i-- {
+		copy(expected[files[i].offset:], datas[i])
+		reversed = append(reversed, files[i])
+	}
+	pr := newPackReader(reversed)
+	data, err := ioutil.ReadAll(pr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, expected) {
+		t.Fatal("pack doesn't match the expected data")
+	}
+	if err := pr.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Truncate one of the files. Reading the pack should fail.
+	if err := os.Truncate(files[1].staticSource, 10); err != nil {
+		t.Fatal(err)
+	}
+	pr = newPackReader(files)
+	_, err = ioutil.ReadAll(pr)
+	if !errors.Contains(err, errPackedFileChanged) {
+		t.Fatal("expected errPackedFileChanged but got", err)
+	}
+	if err := pr.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestPackedFileLifecycle tests that packed files copy the pieces of their
+// pack during repair and that a pack is deleted together with its last file.
+func TestPackedFileLifecycle(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	rt, err := newRenterTesterWithDependency(t.Name(), &dependencies.DependencyDisableRepairAndHealthLoops{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := rt.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	r := rt.renter
+	rsc, _ := modules.NewRSCode(1, 1)
+
+	// Create a pack with 3 packed files, one of them in a directory.
+	packSiaPath, err := modules.PackFolder.Join(persist.UID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pack, err := r.createRenterTestFileWithParams(packSiaPath, rsc, crypto.TypeDefaultRenter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pack.SetPackedFiles(3); err != nil {
+		t.Fatal(err)
+	}
+	dirSiaPath := modules.RandomSiaPath()
+	fileSiaPaths := []modules.SiaPath{modules.RandomSiaPath(), modules.RandomSiaPath()}
+	nested, err := dirSiaPath.Join("file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileSiaPaths = append(fileSiaPaths, nested)
+	for i, siaPath := range fileSiaPaths {
+		entry, err := r.createRenterTestFileWithParams(siaPath, rsc, crypto.TypeDefaultRenter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = errors.Compose(entry.SetPacked(packSiaPath.String(), uint64(i*100)), entry.Close())
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Repair the pack and mark it as stuck. Syncing a packed file should copy
+	// the new pieces and the stuck status without duplicating known pieces.
+	for pieceIndex := uint64(0); pieceIndex < uint64(rsc.NumPieces()); pieceIndex++ {
+		hpk := types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: fastrand.Bytes(crypto.PublicKeySize)}
+		var root crypto.Hash
+		fastrand.Read(root[:])
+		if err := pack.AddPiece(hpk, 0, pieceIndex, root); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pack.SetStuck(0, true); err != nil {
+		t.Fatal(err)
+	}
+	entry, err := r.staticFileSystem.OpenSiaFile(fileSiaPaths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := r.managedSyncPackedFile(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	packPieces, err := pack.Pieces(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pieces, err := entry.Pieces(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(pieces, packPieces) {
+		t.Fatal("packed file doesn't have the pieces of its pack", pieces, packPieces)
+	}
+	if stuck, err := entry.StuckChunkByIndex(0); err != nil || !stuck {
+		t.Fatal("packed file should be stuck", stuck, err)
+	}
+	if err := errors.Compose(entry.Close(), pack.Close()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Deleting a packed file shouldn't delete the pack yet.
+	packExists := func() bool {
+		t.Helper()
+		exists, err := r.staticFileSystem.FileExists(packSiaPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return exists
+	}
+	if err := r.DeleteFile(fileSiaPaths[0]); err != nil {
+		t.Fatal(err)
+	}
+	if !packExists() {
+		t.Fatal("pack was deleted too early")
+	}
+	if err := r.DeleteDir(dirSiaPath); err != nil {
+		t.Fatal(err)
+	}
+	if !packExists() {
+		t.Fatal("pack was deleted too early")
+	}
+
+	// Deleting the last file should delete the pack.
+	if err := r.DeleteFile(fileSiaPaths[1]); err != nil {
+		t.Fatal(err)
+	}
+	if packExists() {
+		t.Fatal("pack wasn't deleted with its last file")
+	}
+}
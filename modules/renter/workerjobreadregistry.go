@@ -224,6 +224,14 @@ func (j *jobReadRegistry) callExpectedBandwidth() (ul, dl uint64) {
 	return readRegistryJobExpectedBandwidth()
 }
 
+// callExpectedJobTime returns the amount of time that a job is expected to
+// take, given the recent performance of the queue.
+func (jq *jobReadRegistryQueue) callExpectedJobTime() time.Duration {
+	jq.mu.Lock()
+	defer jq.mu.Unlock()
+	return time.Duration(jq.weightedJobTime)
+}
+
 // initJobReadRegistryQueue will init the queue for the ReadRegistry jobs.
 func (w *worker) initJobReadRegistryQueue() {
 	// Sanity check that there is no existing job queue.
@@ -203,17 +203,23 @@ func (nh *notificationHandler) managedHandleRegistryEntry(stream siamux.Stream,
 	// not seem bad, but the host might want to spam us with valid entries that
 	// we are not interested in simply to have us pay for bandwidth.
 	subInfo.mu.Lock()
-	defer subInfo.mu.Unlock()
 	sub, exists := subInfo.subscriptions[modules.DeriveRegistryEntryID(sneu.PubKey, sneu.Entry.Tweak)]
-	if !exists || (sub.latestRV != nil && sub.latestRV.Revision >= sneu.Entry.Revision) {
-		if exists && sub.latestRV != nil {
-			return fmt.Errorf("host sent an outdated revision %v >= %v", sub.latestRV.Revision, sneu.Entry.Revision)
-		}
+	if !exists {
+		subInfo.mu.Unlock()
 		return fmt.Errorf("subscription not found")
 	}
+	if sub.latestRV != nil && sub.latestRV.Revision >= sneu.Entry.Revision {
+		latestRevision := sub.latestRV.Revision
+		subInfo.mu.Unlock()
+		return fmt.Errorf("host sent an outdated revision %v >= %v", latestRevision, sneu.Entry.Revision)
+	}
 
 	// Update the subscription.
 	sub.latestRV = &sneu.Entry
+	subInfo.mu.Unlock()
+
+	// Forward the update to the renter's subscription manager.
+	w.renter.staticRegistrySubscriptions.managedNotify(sneu)
 	return nil
 }
 
@@ -515,7 +521,6 @@ func (w *worker) managedSubscribeToRVs(stream siamux.Stream, toSubscribe []modul
 	}
 	// Update the subscriptions with the received values.
 	subInfo.mu.Lock()
-	defer subInfo.mu.Unlock()
 	for _, rv := range rvs {
 		subInfo.subscriptions[modules.DeriveRegistryEntryID(rv.PubKey, rv.Entry.Tweak)].latestRV = &rv.Entry
 	}
@@ -523,6 +528,12 @@ func (w *worker) managedSubscribeToRVs(stream siamux.Stream, toSubscribe []modul
 	for _, c := range subChans {
 		close(c)
 	}
+	subInfo.mu.Unlock()
+
+	// Forward the initial values to the renter's subscription manager. This
+	// makes sure that updates which happened while the worker wasn't
+	// subscribed are not missed.
+	w.renter.staticRegistrySubscriptions.managedNotify(rvs...)
 	return nil
 }
 
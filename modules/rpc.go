@@ -171,6 +171,10 @@ type RPCPriceTable struct {
 	// Registry related fields.
 	RegistryEntriesLeft  uint64 `json:"registryentriesleft"`
 	RegistryEntriesTotal uint64 `json:"registryentriestotal"`
+
+	// Draining indicates that the host is draining for maintenance and
+	// doesn't accept new contracts or renewals.
+	Draining bool `json:"draining"`
 }
 
 var (
@@ -2,6 +2,7 @@ package modules
 
 import (
 	"errors"
+	"net/url"
 	"os"
 	"sync"
 
@@ -19,6 +20,10 @@ type (
 		WriteBPS           int64  `json:"writebps"`
 		PacketSize         uint64 `json:"packetsize"`
 
+		// AlertWebhookURLs are the urls which are notified about registered
+		// and unregistered alerts.
+		AlertWebhookURLs []string `json:"alertwebhooks"`
+
 		// path of config on disk.
 		path string
 		mu   sync.Mutex
@@ -59,6 +64,47 @@ func (cfg *SiadConfig) SetRatelimit(readBPS, writeBPS int64) error {
 	return cfg.save()
 }
 
+// AlertWebhooks returns the urls of the alert webhooks.
+func (cfg *SiadConfig) AlertWebhooks() []string {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	return append([]string{}, cfg.AlertWebhookURLs...)
+}
+
+// AddAlertWebhook adds a webhook which is notified about alerts and persists
+// the config to disk.
+func (cfg *SiadConfig) AddAlertWebhook(webhook string) error {
+	u, err := url.Parse(webhook)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("webhook needs to be an absolute http or https url")
+	}
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	for _, wh := range cfg.AlertWebhookURLs {
+		if wh == webhook {
+			return errors.New("webhook already exists")
+		}
+	}
+	cfg.AlertWebhookURLs = append(cfg.AlertWebhookURLs, webhook)
+	return cfg.save()
+}
+
+// RemoveAlertWebhook removes an alert webhook and persists the config to disk.
+func (cfg *SiadConfig) RemoveAlertWebhook(webhook string) error {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	for i, wh := range cfg.AlertWebhookURLs {
+		if wh == webhook {
+			cfg.AlertWebhookURLs = append(cfg.AlertWebhookURLs[:i], cfg.AlertWebhookURLs[i+1:]...)
+			return cfg.save()
+		}
+	}
+	return errors.New("webhook doesn't exist")
+}
+
 // save saves the config to disk.
 func (cfg *SiadConfig) save() error {
 	return persist.SaveJSON(configMetadata, cfg, cfg.path)
@@ -49,6 +49,10 @@ var (
 	// siafiles are stored by default.
 	BackupFolder = NewGlobalSiaPath("/snapshots")
 
+	// PackFolder is the Sia folder where all of the renter's packs of small
+	// files are stored.
+	PackFolder = NewGlobalSiaPath("/packs")
+
 	// HomeFolder is the Sia folder that is used to store all of the user
 	// accessible data.
 	HomeFolder = NewGlobalSiaPath("/home")
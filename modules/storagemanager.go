@@ -1,7 +1,10 @@
 package modules
 
 import (
+	"time"
+
 	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/types"
 )
 
 const (
@@ -42,6 +45,65 @@ type (
 		ProgressDenominator uint64
 	}
 
+	// StorageFolderScrubStatus contains the progress of the sector scrubber on
+	// a storage folder. ScrubbedSectors and TotalSectors are only set while
+	// the folder is being scrubbed. CorruptSectors is the number of sectors of
+	// the folder whose data didn't match their root when they were last
+	// scrubbed.
+	StorageFolderScrubStatus struct {
+		Index           uint16    `json:"index"`
+		Path            string    `json:"path"`
+		Scrubbing       bool      `json:"scrubbing"`
+		ScrubbedSectors uint64    `json:"scrubbedsectors"`
+		TotalSectors    uint64    `json:"totalsectors"`
+		CorruptSectors  uint64    `json:"corruptsectors"`
+		LastScrub       time.Time `json:"lastscrub"`
+	}
+
+	// StorageScrubStatus contains the settings of the sector scrubber and its
+	// progress on each storage folder. The scrubber periodically re-reads the
+	// sectors of the storage folders to detect data which was corrupted on
+	// disk. RateLimit is the maximum number of bytes per second read by the
+	// scrubber.
+	StorageScrubStatus struct {
+		Enabled   bool                       `json:"enabled"`
+		RateLimit uint64                     `json:"ratelimit"`
+		Folders   []StorageFolderScrubStatus `json:"folders"`
+	}
+
+	// StorageFolderMigration is a queued migration of sectors from one
+	// storage folder to another. Sectors is the number of sectors which remain
+	// to be migrated, unless All is set, in which case the migration continues
+	// until the source folder is empty. Rebalance indicates that the migration
+	// was queued by the rebalance policy rather than by the user.
+	StorageFolderMigration struct {
+		From      uint16 `json:"from"`
+		To        uint16 `json:"to"`
+		All       bool   `json:"all"`
+		Sectors   uint64 `json:"sectors"`
+		Migrated  uint64 `json:"migrated"`
+		Rebalance bool   `json:"rebalance"`
+	}
+
+	// StorageMigrationStatus contains the settings of the sector migrator and
+	// the queued migrations, the first of which is the one in progress. If
+	// Rebalance is set, sectors are migrated automatically to even out the
+	// utilization of the storage folders. RateLimit is the maximum number of
+	// bytes per second moved by the migrator.
+	StorageMigrationStatus struct {
+		Rebalance  bool                     `json:"rebalance"`
+		RateLimit  uint64                   `json:"ratelimit"`
+		Migrations []StorageFolderMigration `json:"migrations"`
+	}
+
+	// CorruptSector is a sector which the scrubber found to be corrupted on
+	// disk, together with the storage obligations that contain it.
+	CorruptSector struct {
+		Root          crypto.Hash            `json:"root"`
+		StorageFolder uint16                 `json:"storagefolder"`
+		Obligations   []types.FileContractID `json:"obligations"`
+	}
+
 	// A StorageManager is responsible for managing storage folders and
 	// sectors. Sectors are the base unit of storage that gets moved between
 	// renters and hosts, and primarily is stored on the hosts.
@@ -62,6 +124,11 @@ type (
 		// a given root or not.
 		HasSector(crypto.Hash) bool
 
+		// FilterCorruptSectors returns the roots of the input which belong to
+		// sectors that the scrubber found to be corrupted on disk, mapped to
+		// the index of the storage folder of the sector.
+		FilterCorruptSectors(sectorRoots []crypto.Hash) map[crypto.Hash]uint16
+
 		// AddSectorBatch is a performance optimization over AddSector when
 		// adding a bunch of virtual sectors. It is necessary because otherwise
 		// potentially thousands or even tens-of-thousands of fsync calls would
@@ -75,6 +142,10 @@ type (
 		// gracefully handle running out of storage unexpectedly.
 		AddStorageFolder(path string, size uint64) error
 
+		// CancelSectorMigrations cancels all queued sector migrations. Sectors
+		// which were already migrated stay in their new storage folder.
+		CancelSectorMigrations() error
+
 		// The storage manager needs to be able to shut down.
 		Close() error
 
@@ -86,6 +157,15 @@ type (
 		// requests to remove data.
 		DeleteSector(sectorRoot crypto.Hash) error
 
+		// MigrateSectors queues a migration of sectors from one storage folder
+		// to another, which is performed in the background. If numSectors is
+		// 0, all sectors of the source folder are migrated.
+		MigrateSectors(from, to uint16, numSectors uint64) error
+
+		// MigrationStatus returns the settings of the sector migrator and the
+		// queued migrations.
+		MigrationStatus() StorageMigrationStatus
+
 		// ReadSector will read a sector from the storage manager, returning the
 		// bytes that match the input sector root.
 		ReadSector(sectorRoot crypto.Hash) ([]byte, error)
@@ -128,6 +208,23 @@ type (
 		// that data will be lost.
 		ResizeStorageFolder(index uint16, newSize uint64, force bool) error
 
+		// ScrubStatus returns the settings and the progress of the sector
+		// scrubber.
+		ScrubStatus() StorageScrubStatus
+
+		// SetMigrationSettings enables or disables the automatic rebalancing
+		// of the storage folders and sets the maximum number of bytes per
+		// second moved by the sector migrator.
+		SetMigrationSettings(rebalance bool, rateLimit uint64) error
+
+		// SetScrubSettings enables or disables the sector scrubber and sets
+		// the maximum number of bytes per second it reads from disk.
+		SetScrubSettings(enabled bool, rateLimit uint64) error
+
+		// StartScrub starts scrubbing all storage folders immediately instead
+		// of waiting for their next scheduled scrub.
+		StartScrub() error
+
 		// StorageFolders will return a list of storage folders tracked by the
 		// manager.
 		StorageFolders() []StorageFolderMetadata
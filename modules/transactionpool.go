@@ -130,6 +130,11 @@ type (
 		// that make this condition necessary.
 		PurgeTransactionPool()
 
+		// ReplaceTransactionSet replaces the transaction set with the provided
+		// id with a set which spends the same outputs and pays a higher fee
+		// per byte, e.g. to bump the fee of a stuck set.
+		ReplaceTransactionSet(id TransactionSetID, replacement []types.Transaction) error
+
 		// Transaction returns the transaction and unconfirmed parents
 		// corresponding to the provided transaction id.
 		Transaction(id types.TransactionID) (txn types.Transaction, unconfirmedParents []types.Transaction, exists bool)
@@ -0,0 +1,180 @@
+package transactionpool
+
+import (
+	"gitlab.com/NebulousLabs/encoding"
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+	"go.sia.tech/siad/types/typesutil"
+)
+
+var (
+	// errLowReplacementFees is returned if a replacement set doesn't pay a
+	// higher fee per byte than the set it replaces.
+	errLowReplacementFees = errors.New("replacement set needs to pay a higher fee per byte than the replaced set")
+
+	// errReplacementInputs is returned if a replacement set doesn't spend all
+	// the outputs spent by the set it replaces.
+	errReplacementInputs = errors.New("replacement set needs to spend the inputs of the replaced set")
+
+	// errUnknownTransactionSet is returned if a set which should be replaced
+	// is not in the transaction pool.
+	errUnknownTransactionSet = errors.New("transaction set is not in the transaction pool")
+)
+
+// removedTransactionSet contains the state of a transaction set which was
+// removed from the transaction pool so that it can be restored.
+type removedTransactionSet struct {
+	id      modules.TransactionSetID
+	set     []types.Transaction
+	diff    *modules.ConsensusChange
+	objects []ObjectID
+}
+
+// transactionSetFees returns the sum of the miner fees of a transaction set.
+func transactionSetFees(ts []types.Transaction) (fees types.Currency) {
+	for _, txn := range ts {
+		for _, fee := range txn.MinerFees {
+			fees = fees.Add(fee)
+		}
+	}
+	return fees
+}
+
+// higherFeeRate returns whether the replacement pays a higher fee per byte
+// than the set.
+func higherFeeRate(replacement, set []types.Transaction) bool {
+	newFees := transactionSetFees(replacement).Mul64(uint64(len(encoding.Marshal(set))))
+	oldFees := transactionSetFees(set).Mul64(uint64(len(encoding.Marshal(replacement))))
+	return newFees.Cmp(oldFees) > 0
+}
+
+// spendsInputs returns whether the replacement spends all the outputs which
+// the set spends but doesn't create itself. This ensures that the replacement
+// conflicts with every transaction of the set instead of replacing unrelated
+// transactions.
+func spendsInputs(replacement, set []types.Transaction) bool {
+	created := make(map[ObjectID]struct{})
+	for _, txn := range set {
+		for i := range txn.SiacoinOutputs {
+			created[ObjectID(txn.SiacoinOutputID(uint64(i)))] = struct{}{}
+		}
+		for i := range txn.SiafundOutputs {
+			created[ObjectID(txn.SiafundOutputID(uint64(i)))] = struct{}{}
+		}
+	}
+	spent := make(map[ObjectID]struct{})
+	for _, txn := range replacement {
+		for _, sci := range txn.SiacoinInputs {
+			spent[ObjectID(sci.ParentID)] = struct{}{}
+		}
+		for _, sfi := range txn.SiafundInputs {
+			spent[ObjectID(sfi.ParentID)] = struct{}{}
+		}
+	}
+	covered := func(oid ObjectID) bool {
+		_, isCreated := created[oid]
+		_, isSpent := spent[oid]
+		return isCreated || isSpent
+	}
+	for _, txn := range set {
+		for _, sci := range txn.SiacoinInputs {
+			if !covered(ObjectID(sci.ParentID)) {
+				return false
+			}
+		}
+		for _, sfi := range txn.SiafundInputs {
+			if !covered(ObjectID(sfi.ParentID)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// removeTransactionSet removes a transaction set from the pool and returns
+// its state.
+func (tp *TransactionPool) removeTransactionSet(id modules.TransactionSetID) removedTransactionSet {
+	rts := removedTransactionSet{
+		id:   id,
+		set:  tp.transactionSets[id],
+		diff: tp.transactionSetDiffs[id],
+	}
+	for oid, setID := range tp.knownObjects {
+		if setID == id {
+			rts.objects = append(rts.objects, oid)
+			delete(tp.knownObjects, oid)
+		}
+	}
+	tp.transactionListSize -= len(encoding.Marshal(rts.set))
+	delete(tp.transactionSets, id)
+	delete(tp.transactionSetDiffs, id)
+	return rts
+}
+
+// restoreTransactionSet adds a removed transaction set back to the pool.
+func (tp *TransactionPool) restoreTransactionSet(rts removedTransactionSet) {
+	for _, oid := range rts.objects {
+		tp.knownObjects[oid] = rts.id
+	}
+	tp.transactionListSize += len(encoding.Marshal(rts.set))
+	tp.transactionSets[rts.id] = rts.set
+	tp.transactionSetDiffs[rts.id] = rts.diff
+}
+
+// ReplaceTransactionSet removes the transaction set with the provided id from
+// the pool and accepts the replacement in its place. The replacement has to
+// spend the inputs of the replaced set and pay a higher fee per byte. If it is not accepted, the
+// original set stays in the pool. The replacement is relayed to connected
+// peers.
+func (tp *TransactionPool) ReplaceTransactionSet(id modules.TransactionSetID, replacement []types.Transaction) error {
+	if err := tp.tg.Add(); err != nil {
+		return err
+	}
+	defer tp.tg.Done()
+
+	// assert on consensus set to get special method
+	cs, ok := tp.consensusSet.(interface {
+		LockedTryTransactionSet(fn func(func(txns []types.Transaction) (modules.ConsensusChange, error)) error) error
+	})
+	if !ok {
+		return errors.New("consensus set does not support LockedTryTransactionSet method")
+	}
+
+	var superset []types.Transaction
+	err := cs.LockedTryTransactionSet(func(txnFn func(txns []types.Transaction) (modules.ConsensusChange, error)) error {
+		tp.mu.Lock()
+		defer tp.mu.Unlock()
+
+		set, exists := tp.transactionSets[id]
+		if !exists {
+			return errUnknownTransactionSet
+		}
+		if !spendsInputs(replacement, set) {
+			return errReplacementInputs
+		}
+		if !higherFeeRate(replacement, set) {
+			return errLowReplacementFees
+		}
+
+		// Remove the set before accepting the replacement since they spend the
+		// same outputs.
+		rts := tp.removeTransactionSet(id)
+		var err error
+		superset, err = tp.acceptTransactionSet(replacement, txnFn)
+		if err != nil {
+			tp.restoreTransactionSet(rts)
+			tp.log.Debugln("Replacement transaction set was rejected:", err)
+			return err
+		}
+		tp.updateSubscribersTransactions()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	minSuperSet := typesutil.MinimumTransactionSet(replacement, superset)
+	go tp.gateway.Broadcast("RelayTransactionSet", minSuperSet, tp.gateway.Peers())
+	return nil
+}
@@ -0,0 +1,96 @@
+package transactionpool
+
+import (
+	"testing"
+
+	"gitlab.com/NebulousLabs/encoding"
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// TestReplaceTransactionSet probes the error paths of ReplaceTransactionSet.
+// Successful replacements are tested by the wallet's fee bumping.
+func TestReplaceTransactionSet(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	tpt, err := createTpoolTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := tpt.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	txns, err := tpt.wallet.SendSiacoins(types.NewCurrency64(100), types.UnlockHash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tpt.tpool.transactionSets) != 1 {
+		t.Fatal("expected one transaction set", len(tpt.tpool.transactionSets))
+	}
+	var setID modules.TransactionSetID
+	for id := range tpt.tpool.transactionSets {
+		setID = id
+	}
+	numObjects := len(tpt.tpool.knownObjects)
+	listSize := tpt.tpool.transactionListSize
+
+	// Unknown sets can't be replaced.
+	err = tpt.tpool.ReplaceTransactionSet(modules.TransactionSetID{}, txns)
+	if !errors.Contains(err, errUnknownTransactionSet) {
+		t.Fatal("expected errUnknownTransactionSet, got", err)
+	}
+
+	// The replacement needs to pay higher fees.
+	err = tpt.tpool.ReplaceTransactionSet(setID, txns)
+	if !errors.Contains(err, errLowReplacementFees) {
+		t.Fatal("expected errLowReplacementFees, got", err)
+	}
+
+	// The replacement needs to spend the inputs of the replaced set.
+	var replacement []types.Transaction
+	if err := encoding.Unmarshal(encoding.Marshal(txns), &replacement); err != nil {
+		t.Fatal(err)
+	}
+	replacement[0].SiacoinInputs[0].ParentID = types.SiacoinOutputID{1}
+	replacement[0].MinerFees = append(replacement[0].MinerFees, types.SiacoinPrecision)
+	err = tpt.tpool.ReplaceTransactionSet(setID, replacement)
+	if !errors.Contains(err, errReplacementInputs) {
+		t.Fatal("expected errReplacementInputs, got", err)
+	}
+
+	// Paying more fees isn't enough if the fee per byte is lower.
+	if err := encoding.Unmarshal(encoding.Marshal(txns), &replacement); err != nil {
+		t.Fatal(err)
+	}
+	last := &replacement[len(replacement)-1]
+	last.MinerFees[0] = last.MinerFees[0].Add(types.NewCurrency64(1))
+	last.ArbitraryData = append(last.ArbitraryData, make([]byte, 10e3))
+	err = tpt.tpool.ReplaceTransactionSet(setID, replacement)
+	if !errors.Contains(err, errLowReplacementFees) {
+		t.Fatal("expected errLowReplacementFees, got", err)
+	}
+
+	// An invalid replacement should be rejected and the original set should
+	// be restored.
+	var invalid []types.Transaction
+	if err := encoding.Unmarshal(encoding.Marshal(txns), &invalid); err != nil {
+		t.Fatal(err)
+	}
+	last = &invalid[len(invalid)-1]
+	last.MinerFees[0] = last.MinerFees[0].Add(types.SiacoinPrecision)
+	if err := tpt.tpool.ReplaceTransactionSet(setID, invalid); err == nil {
+		t.Fatal("invalid replacement was accepted")
+	}
+	if _, exists := tpt.tpool.transactionSets[setID]; !exists || len(tpt.tpool.transactionSets) != 1 {
+		t.Fatal("original set wasn't restored")
+	}
+	if len(tpt.tpool.knownObjects) != numObjects || tpt.tpool.transactionListSize != listSize {
+		t.Fatal("state of the original set wasn't restored")
+	}
+}
@@ -27,6 +27,36 @@ const (
 	WalletDir = "wallet"
 )
 
+const (
+	// CoinSelectionLargestFirst funds a transaction with the largest outputs
+	// of the wallet first. This is the default strategy.
+	CoinSelectionLargestFirst CoinSelectionStrategy = "largest-first"
+
+	// CoinSelectionSmallestFirst funds a transaction with the smallest outputs
+	// of the wallet first which consolidates small outputs.
+	CoinSelectionSmallestFirst CoinSelectionStrategy = "smallest-first"
+
+	// CoinSelectionMinimizeChange funds a transaction with the smallest
+	// single output which covers the amount. If there is no such output it
+	// falls back to CoinSelectionLargestFirst.
+	CoinSelectionMinimizeChange CoinSelectionStrategy = "minimize-change"
+
+	// CoinSelectionPrivacy funds a transaction with outputs of as few
+	// addresses as possible to avoid linking addresses of the wallet.
+	CoinSelectionPrivacy CoinSelectionStrategy = "privacy"
+)
+
+const (
+	// FeeBumpCPFP bumps the fee of a transaction by adding a child
+	// transaction which spends the change output of the transaction and pays
+	// a higher fee.
+	FeeBumpCPFP FeeBumpMethod = "cpfp"
+
+	// FeeBumpReplace bumps the fee of a transaction by replacing its set with
+	// a set which spends the same inputs and pays a higher fee.
+	FeeBumpReplace FeeBumpMethod = "replace"
+)
+
 var (
 	// ErrBadEncryptionKey is returned if the incorrect encryption key to a
 	// file is provided.
@@ -46,9 +76,21 @@ var (
 	// complete the desired action.
 	ErrLowBalance = errors.New("insufficient balance")
 
+	// ErrUnknownSigningKey is returned if a transaction signature references a
+	// public key which doesn't belong to the wallet.
+	ErrUnknownSigningKey = errors.New("could not locate signing key")
+
 	// ErrWalletShutdown is returned when a method can't continue execution due
 	// to the wallet shutting down.
 	ErrWalletShutdown = errors.New("wallet is shutting down")
+
+	// ErrUnknownScheduledPayment is returned if a scheduled payment with the
+	// provided id doesn't exist.
+	ErrUnknownScheduledPayment = errors.New("scheduled payment not found")
+
+	// ErrUnknownCoinSelectionStrategy is returned if a CoinControl specifies
+	// an unknown coin selection strategy.
+	ErrUnknownCoinSelectionStrategy = errors.New("unknown coin selection strategy")
 )
 
 type (
@@ -59,6 +101,97 @@ type (
 	// WalletTransactionID is a unique identifier for a wallet transaction.
 	WalletTransactionID crypto.Hash
 
+	// CoinSelectionStrategy determines the order in which the wallet selects
+	// siacoin outputs to fund a transaction.
+	CoinSelectionStrategy string
+
+	// FeeBumpMethod is the method used to bump the fee of an unconfirmed
+	// transaction.
+	FeeBumpMethod string
+
+	// ScheduledPaymentID is a unique identifier for a scheduled payment.
+	ScheduledPaymentID string
+
+	// ScheduledPayment is a payment of siacoins which the wallet sends once it
+	// reaches a target block height. Recurring payments are sent again every
+	// Interval blocks.
+	ScheduledPayment struct {
+		ID          ScheduledPaymentID `json:"id"`
+		Amount      types.Currency     `json:"amount"`
+		Destination types.UnlockHash   `json:"destination"`
+
+		// NextHeight is the height at which the next payment is due.
+		NextHeight types.BlockHeight `json:"nextheight"`
+
+		// Interval is the number of blocks between two payments of a
+		// recurring payment. It is 0 for one-time payments.
+		Interval types.BlockHeight `json:"interval"`
+
+		// Payments is the number of payments which were sent so far and
+		// LastTransactionID is the id of the transaction of the last payment.
+		// A one-time payment is completed after it was sent.
+		Payments          uint64              `json:"payments"`
+		LastTransactionID types.TransactionID `json:"lasttransactionid"`
+		Completed         bool                `json:"completed"`
+
+		// LastError is the error of the last failed attempt to send the
+		// payment. It is reset once the payment succeeds.
+		LastError string `json:"lasterror"`
+	}
+
+	// CoinControl restricts which siacoin outputs the wallet uses to fund a
+	// transaction. The zero value lets the wallet select outputs freely.
+	CoinControl struct {
+		// Include contains outputs which have to be spent. If it is not
+		// empty, only these outputs are spent and funding fails if they
+		// don't cover the amount.
+		Include []types.SiacoinOutputID `json:"include"`
+
+		// Exclude contains outputs which must not be spent.
+		Exclude []types.SiacoinOutputID `json:"exclude"`
+
+		// Strategy is the strategy used to select outputs. It defaults to
+		// CoinSelectionLargestFirst.
+		Strategy CoinSelectionStrategy `json:"strategy"`
+
+		// ChangeAddress is the address that receives the change. If it is
+		// not set, a new address of the wallet is used.
+		ChangeAddress types.UnlockHash `json:"changeaddress"`
+
+		// Account is the name of the wallet account whose outputs are spent.
+		// The change is sent to a new address of the account. If it is empty,
+		// only outputs which don't belong to an account are spent.
+		Account string `json:"account"`
+	}
+
+	// WalletAccount is a named set of wallet addresses with a separate
+	// balance. Outputs of an account are only spent if the account is
+	// selected when funding a transaction.
+	WalletAccount struct {
+		Name           string            `json:"name"`
+		CreationHeight types.BlockHeight `json:"creationheight"`
+		NumAddresses   uint64            `json:"numaddresses"`
+
+		ConfirmedSiacoinBalance types.Currency `json:"confirmedsiacoinbalance"`
+		ConfirmedSiafundBalance types.Currency `json:"confirmedsiafundbalance"`
+	}
+
+	// AddressLabel is a name which the user gave to an address. Both
+	// addresses of the wallet and addresses of counterparties can be
+	// labelled.
+	AddressLabel struct {
+		Address types.UnlockHash `json:"address"`
+		Name    string           `json:"name"`
+	}
+
+	// TransactionLabel is a free-text note and a set of tags which the user
+	// attached to a transaction.
+	TransactionLabel struct {
+		TransactionID types.TransactionID `json:"transactionid"`
+		Note          string              `json:"note"`
+		Tags          []string            `json:"tags"`
+	}
+
 	// A ProcessedInput represents funding to a transaction. The input is
 	// coming from an address and going to the outputs. The fund types are
 	// 'SiacoinInput', 'SiafundInput'.
@@ -148,6 +281,10 @@ type (
 		// transaction failed.
 		FundSiacoins(amount types.Currency) error
 
+		// FundSiacoinsCoinControl is like FundSiacoins but only selects
+		// siacoin outputs which are allowed by the provided CoinControl.
+		FundSiacoinsCoinControl(amount types.Currency, cc CoinControl) error
+
 		// FundSiafunds will add a siafund input of exactly 'amount' to the
 		// transaction. A parent transaction may be needed to achieve an input
 		// with the correct value. The siafund input will not be signed until
@@ -423,6 +560,14 @@ type (
 		EncryptionManager
 		KeyManager
 
+		// AddMultisigAddress creates the unlock conditions of an M-of-N
+		// multisig address from a set of public keys, at least one of which
+		// has to belong to the wallet. The unlock conditions are added to the
+		// wallet database and the address is tracked separately from the
+		// wallet's balance. If the address hasn't appeared in the blockchain,
+		// the unused flag may be set to true to avoid a rescan.
+		AddMultisigAddress(publicKeys []types.SiaPublicKey, signaturesRequired uint64, unused bool) (types.UnlockConditions, error)
+
 		// AddUnlockConditions adds a set of UnlockConditions to the wallet database.
 		AddUnlockConditions(uc types.UnlockConditions) error
 
@@ -451,6 +596,59 @@ type (
 		// Height returns the wallet's internal processed consensus height
 		Height() (types.BlockHeight, error)
 
+		// AccountAddress returns a new address of the primary seed which is
+		// assigned to the account.
+		AccountAddress(name string) (types.UnlockConditions, error)
+
+		// AccountAddresses returns the addresses assigned to the account.
+		AccountAddresses(name string) ([]types.UnlockHash, error)
+
+		// Accounts returns the accounts of the wallet and their balances.
+		Accounts() ([]WalletAccount, error)
+
+		// AddAccount creates a new account without addresses.
+		AddAccount(name string) error
+
+		// AssignAccountAddresses assigns addresses of the wallet to the
+		// account. An empty name removes the addresses from their accounts.
+		AssignAccountAddresses(name string, addrs []types.UnlockHash) error
+
+		// AssignAccountSeed assigns the addresses of a loaded auxiliary seed
+		// to the account.
+		AssignAccountSeed(name string, seed Seed) error
+
+		// RemoveAccount deletes an account. Its addresses are no longer
+		// assigned to an account.
+		RemoveAccount(name string) error
+
+		// AddressLabels returns the labels of all labelled addresses.
+		AddressLabels() ([]AddressLabel, error)
+
+		// SetAddressLabel sets the name of an address. An empty name removes
+		// the label.
+		SetAddressLabel(addr types.UnlockHash, name string) error
+
+		// SetTransactionLabel sets the note and the tags of a transaction. A
+		// label without note and tags removes the label.
+		SetTransactionLabel(label TransactionLabel) error
+
+		// TransactionLabels returns the labels of all labelled transactions.
+		TransactionLabels() ([]TransactionLabel, error)
+
+		// AddExchangeRates stores historical exchange rate points. A point
+		// replaces an existing point of the same currency and timestamp.
+		AddExchangeRates(points []ExchangeRatePoint) error
+
+		// ExchangeRates returns the stored exchange rate points of the
+		// currency with the given symbol sorted by timestamp. If symbol is
+		// empty, the points of all currencies are returned.
+		ExchangeRates(symbol string) ([]ExchangeRatePoint, error)
+
+		// FiatReport values the transactions confirmed between start and end
+		// using the stored exchange rates of the currency with the given
+		// symbol and summarizes them by period.
+		FiatReport(symbol string, start, end types.Timestamp, period ReportPeriod) (FiatReport, error)
+
 		// AddressTransactions returns all of the transactions that are related
 		// to a given address.
 		AddressTransactions(types.UnlockHash) ([]ProcessedTransaction, error)
@@ -498,6 +696,32 @@ type (
 		// RegisterTransaction(types.Transaction{}, nil)
 		StartTransaction() (TransactionBuilder, error)
 
+		// BumpTransaction raises the fee of the unconfirmed transaction set
+		// containing the transaction to 'feePerByte'. If no method is
+		// provided, FeeBumpCPFP is used if the set has a change output and
+		// FeeBumpReplace otherwise. The new transactions and the used method
+		// are returned.
+		BumpTransaction(txid types.TransactionID, method FeeBumpMethod, feePerByte types.Currency) ([]types.Transaction, FeeBumpMethod, error)
+
+		// AddScheduledPayment schedules a payment of Amount to Destination at
+		// NextHeight, repeating every Interval blocks if Interval is not 0. The
+		// payment is retried on every block until it succeeds. The scheduled
+		// payment with its new id is returned.
+		AddScheduledPayment(sp ScheduledPayment) (ScheduledPayment, error)
+
+		// RemoveScheduledPayment cancels and removes a scheduled payment.
+		RemoveScheduledPayment(id ScheduledPaymentID) error
+
+		// ScheduledPayment returns the scheduled payment with the given id.
+		ScheduledPayment(id ScheduledPaymentID) (ScheduledPayment, error)
+
+		// ScheduledPayments returns all scheduled payments.
+		ScheduledPayments() ([]ScheduledPayment, error)
+
+		// UpdateScheduledPayment replaces the amount, destination, next
+		// height and interval of an existing scheduled payment.
+		UpdateScheduledPayment(sp ScheduledPayment) (ScheduledPayment, error)
+
 		// SendSiacoins is a tool for sending siacoins from the wallet to an
 		// address. Sending money usually results in multiple transactions. The
 		// transactions are automatically given to the transaction pool, and are
@@ -507,8 +731,17 @@ type (
 		// SendSiacoinsFeeIncluded sends siacoins with fees included.
 		SendSiacoinsFeeIncluded(amount types.Currency, dest types.UnlockHash) ([]types.Transaction, error)
 
+		// SendSiacoinsCoinControl is like SendSiacoins, or
+		// SendSiacoinsFeeIncluded if feeIncluded is set, but only spends the
+		// outputs allowed by the provided CoinControl.
+		SendSiacoinsCoinControl(amount types.Currency, dest types.UnlockHash, feeIncluded bool, cc CoinControl) ([]types.Transaction, error)
+
 		SiacoinSenderMulti
 
+		// SendSiacoinsMultiCoinControl is like SendSiacoinsMulti but only
+		// spends the outputs allowed by the provided CoinControl.
+		SendSiacoinsMultiCoinControl(outputs []types.SiacoinOutput, cc CoinControl) ([]types.Transaction, error)
+
 		// SendSiafunds is a tool for sending siafunds from the wallet to an
 		// address. Sending money usually results in multiple transactions. The
 		// transactions are automatically given to the transaction pool, and
@@ -526,9 +759,47 @@ type (
 		// address, if they are known to the wallet.
 		UnlockConditions(addr types.UnlockHash) (types.UnlockConditions, error)
 
+		// MultisigAddresses returns the unlock conditions of the multisig
+		// addresses which contain a key of the wallet.
+		MultisigAddresses() ([]types.UnlockConditions, error)
+
+		// MultisigBalance returns the confirmed siacoin and siafund balance of
+		// the wallet's multisig addresses, which is not part of the confirmed
+		// balance.
+		MultisigBalance() (siacoinBalance, siafundBalance types.Currency, err error)
+
 		// WatchAddresses returns the set of addresses that the wallet is
 		// currently watching.
 		WatchAddresses() ([]types.UnlockHash, error)
+
+		// AddTimelockedAddress adds unlock conditions with a timelock whose
+		// keys belong to the wallet. Outputs of the address are tracked as
+		// pending unlock and spent once the timelock has passed. If unused is
+		// false, the wallet rescans the blockchain.
+		AddTimelockedAddress(uc types.UnlockConditions, unused bool) error
+
+		// TimelockedAddress returns a new address of the wallet whose outputs
+		// can't be spent before the unlock height.
+		TimelockedAddress(unlockHeight types.BlockHeight) (types.UnlockConditions, error)
+
+		// TimelockedAddresses returns the unlock conditions of the wallet's
+		// time-locked addresses.
+		TimelockedAddresses() ([]types.UnlockConditions, error)
+
+		// TimelockedOutputs returns the confirmed outputs of the wallet which
+		// are still locked.
+		TimelockedOutputs() ([]TimelockedOutput, error)
+	}
+
+	// TimelockedOutput is an output of a time-locked wallet address which
+	// can't be spent before its unlock height. Once the unlock height is
+	// reached, the output is spent like any other output of the wallet.
+	TimelockedOutput struct {
+		ID           types.OutputID    `json:"id"`
+		FundType     types.Specifier   `json:"fundtype"`
+		UnlockHash   types.UnlockHash  `json:"unlockhash"`
+		Value        types.Currency    `json:"value"`
+		UnlockHeight types.BlockHeight `json:"unlockheight"`
 	}
 
 	// WalletSettings control the behavior of the Wallet.
@@ -537,6 +808,49 @@ type (
 	}
 )
 
+// Validate checks that the strategy of the CoinControl is known and that no
+// output is both included and excluded.
+func (cc CoinControl) Validate() error {
+	switch cc.Strategy {
+	case "", CoinSelectionLargestFirst, CoinSelectionSmallestFirst, CoinSelectionMinimizeChange, CoinSelectionPrivacy:
+	default:
+		return ErrUnknownCoinSelectionStrategy
+	}
+	excluded := make(map[types.SiacoinOutputID]struct{}, len(cc.Exclude))
+	for _, id := range cc.Exclude {
+		excluded[id] = struct{}{}
+	}
+	for _, id := range cc.Include {
+		if _, exists := excluded[id]; exists {
+			return fmt.Errorf("output %v is both included and excluded", id)
+		}
+	}
+	return nil
+}
+
+// Addresses returns the related addresses of the inputs and outputs of the
+// transaction.
+func (pt ProcessedTransaction) Addresses() []types.UnlockHash {
+	addrs := make([]types.UnlockHash, 0, len(pt.Inputs)+len(pt.Outputs))
+	for _, input := range pt.Inputs {
+		addrs = append(addrs, input.RelatedAddress)
+	}
+	for _, output := range pt.Outputs {
+		addrs = append(addrs, output.RelatedAddress)
+	}
+	return addrs
+}
+
+// HasTag returns whether the label contains the tag.
+func (tl TransactionLabel) HasTag(tag string) bool {
+	for _, t := range tl.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
 // CalculateWalletTransactionID is a helper function for determining the id of
 // a wallet transaction.
 func CalculateWalletTransactionID(tid types.TransactionID, oid types.OutputID) WalletTransactionID {
@@ -0,0 +1,320 @@
+package wallet
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"gitlab.com/NebulousLabs/bolt"
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+var (
+	// errAccountExists is returned if an account is created with the name of
+	// an existing account.
+	errAccountExists = errors.New("account already exists")
+
+	// errInvalidAccountName is returned if an account name is empty or
+	// exceeds maxLabelLength.
+	errInvalidAccountName = errors.New("account name must not be empty or exceed the maximum length")
+
+	// errNotWalletAddress is returned if an address which isn't spendable by
+	// the wallet is assigned to an account.
+	errNotWalletAddress = errors.New("address doesn't belong to the wallet")
+
+	// errPrimarySeedAccount is returned if the primary seed is assigned to an
+	// account. Its addresses are assigned individually instead.
+	errPrimarySeedAccount = errors.New("the primary seed can't be assigned to an account")
+
+	// errUnknownAccount is returned if an account doesn't exist.
+	errUnknownAccount = errors.New("account doesn't exist")
+
+	// errUnknownSeed is returned if a seed is assigned to an account before
+	// it was loaded into the wallet.
+	errUnknownSeed = errors.New("seed hasn't been loaded into the wallet")
+)
+
+// checkAccount returns an error if the account doesn't exist.
+func checkAccount(tx *bolt.Tx, name string) error {
+	if _, err := dbGetAccount(tx, name); errors.Contains(err, errNoKey) {
+		return errUnknownAccount
+	} else if err != nil {
+		return errors.AddContext(err, "failed to read account")
+	}
+	return nil
+}
+
+// accountAddresses returns the map of addresses to the names of the accounts
+// which they are assigned to.
+func accountAddresses(tx *bolt.Tx) (map[types.UnlockHash]string, error) {
+	addrs := make(map[types.UnlockHash]string)
+	err := dbForEachAccountAddress(tx, func(addr types.UnlockHash, name string) {
+		addrs[addr] = name
+	})
+	if err != nil {
+		return nil, errors.AddContext(err, "failed to read account addresses")
+	}
+	return addrs, nil
+}
+
+// nextAccountAddress fetches the next address from the primary seed and
+// assigns it to the account. If name is empty, the address isn't assigned to
+// an account.
+func (w *Wallet) nextAccountAddress(tx *bolt.Tx, name string) (types.UnlockConditions, error) {
+	uc, err := w.nextPrimarySeedAddress(tx)
+	if err != nil || name == "" {
+		return uc, err
+	}
+	if err := dbPutAccountAddress(tx, uc.UnlockHash(), name); err != nil {
+		w.markAddressUnused(uc)
+		return types.UnlockConditions{}, errors.AddContext(err, "failed to assign address")
+	}
+	return uc, nil
+}
+
+// AccountAddress returns a new address of the primary seed which is assigned
+// to the account.
+func (w *Wallet) AccountAddress(name string) (types.UnlockConditions, error) {
+	if err := w.tg.Add(); err != nil {
+		return types.UnlockConditions{}, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := checkAccount(w.dbTx, name); err != nil {
+		return types.UnlockConditions{}, err
+	}
+	uc, err := w.nextAccountAddress(w.dbTx, name)
+	if err != nil {
+		return types.UnlockConditions{}, err
+	}
+	return uc, w.syncDB()
+}
+
+// AccountAddresses returns the addresses assigned to the account, sorted by
+// address.
+func (w *Wallet) AccountAddresses(name string) ([]types.UnlockHash, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := checkAccount(w.dbTx, name); err != nil {
+		return nil, err
+	}
+	all, err := accountAddresses(w.dbTx)
+	if err != nil {
+		return nil, err
+	}
+	addrs := []types.UnlockHash{}
+	for addr, account := range all {
+		if account == name {
+			addrs = append(addrs, addr)
+		}
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return bytes.Compare(addrs[i][:], addrs[j][:]) < 0
+	})
+	return addrs, nil
+}
+
+// Accounts returns the accounts of the wallet and their confirmed balances,
+// sorted by name.
+func (w *Wallet) Accounts() ([]modules.WalletAccount, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	// dustThreshold has to be obtained separate from the lock
+	dustThreshold, err := w.DustThreshold()
+	if err != nil {
+		return nil, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	accounts := make(map[string]*modules.WalletAccount)
+	err = dbForEachAccount(w.dbTx, func(name string, height types.BlockHeight) {
+		accounts[name] = &modules.WalletAccount{
+			Name:           name,
+			CreationHeight: height,
+		}
+	})
+	if err != nil {
+		return nil, errors.AddContext(err, "failed to read accounts")
+	}
+	addrs, err := accountAddresses(w.dbTx)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range addrs {
+		if account, exists := accounts[name]; exists {
+			account.NumAddresses++
+		}
+	}
+	err = dbForEachSiacoinOutput(w.dbTx, func(_ types.SiacoinOutputID, sco types.SiacoinOutput) {
+		account, exists := accounts[addrs[sco.UnlockHash]]
+		if exists && sco.Value.Cmp(dustThreshold) > 0 {
+			account.ConfirmedSiacoinBalance = account.ConfirmedSiacoinBalance.Add(sco.Value)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = dbForEachSiafundOutput(w.dbTx, func(_ types.SiafundOutputID, sfo types.SiafundOutput) {
+		if account, exists := accounts[addrs[sfo.UnlockHash]]; exists {
+			account.ConfirmedSiafundBalance = account.ConfirmedSiafundBalance.Add(sfo.Value)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	was := make([]modules.WalletAccount, 0, len(accounts))
+	for _, account := range accounts {
+		was = append(was, *account)
+	}
+	sort.Slice(was, func(i, j int) bool {
+		return was[i].Name < was[j].Name
+	})
+	return was, nil
+}
+
+// AddAccount creates a new account without addresses.
+func (w *Wallet) AddAccount(name string) error {
+	if err := w.tg.Add(); err != nil {
+		return modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxLabelLength {
+		return errInvalidAccountName
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := checkAccount(w.dbTx, name); err == nil {
+		return errAccountExists
+	} else if !errors.Contains(err, errUnknownAccount) {
+		return err
+	}
+	height, err := dbGetConsensusHeight(w.dbTx)
+	if err != nil {
+		return err
+	}
+	if err := dbPutAccount(w.dbTx, name, height); err != nil {
+		return errors.AddContext(err, "failed to store account")
+	}
+	return w.syncDB()
+}
+
+// AssignAccountAddresses assigns addresses of the wallet to the account. An
+// address can only be assigned to one account at a time. An empty name
+// removes the addresses from their accounts.
+func (w *Wallet) AssignAccountAddresses(name string, addrs []types.UnlockHash) error {
+	if err := w.tg.Add(); err != nil {
+		return modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.unlocked {
+		return modules.ErrLockedWallet
+	}
+	if name != "" {
+		if err := checkAccount(w.dbTx, name); err != nil {
+			return err
+		}
+	}
+	for _, addr := range addrs {
+		if _, exists := w.keys[addr]; !exists {
+			return errors.AddContext(errNotWalletAddress, addr.String())
+		}
+	}
+	for _, addr := range addrs {
+		var err error
+		if name == "" {
+			err = dbDeleteAccountAddress(w.dbTx, addr)
+		} else {
+			err = dbPutAccountAddress(w.dbTx, addr, name)
+		}
+		if err != nil {
+			return errors.AddContext(err, "failed to assign address")
+		}
+	}
+	return w.syncDB()
+}
+
+// AssignAccountSeed assigns the addresses of an auxiliary seed, which was
+// loaded using LoadSeed, to the account.
+func (w *Wallet) AssignAccountSeed(name string, seed modules.Seed) error {
+	if err := w.tg.Add(); err != nil {
+		return modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.unlocked {
+		return modules.ErrLockedWallet
+	}
+	if err := checkAccount(w.dbTx, name); err != nil {
+		return err
+	}
+	if seed == w.primarySeed {
+		return errPrimarySeedAccount
+	}
+	var loaded bool
+	for _, s := range w.seeds {
+		loaded = loaded || s == seed
+	}
+	if !loaded {
+		return errUnknownSeed
+	}
+	for _, sk := range generateKeys(seed, 0, modules.PublicKeysPerSeed) {
+		if err := dbPutAccountAddress(w.dbTx, sk.UnlockConditions.UnlockHash(), name); err != nil {
+			return errors.AddContext(err, "failed to assign address")
+		}
+	}
+	return w.syncDB()
+}
+
+// RemoveAccount deletes an account. Its addresses are no longer assigned to
+// an account, which means that their outputs are spent like any other output
+// of the wallet.
+func (w *Wallet) RemoveAccount(name string) error {
+	if err := w.tg.Add(); err != nil {
+		return modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := checkAccount(w.dbTx, name); err != nil {
+		return err
+	}
+	addrs, err := accountAddresses(w.dbTx)
+	if err != nil {
+		return err
+	}
+	for addr, account := range addrs {
+		if account != name {
+			continue
+		}
+		if err := dbDeleteAccountAddress(w.dbTx, addr); err != nil {
+			return errors.AddContext(err, "failed to unassign address")
+		}
+	}
+	if err := dbDeleteAccount(w.dbTx, name); err != nil {
+		return errors.AddContext(err, "failed to delete account")
+	}
+	return w.syncDB()
+}
@@ -0,0 +1,129 @@
+package wallet
+
+import (
+	"testing"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// TestAccounts probes creating an account, funding it and spending only its
+// outputs.
+func TestAccounts(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := wt.closeWt(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Create the account.
+	if err := wt.wallet.AddAccount(" "); !errors.Contains(err, errInvalidAccountName) {
+		t.Fatal("expected errInvalidAccountName, got", err)
+	}
+	if err := wt.wallet.AddAccount("ops"); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.wallet.AddAccount("ops"); !errors.Contains(err, errAccountExists) {
+		t.Fatal("expected errAccountExists, got", err)
+	}
+	if _, err := wt.wallet.AccountAddress("unknown"); !errors.Contains(err, errUnknownAccount) {
+		t.Fatal("expected errUnknownAccount, got", err)
+	}
+
+	// Fund the account from the outputs outside of accounts.
+	uc, err := wt.wallet.AccountAddress("ops")
+	if err != nil {
+		t.Fatal(err)
+	}
+	funding := types.SiacoinPrecision.Mul64(100)
+	if _, err := wt.wallet.SendSiacoins(funding, uc.UnlockHash()); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+	accounts, err := wt.wallet.Accounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 1 || accounts[0].Name != "ops" || !accounts[0].ConfirmedSiacoinBalance.Equals(funding) {
+		t.Fatal("wrong accounts", accounts)
+	}
+
+	// Outputs of the account can't be spent without selecting the account.
+	outputs, err := wt.wallet.UnspentOutputs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var accountOutput types.SiacoinOutputID
+	for _, uo := range outputs {
+		if uo.UnlockHash == uc.UnlockHash() {
+			accountOutput = types.SiacoinOutputID(uo.ID)
+		}
+	}
+	_, err = wt.wallet.SendSiacoinsCoinControl(types.SiacoinPrecision, types.UnlockHash{}, false, modules.CoinControl{
+		Include: []types.SiacoinOutputID{accountOutput},
+	})
+	if err == nil {
+		t.Fatal("output of the account was spent without selecting the account")
+	}
+
+	// Spending more than the balance of the account should fail even though
+	// the wallet has enough money.
+	tb, err := wt.wallet.StartTransaction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tb.FundSiacoinsCoinControl(funding.Mul64(2), modules.CoinControl{Account: "ops"})
+	if !errors.Contains(err, modules.ErrLowBalance) {
+		t.Fatal("expected ErrLowBalance, got", err)
+	}
+	tb.Drop()
+
+	// Spend from the account. The change should stay in the account.
+	amount := types.SiacoinPrecision.Mul64(40)
+	txns, err := wt.wallet.SendSiacoinsCoinControl(amount, types.UnlockHash{}, false, modules.CoinControl{Account: "ops"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fees types.Currency
+	for _, txn := range txns {
+		for _, fee := range txn.MinerFees {
+			fees = fees.Add(fee)
+		}
+	}
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+	accounts, err = wt.wallet.Accounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := funding.Sub(amount).Sub(fees); !accounts[0].ConfirmedSiacoinBalance.Equals(expected) {
+		t.Fatalf("expected account balance %v, got %v", expected, accounts[0].ConfirmedSiacoinBalance)
+	}
+
+	// Removing the account should release its addresses.
+	if err := wt.wallet.RemoveAccount("ops"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.wallet.AccountAddresses("ops"); !errors.Contains(err, errUnknownAccount) {
+		t.Fatal("expected errUnknownAccount, got", err)
+	}
+	accounts, err = wt.wallet.Accounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 0 {
+		t.Fatal("account wasn't removed", accounts)
+	}
+}
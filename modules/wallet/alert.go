@@ -6,5 +6,5 @@ import (
 
 // Alerts implements the Alerter interface for the wallet.
 func (w *Wallet) Alerts() (crit, err, warn []modules.Alert) {
-	return []modules.Alert{}, []modules.Alert{}, []modules.Alert{}
+	return w.staticAlerter.Alerts()
 }
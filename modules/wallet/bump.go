@@ -0,0 +1,357 @@
+package wallet
+
+import (
+	"gitlab.com/NebulousLabs/encoding"
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+var (
+	// errBumpNotNeeded is returned if a transaction set already pays the
+	// requested fee.
+	errBumpNotNeeded = errors.New("transaction set already pays the requested fee")
+
+	// errChangeTooSmall is returned if the change output of a transaction set
+	// can't pay for the fee bump.
+	errChangeTooSmall = errors.New("change output is too small to pay for the fee bump")
+
+	// errNoChangeOutput is returned if a transaction set doesn't have an
+	// unspent output which belongs to the wallet.
+	errNoChangeOutput = errors.New("transaction set has no change output")
+
+	// errNotReplaceable is returned if a transaction set contains
+	// transactions which can't be re-signed by the wallet.
+	errNotReplaceable = errors.New("transaction set contains transactions which weren't created by the wallet")
+
+	// errTransactionNotUnconfirmed is returned if the transaction to bump is
+	// not in the transaction pool.
+	errTransactionNotUnconfirmed = errors.New("transaction is not in the transaction pool")
+
+	// errUnknownFeeBumpMethod is returned if an unknown fee bump method is
+	// requested.
+	errUnknownFeeBumpMethod = errors.New("unknown fee bump method")
+)
+
+// transactionSetFees returns the sum of the miner fees of a transaction set.
+func transactionSetFees(set []types.Transaction) (fees types.Currency) {
+	for _, txn := range set {
+		for _, fee := range txn.MinerFees {
+			fees = fees.Add(fee)
+		}
+	}
+	return fees
+}
+
+// changeOutput returns the position of the largest output of the set which
+// belongs to the wallet and is not spent by the set or by another transaction
+// of the wallet.
+func (w *Wallet) changeOutput(set []types.Transaction) (txnIndex, outputIndex int, err error) {
+	spent := make(map[types.SiacoinOutputID]struct{})
+	for _, txn := range set {
+		for _, sci := range txn.SiacoinInputs {
+			spent[sci.ParentID] = struct{}{}
+		}
+	}
+	txnIndex, outputIndex = -1, -1
+	var value types.Currency
+	for i, txn := range set {
+		for j, sco := range txn.SiacoinOutputs {
+			id := txn.SiacoinOutputID(uint64(j))
+			if _, exists := w.keys[sco.UnlockHash]; !exists {
+				continue
+			}
+			if _, exists := spent[id]; exists {
+				continue
+			}
+			if _, err := dbGetSpentOutput(w.dbTx, types.OutputID(id)); err == nil {
+				continue
+			}
+			if txnIndex == -1 || sco.Value.Cmp(value) > 0 {
+				txnIndex, outputIndex, value = i, j, sco.Value
+			}
+		}
+	}
+	if txnIndex == -1 {
+		return -1, -1, errNoChangeOutput
+	}
+	return txnIndex, outputIndex, nil
+}
+
+// BumpTransaction raises the fee of the unconfirmed transaction set containing
+// the transaction to 'feePerByte'. If 'feePerByte' is zero, the maximum
+// recommended fee of the transaction pool is used. If no method is provided,
+// FeeBumpCPFP is used if the set has a change output and FeeBumpReplace
+// otherwise. The new transactions and the used method are returned.
+func (w *Wallet) BumpTransaction(txid types.TransactionID, method modules.FeeBumpMethod, feePerByte types.Currency) (txns []types.Transaction, _ modules.FeeBumpMethod, err error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, "", modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	w.mu.RLock()
+	unlocked := w.unlocked
+	w.mu.RUnlock()
+	if !unlocked {
+		return nil, "", modules.ErrLockedWallet
+	}
+	if feePerByte.IsZero() {
+		_, feePerByte = w.tpool.FeeEstimation()
+	}
+
+	txn, parents, exists := w.tpool.Transaction(txid)
+	if !exists {
+		return nil, "", errTransactionNotUnconfirmed
+	}
+	set := append(parents, txn)
+	if method == "" {
+		w.mu.Lock()
+		_, _, err := w.changeOutput(set)
+		w.mu.Unlock()
+		method = modules.FeeBumpCPFP
+		if errors.Contains(err, errNoChangeOutput) {
+			method = modules.FeeBumpReplace
+		}
+	}
+
+	switch method {
+	case modules.FeeBumpCPFP:
+		txns, err = w.managedBumpCPFP(set, feePerByte)
+	case modules.FeeBumpReplace:
+		txns, err = w.managedBumpReplace(txid, feePerByte)
+	default:
+		err = errUnknownFeeBumpMethod
+	}
+	if err != nil {
+		w.log.Printf("Failed to bump fee of transaction %v using %v: %v", txid, method, err)
+		return nil, method, err
+	}
+	w.log.Printf("Bumped fee of transaction %v to %v per byte using %v", txid, feePerByte.HumanString(), method)
+	return txns, method, nil
+}
+
+// managedBumpCPFP adds a child transaction to the set which spends the change
+// output of the set and pays enough fees for the whole set to pay
+// 'feePerByte'.
+func (w *Wallet) managedBumpCPFP(set []types.Transaction, feePerByte types.Currency) (_ []types.Transaction, err error) {
+	w.mu.Lock()
+	txnIndex, outputIndex, err := w.changeOutput(set)
+	if err != nil {
+		w.mu.Unlock()
+		return nil, err
+	}
+	changeID := set[txnIndex].SiacoinOutputID(uint64(outputIndex))
+	change := set[txnIndex].SiacoinOutputs[outputIndex]
+	changeUC := w.keys[change.UnlockHash].UnlockConditions
+	w.mu.Unlock()
+
+	// The child pays for the fees of the whole set including itself.
+	setSize := uint64(len(encoding.Marshal(set)))
+	required := feePerByte.Mul64(setSize + estimatedTransactionSize)
+	paid := transactionSetFees(set)
+	if paid.Cmp(required) >= 0 {
+		return nil, errBumpNotNeeded
+	}
+	fee := required.Sub(paid)
+	dustThreshold, err := w.DustThreshold()
+	if err != nil {
+		return nil, err
+	}
+	if change.Value.Cmp(fee.Add(dustThreshold)) <= 0 {
+		return nil, errChangeTooSmall
+	}
+
+	uc, err := w.NextAddress()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Compose(err, w.MarkAddressUnused(uc))
+		}
+	}()
+
+	// Build the child. The builder isn't dropped on failure since that would
+	// release the outputs spent by the parents.
+	tb, err := w.RegisterTransaction(types.Transaction{}, set)
+	if err != nil {
+		return nil, err
+	}
+	tb.AddSiacoinInput(types.SiacoinInput{
+		ParentID:         changeID,
+		UnlockConditions: changeUC,
+	})
+	tb.MarkWalletInputs()
+	tb.AddSiacoinOutput(types.SiacoinOutput{
+		Value:      change.Value.Sub(fee),
+		UnlockHash: uc.UnlockHash(),
+	})
+	tb.AddMinerFee(fee)
+	txnSet, err := tb.Sign(true)
+	if err != nil {
+		return nil, errors.AddContext(err, "failed to sign child transaction")
+	}
+
+	// Mark the change output as spent before submitting the set to avoid
+	// funding another transaction with it in the meantime.
+	w.mu.Lock()
+	consensusHeight, err := dbGetConsensusHeight(w.dbTx)
+	if err == nil {
+		err = dbPutSpentOutput(w.dbTx, types.OutputID(changeID), consensusHeight)
+	}
+	w.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if err := w.tpool.AcceptTransactionSet(txnSet); err != nil {
+		w.mu.Lock()
+		dbDeleteSpentOutput(w.dbTx, types.OutputID(changeID))
+		w.mu.Unlock()
+		return nil, errors.AddContext(err, "child transaction was rejected")
+	}
+	return txnSet, nil
+}
+
+// managedBumpReplace replaces the unconfirmed set containing the transaction
+// with a re-signed set which spends the same inputs and pays 'feePerByte'. The
+// additional fee is taken from the change output of the set. This is only
+// possible if all transactions of the set were created by the wallet.
+func (w *Wallet) managedBumpReplace(txid types.TransactionID, feePerByte types.Currency) ([]types.Transaction, error) {
+	dustThreshold, err := w.DustThreshold()
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the set as it is stored in the transaction pool since the pool
+	// might have merged the wallet's transactions with other ones.
+	txn, _, exists := w.tpool.Transaction(txid)
+	if !exists {
+		return nil, errTransactionNotUnconfirmed
+	}
+	if len(txn.SiacoinInputs) == 0 {
+		return nil, errNotReplaceable
+	}
+	set := w.tpool.TransactionSet(crypto.Hash(txn.SiacoinInputs[0].ParentID))
+	if len(set) == 0 {
+		return nil, errTransactionNotUnconfirmed
+	}
+	setID := modules.TransactionSetID(crypto.HashObject(set))
+
+	w.mu.Lock()
+	replacement, err := w.buildReplacementSet(set, feePerByte, dustThreshold)
+	w.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	// The wallet can't be locked while replacing the set since the
+	// transaction pool notifies the wallet about the change.
+	if err := w.tpool.ReplaceTransactionSet(setID, replacement); err != nil {
+		return nil, errors.AddContext(err, "replacement set was rejected")
+	}
+
+	// Mark the outputs spent by the replacement as spent. This only happens
+	// after the replacement was accepted since the outputs spent by the
+	// original set are still in use otherwise.
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	consensusHeight, err := dbGetConsensusHeight(w.dbTx)
+	if err != nil {
+		return nil, err
+	}
+	for _, txn := range replacement {
+		for _, sci := range txn.SiacoinInputs {
+			if err := dbPutSpentOutput(w.dbTx, types.OutputID(sci.ParentID), consensusHeight); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return replacement, nil
+}
+
+// buildReplacementSet creates a replacement for the unconfirmed set which pays
+// 'feePerByte'. Every transaction of the set has to be a plain siacoin
+// transaction which was created and signed by the wallet.
+func (w *Wallet) buildReplacementSet(set []types.Transaction, feePerByte, dustThreshold types.Currency) ([]types.Transaction, error) {
+	unconfirmed := make(map[types.TransactionID]struct{})
+	for _, pt := range w.unconfirmedProcessedTransactions {
+		unconfirmed[pt.TransactionID] = struct{}{}
+	}
+	for _, txn := range set {
+		if _, exists := unconfirmed[txn.ID()]; !exists {
+			return nil, errNotReplaceable
+		}
+		if len(txn.SiacoinInputs) == 0 || len(txn.FileContracts) > 0 || len(txn.FileContractRevisions) > 0 || len(txn.StorageProofs) > 0 || len(txn.SiafundInputs) > 0 || len(txn.SiafundOutputs) > 0 {
+			return nil, errNotReplaceable
+		}
+		inputs := make(map[crypto.Hash]struct{})
+		for _, sci := range txn.SiacoinInputs {
+			if _, exists := w.keys[sci.UnlockConditions.UnlockHash()]; !exists {
+				return nil, errNotReplaceable
+			}
+			inputs[crypto.Hash(sci.ParentID)] = struct{}{}
+		}
+		// Signatures for anything but the wallet's inputs were added by
+		// someone else.
+		for _, sig := range txn.TransactionSignatures {
+			if _, exists := inputs[sig.ParentID]; !exists {
+				return nil, errNotReplaceable
+			}
+		}
+	}
+
+	// Take the additional fee from the change output.
+	setSize := uint64(len(encoding.Marshal(set)))
+	required := feePerByte.Mul64(setSize)
+	paid := transactionSetFees(set)
+	if paid.Cmp(required) >= 0 {
+		return nil, errBumpNotNeeded
+	}
+	fee := required.Sub(paid)
+	changeTxn, changeOutput, err := w.changeOutput(set)
+	if err != nil {
+		return nil, err
+	}
+	if set[changeTxn].SiacoinOutputs[changeOutput].Value.Cmp(fee.Add(dustThreshold)) <= 0 {
+		return nil, errChangeTooSmall
+	}
+
+	// Create the replacement. Changing a transaction changes the ids of its
+	// outputs, so the inputs of its children need to be updated before they
+	// are re-signed.
+	consensusHeight, err := dbGetConsensusHeight(w.dbTx)
+	if err != nil {
+		return nil, err
+	}
+	newIDs := make(map[types.SiacoinOutputID]types.SiacoinOutputID)
+	replacement := make([]types.Transaction, 0, len(set))
+	for i, original := range set {
+		var txn types.Transaction
+		if err := encoding.Unmarshal(encoding.Marshal(original), &txn); err != nil {
+			return nil, err
+		}
+		for j, sci := range txn.SiacoinInputs {
+			if newID, exists := newIDs[sci.ParentID]; exists {
+				txn.SiacoinInputs[j].ParentID = newID
+			}
+		}
+		if i == changeTxn {
+			txn.SiacoinOutputs[changeOutput].Value = txn.SiacoinOutputs[changeOutput].Value.Sub(fee)
+			if len(txn.MinerFees) == 0 {
+				txn.MinerFees = append(txn.MinerFees, fee)
+			} else {
+				txn.MinerFees[0] = txn.MinerFees[0].Add(fee)
+			}
+		}
+		for j := range txn.SiacoinOutputs {
+			newIDs[original.SiacoinOutputID(uint64(j))] = txn.SiacoinOutputID(uint64(j))
+		}
+		txn.TransactionSignatures = nil
+		for _, sci := range txn.SiacoinInputs {
+			addSignatures(&txn, types.FullCoveredFields, sci.UnlockConditions, crypto.Hash(sci.ParentID), w.keys[sci.UnlockConditions.UnlockHash()], consensusHeight)
+		}
+		replacement = append(replacement, txn)
+	}
+	return replacement, nil
+}
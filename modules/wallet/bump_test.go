@@ -0,0 +1,122 @@
+package wallet
+
+import (
+	"testing"
+
+	"gitlab.com/NebulousLabs/encoding"
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// TestBumpTransaction probes bumping the fee of unconfirmed transactions.
+func TestBumpTransaction(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := wt.closeWt(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	_, maxFee := wt.tpool.FeeEstimation()
+	highFee := maxFee.Mul64(10)
+
+	// confirm mines a block and checks that the transaction was confirmed.
+	confirm := func(txid types.TransactionID) {
+		t.Helper()
+		if err := wt.addBlockNoPayout(); err != nil {
+			t.Fatal(err)
+		}
+		if _, exists, err := wt.wallet.Transaction(txid); err != nil || !exists {
+			t.Fatal("transaction wasn't confirmed", err)
+		}
+	}
+
+	// Unknown transactions can't be bumped.
+	_, _, err = wt.wallet.BumpTransaction(types.TransactionID{1}, "", highFee)
+	if !errors.Contains(err, errTransactionNotUnconfirmed) {
+		t.Fatal("expected errTransactionNotUnconfirmed, got", err)
+	}
+
+	// Bump a transaction using CPFP.
+	txns, err := wt.wallet.SendSiacoins(types.SiacoinPrecision, types.UnlockHash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	txid := txns[len(txns)-1].ID()
+	_, _, err = wt.wallet.BumpTransaction(txid, "", types.NewCurrency64(1))
+	if !errors.Contains(err, errBumpNotNeeded) {
+		t.Fatal("expected errBumpNotNeeded, got", err)
+	}
+	_, _, err = wt.wallet.BumpTransaction(txid, "unknown", highFee)
+	if !errors.Contains(err, errUnknownFeeBumpMethod) {
+		t.Fatal("expected errUnknownFeeBumpMethod, got", err)
+	}
+	bumped, method, err := wt.wallet.BumpTransaction(txid, "", highFee)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if method != modules.FeeBumpCPFP {
+		t.Fatal("expected cpfp, got", method)
+	}
+	if transactionSetFees(bumped).Cmp(highFee.Mul64(uint64(len(encoding.Marshal(bumped))))) < 0 {
+		t.Fatal("bumped set doesn't pay the requested fee")
+	}
+	// The change output was spent by the child.
+	_, _, err = wt.wallet.BumpTransaction(txid, modules.FeeBumpCPFP, highFee.Mul64(2))
+	if !errors.Contains(err, errNoChangeOutput) {
+		t.Fatal("expected errNoChangeOutput, got", err)
+	}
+	confirm(bumped[len(bumped)-1].ID())
+
+	// Bump a transaction by replacing it.
+	txns, err = wt.wallet.SendSiacoins(types.SiacoinPrecision, types.UnlockHash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := txns[len(txns)-1]
+	replacement, method, err := wt.wallet.BumpTransaction(original.ID(), modules.FeeBumpReplace, highFee)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if method != modules.FeeBumpReplace {
+		t.Fatal("expected replace, got", method)
+	}
+	if transactionSetFees(replacement).Cmp(highFee.Mul64(uint64(len(encoding.Marshal(txns))))) < 0 {
+		t.Fatal("replacement doesn't pay the requested fee")
+	}
+	if _, _, exists := wt.tpool.Transaction(original.ID()); exists {
+		t.Fatal("original transaction is still in the transaction pool")
+	}
+	replaced := replacement[len(replacement)-1]
+	if _, _, exists := wt.tpool.Transaction(replaced.ID()); !exists {
+		t.Fatal("replacement isn't in the transaction pool")
+	}
+	// The inputs of the set are the same, only the ids of outputs created
+	// within the set change.
+	if len(replacement) != len(txns) {
+		t.Fatal("replacement has a different number of transactions")
+	}
+	for i, sci := range txns[0].SiacoinInputs {
+		if replacement[0].SiacoinInputs[i].ParentID != sci.ParentID {
+			t.Fatal("replacement doesn't spend the same inputs")
+		}
+	}
+	// The outputs spent by the replacement should be marked as spent.
+	wt.wallet.mu.Lock()
+	for _, txn := range replacement {
+		for _, sci := range txn.SiacoinInputs {
+			if _, err := dbGetSpentOutput(wt.wallet.dbTx, types.OutputID(sci.ParentID)); err != nil {
+				t.Error("output spent by the replacement isn't marked as spent", err)
+			}
+		}
+	}
+	wt.wallet.mu.Unlock()
+	confirm(replaced.ID())
+}
@@ -0,0 +1,110 @@
+package wallet
+
+import (
+	"sort"
+
+	"gitlab.com/NebulousLabs/fastrand"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// selectSiacoinOutputs selects outputs from the spendable outputs which add up
+// to at least 'amount' according to the strategy. If the outputs don't cover
+// the amount, all of them are returned.
+func selectSiacoinOutputs(so sortedOutputs, amount types.Currency, strategy modules.CoinSelectionStrategy) (selected sortedOutputs, fund types.Currency) {
+	// accumulate adds outputs in the order of 'so' until the amount is
+	// covered.
+	accumulate := func(so sortedOutputs) {
+		for i := range so.ids {
+			if fund.Cmp(amount) >= 0 {
+				return
+			}
+			selected.ids = append(selected.ids, so.ids[i])
+			selected.outputs = append(selected.outputs, so.outputs[i])
+			fund = fund.Add(so.outputs[i].Value)
+		}
+	}
+
+	switch strategy {
+	case modules.CoinSelectionSmallestFirst:
+		sort.Sort(so)
+		accumulate(so)
+	case modules.CoinSelectionMinimizeChange:
+		// Use the smallest output which covers the amount on its own.
+		sort.Sort(so)
+		for i := range so.ids {
+			if so.outputs[i].Value.Cmp(amount) >= 0 {
+				accumulate(sortedOutputs{
+					ids:     so.ids[i : i+1],
+					outputs: so.outputs[i : i+1],
+				})
+				return
+			}
+		}
+		sort.Sort(sort.Reverse(so))
+		accumulate(so)
+	case modules.CoinSelectionPrivacy:
+		groups, single := privacyGroups(so, amount)
+		if single {
+			accumulate(groups[0])
+			return
+		}
+		// Spend whole addresses to not leave outputs behind on addresses
+		// which are already linked.
+		for _, group := range groups {
+			if fund.Cmp(amount) >= 0 {
+				return
+			}
+			selected.ids = append(selected.ids, group.ids...)
+			selected.outputs = append(selected.outputs, group.outputs...)
+			fund = fund.Add(group.total())
+		}
+	default:
+		sort.Sort(sort.Reverse(so))
+		accumulate(so)
+	}
+	return
+}
+
+// privacyGroups groups the outputs by address to link as few addresses as
+// possible. If a single address covers the amount, only the group of the
+// address with the smallest sufficient balance is returned and 'single' is
+// true. Otherwise all groups are returned in random order. The outputs within
+// a group are sorted by value in descending order.
+func privacyGroups(so sortedOutputs, amount types.Currency) (groups []sortedOutputs, single bool) {
+	sort.Sort(sort.Reverse(so))
+	var addrs []types.UnlockHash
+	byAddr := make(map[types.UnlockHash]sortedOutputs)
+	for i := range so.ids {
+		uh := so.outputs[i].UnlockHash
+		group, exists := byAddr[uh]
+		if !exists {
+			addrs = append(addrs, uh)
+		}
+		group.ids = append(group.ids, so.ids[i])
+		group.outputs = append(group.outputs, so.outputs[i])
+		byAddr[uh] = group
+	}
+
+	// Prefer a single address.
+	var best sortedOutputs
+	for _, uh := range addrs {
+		group := byAddr[uh]
+		if group.total().Cmp(amount) < 0 {
+			continue
+		}
+		if len(best.ids) == 0 || group.total().Cmp(best.total()) < 0 {
+			best = group
+		}
+	}
+	if len(best.ids) > 0 {
+		return []sortedOutputs{best}, true
+	}
+
+	// Otherwise return all addresses in random order.
+	for _, i := range fastrand.Perm(len(addrs)) {
+		groups = append(groups, byAddr[addrs[i]])
+	}
+	return groups, false
+}
@@ -0,0 +1,213 @@
+package wallet
+
+import (
+	"testing"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// TestSelectSiacoinOutputs is a unit test for the coin selection strategies.
+func TestSelectSiacoinOutputs(t *testing.T) {
+	t.Parallel()
+
+	// Create outputs worth 1, 2, 3, 4 and 11 where the first four outputs
+	// belong to the address a and the last one to b.
+	a, b := types.UnlockHash{1}, types.UnlockHash{2}
+	newOutputs := func() sortedOutputs {
+		var so sortedOutputs
+		for i, v := range []uint64{3, 11, 1, 4, 2} {
+			uh := a
+			if v == 11 {
+				uh = b
+			}
+			so.ids = append(so.ids, types.SiacoinOutputID{byte(i)})
+			so.outputs = append(so.outputs, types.SiacoinOutput{Value: types.NewCurrency64(v), UnlockHash: uh})
+		}
+		return so
+	}
+	values := func(so sortedOutputs) (vs []uint64) {
+		for _, sco := range so.outputs {
+			vs = append(vs, sco.Value.Big().Uint64())
+		}
+		return
+	}
+	equal := func(a, b []uint64) bool {
+		if len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if a[i] != b[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	tests := []struct {
+		strategy modules.CoinSelectionStrategy
+		amount   uint64
+		expected []uint64
+	}{
+		{"", 5, []uint64{11}},
+		{modules.CoinSelectionLargestFirst, 12, []uint64{11, 4}},
+		{modules.CoinSelectionSmallestFirst, 5, []uint64{1, 2, 3}},
+		{modules.CoinSelectionMinimizeChange, 4, []uint64{4}},
+		{modules.CoinSelectionMinimizeChange, 13, []uint64{11, 4}},
+		{modules.CoinSelectionPrivacy, 9, []uint64{4, 3, 2}},
+		{modules.CoinSelectionPrivacy, 11, []uint64{11}},
+		{modules.CoinSelectionLargestFirst, 100, []uint64{11, 4, 3, 2, 1}},
+	}
+	for _, test := range tests {
+		selected, fund := selectSiacoinOutputs(newOutputs(), types.NewCurrency64(test.amount), test.strategy)
+		if !equal(values(selected), test.expected) {
+			t.Errorf("%v: expected %v for %v but got %v", test.strategy, test.expected, test.amount, values(selected))
+		}
+		if !fund.Equals(selected.total()) {
+			t.Errorf("%v: fund %v doesn't match selected outputs %v", test.strategy, fund, selected.total())
+		}
+	}
+
+	// If no single address covers the amount, the privacy strategy should
+	// spend whole addresses.
+	selected, _ := selectSiacoinOutputs(newOutputs(), types.NewCurrency64(12), modules.CoinSelectionPrivacy)
+	if vs := values(selected); !equal(vs, []uint64{11, 4, 3, 2, 1}) && !equal(vs, []uint64{4, 3, 2, 1, 11}) {
+		t.Error("privacy strategy didn't group outputs by address", vs)
+	}
+}
+
+// TestFundSiacoinsCoinControl probes funding a transaction with coin control.
+func TestFundSiacoinsCoinControl(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := wt.closeWt(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Send 10, 20 and 30 SC to new addresses of the wallet.
+	var outputs []types.SiacoinOutput
+	for i := uint64(1); i <= 3; i++ {
+		uc, err := wt.wallet.NextAddress()
+		if err != nil {
+			t.Fatal(err)
+		}
+		outputs = append(outputs, types.SiacoinOutput{
+			Value:      types.SiacoinPrecision.Mul64(10 * i),
+			UnlockHash: uc.UnlockHash(),
+		})
+	}
+	if _, err := wt.wallet.SendSiacoinsMulti(outputs); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]types.SiacoinOutputID, len(outputs))
+	uos, err := wt.wallet.UnspentOutputs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, uo := range uos {
+		for i, sco := range outputs {
+			if uo.UnlockHash == sco.UnlockHash {
+				ids[i] = types.SiacoinOutputID(uo.ID)
+			}
+		}
+	}
+	for i := range ids {
+		if ids[i] == (types.SiacoinOutputID{}) {
+			t.Fatal("output wasn't found", i)
+		}
+	}
+
+	// Fund a transaction with the first two outputs and send the change to a
+	// custom address.
+	change := types.UnlockHash{1, 2, 3}
+	tb, err := wt.wallet.StartTransaction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tb.FundSiacoinsCoinControl(types.SiacoinPrecision.Mul64(15), modules.CoinControl{
+		Include:       ids[:2],
+		ChangeAddress: change,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, parents := tb.View()
+	if len(parents) != 1 {
+		t.Fatal("expected one parent", len(parents))
+	}
+	parent := parents[0]
+	if len(parent.SiacoinInputs) != 2 {
+		t.Fatal("expected two inputs", len(parent.SiacoinInputs))
+	}
+	for i, sci := range parent.SiacoinInputs {
+		if sci.ParentID != ids[0] && sci.ParentID != ids[1] {
+			t.Fatal("unexpected input", i)
+		}
+	}
+	if len(parent.SiacoinOutputs) != 2 {
+		t.Fatal("expected a change output", len(parent.SiacoinOutputs))
+	}
+	if parent.SiacoinOutputs[1].UnlockHash != change || !parent.SiacoinOutputs[1].Value.Equals(types.SiacoinPrecision.Mul64(15)) {
+		t.Fatal("wrong change output", parent.SiacoinOutputs[1])
+	}
+	tb.Drop()
+
+	// Included outputs which don't cover the amount shouldn't be topped up
+	// with other outputs.
+	tb, err = wt.wallet.StartTransaction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tb.FundSiacoinsCoinControl(types.SiacoinPrecision.Mul64(15), modules.CoinControl{Include: ids[:1]})
+	if !errors.Contains(err, modules.ErrLowBalance) {
+		t.Fatal("expected ErrLowBalance, got", err)
+	}
+	// Unknown outputs can't be included.
+	err = tb.FundSiacoinsCoinControl(types.SiacoinPrecision, modules.CoinControl{Include: []types.SiacoinOutputID{{1}}})
+	if err == nil {
+		t.Fatal("unknown output was included")
+	}
+	// Unknown strategies should be rejected.
+	err = tb.FundSiacoinsCoinControl(types.SiacoinPrecision, modules.CoinControl{Strategy: "unknown"})
+	if !errors.Contains(err, modules.ErrUnknownCoinSelectionStrategy) {
+		t.Fatal("expected ErrUnknownCoinSelectionStrategy, got", err)
+	}
+
+	// Excluded outputs shouldn't be spent.
+	err = tb.FundSiacoinsCoinControl(types.SiacoinPrecision.Mul64(5), modules.CoinControl{
+		Exclude:  ids[:1],
+		Strategy: modules.CoinSelectionMinimizeChange,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, parents = tb.View()
+	if len(parents) != 1 || len(parents[0].SiacoinInputs) != 1 {
+		t.Fatal("expected a single input")
+	}
+	if parents[0].SiacoinInputs[0].ParentID == ids[0] {
+		t.Fatal("excluded output was spent")
+	}
+	tb.Drop()
+
+	// Send coins using only the largest output.
+	txns, err := wt.wallet.SendSiacoinsCoinControl(types.SiacoinPrecision, types.UnlockHash{}, false, modules.CoinControl{Include: ids[2:]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txns[0].SiacoinInputs) != 1 || txns[0].SiacoinInputs[0].ParentID != ids[2] {
+		t.Fatal("transaction wasn't funded by the included output")
+	}
+}
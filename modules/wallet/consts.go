@@ -48,3 +48,9 @@ func init() {
 func maxLookahead(start uint64) uint64 {
 	return start + lookaheadRescanThreshold + lookaheadBuffer + start/10
 }
+
+const (
+	// AlertMSGScheduledPaymentFailed indicates that the wallet failed to send
+	// a scheduled payment. The payment is retried on the next block.
+	AlertMSGScheduledPaymentFailed = "a scheduled payment could not be sent"
+)
@@ -16,6 +16,25 @@ import (
 )
 
 var (
+	// bucketAccountAddresses maps an UnlockHash to the name of the account
+	// which the address is assigned to.
+	bucketAccountAddresses = []byte("bucketAccountAddresses")
+	// bucketAccounts maps the name of an account to the height at which it
+	// was created.
+	bucketAccounts = []byte("bucketAccounts")
+	// bucketAddressLabels maps an UnlockHash to the name the user gave to
+	// the address.
+	bucketAddressLabels = []byte("bucketAddressLabels")
+	// bucketExchangeRates maps an exchangeRateKey to the exchange rate of
+	// the currency at that time.
+	bucketExchangeRates = []byte("bucketExchangeRates")
+	// bucketMultisigAddresses maps an UnlockHash to the UnlockConditions of
+	// a multisig address which contains a key of the wallet.
+	bucketMultisigAddresses = []byte("bucketMultisigAddresses")
+	// bucketPendingScheduledPayments maps a ScheduledPaymentID to the
+	// pendingScheduledPayment of a payment which was stored but might not
+	// have been broadcast yet.
+	bucketPendingScheduledPayments = []byte("bucketPendingScheduledPayments")
 	// bucketProcessedTransactions stores ProcessedTransactions in
 	// chronological order. Only transactions relevant to the wallet are
 	// stored. The key of this bucket is an autoincrementing integer.
@@ -26,6 +45,9 @@ var (
 	// bucketAddrTransactions maps an UnlockHash to the
 	// ProcessedTransactions that it appears in.
 	bucketAddrTransactions = []byte("bucketAddrTransactions")
+	// bucketScheduledPayments maps a ScheduledPaymentID to its
+	// ScheduledPayment.
+	bucketScheduledPayments = []byte("bucketScheduledPayments")
 	// bucketSiacoinOutputs maps a SiacoinOutputID to its SiacoinOutput. Only
 	// outputs that the wallet controls are stored. The wallet uses these
 	// outputs to fund transactions.
@@ -39,6 +61,12 @@ var (
 	// these outputs so that it can reuse them if they are not confirmed on
 	// the blockchain.
 	bucketSpentOutputs = []byte("bucketSpentOutputs")
+	// bucketTimelockedAddresses maps an UnlockHash to the UnlockConditions
+	// of a time-locked address of the wallet.
+	bucketTimelockedAddresses = []byte("bucketTimelockedAddresses")
+	// bucketTransactionLabels maps a TransactionID to the note and tags the
+	// user attached to the transaction.
+	bucketTransactionLabels = []byte("bucketTransactionLabels")
 	// bucketUnlockConditions maps an UnlockHash to its UnlockConditions. It
 	// is used to track UnlockConditions manually stored by the user,
 	// typically with an offline wallet.
@@ -48,12 +76,21 @@ var (
 	bucketWallet = []byte("bucketWallet")
 
 	dbBuckets = [][]byte{
+		bucketAccountAddresses,
+		bucketAccounts,
+		bucketAddressLabels,
+		bucketExchangeRates,
+		bucketMultisigAddresses,
+		bucketPendingScheduledPayments,
 		bucketProcessedTransactions,
 		bucketProcessedTxnIndex,
 		bucketAddrTransactions,
+		bucketScheduledPayments,
 		bucketSiacoinOutputs,
 		bucketSiafundOutputs,
 		bucketSpentOutputs,
+		bucketTimelockedAddresses,
+		bucketTransactionLabels,
 		bucketUnlockConditions,
 		bucketWallet,
 	}
@@ -240,6 +277,95 @@ func dbGetAddrTransactions(tx *bolt.Tx, addr types.UnlockHash) (txns []uint64, e
 	return
 }
 
+func dbPutAccount(tx *bolt.Tx, name string, height types.BlockHeight) error {
+	return dbPut(tx.Bucket(bucketAccounts), name, height)
+}
+func dbGetAccount(tx *bolt.Tx, name string) (height types.BlockHeight, err error) {
+	err = dbGet(tx.Bucket(bucketAccounts), name, &height)
+	return
+}
+func dbDeleteAccount(tx *bolt.Tx, name string) error {
+	return dbDelete(tx.Bucket(bucketAccounts), name)
+}
+func dbForEachAccount(tx *bolt.Tx, fn func(string, types.BlockHeight)) error {
+	return dbForEach(tx.Bucket(bucketAccounts), fn)
+}
+
+func dbPutAccountAddress(tx *bolt.Tx, addr types.UnlockHash, name string) error {
+	return dbPut(tx.Bucket(bucketAccountAddresses), addr, name)
+}
+func dbDeleteAccountAddress(tx *bolt.Tx, addr types.UnlockHash) error {
+	return dbDelete(tx.Bucket(bucketAccountAddresses), addr)
+}
+func dbForEachAccountAddress(tx *bolt.Tx, fn func(types.UnlockHash, string)) error {
+	return dbForEach(tx.Bucket(bucketAccountAddresses), fn)
+}
+
+func dbPutTimelockedAddress(tx *bolt.Tx, uc types.UnlockConditions) error {
+	return dbPut(tx.Bucket(bucketTimelockedAddresses), uc.UnlockHash(), uc)
+}
+func dbForEachTimelockedAddress(tx *bolt.Tx, fn func(types.UnlockHash, types.UnlockConditions)) error {
+	return dbForEach(tx.Bucket(bucketTimelockedAddresses), fn)
+}
+
+func dbPutMultisigAddress(tx *bolt.Tx, uc types.UnlockConditions) error {
+	return dbPut(tx.Bucket(bucketMultisigAddresses), uc.UnlockHash(), uc)
+}
+func dbForEachMultisigAddress(tx *bolt.Tx, fn func(types.UnlockHash, types.UnlockConditions)) error {
+	return dbForEach(tx.Bucket(bucketMultisigAddresses), fn)
+}
+
+func dbPutAddressLabel(tx *bolt.Tx, addr types.UnlockHash, name string) error {
+	return dbPut(tx.Bucket(bucketAddressLabels), addr, name)
+}
+func dbDeleteAddressLabel(tx *bolt.Tx, addr types.UnlockHash) error {
+	return dbDelete(tx.Bucket(bucketAddressLabels), addr)
+}
+func dbForEachAddressLabel(tx *bolt.Tx, fn func(types.UnlockHash, string)) error {
+	return dbForEach(tx.Bucket(bucketAddressLabels), fn)
+}
+
+func dbPutExchangeRate(tx *bolt.Tx, key exchangeRateKey, rate types.ExchangeRate) error {
+	return dbPut(tx.Bucket(bucketExchangeRates), key, rate)
+}
+func dbForEachExchangeRate(tx *bolt.Tx, fn func(exchangeRateKey, types.ExchangeRate)) error {
+	return dbForEach(tx.Bucket(bucketExchangeRates), fn)
+}
+
+func dbPutScheduledPayment(tx *bolt.Tx, sp modules.ScheduledPayment) error {
+	return dbPut(tx.Bucket(bucketScheduledPayments), sp.ID, sp)
+}
+func dbGetScheduledPayment(tx *bolt.Tx, id modules.ScheduledPaymentID) (sp modules.ScheduledPayment, err error) {
+	err = dbGet(tx.Bucket(bucketScheduledPayments), id, &sp)
+	return
+}
+func dbDeleteScheduledPayment(tx *bolt.Tx, id modules.ScheduledPaymentID) error {
+	return dbDelete(tx.Bucket(bucketScheduledPayments), id)
+}
+func dbForEachScheduledPayment(tx *bolt.Tx, fn func(modules.ScheduledPaymentID, modules.ScheduledPayment)) error {
+	return dbForEach(tx.Bucket(bucketScheduledPayments), fn)
+}
+
+func dbPutPendingScheduledPayment(tx *bolt.Tx, psp pendingScheduledPayment) error {
+	return dbPut(tx.Bucket(bucketPendingScheduledPayments), psp.Previous.ID, psp)
+}
+func dbDeletePendingScheduledPayment(tx *bolt.Tx, id modules.ScheduledPaymentID) error {
+	return dbDelete(tx.Bucket(bucketPendingScheduledPayments), id)
+}
+func dbForEachPendingScheduledPayment(tx *bolt.Tx, fn func(modules.ScheduledPaymentID, pendingScheduledPayment)) error {
+	return dbForEach(tx.Bucket(bucketPendingScheduledPayments), fn)
+}
+
+func dbPutTransactionLabel(tx *bolt.Tx, label modules.TransactionLabel) error {
+	return dbPut(tx.Bucket(bucketTransactionLabels), label.TransactionID, label)
+}
+func dbDeleteTransactionLabel(tx *bolt.Tx, txid types.TransactionID) error {
+	return dbDelete(tx.Bucket(bucketTransactionLabels), txid)
+}
+func dbForEachTransactionLabel(tx *bolt.Tx, fn func(types.TransactionID, modules.TransactionLabel)) error {
+	return dbForEach(tx.Bucket(bucketTransactionLabels), fn)
+}
+
 func dbPutUnlockConditions(tx *bolt.Tx, uc types.UnlockConditions) error {
 	return dbPut(tx.Bucket(bucketUnlockConditions), uc.UnlockHash(), uc)
 }
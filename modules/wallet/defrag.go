@@ -30,9 +30,22 @@ func (w *Wallet) managedCreateDefragTransaction() (_ []types.Transaction, err er
 		return nil, err
 	}
 
-	// Collect a value-sorted set of siacoin outputs.
+	// Collect a value-sorted set of siacoin outputs. Outputs of accounts are
+	// not defragged since the defrag transaction would move them out of the
+	// account. Outputs of multisig addresses can't be spent by the wallet
+	// alone.
+	accountAddrs, err := accountAddresses(w.dbTx)
+	if err != nil {
+		return nil, err
+	}
 	var so sortedOutputs
 	err = dbForEachSiacoinOutput(w.dbTx, func(scoid types.SiacoinOutputID, sco types.SiacoinOutput) {
+		if _, isAccount := accountAddrs[sco.UnlockHash]; isAccount {
+			return
+		}
+		if _, isMultisig := w.multisigAddrs[sco.UnlockHash]; isMultisig {
+			return
+		}
 		if w.checkOutput(w.dbTx, consensusHeight, scoid, sco, dustThreshold) == nil {
 			so.ids = append(so.ids, scoid)
 			so.outputs = append(so.outputs, sco)
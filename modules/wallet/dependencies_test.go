@@ -17,6 +17,13 @@ type (
 		modules.ProductionDependencies
 		f bool // indicates if the next call should fail
 	}
+
+	// dependencyScheduledPaymentInterrupted is a dependency used to cause
+	// sending a scheduled payment to stop after the payment was stored but
+	// before AcceptTransactionSet is called
+	dependencyScheduledPaymentInterrupted struct {
+		modules.ProductionDependencies
+	}
 )
 
 // Disrupt will return true if fail was called and the correct string value is
@@ -50,3 +57,8 @@ func (d *dependencyDefragInterrupted) Disrupt(s string) bool {
 func (d *dependencyDefragInterrupted) fail() {
 	d.f = true
 }
+
+// Disrupt will return true if the correct string value is provided.
+func (d *dependencyScheduledPaymentInterrupted) Disrupt(s string) bool {
+	return s == "ScheduledPaymentInterrupted"
+}
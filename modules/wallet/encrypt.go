@@ -276,11 +276,21 @@ func (w *Wallet) managedBlockingUnlock(masterKey crypto.CipherKey) (modules.Cons
 			w.integrateSpendableKey(masterKey, sk)
 		}
 
+		// timelockedAddresses
+		if err := w.integrateTimelockedAddresses(); err != nil {
+			return err
+		}
+
 		// watchedAddrs
 		for _, addr := range watchedAddrs {
 			w.watchedAddrs[addr] = struct{}{}
 		}
 
+		// multisigAddrs
+		if err := w.integrateMultisigAddresses(); err != nil {
+			return err
+		}
+
 		// COMPATv141 if the wallet password hasn't been encrypted yet using the seed,
 		// do it.
 		wpk := walletPasswordEncryptionKey(primarySeed, dbGetWalletSalt(w.dbTx))
@@ -333,6 +343,10 @@ func (w *Wallet) managedAsyncUnlock(lastChange modules.ConsensusChangeID) error
 			return fmt.Errorf("wallet subscription failed: %v", err)
 		}
 		w.tpool.TransactionPoolSubscribe(w)
+
+		// Resolve the scheduled payments which might not have been broadcast
+		// before the wallet was shut down.
+		w.managedReconcileScheduledPayments()
 	}
 	w.subscribed = true
 	return nil
@@ -452,6 +466,7 @@ func (w *Wallet) Reset() error {
 	w.wipeSecrets()
 	w.keys = make(map[types.UnlockHash]spendableKey)
 	w.lookahead = make(map[types.UnlockHash]uint64)
+	w.multisigAddrs = make(map[types.UnlockHash]types.UnlockConditions)
 	w.seeds = []modules.Seed{}
 	w.unconfirmedProcessedTransactions = []modules.ProcessedTransaction{}
 	w.unlocked = false
@@ -0,0 +1,153 @@
+package wallet
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+const (
+	// maxLabelLength is the maximum length in bytes of an address name, a
+	// transaction note or a transaction tag.
+	maxLabelLength = 1024
+)
+
+var (
+	// errLabelTooLong is returned if a name, note or tag exceeds
+	// maxLabelLength.
+	errLabelTooLong = errors.New("label exceeds the maximum length")
+)
+
+// normalizeTags trims the tags and removes empty and duplicate tags. The
+// remaining tags are sorted.
+func normalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]struct{})
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		if len(tag) > maxLabelLength {
+			return nil, errLabelTooLong
+		}
+		if _, exists := seen[tag]; exists {
+			continue
+		}
+		seen[tag] = struct{}{}
+		normalized = append(normalized, tag)
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+// AddressLabels returns the labels of all labelled addresses, sorted by
+// address.
+func (w *Wallet) AddressLabels() ([]modules.AddressLabel, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var labels []modules.AddressLabel
+	err := dbForEachAddressLabel(w.dbTx, func(addr types.UnlockHash, name string) {
+		labels = append(labels, modules.AddressLabel{
+			Address: addr,
+			Name:    name,
+		})
+	})
+	if err != nil {
+		return nil, errors.AddContext(err, "failed to read address labels")
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		return bytes.Compare(labels[i].Address[:], labels[j].Address[:]) < 0
+	})
+	return labels, nil
+}
+
+// SetAddressLabel sets the name of an address. An empty name removes the
+// label.
+func (w *Wallet) SetAddressLabel(addr types.UnlockHash, name string) error {
+	if err := w.tg.Add(); err != nil {
+		return modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	name = strings.TrimSpace(name)
+	if len(name) > maxLabelLength {
+		return errLabelTooLong
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var err error
+	if name == "" {
+		err = dbDeleteAddressLabel(w.dbTx, addr)
+	} else {
+		err = dbPutAddressLabel(w.dbTx, addr, name)
+	}
+	if err != nil {
+		return errors.AddContext(err, "failed to update address label")
+	}
+	return w.syncDB()
+}
+
+// SetTransactionLabel sets the note and the tags of a transaction. A label
+// without note and tags removes the label.
+func (w *Wallet) SetTransactionLabel(label modules.TransactionLabel) error {
+	if err := w.tg.Add(); err != nil {
+		return modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	label.Note = strings.TrimSpace(label.Note)
+	if len(label.Note) > maxLabelLength {
+		return errLabelTooLong
+	}
+	tags, err := normalizeTags(label.Tags)
+	if err != nil {
+		return err
+	}
+	label.Tags = tags
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if label.Note == "" && len(label.Tags) == 0 {
+		err = dbDeleteTransactionLabel(w.dbTx, label.TransactionID)
+	} else {
+		err = dbPutTransactionLabel(w.dbTx, label)
+	}
+	if err != nil {
+		return errors.AddContext(err, "failed to update transaction label")
+	}
+	return w.syncDB()
+}
+
+// TransactionLabels returns the labels of all labelled transactions, sorted
+// by transaction id.
+func (w *Wallet) TransactionLabels() ([]modules.TransactionLabel, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var labels []modules.TransactionLabel
+	err := dbForEachTransactionLabel(w.dbTx, func(_ types.TransactionID, label modules.TransactionLabel) {
+		labels = append(labels, label)
+	})
+	if err != nil {
+		return nil, errors.AddContext(err, "failed to read transaction labels")
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		return bytes.Compare(labels[i].TransactionID[:], labels[j].TransactionID[:]) < 0
+	})
+	return labels, nil
+}
@@ -0,0 +1,99 @@
+package wallet
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// TestLabels probes setting, removing and persisting address and transaction
+// labels.
+func TestLabels(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := wt.closeWt(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Label an address and a transaction.
+	addr := types.UnlockHash{1}
+	if err := wt.wallet.SetAddressLabel(addr, " alice "); err != nil {
+		t.Fatal(err)
+	}
+	txid := types.TransactionID{2}
+	label := modules.TransactionLabel{
+		TransactionID: txid,
+		Note:          "rent for march",
+		Tags:          []string{"rent", " expenses", "rent", ""},
+	}
+	if err := wt.wallet.SetTransactionLabel(label); err != nil {
+		t.Fatal(err)
+	}
+
+	// Labels that are too long should be rejected.
+	long := strings.Repeat("a", maxLabelLength+1)
+	if err := wt.wallet.SetAddressLabel(addr, long); !errors.Contains(err, errLabelTooLong) {
+		t.Fatal("expected errLabelTooLong, got", err)
+	}
+	if err := wt.wallet.SetTransactionLabel(modules.TransactionLabel{Tags: []string{long}}); !errors.Contains(err, errLabelTooLong) {
+		t.Fatal("expected errLabelTooLong, got", err)
+	}
+
+	// The labels should survive a restart.
+	if err := wt.wallet.Close(); err != nil {
+		t.Fatal(err)
+	}
+	wt.wallet, err = New(wt.cs, wt.tpool, filepath.Join(wt.persistDir, modules.WalletDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	addrLabels, err := wt.wallet.AddressLabels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(addrLabels, []modules.AddressLabel{{Address: addr, Name: "alice"}}) {
+		t.Fatal("wrong address labels", addrLabels)
+	}
+	txnLabels, err := wt.wallet.TransactionLabels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txnLabels) != 1 || txnLabels[0].Note != label.Note || !reflect.DeepEqual(txnLabels[0].Tags, []string{"expenses", "rent"}) {
+		t.Fatal("wrong transaction labels", txnLabels)
+	}
+	if !txnLabels[0].HasTag("rent") || txnLabels[0].HasTag("food") {
+		t.Fatal("HasTag returned the wrong result")
+	}
+
+	// Empty labels remove the labels.
+	if err := wt.wallet.SetAddressLabel(addr, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.wallet.SetTransactionLabel(modules.TransactionLabel{TransactionID: txid}); err != nil {
+		t.Fatal(err)
+	}
+	addrLabels, err = wt.wallet.AddressLabels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	txnLabels, err = wt.wallet.TransactionLabels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(addrLabels) != 0 || len(txnLabels) != 0 {
+		t.Fatal("labels weren't removed", addrLabels, txnLabels)
+	}
+}
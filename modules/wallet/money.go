@@ -31,7 +31,8 @@ func (w *Wallet) DustThreshold() (types.Currency, error) {
 }
 
 // ConfirmedBalance returns the balance of the wallet according to all of the
-// confirmed transactions.
+// confirmed transactions. Outputs of time-locked addresses are excluded until
+// their unlock height is reached.
 func (w *Wallet) ConfirmedBalance() (siacoinBalance types.Currency, siafundBalance types.Currency, siafundClaimBalance types.Currency, err error) {
 	if err := w.tg.Add(); err != nil {
 		return types.ZeroCurrency, types.ZeroCurrency, types.ZeroCurrency, modules.ErrWalletShutdown
@@ -52,7 +53,21 @@ func (w *Wallet) ConfirmedBalance() (siacoinBalance types.Currency, siafundBalan
 		return
 	}
 
+	// outputs of time-locked addresses are pending unlock and not part of
+	// the confirmed balance until their timelock has passed. Outputs of
+	// multisig addresses are reported separately.
+	locked, err := lockedAddresses(w.dbTx)
+	if err != nil {
+		return
+	}
+
 	dbForEachSiacoinOutput(w.dbTx, func(_ types.SiacoinOutputID, sco types.SiacoinOutput) {
+		if _, ok := locked[sco.UnlockHash]; ok {
+			return
+		}
+		if _, ok := w.multisigAddrs[sco.UnlockHash]; ok {
+			return
+		}
 		if sco.Value.Cmp(dustThreshold) > 0 {
 			siacoinBalance = siacoinBalance.Add(sco.Value)
 		}
@@ -63,6 +78,12 @@ func (w *Wallet) ConfirmedBalance() (siacoinBalance types.Currency, siafundBalan
 		return
 	}
 	dbForEachSiafundOutput(w.dbTx, func(_ types.SiafundOutputID, sfo types.SiafundOutput) {
+		if _, ok := locked[sfo.UnlockHash]; ok {
+			return
+		}
+		if _, ok := w.multisigAddrs[sfo.UnlockHash]; ok {
+			return
+		}
 		siafundBalance = siafundBalance.Add(sfo.Value)
 		if sfo.ClaimStart.Cmp(siafundPool) > 0 {
 			// Skip claims larger than the siafund pool. This should only
@@ -112,21 +133,22 @@ func (w *Wallet) UnconfirmedBalance() (outgoingSiacoins types.Currency, incoming
 // transaction is submitted to the transaction pool and is also returned. Fees
 // are added to the amount sent.
 func (w *Wallet) SendSiacoins(amount types.Currency, dest types.UnlockHash) ([]types.Transaction, error) {
-	if err := w.tg.Add(); err != nil {
-		err = modules.ErrWalletShutdown
-		return nil, err
-	}
-	defer w.tg.Done()
-
-	_, fee := w.tpool.FeeEstimation()
-	fee = fee.Mul64(estimatedTransactionSize)
-	return w.managedSendSiacoins(amount, fee, dest)
+	return w.SendSiacoinsCoinControl(amount, dest, false, modules.CoinControl{})
 }
 
 // SendSiacoinsFeeIncluded creates a transaction sending 'amount' to 'dest'. The
 // transaction is submitted to the transaction pool and is also returned. Fees
 // are subtracted from the amount sent.
 func (w *Wallet) SendSiacoinsFeeIncluded(amount types.Currency, dest types.UnlockHash) ([]types.Transaction, error) {
+	return w.SendSiacoinsCoinControl(amount, dest, true, modules.CoinControl{})
+}
+
+// SendSiacoinsCoinControl creates a transaction sending 'amount' to 'dest'
+// which is only funded by the outputs allowed by the CoinControl. The
+// transaction is submitted to the transaction pool and is also returned. If
+// 'feeIncluded' is set, fees are subtracted from the amount sent. Otherwise
+// they are added to it.
+func (w *Wallet) SendSiacoinsCoinControl(amount types.Currency, dest types.UnlockHash, feeIncluded bool, cc modules.CoinControl) ([]types.Transaction, error) {
 	if err := w.tg.Add(); err != nil {
 		err = modules.ErrWalletShutdown
 		return nil, err
@@ -135,21 +157,52 @@ func (w *Wallet) SendSiacoinsFeeIncluded(amount types.Currency, dest types.Unloc
 
 	_, fee := w.tpool.FeeEstimation()
 	fee = fee.Mul64(estimatedTransactionSize)
+	if !feeIncluded {
+		return w.managedSendSiacoins(amount, fee, dest, cc)
+	}
 	// Don't allow sending an amount equal to the fee, as zero spending is not
 	// allowed and would error out later.
 	if amount.Cmp(fee) <= 0 {
 		w.log.Println("Attempt to send coins has failed - not enough to cover fee")
 		return nil, errors.AddContext(modules.ErrLowBalance, "not enough coins to cover fee")
 	}
-	return w.managedSendSiacoins(amount.Sub(fee), fee, dest)
+	return w.managedSendSiacoins(amount.Sub(fee), fee, dest, cc)
 }
 
 // managedSendSiacoins creates a transaction sending 'amount' to 'dest'. The
 // transaction is submitted to the transaction pool and is also returned.
-func (w *Wallet) managedSendSiacoins(amount, fee types.Currency, dest types.UnlockHash) (txns []types.Transaction, err error) {
+func (w *Wallet) managedSendSiacoins(amount, fee types.Currency, dest types.UnlockHash, cc modules.CoinControl) (txns []types.Transaction, err error) {
+	txnSet, txnBuilder, err := w.managedSignSiacoins(amount, fee, dest, cc)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			txnBuilder.Drop()
+		}
+	}()
+	if w.deps.Disrupt("SendSiacoinsInterrupted") {
+		return nil, errors.New("failed to accept transaction set (SendSiacoinsInterrupted)")
+	}
+	err = w.tpool.AcceptTransactionSet(txnSet)
+	if err != nil {
+		w.log.Println("Attempt to send coins has failed - transaction pool rejected transaction:", err)
+		return nil, build.ExtendErr("unable to get transaction accepted", err)
+	}
+	w.log.Println("Submitted a siacoin transfer transaction set for value", amount.HumanString(), "with fees", fee.HumanString(), "IDs:")
+	for _, txn := range txnSet {
+		w.log.Println("\t", txn.ID())
+	}
+	return txnSet, nil
+}
+
+// managedSignSiacoins creates and signs a transaction sending 'amount' to
+// 'dest' without submitting it to the transaction pool. The caller has to drop
+// the returned transaction builder if the transaction isn't broadcast.
+func (w *Wallet) managedSignSiacoins(amount, fee types.Currency, dest types.UnlockHash, cc modules.CoinControl) (txnSet []types.Transaction, txnBuilder modules.TransactionBuilder, err error) {
 	// Check if consensus is synced
 	if !w.cs.Synced() || w.deps.Disrupt("UnsyncedConsensus") {
-		return nil, errors.New("cannot send siacoin until fully synced")
+		return nil, nil, errors.New("cannot send siacoin until fully synced")
 	}
 
 	w.mu.RLock()
@@ -157,7 +210,7 @@ func (w *Wallet) managedSendSiacoins(amount, fee types.Currency, dest types.Unlo
 	w.mu.RUnlock()
 	if !unlocked {
 		w.log.Println("Attempt to send coins has failed - wallet is locked")
-		return nil, modules.ErrLockedWallet
+		return nil, nil, modules.ErrLockedWallet
 	}
 
 	output := types.SiacoinOutput{
@@ -165,46 +218,39 @@ func (w *Wallet) managedSendSiacoins(amount, fee types.Currency, dest types.Unlo
 		UnlockHash: dest,
 	}
 
-	txnBuilder, err := w.StartTransaction()
+	txnBuilder, err = w.StartTransaction()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	defer func() {
-		if err != nil {
-			txnBuilder.Drop()
-		}
-	}()
-	err = txnBuilder.FundSiacoins(amount.Add(fee))
+	err = txnBuilder.FundSiacoinsCoinControl(amount.Add(fee), cc)
 	if err != nil {
+		txnBuilder.Drop()
 		w.log.Println("Attempt to send coins has failed - failed to fund transaction:", err)
-		return nil, build.ExtendErr("unable to fund transaction", err)
+		return nil, nil, build.ExtendErr("unable to fund transaction", err)
 	}
 	txnBuilder.AddMinerFee(fee)
 	txnBuilder.AddSiacoinOutput(output)
-	txnSet, err := txnBuilder.Sign(true)
+	txnSet, err = txnBuilder.Sign(true)
 	if err != nil {
+		txnBuilder.Drop()
 		w.log.Println("Attempt to send coins has failed - failed to sign transaction:", err)
-		return nil, build.ExtendErr("unable to sign transaction", err)
+		return nil, nil, build.ExtendErr("unable to sign transaction", err)
 	}
-	if w.deps.Disrupt("SendSiacoinsInterrupted") {
-		return nil, errors.New("failed to accept transaction set (SendSiacoinsInterrupted)")
-	}
-	err = w.tpool.AcceptTransactionSet(txnSet)
-	if err != nil {
-		w.log.Println("Attempt to send coins has failed - transaction pool rejected transaction:", err)
-		return nil, build.ExtendErr("unable to get transaction accepted", err)
-	}
-	w.log.Println("Submitted a siacoin transfer transaction set for value", amount.HumanString(), "with fees", fee.HumanString(), "IDs:")
-	for _, txn := range txnSet {
-		w.log.Println("\t", txn.ID())
-	}
-	return txnSet, nil
+	return txnSet, txnBuilder, nil
 }
 
 // SendSiacoinsMulti creates a transaction that includes the specified
 // outputs. The transaction is submitted to the transaction pool and is also
 // returned.
-func (w *Wallet) SendSiacoinsMulti(outputs []types.SiacoinOutput) (txns []types.Transaction, err error) {
+func (w *Wallet) SendSiacoinsMulti(outputs []types.SiacoinOutput) ([]types.Transaction, error) {
+	return w.SendSiacoinsMultiCoinControl(outputs, modules.CoinControl{})
+}
+
+// SendSiacoinsMultiCoinControl creates a transaction that includes the
+// specified outputs and is only funded by the outputs allowed by the
+// CoinControl. The transaction is submitted to the transaction pool and is
+// also returned.
+func (w *Wallet) SendSiacoinsMultiCoinControl(outputs []types.SiacoinOutput, cc modules.CoinControl) (txns []types.Transaction, err error) {
 	if err := w.tg.Add(); err != nil {
 		err = modules.ErrWalletShutdown
 		return nil, err
@@ -250,7 +296,7 @@ func (w *Wallet) SendSiacoinsMulti(outputs []types.SiacoinOutput) (txns []types.
 	for _, sco := range outputs {
 		totalCost = totalCost.Add(sco.Value)
 	}
-	err = txnBuilder.FundSiacoins(totalCost)
+	err = txnBuilder.FundSiacoinsCoinControl(totalCost, cc)
 	if err != nil {
 		return nil, build.ExtendErr("unable to fund transaction", err)
 	}
@@ -354,6 +400,14 @@ func (so sortedOutputs) Len() int {
 	return len(so.ids)
 }
 
+// total returns the total value of the outputs.
+func (so sortedOutputs) total() (total types.Currency) {
+	for _, sco := range so.outputs {
+		total = total.Add(sco.Value)
+	}
+	return
+}
+
 // Less returns whether element 'i' is less than element 'j'. The currency
 // value of each output is used for comparison.
 func (so sortedOutputs) Less(i, j int) bool {
@@ -0,0 +1,226 @@
+package wallet
+
+import (
+	"bytes"
+	"sort"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+var (
+	// errDuplicatePublicKey is returned if a multisig address contains the
+	// same public key multiple times.
+	errDuplicatePublicKey = errors.New("public keys of a multisig address must be unique")
+
+	// errInvalidSignaturesRequired is returned if the number of required
+	// signatures of a multisig address is out of range.
+	errInvalidSignaturesRequired = errors.New("signatures required must be between 1 and the number of public keys")
+
+	// errNoWalletKey is returned if none of the public keys of a multisig
+	// address belong to the wallet.
+	errNoWalletKey = errors.New("none of the public keys belong to the wallet")
+)
+
+// findSecretKey returns the secret key for a public key from the keys. Keys
+// which are part of multisig unlock conditions are found using the standard
+// unlock conditions of the wallet's single key addresses.
+func findSecretKey(keys map[types.UnlockHash]spendableKey, uc types.UnlockConditions, pk types.SiaPublicKey) (crypto.SecretKey, bool) {
+	sk, ok := keys[uc.UnlockHash()]
+	if !ok {
+		sk, ok = keys[types.UnlockConditions{
+			PublicKeys:         []types.SiaPublicKey{pk},
+			SignaturesRequired: 1,
+		}.UnlockHash()]
+	}
+	if !ok {
+		return crypto.SecretKey{}, false
+	}
+	for _, key := range sk.SecretKeys {
+		pubKey := key.PublicKey()
+		if bytes.Equal(pk.Key, pubKey[:]) {
+			return key, true
+		}
+	}
+	return crypto.SecretKey{}, false
+}
+
+// ownedKeyIndices returns the indices of the public keys of the unlock
+// conditions which belong to the wallet.
+func (w *Wallet) ownedKeyIndices(uc types.UnlockConditions) (indices []uint64) {
+	for i, pk := range uc.PublicKeys {
+		if _, ok := findSecretKey(w.keys, uc, pk); ok {
+			indices = append(indices, uint64(i))
+		}
+	}
+	return indices
+}
+
+// integrateMultisigAddresses loads the multisig addresses of the wallet.
+func (w *Wallet) integrateMultisigAddresses() error {
+	err := dbForEachMultisigAddress(w.dbTx, func(addr types.UnlockHash, uc types.UnlockConditions) {
+		w.multisigAddrs[addr] = uc
+	})
+	if err != nil {
+		return errors.AddContext(err, "failed to read multisig addresses")
+	}
+	return nil
+}
+
+// signingKey returns the key which signs the inputs spending outputs of the
+// address. Outputs of multisig addresses can only be signed if the wallet owns
+// enough of their keys.
+func (w *Wallet) signingKey(uh types.UnlockHash) (spendableKey, bool) {
+	if sk, ok := w.keys[uh]; ok {
+		return sk, true
+	}
+	if uc, ok := w.multisigAddrs[uh]; ok {
+		return w.unlockConditionsKey(uc)
+	}
+	return spendableKey{}, false
+}
+
+// AddMultisigAddress creates the unlock conditions of an M-of-N multisig
+// address from a set of public keys, at least one of which has to belong to
+// the wallet. The outputs of the address are tracked separately from the
+// wallet's balance and are only spent if they are selected explicitly. The
+// wallet signs their inputs with its own keys. If unused is false, the wallet
+// rescans the blockchain.
+func (w *Wallet) AddMultisigAddress(publicKeys []types.SiaPublicKey, signaturesRequired uint64, unused bool) (types.UnlockConditions, error) {
+	if err := w.tg.Add(); err != nil {
+		return types.UnlockConditions{}, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	if signaturesRequired == 0 || signaturesRequired > uint64(len(publicKeys)) {
+		return types.UnlockConditions{}, errInvalidSignaturesRequired
+	}
+	seen := make(map[string]struct{})
+	for _, pk := range publicKeys {
+		if _, exists := seen[pk.String()]; exists {
+			return types.UnlockConditions{}, errDuplicatePublicKey
+		}
+		seen[pk.String()] = struct{}{}
+	}
+	uc := types.UnlockConditions{
+		PublicKeys:         append([]types.SiaPublicKey(nil), publicKeys...),
+		SignaturesRequired: signaturesRequired,
+	}
+
+	w.mu.RLock()
+	unlocked := w.unlocked
+	owned := w.ownedKeyIndices(uc)
+	w.mu.RUnlock()
+	if !unlocked {
+		return types.UnlockConditions{}, modules.ErrLockedWallet
+	}
+	if len(owned) == 0 {
+		return types.UnlockConditions{}, errNoWalletKey
+	}
+	if err := w.AddUnlockConditions(uc); err != nil {
+		return types.UnlockConditions{}, errors.AddContext(err, "failed to add unlock conditions")
+	}
+
+	err := func() error {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if err := dbPutMultisigAddress(w.dbTx, uc); err != nil {
+			return errors.AddContext(err, "failed to store multisig address")
+		}
+		w.multisigAddrs[uc.UnlockHash()] = uc
+
+		if !unused {
+			// prepare to rescan
+			if err := w.dbTx.DeleteBucket(bucketProcessedTransactions); err != nil {
+				return err
+			}
+			if _, err := w.dbTx.CreateBucket(bucketProcessedTransactions); err != nil {
+				return err
+			}
+			w.unconfirmedProcessedTransactions = nil
+			if err := dbPutConsensusChangeID(w.dbTx, modules.ConsensusChangeBeginning); err != nil {
+				return err
+			}
+			if err := dbPutConsensusHeight(w.dbTx, 0); err != nil {
+				return err
+			}
+		}
+		return w.syncDB()
+	}()
+	if err != nil {
+		return types.UnlockConditions{}, err
+	}
+	w.log.Printf("Added %v-of-%v multisig address %v", signaturesRequired, len(publicKeys), uc.UnlockHash())
+
+	if !unused {
+		// rescan the blockchain
+		w.cs.Unsubscribe(w)
+		w.tpool.Unsubscribe(w)
+
+		done := make(chan struct{})
+		go w.rescanMessage(done)
+		defer close(done)
+		if err := w.cs.ConsensusSetSubscribe(w, modules.ConsensusChangeBeginning, w.tg.StopChan()); err != nil {
+			return types.UnlockConditions{}, err
+		}
+		w.tpool.TransactionPoolSubscribe(w)
+	}
+	return uc, nil
+}
+
+// MultisigAddresses returns the unlock conditions of the multisig addresses
+// which contain a key of the wallet.
+func (w *Wallet) MultisigAddresses() ([]types.UnlockConditions, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if !w.unlocked {
+		return nil, modules.ErrLockedWallet
+	}
+	ucs := make([]types.UnlockConditions, 0, len(w.multisigAddrs))
+	for _, uc := range w.multisigAddrs {
+		ucs = append(ucs, uc)
+	}
+	sort.Slice(ucs, func(i, j int) bool {
+		uhi, uhj := ucs[i].UnlockHash(), ucs[j].UnlockHash()
+		return bytes.Compare(uhi[:], uhj[:]) < 0
+	})
+	return ucs, nil
+}
+
+// MultisigBalance returns the confirmed siacoin and siafund balance of the
+// wallet's multisig addresses. It is not part of the confirmed balance since
+// spending it usually requires the signatures of other parties.
+func (w *Wallet) MultisigBalance() (siacoinBalance, siafundBalance types.Currency, err error) {
+	if err := w.tg.Add(); err != nil {
+		return types.ZeroCurrency, types.ZeroCurrency, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	err = dbForEachSiacoinOutput(w.dbTx, func(_ types.SiacoinOutputID, sco types.SiacoinOutput) {
+		if _, ok := w.multisigAddrs[sco.UnlockHash]; ok {
+			siacoinBalance = siacoinBalance.Add(sco.Value)
+		}
+	})
+	if err != nil {
+		return types.ZeroCurrency, types.ZeroCurrency, err
+	}
+	err = dbForEachSiafundOutput(w.dbTx, func(_ types.SiafundOutputID, sfo types.SiafundOutput) {
+		if _, ok := w.multisigAddrs[sfo.UnlockHash]; ok {
+			siafundBalance = siafundBalance.Add(sfo.Value)
+		}
+	})
+	if err != nil {
+		return types.ZeroCurrency, types.ZeroCurrency, err
+	}
+	return siacoinBalance, siafundBalance, nil
+}
@@ -0,0 +1,332 @@
+package wallet
+
+import (
+	"strings"
+	"testing"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// TestMultisigAddress probes creating a 2-of-2 multisig address with a
+// partner and spending from it.
+func TestMultisigAddress(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := wt.closeWt(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	uc, err := wt.wallet.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ours := uc.PublicKeys[0]
+	partnerSK, partnerPK := crypto.GenerateKeyPair()
+	partner := types.Ed25519PublicKey(partnerPK)
+
+	// Invalid multisig addresses should be rejected.
+	_, err = wt.wallet.AddMultisigAddress([]types.SiaPublicKey{ours, partner}, 3, true)
+	if !errors.Contains(err, errInvalidSignaturesRequired) {
+		t.Fatal("expected errInvalidSignaturesRequired, got", err)
+	}
+	_, err = wt.wallet.AddMultisigAddress([]types.SiaPublicKey{ours, ours}, 2, true)
+	if !errors.Contains(err, errDuplicatePublicKey) {
+		t.Fatal("expected errDuplicatePublicKey, got", err)
+	}
+	_, err = wt.wallet.AddMultisigAddress([]types.SiaPublicKey{partner}, 1, true)
+	if !errors.Contains(err, errNoWalletKey) {
+		t.Fatal("expected errNoWalletKey, got", err)
+	}
+
+	// Create the address and fund it.
+	msuc, err := wt.wallet.AddMultisigAddress([]types.SiaPublicKey{partner, ours}, 2, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ucs, err := wt.wallet.MultisigAddresses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ucs) != 1 || ucs[0].UnlockHash() != msuc.UnlockHash() {
+		t.Fatal("multisig address isn't tracked", ucs)
+	}
+	addr := msuc.UnlockHash()
+	if _, err := wt.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(10), addr); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The balance of the address should be reported separately.
+	dustThreshold, err := wt.wallet.DustThreshold()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total types.Currency
+	wt.wallet.mu.Lock()
+	err = dbForEachSiacoinOutput(wt.wallet.dbTx, func(_ types.SiacoinOutputID, sco types.SiacoinOutput) {
+		if sco.Value.Cmp(dustThreshold) > 0 {
+			total = total.Add(sco.Value)
+		}
+	})
+	wt.wallet.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	balance, _, _, err := wt.wallet.ConfirmedBalance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !balance.Equals(total.Sub(types.SiacoinPrecision.Mul64(10))) {
+		t.Fatal("multisig output is part of the confirmed balance", balance, total)
+	}
+	multisigBalance, _, err := wt.wallet.MultisigBalance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !multisigBalance.Equals(types.SiacoinPrecision.Mul64(10)) {
+		t.Fatal("wrong multisig balance", multisigBalance)
+	}
+	outputs, err := wt.wallet.UnspentOutputs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var output modules.UnspentOutput
+	for _, uo := range outputs {
+		if uo.UnlockHash == addr {
+			output = uo
+		}
+	}
+	if output.Value.IsZero() {
+		t.Fatal("output of multisig address isn't tracked")
+	}
+
+	// The output can't be used for funding since the wallet can't sign it on
+	// its own.
+	cc := modules.CoinControl{Include: []types.SiacoinOutputID{types.SiacoinOutputID(output.ID)}}
+	_, err = wt.wallet.SendSiacoinsCoinControl(types.SiacoinPrecision, types.UnlockHash{}, false, cc)
+	if err == nil || !strings.Contains(err.Error(), errInsufficientWalletKeys.Error()) {
+		t.Fatal("expected errInsufficientWalletKeys, got", err)
+	}
+
+	// The wallet should supply its signature automatically.
+	txn := types.Transaction{
+		SiacoinInputs: []types.SiacoinInput{{
+			ParentID:         types.SiacoinOutputID(output.ID),
+			UnlockConditions: msuc,
+		}},
+		SiacoinOutputs: []types.SiacoinOutput{{
+			Value:      output.Value,
+			UnlockHash: types.UnlockHash{},
+		}},
+	}
+	if err := wt.wallet.SignTransaction(&txn, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(txn.TransactionSignatures) != 1 || txn.TransactionSignatures[0].PublicKeyIndex != 1 {
+		t.Fatal("expected a signature for our key", txn.TransactionSignatures)
+	}
+
+	// Add the partner's signature and submit the transaction.
+	height, err := wt.wallet.Height()
+	if err != nil {
+		t.Fatal(err)
+	}
+	txn.TransactionSignatures = append(txn.TransactionSignatures, types.TransactionSignature{
+		ParentID:       crypto.Hash(output.ID),
+		CoveredFields:  types.FullCoveredFields,
+		PublicKeyIndex: 0,
+	})
+	sig := crypto.SignHash(txn.SigHash(1, height), partnerSK)
+	txn.TransactionSignatures[1].Signature = sig[:]
+	if err := txn.StandaloneValid(height); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.tpool.AcceptTransactionSet([]types.Transaction{txn}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Signing an input which already has enough signatures shouldn't add
+	// more signatures.
+	if err := wt.wallet.SignTransaction(&txn, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(txn.TransactionSignatures) != 2 {
+		t.Fatal("frivolous signature was added")
+	}
+}
+
+// TestPartiallySignedMultisig tests spending from a 2-of-3 multisig address
+// using a partially signed transaction which is signed by the wallet and a
+// partner.
+func TestPartiallySignedMultisig(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := wt.closeWt(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Create a 2-of-3 address with two partners and fund it.
+	uc, err := wt.wallet.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	partnerSK, partnerPK := crypto.GenerateKeyPair()
+	_, otherPK := crypto.GenerateKeyPair()
+	msuc, err := wt.wallet.AddMultisigAddress([]types.SiaPublicKey{types.Ed25519PublicKey(partnerPK), types.Ed25519PublicKey(otherPK), uc.PublicKeys[0]}, 2, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txns, err := wt.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(10), msuc.UnlockHash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+	var parentID types.SiacoinOutputID
+	var value types.Currency
+	for i, sco := range txns[len(txns)-1].SiacoinOutputs {
+		if sco.UnlockHash == msuc.UnlockHash() {
+			parentID, value = txns[len(txns)-1].SiacoinOutputID(uint64(i)), sco.Value
+		}
+	}
+	txn := types.Transaction{
+		SiacoinInputs: []types.SiacoinInput{{
+			ParentID:         parentID,
+			UnlockConditions: msuc,
+		}},
+		SiacoinOutputs: []types.SiacoinOutput{{
+			Value:      value,
+			UnlockHash: types.UnlockHash{},
+		}},
+	}
+	height, err := wt.wallet.Height()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The wallet should only sign with its own key.
+	pst, err := modules.NewPartiallySignedTransaction(txn, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	added, err := pst.Sign(wt.wallet.SignTransaction, height)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added != 1 || pst.Complete() || pst.Inputs[0].Signatures[0].PublicKeyIndex != 2 {
+		t.Fatal("expected a signature for the wallet's key", added, pst.Inputs[0].Signatures)
+	}
+
+	// Sign with the partner's key and merge the signatures.
+	partner, err := modules.NewPartiallySignedTransaction(txn, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = partner.Sign(func(txn *types.Transaction, _ []crypto.Hash) error {
+		if txn.TransactionSignatures[0].PublicKeyIndex != 0 {
+			return modules.ErrUnknownSigningKey
+		}
+		sig := crypto.SignHash(txn.SigHash(0, height), partnerSK)
+		txn.TransactionSignatures[0].Signature = sig[:]
+		return nil
+	}, height)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pst.Merge(partner, height); err != nil {
+		t.Fatal(err)
+	}
+	set, err := pst.Finalize(height)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.tpool.AcceptTransactionSet(set); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestMultisigFunding checks that outputs of multisig addresses are only used
+// for funding if they are included explicitly.
+func TestMultisigFunding(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := wt.closeWt(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Create a 1-of-2 address which the wallet can spend on its own and fund
+	// it.
+	uc, err := wt.wallet.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, partnerPK := crypto.GenerateKeyPair()
+	msuc, err := wt.wallet.AddMultisigAddress([]types.SiaPublicKey{uc.PublicKeys[0], types.Ed25519PublicKey(partnerPK)}, 1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txns, err := wt.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(10), msuc.UnlockHash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+	var outputID types.SiacoinOutputID
+	for i, sco := range txns[len(txns)-1].SiacoinOutputs {
+		if sco.UnlockHash == msuc.UnlockHash() {
+			outputID = txns[len(txns)-1].SiacoinOutputID(uint64(i))
+		}
+	}
+
+	// Funding without coin control shouldn't spend the output.
+	txns, err = wt.wallet.SendSiacoins(types.SiacoinPrecision, types.UnlockHash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, txn := range txns {
+		for _, sci := range txn.SiacoinInputs {
+			if sci.ParentID == outputID {
+				t.Fatal("multisig output was spent without being included")
+			}
+		}
+	}
+
+	// Including the output should spend it.
+	cc := modules.CoinControl{Include: []types.SiacoinOutputID{outputID}}
+	txns, err = wt.wallet.SendSiacoinsCoinControl(types.SiacoinPrecision, types.UnlockHash{}, false, cc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if txns[0].SiacoinInputs[0].ParentID != outputID || txns[0].SiacoinInputs[0].UnlockConditions.UnlockHash() != msuc.UnlockHash() {
+		t.Fatal("included multisig output wasn't spent", txns[0].SiacoinInputs)
+	}
+}
@@ -1,10 +1,10 @@
 package wallet
 
 import (
-	"bytes"
-	"errors"
 	"math"
 
+	"gitlab.com/NebulousLabs/errors"
+
 	"go.sia.tech/siad/crypto"
 	"go.sia.tech/siad/modules"
 	"go.sia.tech/siad/types"
@@ -169,12 +169,16 @@ func (w *Wallet) SignTransaction(txn *types.Transaction, toSign []crypto.Hash) e
 	// if toSign is empty, sign all inputs that we have keys for
 	if len(toSign) == 0 {
 		for _, sci := range txn.SiacoinInputs {
-			if _, ok := w.keys[sci.UnlockConditions.UnlockHash()]; ok {
+			if w.addMultisigSignatures(txn, crypto.Hash(sci.ParentID), sci.UnlockConditions) {
+				toSign = append(toSign, crypto.Hash(sci.ParentID))
+			} else if _, ok := w.keys[sci.UnlockConditions.UnlockHash()]; ok {
 				toSign = append(toSign, crypto.Hash(sci.ParentID))
 			}
 		}
 		for _, sfi := range txn.SiafundInputs {
-			if _, ok := w.keys[sfi.UnlockConditions.UnlockHash()]; ok {
+			if w.addMultisigSignatures(txn, crypto.Hash(sfi.ParentID), sfi.UnlockConditions) {
+				toSign = append(toSign, crypto.Hash(sfi.ParentID))
+			} else if _, ok := w.keys[sfi.UnlockConditions.UnlockHash()]; ok {
 				toSign = append(toSign, crypto.Hash(sfi.ParentID))
 			}
 		}
@@ -182,6 +186,41 @@ func (w *Wallet) SignTransaction(txn *types.Transaction, toSign []crypto.Hash) e
 	return signTransaction(txn, w.keys, toSign, consensusHeight)
 }
 
+// addMultisigSignatures adds unsigned TransactionSignatures for the wallet's
+// keys to an input of a multisig address, unless the input already has
+// enough signatures. It returns whether the input has a signature which the
+// wallet can sign.
+func (w *Wallet) addMultisigSignatures(txn *types.Transaction, parentID crypto.Hash, uc types.UnlockConditions) bool {
+	if _, ok := w.keys[uc.UnlockHash()]; ok {
+		return false
+	}
+	owned := w.ownedKeyIndices(uc)
+	if len(owned) == 0 {
+		return false
+	}
+	existing := make(map[uint64]struct{})
+	for _, sig := range txn.TransactionSignatures {
+		if sig.ParentID == parentID {
+			existing[sig.PublicKeyIndex] = struct{}{}
+		}
+	}
+	for _, i := range owned {
+		if uint64(len(existing)) >= uc.SignaturesRequired {
+			break
+		}
+		if _, exists := existing[i]; exists {
+			continue
+		}
+		txn.TransactionSignatures = append(txn.TransactionSignatures, types.TransactionSignature{
+			ParentID:       parentID,
+			CoveredFields:  types.FullCoveredFields,
+			PublicKeyIndex: i,
+		})
+		existing[i] = struct{}{}
+	}
+	return true
+}
+
 // SignTransaction signs txn using secret keys derived from seed. The
 // transaction should be complete with the exception of the Signature fields
 // of each TransactionSignature referenced by toSign, which must not be empty.
@@ -235,51 +274,45 @@ func signTransaction(txn *types.Transaction, keys map[types.UnlockHash]spendable
 		if pubkeyIndex >= uint64(len(uc.PublicKeys)) {
 			return crypto.SecretKey{}, false
 		}
-		pk := uc.PublicKeys[pubkeyIndex]
-		sk, ok := keys[uc.UnlockHash()]
-		if !ok {
-			return crypto.SecretKey{}, false
-		}
-		for _, key := range sk.SecretKeys {
-			pubKey := key.PublicKey()
-			if bytes.Equal(pk.Key, pubKey[:]) {
-				return key, true
-			}
-		}
-		return crypto.SecretKey{}, false
+		return findSecretKey(keys, uc, uc.PublicKeys[pubkeyIndex])
 	}
 
 	for _, id := range toSign {
-		// find associated txn signature
-		sigIndex := -1
-		for i, sig := range txn.TransactionSignatures {
-			if sig.ParentID == id {
-				sigIndex = i
-				break
+		// find associated input
+		uc, ok := findUnlockConditions(id)
+		// sign every associated txn signature we have a key for. Multisig
+		// inputs might contain signatures of other parties.
+		found, signed := false, false
+		for sigIndex, sig := range txn.TransactionSignatures {
+			if sig.ParentID != id {
+				continue
+			}
+			found = true
+			if !ok {
+				return errors.New("toSign references IDs not present in transaction")
+			}
+			// lookup the signing key
+			sk, ok := findSigningKey(uc, sig.PublicKeyIndex)
+			if !ok {
+				continue
 			}
+			// add signature
+			//
+			// NOTE: it's possible that the Signature field will already be
+			// filled out. Although we could save a bit of work by not signing
+			// it, in practice it's probably best to overwrite any existing
+			// signatures, since we know that ours will be valid.
+			sigHash := txn.SigHash(sigIndex, height)
+			encodedSig := crypto.SignHash(sigHash, sk)
+			txn.TransactionSignatures[sigIndex].Signature = encodedSig[:]
+			signed = true
 		}
-		if sigIndex == -1 {
+		if !found {
 			return errors.New("toSign references signatures not present in transaction")
 		}
-		// find associated input
-		uc, ok := findUnlockConditions(id)
-		if !ok {
-			return errors.New("toSign references IDs not present in transaction")
-		}
-		// lookup the signing key
-		sk, ok := findSigningKey(uc, txn.TransactionSignatures[sigIndex].PublicKeyIndex)
-		if !ok {
-			return errors.New("could not locate signing key for " + id.String())
+		if !signed {
+			return errors.AddContext(modules.ErrUnknownSigningKey, id.String())
 		}
-		// add signature
-		//
-		// NOTE: it's possible that the Signature field will already be filled
-		// out. Although we could save a bit of work by not signing it, in
-		// practice it's probably best to overwrite any existing signatures,
-		// since we know that ours will be valid.
-		sigHash := txn.SigHash(sigIndex, height)
-		encodedSig := crypto.SignHash(sigHash, sk)
-		txn.TransactionSignatures[sigIndex].Signature = encodedSig[:]
 	}
 
 	return nil
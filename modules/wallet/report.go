@@ -0,0 +1,318 @@
+package wallet
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+var (
+	// errInvalidExchangeRate is returned if an exchange rate point without a
+	// rate is added.
+	errInvalidExchangeRate = errors.New("exchange rate point has no rate")
+
+	// errInvalidReportRange is returned if the end of a fiat report is before
+	// its start.
+	errInvalidReportRange = errors.New("report end must not be before its start")
+
+	// errNoReportSymbol is returned if a fiat report is requested without a
+	// currency symbol.
+	errNoReportSymbol = errors.New("no currency symbol specified")
+)
+
+type (
+	// exchangeRateKey is the key of an exchange rate point in the database.
+	// Symbols are stored in upper case so that lookups are case-insensitive.
+	exchangeRateKey struct {
+		Symbol    string
+		Timestamp types.Timestamp
+	}
+
+	// fiatLot is an amount of siacoins received by the wallet together with
+	// the exchange rate at which it was received. A nil rate means that the
+	// exchange rate was unknown and the lot has a cost basis of zero.
+	fiatLot struct {
+		amount types.Currency
+		rate   *types.ExchangeRate
+	}
+
+	// fiatSummary accumulates the values of a FiatPeriodSummary.
+	fiatSummary struct {
+		modules.FiatPeriodSummary
+		fiatReceived *big.Rat
+		fiatSent     *big.Rat
+		realizedGain *big.Rat
+	}
+)
+
+// add adds the values of a transaction to the summary.
+func (fs *fiatSummary) add(ft modules.FiatTransaction, fiatReceived, fiatSent, gain *big.Rat) {
+	fs.Transactions++
+	fs.Received = fs.Received.Add(ft.Received)
+	fs.Sent = fs.Sent.Add(ft.Sent)
+	fs.fiatReceived.Add(fs.fiatReceived, fiatReceived)
+	fs.fiatSent.Add(fs.fiatSent, fiatSent)
+	fs.realizedGain.Add(fs.realizedGain, gain)
+}
+
+// summary returns the FiatPeriodSummary with the formatted fiat values.
+func (fs *fiatSummary) summary() modules.FiatPeriodSummary {
+	s := fs.FiatPeriodSummary
+	s.FiatReceived = fs.fiatReceived.FloatString(2)
+	s.FiatSent = fs.fiatSent.FloatString(2)
+	s.RealizedGain = fs.realizedGain.FloatString(2)
+	return s
+}
+
+// newFiatSummary creates an empty summary for a period.
+func newFiatSummary(period string) *fiatSummary {
+	return &fiatSummary{
+		FiatPeriodSummary: modules.FiatPeriodSummary{Period: period},
+		fiatReceived:      new(big.Rat),
+		fiatSent:          new(big.Rat),
+		realizedGain:      new(big.Rat),
+	}
+}
+
+// rateAt returns the most recent exchange rate at or before the timestamp.
+// The points must be sorted by timestamp.
+func rateAt(points []modules.ExchangeRatePoint, ts types.Timestamp) *types.ExchangeRate {
+	i := sort.Search(len(points), func(i int) bool {
+		return points[i].Timestamp > ts
+	})
+	if i == 0 {
+		return nil
+	}
+	rate := points[i-1].Rate
+	return &rate
+}
+
+// computeFiatReport values the confirmed transactions using the exchange rate
+// points. The transactions must be sorted by confirmation height and the
+// points by timestamp. Realized gains are computed by matching sent siacoins
+// with previously received siacoins first-in-first-out, which is why all
+// transactions up to 'end' need to be provided and not only the ones in the
+// report.
+func computeFiatReport(vts []modules.ValuedTransaction, points []modules.ExchangeRatePoint, symbol string, start, end types.Timestamp, period modules.ReportPeriod) (modules.FiatReport, error) {
+	report := modules.FiatReport{
+		Symbol:       strings.ToUpper(symbol),
+		Period:       period,
+		Start:        start,
+		End:          end,
+		Transactions: []modules.FiatTransaction{},
+		Periods:      []modules.FiatPeriodSummary{},
+	}
+	total := newFiatSummary("total")
+	var current *fiatSummary
+	var lots []fiatLot
+	for _, vt := range vts {
+		if vt.ConfirmationTimestamp > end {
+			// Timestamps aren't strictly increasing with the height, so
+			// later transactions might still be part of the report.
+			continue
+		}
+		in, out := vt.ConfirmedIncomingValue, vt.ConfirmedOutgoingValue
+		if in.Equals(out) {
+			// The transaction doesn't change the siacoin balance of the
+			// wallet.
+			continue
+		}
+		ft := modules.FiatTransaction{
+			TransactionID:         vt.TransactionID,
+			ConfirmationHeight:    vt.ConfirmationHeight,
+			ConfirmationTimestamp: vt.ConfirmationTimestamp,
+			Rate:                  rateAt(points, vt.ConfirmationTimestamp),
+		}
+		if ft.Rate == nil {
+			report.MissingRates++
+		}
+		fiatReceived, fiatSent, gain := new(big.Rat), new(big.Rat), new(big.Rat)
+		if in.Cmp(out) > 0 {
+			ft.Received = in.Sub(out)
+			lots = append(lots, fiatLot{amount: ft.Received, rate: ft.Rate})
+			if ft.Rate != nil {
+				fiatReceived = ft.Rate.Apply(ft.Received)
+				ft.FiatReceived = fiatReceived.FloatString(2)
+			}
+		} else {
+			ft.Sent = out.Sub(in)
+			// Consume the oldest lots. Siacoins which can't be matched with a
+			// lot have a cost basis of zero.
+			basis := new(big.Rat)
+			remaining := ft.Sent
+			for len(lots) > 0 && !remaining.IsZero() {
+				taken := lots[0].amount
+				if taken.Cmp(remaining) > 0 {
+					taken = remaining
+				}
+				if lots[0].rate != nil {
+					basis.Add(basis, lots[0].rate.Apply(taken))
+				}
+				remaining = remaining.Sub(taken)
+				lots[0].amount = lots[0].amount.Sub(taken)
+				if lots[0].amount.IsZero() {
+					lots = lots[1:]
+				}
+			}
+			if ft.Rate != nil {
+				fiatSent = ft.Rate.Apply(ft.Sent)
+				gain.Sub(fiatSent, basis)
+				ft.FiatSent = fiatSent.FloatString(2)
+				ft.RealizedGain = gain.FloatString(2)
+			}
+		}
+		if vt.ConfirmationTimestamp < start {
+			continue
+		}
+
+		key, err := period.Key(vt.ConfirmationTimestamp)
+		if err != nil {
+			return modules.FiatReport{}, err
+		}
+		if current == nil || current.Period != key {
+			if current != nil {
+				report.Periods = append(report.Periods, current.summary())
+			}
+			current = newFiatSummary(key)
+		}
+		current.add(ft, fiatReceived, fiatSent, gain)
+		total.add(ft, fiatReceived, fiatSent, gain)
+		report.Transactions = append(report.Transactions, ft)
+	}
+	if current != nil {
+		report.Periods = append(report.Periods, current.summary())
+	}
+	report.Total = total.summary()
+	return report, nil
+}
+
+// exchangeRates returns the stored exchange rate points of a currency sorted
+// by timestamp. An empty symbol returns the points of all currencies sorted by
+// symbol and timestamp.
+func (w *Wallet) exchangeRates(symbol string) ([]modules.ExchangeRatePoint, error) {
+	symbol = strings.ToUpper(symbol)
+	var keys []exchangeRateKey
+	var points []modules.ExchangeRatePoint
+	err := dbForEachExchangeRate(w.dbTx, func(key exchangeRateKey, rate types.ExchangeRate) {
+		if symbol != "" && key.Symbol != symbol {
+			return
+		}
+		keys = append(keys, key)
+		points = append(points, modules.ExchangeRatePoint{
+			Timestamp: key.Timestamp,
+			Rate:      rate,
+		})
+	})
+	if err != nil {
+		return nil, errors.AddContext(err, "failed to read exchange rates")
+	}
+	// Bolt iterates the keys in byte order which isn't the numeric order of
+	// the timestamps.
+	sort.Sort(exchangeRatesByKey{keys, points})
+	return points, nil
+}
+
+// exchangeRatesByKey sorts exchange rate points by their database keys.
+type exchangeRatesByKey struct {
+	keys   []exchangeRateKey
+	points []modules.ExchangeRatePoint
+}
+
+func (s exchangeRatesByKey) Len() int { return len(s.keys) }
+func (s exchangeRatesByKey) Less(i, j int) bool {
+	if s.keys[i].Symbol != s.keys[j].Symbol {
+		return s.keys[i].Symbol < s.keys[j].Symbol
+	}
+	return s.keys[i].Timestamp < s.keys[j].Timestamp
+}
+func (s exchangeRatesByKey) Swap(i, j int) {
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+	s.points[i], s.points[j] = s.points[j], s.points[i]
+}
+
+// AddExchangeRates stores historical exchange rate points. A point replaces
+// an existing point of the same currency and timestamp.
+func (w *Wallet) AddExchangeRates(points []modules.ExchangeRatePoint) error {
+	if err := w.tg.Add(); err != nil {
+		return modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	for _, point := range points {
+		if point.Rate.Symbol() == "" {
+			return errInvalidExchangeRate
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, point := range points {
+		key := exchangeRateKey{
+			Symbol:    strings.ToUpper(point.Rate.Symbol()),
+			Timestamp: point.Timestamp,
+		}
+		if err := dbPutExchangeRate(w.dbTx, key, point.Rate); err != nil {
+			return errors.AddContext(err, "failed to store exchange rate")
+		}
+	}
+	return w.syncDB()
+}
+
+// ExchangeRates returns the stored exchange rate points of the currency with
+// the given symbol sorted by timestamp. If symbol is empty, the points of all
+// currencies are returned.
+func (w *Wallet) ExchangeRates(symbol string) ([]modules.ExchangeRatePoint, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.exchangeRates(symbol)
+}
+
+// FiatReport values the transactions confirmed between start and end using
+// the stored exchange rates of the currency with the given symbol and
+// summarizes them by period.
+func (w *Wallet) FiatReport(symbol string, start, end types.Timestamp, period modules.ReportPeriod) (modules.FiatReport, error) {
+	if err := w.tg.Add(); err != nil {
+		return modules.FiatReport{}, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	if symbol == "" {
+		return modules.FiatReport{}, errNoReportSymbol
+	}
+	if end < start {
+		return modules.FiatReport{}, errInvalidReportRange
+	}
+	if _, err := period.Key(start); err != nil {
+		return modules.FiatReport{}, err
+	}
+
+	w.mu.Lock()
+	points, err := w.exchangeRates(symbol)
+	w.mu.Unlock()
+	if err != nil {
+		return modules.FiatReport{}, err
+	}
+	height, err := w.Height()
+	if err != nil {
+		return modules.FiatReport{}, err
+	}
+	pts, err := w.Transactions(0, height)
+	if err != nil {
+		return modules.FiatReport{}, errors.AddContext(err, "failed to get transactions")
+	}
+	vts, err := ComputeValuedTransactions(pts, height)
+	if err != nil {
+		return modules.FiatReport{}, errors.AddContext(err, "failed to compute transaction values")
+	}
+	return computeFiatReport(vts, points, symbol, start, end, period)
+}
@@ -0,0 +1,161 @@
+package wallet
+
+import (
+	"testing"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// TestComputeFiatReport probes valuing transactions and computing realized
+// gains from exchange rate points.
+func TestComputeFiatReport(t *testing.T) {
+	rate := func(s string) types.ExchangeRate {
+		r, err := types.ParseExchangeRate(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return *r
+	}
+	sc := func(n uint64) types.Currency {
+		return types.SiacoinPrecision.Mul64(n)
+	}
+	vt := func(id byte, ts types.Timestamp, in, out types.Currency) modules.ValuedTransaction {
+		return modules.ValuedTransaction{
+			ProcessedTransaction: modules.ProcessedTransaction{
+				TransactionID:         types.TransactionID{id},
+				ConfirmationTimestamp: ts,
+			},
+			ConfirmedIncomingValue: in,
+			ConfirmedOutgoingValue: out,
+		}
+	}
+
+	// 2021-01-01, 2021-02-01 and 2021-03-01
+	const jan, feb, mar = 1609459200, 1612137600, 1614556800
+	points := []modules.ExchangeRatePoint{
+		{Timestamp: jan, Rate: rate("0.01 usd")},
+		{Timestamp: mar, Rate: rate("0.03 usd")},
+	}
+	vts := []modules.ValuedTransaction{
+		// Received before the first rate, zero cost basis.
+		vt(1, jan-1, sc(100), types.ZeroCurrency),
+		// Received 1000 SC at 0.01.
+		vt(2, jan+10, sc(1000), types.ZeroCurrency),
+		// Doesn't change the balance.
+		vt(3, feb, sc(5), sc(5)),
+		// Sent 600 SC at 0.03, 100 SC with no cost basis and 500 SC at 0.01.
+		vt(4, mar+10, sc(10), sc(610)),
+		// After the end of the report.
+		vt(5, mar+100, types.ZeroCurrency, sc(100)),
+		// Received 50 SC at 0.03 in a later block with an earlier timestamp.
+		vt(6, mar+20, sc(50), types.ZeroCurrency),
+	}
+
+	report, err := computeFiatReport(vts, points, "usd", jan, mar+99, modules.ReportPeriodMonth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Symbol != "USD" || report.MissingRates != 1 {
+		t.Fatal("wrong report", report.Symbol, report.MissingRates)
+	}
+	if len(report.Transactions) != 3 {
+		t.Fatal("expected 3 transactions, got", len(report.Transactions))
+	}
+	received, sent := report.Transactions[0], report.Transactions[1]
+	if !received.Received.Equals(sc(1000)) || received.FiatReceived != "10.00" || received.RealizedGain != "" {
+		t.Fatal("wrong received transaction", received)
+	}
+	if !sent.Sent.Equals(sc(600)) || sent.FiatSent != "18.00" || sent.RealizedGain != "13.00" {
+		t.Fatal("wrong sent transaction", sent)
+	}
+	if late := report.Transactions[2]; !late.Received.Equals(sc(50)) || late.FiatReceived != "1.50" {
+		t.Fatal("wrong late transaction", late)
+	}
+	if len(report.Periods) != 2 || report.Periods[0].Period != "2021-01" || report.Periods[1].Period != "2021-03" {
+		t.Fatal("wrong periods", report.Periods)
+	}
+	if report.Total.Transactions != 3 || report.Total.FiatReceived != "11.50" || report.Total.FiatSent != "18.00" || report.Total.RealizedGain != "13.00" {
+		t.Fatal("wrong total", report.Total)
+	}
+
+	// Without exchange rates the values are unknown but the siacoin amounts
+	// are still reported.
+	report, err = computeFiatReport(vts, nil, "eur", 0, mar+99, modules.ReportPeriodYear)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.MissingRates != 4 || len(report.Periods) != 2 || report.Periods[0].Period != "2020" || !report.Total.Sent.Equals(sc(600)) {
+		t.Fatal("wrong report", report)
+	}
+	if report.Transactions[2].RealizedGain != "" || report.Total.RealizedGain != "0.00" {
+		t.Fatal("gain without rate", report.Transactions[2])
+	}
+
+	// Unknown periods are rejected.
+	if _, err := computeFiatReport(vts, points, "usd", jan, mar, "week"); err != modules.ErrUnknownReportPeriod {
+		t.Fatal("expected ErrUnknownReportPeriod, got", err)
+	}
+}
+
+// TestExchangeRates probes storing and retrieving exchange rate points.
+func TestExchangeRates(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := wt.closeWt(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	var points []modules.ExchangeRatePoint
+	for _, s := range []string{"0.02 USD", "0.01 usd", "0.5 EUR"} {
+		r, err := types.ParseExchangeRate(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		points = append(points, modules.ExchangeRatePoint{
+			Timestamp: types.Timestamp(1000 - len(points)*300),
+			Rate:      *r,
+		})
+	}
+	if err := wt.wallet.AddExchangeRates(points); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.wallet.AddExchangeRates([]modules.ExchangeRatePoint{{Timestamp: 1}}); err != errInvalidExchangeRate {
+		t.Fatal("expected errInvalidExchangeRate, got", err)
+	}
+
+	usd, err := wt.wallet.ExchangeRates("Usd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(usd) != 2 || usd[0].Timestamp != 700 || usd[0].Rate.String() != "0.01 usd" || usd[1].Timestamp != 1000 {
+		t.Fatal("wrong points", usd)
+	}
+	all, err := wt.wallet.ExchangeRates("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 || all[0].Rate.Symbol() != "EUR" {
+		t.Fatal("wrong points", all)
+	}
+
+	// The wallet tester has mined blocks, so the report contains the miner
+	// payouts.
+	report, err := wt.wallet.FiatReport("usd", 0, types.CurrentTimestamp(), modules.ReportPeriodDay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Transactions) == 0 || report.Total.Received.IsZero() || report.Total.FiatReceived == "0.00" {
+		t.Fatal("wrong report", report.Total)
+	}
+	if _, err := wt.wallet.FiatReport("usd", 10, 5, modules.ReportPeriodDay); err != errInvalidReportRange {
+		t.Fatal("expected errInvalidReportRange, got", err)
+	}
+}
@@ -0,0 +1,363 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+var (
+	// errScheduledPaymentAmount is returned if a scheduled payment doesn't
+	// send any siacoins.
+	errScheduledPaymentAmount = errors.New("scheduled payment amount must be greater than zero")
+
+	// errScheduledPaymentCompleted is returned when updating a one-time
+	// payment which was already sent.
+	errScheduledPaymentCompleted = errors.New("scheduled payment was already completed")
+
+	// errScheduledPaymentDestination is returned if a scheduled payment has
+	// no destination.
+	errScheduledPaymentDestination = errors.New("scheduled payment requires a destination")
+)
+
+type (
+	// pendingScheduledPayment is stored before the transactions of a
+	// scheduled payment are broadcast. It contains the state of the payment
+	// before it was sent and the signed transactions, so that the payment can
+	// be broadcast again or reverted after a restart.
+	pendingScheduledPayment struct {
+		Previous     modules.ScheduledPayment
+		Transactions []types.Transaction
+	}
+)
+
+// validateScheduledPayment checks the user provided fields of a scheduled
+// payment.
+func validateScheduledPayment(sp modules.ScheduledPayment) error {
+	if sp.Amount.IsZero() {
+		return errScheduledPaymentAmount
+	}
+	if sp.Destination == (types.UnlockHash{}) {
+		return errScheduledPaymentDestination
+	}
+	return nil
+}
+
+// managedPutScheduledPayment stores a scheduled payment and syncs the
+// database.
+func (w *Wallet) managedPutScheduledPayment(sp modules.ScheduledPayment) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := dbPutScheduledPayment(w.dbTx, sp); err != nil {
+		return err
+	}
+	return w.syncDB()
+}
+
+// AddScheduledPayment schedules a payment of sp.Amount to sp.Destination at
+// sp.NextHeight. If sp.Interval is not 0, the payment is repeated every
+// sp.Interval blocks.
+func (w *Wallet) AddScheduledPayment(sp modules.ScheduledPayment) (modules.ScheduledPayment, error) {
+	if err := w.tg.Add(); err != nil {
+		return modules.ScheduledPayment{}, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	if err := validateScheduledPayment(sp); err != nil {
+		return modules.ScheduledPayment{}, err
+	}
+	sp = modules.ScheduledPayment{
+		ID:          modules.ScheduledPaymentID(hex.EncodeToString(fastrand.Bytes(16))),
+		Amount:      sp.Amount,
+		Destination: sp.Destination,
+		NextHeight:  sp.NextHeight,
+		Interval:    sp.Interval,
+	}
+
+	w.scheduleMu.Lock()
+	defer w.scheduleMu.Unlock()
+	if err := w.managedPutScheduledPayment(sp); err != nil {
+		return modules.ScheduledPayment{}, errors.AddContext(err, "failed to store scheduled payment")
+	}
+	w.log.Printf("Scheduled payment %v of %v to %v at height %v with interval %v", sp.ID, sp.Amount.HumanString(), sp.Destination, sp.NextHeight, sp.Interval)
+	return sp, nil
+}
+
+// RemoveScheduledPayment cancels and removes a scheduled payment.
+func (w *Wallet) RemoveScheduledPayment(id modules.ScheduledPaymentID) error {
+	if err := w.tg.Add(); err != nil {
+		return modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	w.scheduleMu.Lock()
+	defer w.scheduleMu.Unlock()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := dbGetScheduledPayment(w.dbTx, id); errors.Contains(err, errNoKey) {
+		return modules.ErrUnknownScheduledPayment
+	} else if err != nil {
+		return err
+	}
+	if err := dbDeleteScheduledPayment(w.dbTx, id); err != nil {
+		return errors.AddContext(err, "failed to delete scheduled payment")
+	}
+	if err := dbDeletePendingScheduledPayment(w.dbTx, id); err != nil {
+		return errors.AddContext(err, "failed to delete pending scheduled payment")
+	}
+	w.staticAlerter.UnregisterAlert(modules.AlertIDScheduledPaymentFailed(string(id)))
+	w.log.Println("Removed scheduled payment", id)
+	return w.syncDB()
+}
+
+// ScheduledPayment returns the scheduled payment with the given id.
+func (w *Wallet) ScheduledPayment(id modules.ScheduledPaymentID) (modules.ScheduledPayment, error) {
+	if err := w.tg.Add(); err != nil {
+		return modules.ScheduledPayment{}, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	sp, err := dbGetScheduledPayment(w.dbTx, id)
+	if errors.Contains(err, errNoKey) {
+		return modules.ScheduledPayment{}, modules.ErrUnknownScheduledPayment
+	}
+	return sp, err
+}
+
+// ScheduledPayments returns all scheduled payments sorted by the height of
+// their next payment.
+func (w *Wallet) ScheduledPayments() ([]modules.ScheduledPayment, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	sps := []modules.ScheduledPayment{}
+	err := dbForEachScheduledPayment(w.dbTx, func(_ modules.ScheduledPaymentID, sp modules.ScheduledPayment) {
+		sps = append(sps, sp)
+	})
+	if err != nil {
+		return nil, errors.AddContext(err, "failed to read scheduled payments")
+	}
+	sort.Slice(sps, func(i, j int) bool {
+		if sps[i].NextHeight != sps[j].NextHeight {
+			return sps[i].NextHeight < sps[j].NextHeight
+		}
+		return sps[i].ID < sps[j].ID
+	})
+	return sps, nil
+}
+
+// UpdateScheduledPayment replaces the amount, destination, next height and
+// interval of an existing scheduled payment. Completed one-time payments
+// can't be updated.
+func (w *Wallet) UpdateScheduledPayment(update modules.ScheduledPayment) (modules.ScheduledPayment, error) {
+	if err := w.tg.Add(); err != nil {
+		return modules.ScheduledPayment{}, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	if err := validateScheduledPayment(update); err != nil {
+		return modules.ScheduledPayment{}, err
+	}
+
+	w.scheduleMu.Lock()
+	defer w.scheduleMu.Unlock()
+	sp, err := w.ScheduledPayment(update.ID)
+	if err != nil {
+		return modules.ScheduledPayment{}, err
+	}
+	if sp.Completed {
+		return modules.ScheduledPayment{}, errScheduledPaymentCompleted
+	}
+	sp.Amount = update.Amount
+	sp.Destination = update.Destination
+	sp.NextHeight = update.NextHeight
+	sp.Interval = update.Interval
+	if err := w.managedPutScheduledPayment(sp); err != nil {
+		return modules.ScheduledPayment{}, errors.AddContext(err, "failed to store scheduled payment")
+	}
+	w.log.Printf("Updated scheduled payment %v to %v to %v at height %v with interval %v", sp.ID, sp.Amount.HumanString(), sp.Destination, sp.NextHeight, sp.Interval)
+	return sp, nil
+}
+
+// managedPutPendingScheduledPayment stores the advanced state of a scheduled
+// payment together with its pending marker and syncs the database.
+func (w *Wallet) managedPutPendingScheduledPayment(sp modules.ScheduledPayment, psp pendingScheduledPayment) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := dbPutScheduledPayment(w.dbTx, sp); err != nil {
+		return err
+	}
+	if err := dbPutPendingScheduledPayment(w.dbTx, psp); err != nil {
+		return err
+	}
+	return w.syncDB()
+}
+
+// managedResolveScheduledPayment stores the final state of a pending scheduled
+// payment, removes its pending marker and syncs the database.
+func (w *Wallet) managedResolveScheduledPayment(sp modules.ScheduledPayment) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := dbPutScheduledPayment(w.dbTx, sp); err != nil {
+		return err
+	}
+	if err := dbDeletePendingScheduledPayment(w.dbTx, sp.ID); err != nil {
+		return err
+	}
+	return w.syncDB()
+}
+
+// managedSendScheduledPayment sends a due scheduled payment and updates its
+// state. The advanced state is stored together with the signed transactions
+// before they are broadcast, so that a payment is neither lost nor sent twice
+// if the wallet shuts down in between. If sending fails, an alert is
+// registered and the payment is retried on the next block.
+func (w *Wallet) managedSendScheduledPayment(sp modules.ScheduledPayment, height types.BlockHeight) {
+	alertID := modules.AlertIDScheduledPaymentFailed(string(sp.ID))
+	_, fee := w.tpool.FeeEstimation()
+	fee = fee.Mul64(estimatedTransactionSize)
+	txnSet, txnBuilder, err := w.managedSignSiacoins(sp.Amount, fee, sp.Destination, modules.CoinControl{})
+	if err != nil {
+		w.log.Printf("WARN: failed to send scheduled payment %v: %v", sp.ID, err)
+		sp.LastError = err.Error()
+		w.staticAlerter.RegisterAlert(alertID, AlertMSGScheduledPaymentFailed, fmt.Sprintf("payment %v: %v", sp.ID, err), modules.SeverityError)
+		if err := w.managedPutScheduledPayment(sp); err != nil {
+			w.log.Severe("ERROR: failed to update scheduled payment:", sp.ID, err)
+		}
+		return
+	}
+
+	psp := pendingScheduledPayment{
+		Previous:     sp,
+		Transactions: txnSet,
+	}
+	sent := sp
+	sent.Payments++
+	sent.LastTransactionID = txnSet[len(txnSet)-1].ID()
+	sent.LastError = ""
+	if sent.Interval == 0 {
+		sent.Completed = true
+	} else {
+		// Missed intervals are skipped instead of being paid at once.
+		for sent.NextHeight <= height {
+			sent.NextHeight += sent.Interval
+		}
+	}
+	if err := w.managedPutPendingScheduledPayment(sent, psp); err != nil {
+		txnBuilder.Drop()
+		w.log.Severe("ERROR: failed to store pending scheduled payment:", sp.ID, err)
+		return
+	}
+	if w.deps.Disrupt("ScheduledPaymentInterrupted") {
+		return
+	}
+
+	err = w.tpool.AcceptTransactionSet(txnSet)
+	if err != nil && !errors.Contains(err, modules.ErrDuplicateTransactionSet) {
+		txnBuilder.Drop()
+		w.log.Printf("WARN: failed to send scheduled payment %v: %v", sp.ID, err)
+		sp.LastError = err.Error()
+		w.staticAlerter.RegisterAlert(alertID, AlertMSGScheduledPaymentFailed, fmt.Sprintf("payment %v: %v", sp.ID, err), modules.SeverityError)
+		if err := w.managedResolveScheduledPayment(sp); err != nil {
+			w.log.Severe("ERROR: failed to update scheduled payment:", sp.ID, err)
+		}
+		return
+	}
+	w.log.Printf("Sent scheduled payment %v of %v to %v", sp.ID, sp.Amount.HumanString(), sp.Destination)
+	w.staticAlerter.UnregisterAlert(alertID)
+	if err := w.managedResolveScheduledPayment(sent); err != nil {
+		w.log.Severe("ERROR: failed to update scheduled payment:", sp.ID, err)
+	}
+}
+
+// managedReconcileScheduledPayments resolves the scheduled payments which were
+// stored as pending when the wallet shut down. Their transactions are
+// broadcast again. If they can't be broadcast and aren't known to the wallet,
+// the payment is reverted to its previous state and retried on the next block.
+func (w *Wallet) managedReconcileScheduledPayments() {
+	w.scheduleMu.Lock()
+	defer w.scheduleMu.Unlock()
+	w.mu.Lock()
+	var psps []pendingScheduledPayment
+	err := dbForEachPendingScheduledPayment(w.dbTx, func(_ modules.ScheduledPaymentID, psp pendingScheduledPayment) {
+		psps = append(psps, psp)
+	})
+	w.mu.Unlock()
+	if err != nil {
+		w.log.Println("WARN: failed to load pending scheduled payments:", err)
+		return
+	}
+
+	for _, psp := range psps {
+		sp, err := w.ScheduledPayment(psp.Previous.ID)
+		if err != nil {
+			w.log.Println("WARN: failed to load pending scheduled payment:", psp.Previous.ID, err)
+			continue
+		}
+		_, known, err := w.Transaction(sp.LastTransactionID)
+		if err != nil {
+			w.log.Println("WARN: failed to look up pending scheduled payment:", sp.ID, err)
+			continue
+		}
+		if !known {
+			err = w.tpool.AcceptTransactionSet(psp.Transactions)
+			if errors.Contains(err, modules.ErrDuplicateTransactionSet) {
+				err = nil
+			}
+		}
+		if err != nil {
+			w.log.Printf("WARN: failed to rebroadcast scheduled payment %v: %v", sp.ID, err)
+			sp.Payments = psp.Previous.Payments
+			sp.LastTransactionID = psp.Previous.LastTransactionID
+			sp.NextHeight = psp.Previous.NextHeight
+			sp.Completed = psp.Previous.Completed
+			sp.LastError = err.Error()
+			alertID := modules.AlertIDScheduledPaymentFailed(string(sp.ID))
+			w.staticAlerter.RegisterAlert(alertID, AlertMSGScheduledPaymentFailed, fmt.Sprintf("payment %v: %v", sp.ID, err), modules.SeverityError)
+		} else {
+			w.log.Printf("Rebroadcast pending scheduled payment %v of %v to %v", sp.ID, sp.Amount.HumanString(), sp.Destination)
+		}
+		if err := w.managedResolveScheduledPayment(sp); err != nil {
+			w.log.Severe("ERROR: failed to update scheduled payment:", sp.ID, err)
+		}
+	}
+}
+
+// threadedSendScheduledPayments sends all scheduled payments which are due at
+// the current height.
+func (w *Wallet) threadedSendScheduledPayments() {
+	if err := w.tg.Add(); err != nil {
+		return
+	}
+	defer w.tg.Done()
+
+	w.scheduleMu.Lock()
+	defer w.scheduleMu.Unlock()
+	height, err := w.Height()
+	if err != nil {
+		return
+	}
+	sps, err := w.ScheduledPayments()
+	if err != nil {
+		w.log.Println("WARN: failed to load scheduled payments:", err)
+		return
+	}
+	for _, sp := range sps {
+		if sp.Completed || sp.NextHeight > height {
+			continue
+		}
+		w.managedSendScheduledPayment(sp, height)
+	}
+}
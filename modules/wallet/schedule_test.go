@@ -0,0 +1,293 @@
+package wallet
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// TestScheduledPayments probes sending one-time and recurring scheduled
+// payments and retrying them while the wallet is underfunded.
+func TestScheduledPayments(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := wt.closeWt(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Invalid payments should be rejected.
+	_, err = wt.wallet.AddScheduledPayment(modules.ScheduledPayment{Destination: types.UnlockHash{1}})
+	if !errors.Contains(err, errScheduledPaymentAmount) {
+		t.Fatal("expected errScheduledPaymentAmount, got", err)
+	}
+	_, err = wt.wallet.AddScheduledPayment(modules.ScheduledPayment{Amount: types.SiacoinPrecision})
+	if !errors.Contains(err, errScheduledPaymentDestination) {
+		t.Fatal("expected errScheduledPaymentDestination, got", err)
+	}
+	if err := wt.wallet.RemoveScheduledPayment("foo"); !errors.Contains(err, modules.ErrUnknownScheduledPayment) {
+		t.Fatal("expected ErrUnknownScheduledPayment, got", err)
+	}
+
+	// Schedule a one-time and a recurring payment for the next block.
+	height, err := wt.wallet.Height()
+	if err != nil {
+		t.Fatal(err)
+	}
+	once, err := wt.wallet.AddScheduledPayment(modules.ScheduledPayment{
+		Amount:      types.SiacoinPrecision.Mul64(10),
+		Destination: types.UnlockHash{1},
+		NextHeight:  height + 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recurring, err := wt.wallet.AddScheduledPayment(modules.ScheduledPayment{
+		Amount:      types.SiacoinPrecision,
+		Destination: types.UnlockHash{2},
+		NextHeight:  height + 1,
+		Interval:    2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// check waits until the scheduled payment matches the condition.
+	check := func(id modules.ScheduledPaymentID, cond func(modules.ScheduledPayment) error) {
+		t.Helper()
+		err := build.Retry(50, 100*time.Millisecond, func() error {
+			sp, err := wt.wallet.ScheduledPayment(id)
+			if err != nil {
+				return err
+			}
+			return cond(sp)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// paymentAlerts returns the number of failed payment alerts.
+	paymentAlerts := func() (n int) {
+		_, alerts, _ := wt.wallet.Alerts()
+		for _, alert := range alerts {
+			if alert.Msg == AlertMSGScheduledPaymentFailed {
+				n++
+			}
+		}
+		return n
+	}
+
+	// Both payments should be sent with the next block.
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+	check(once.ID, func(sp modules.ScheduledPayment) error {
+		if !sp.Completed || sp.Payments != 1 {
+			return fmt.Errorf("one-time payment wasn't sent: %+v", sp)
+		}
+		return nil
+	})
+	check(recurring.ID, func(sp modules.ScheduledPayment) error {
+		if sp.Payments != 1 || sp.NextHeight != height+3 {
+			return fmt.Errorf("recurring payment wasn't sent: %+v", sp)
+		}
+		return nil
+	})
+	if _, err := wt.wallet.UpdateScheduledPayment(once); !errors.Contains(err, errScheduledPaymentCompleted) {
+		t.Fatal("expected errScheduledPaymentCompleted, got", err)
+	}
+
+	// If the wallet is underfunded, the payment fails and an alert is
+	// registered.
+	recurring.Amount = types.SiacoinPrecision.Mul64(1e12)
+	recurring.NextHeight = height + 3
+	if _, err := wt.wallet.UpdateScheduledPayment(recurring); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := wt.addBlockNoPayout(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check(recurring.ID, func(sp modules.ScheduledPayment) error {
+		if sp.LastError == "" || sp.Payments != 1 {
+			return fmt.Errorf("payment should have failed: %+v", sp)
+		}
+		return nil
+	})
+	if n := paymentAlerts(); n != 1 {
+		t.Fatal("expected one alert, got", n)
+	}
+
+	// After lowering the amount, the payment is retried with the next block.
+	recurring.Amount = types.SiacoinPrecision
+	if _, err := wt.wallet.UpdateScheduledPayment(recurring); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+	check(recurring.ID, func(sp modules.ScheduledPayment) error {
+		if sp.LastError != "" || sp.Payments != 2 || sp.NextHeight != height+5 {
+			return fmt.Errorf("payment wasn't retried: %+v", sp)
+		}
+		return nil
+	})
+	if n := paymentAlerts(); n != 0 {
+		t.Fatal("alert wasn't removed", n)
+	}
+
+	// Remove the recurring payment.
+	if err := wt.wallet.RemoveScheduledPayment(recurring.ID); err != nil {
+		t.Fatal(err)
+	}
+	sps, err := wt.wallet.ScheduledPayments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sps) != 1 || sps[0].ID != once.ID {
+		t.Fatal("wrong scheduled payments", sps)
+	}
+}
+
+// TestScheduledPaymentInterrupted checks that a scheduled payment which was
+// stored but not broadcast before the wallet shut down is broadcast again or
+// reverted after a restart.
+func TestScheduledPaymentInterrupted(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	wt, err := createWalletTester(t.Name(), &dependencyScheduledPaymentInterrupted{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := wt.closeWt(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	height, err := wt.wallet.Height()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sp, err := wt.wallet.AddScheduledPayment(modules.ScheduledPayment{
+		Amount:      types.SiacoinPrecision,
+		Destination: types.UnlockHash{1},
+		NextHeight:  height + 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// pending returns the number of pending scheduled payments.
+	pending := func(w *Wallet) (n int) {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		err := dbForEachPendingScheduledPayment(w.dbTx, func(modules.ScheduledPaymentID, pendingScheduledPayment) {
+			n++
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	// The payment should be stored as sent and pending, but it shouldn't be
+	// broadcast.
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(50, 100*time.Millisecond, func() error {
+		sent, err := wt.wallet.ScheduledPayment(sp.ID)
+		if err != nil {
+			return err
+		}
+		if !sent.Completed || sent.Payments != 1 {
+			return fmt.Errorf("payment wasn't stored: %+v", sent)
+		}
+		sp = sent
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := pending(wt.wallet); n != 1 {
+		t.Fatal("expected one pending payment, got", n)
+	}
+	if _, _, exists := wt.tpool.Transaction(sp.LastTransactionID); exists {
+		t.Fatal("payment shouldn't have been broadcast")
+	}
+
+	// After a restart, the payment should be broadcast.
+	if err := wt.wallet.Close(); err != nil {
+		t.Fatal(err)
+	}
+	w, err := New(wt.cs, wt.tpool, filepath.Join(wt.persistDir, modules.WalletDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt.wallet = w
+	if err := w.Unlock(wt.walletMasterKey); err != nil {
+		t.Fatal(err)
+	}
+	if n := pending(w); n != 0 {
+		t.Fatal("expected no pending payments, got", n)
+	}
+	if _, _, exists := wt.tpool.Transaction(sp.LastTransactionID); !exists {
+		t.Fatal("payment wasn't broadcast")
+	}
+	if sent, err := w.ScheduledPayment(sp.ID); err != nil || sent.Payments != 1 || sent.LastError != "" {
+		t.Fatal("unexpected payment", sent, err)
+	}
+
+	// A pending payment which can't be broadcast should be reverted.
+	sp, err = w.AddScheduledPayment(modules.ScheduledPayment{
+		Amount:      types.SiacoinPrecision,
+		Destination: types.UnlockHash{1},
+		NextHeight:  height + 100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	invalid := []types.Transaction{{
+		SiacoinInputs: []types.SiacoinInput{{ParentID: types.SiacoinOutputID{1}}},
+	}}
+	sent := sp
+	sent.Payments = 1
+	sent.Completed = true
+	sent.LastTransactionID = invalid[0].ID()
+	err = w.managedPutPendingScheduledPayment(sent, pendingScheduledPayment{
+		Previous:     sp,
+		Transactions: invalid,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.managedReconcileScheduledPayments()
+	if n := pending(w); n != 0 {
+		t.Fatal("expected no pending payments, got", n)
+	}
+	reverted, err := w.ScheduledPayment(sp.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reverted.Completed || reverted.Payments != 0 || reverted.LastTransactionID != sp.LastTransactionID || reverted.LastError == "" {
+		t.Fatal("payment wasn't reverted", reverted)
+	}
+}
@@ -246,10 +246,14 @@ func (w *Wallet) managedMarkAddressUnused(addrs ...types.UnlockConditions) {
 }
 
 // markAddressUnused marks the provided address as unused which causes it
-// to be handed out by a subsequent call to `NextAddresses` again.
+// to be handed out by a subsequent call to `NextAddresses` again. The address
+// is removed from the account it was assigned to.
 func (w *Wallet) markAddressUnused(addrs ...types.UnlockConditions) {
 	for _, addr := range addrs {
 		w.unusedKeys[addr.UnlockHash()] = addr
+		if err := dbDeleteAccountAddress(w.dbTx, addr.UnlockHash()); err != nil {
+			w.log.Printf("WARN: failed to unassign unused address %v: %v", addr.UnlockHash(), err)
+		}
 	}
 }
 
@@ -0,0 +1,262 @@
+package wallet
+
+import (
+	"bytes"
+	"sort"
+
+	"gitlab.com/NebulousLabs/bolt"
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+var (
+	// errInsufficientWalletKeys is returned if a time-locked address can't be
+	// spent using only the keys of the wallet.
+	errInsufficientWalletKeys = errors.New("the wallet doesn't own enough keys to spend from the address")
+
+	// errNoTimelock is returned if unlock conditions without a timelock are
+	// added as a time-locked address.
+	errNoTimelock = errors.New("unlock conditions have no timelock")
+
+	// errUnlockHeightReached is returned if a time-locked address is created
+	// with an unlock height that has already been reached.
+	errUnlockHeightReached = errors.New("unlock height must be greater than the current height")
+)
+
+// unlockConditionsKey returns the spendable key of unlock conditions, e.g. of
+// a time-locked or multisig address, using the secret keys of the wallet. It
+// returns false if the wallet doesn't own enough keys to satisfy the unlock
+// conditions.
+func (w *Wallet) unlockConditionsKey(uc types.UnlockConditions) (spendableKey, bool) {
+	sk := spendableKey{UnlockConditions: uc}
+	for _, pk := range uc.PublicKeys {
+		if key, ok := findSecretKey(w.keys, uc, pk); ok {
+			sk.SecretKeys = append(sk.SecretKeys, key)
+		}
+	}
+	return sk, uint64(len(sk.SecretKeys)) >= uc.SignaturesRequired
+}
+
+// integrateTimelockedAddresses adds the keys of the time-locked addresses to
+// the spendable keys of the wallet. It has to be called after the seeds and
+// unseeded keys were integrated.
+func (w *Wallet) integrateTimelockedAddresses() error {
+	return dbForEachTimelockedAddress(w.dbTx, func(addr types.UnlockHash, uc types.UnlockConditions) {
+		if sk, ok := w.unlockConditionsKey(uc); ok {
+			w.keys[addr] = sk
+		}
+	})
+}
+
+// lockedAddresses returns the time-locked addresses of the wallet whose unlock
+// height hasn't been reached yet, mapped to their unlock height.
+func lockedAddresses(tx *bolt.Tx) (map[types.UnlockHash]types.BlockHeight, error) {
+	height, err := dbGetConsensusHeight(tx)
+	if err != nil {
+		return nil, err
+	}
+	locked := make(map[types.UnlockHash]types.BlockHeight)
+	err = dbForEachTimelockedAddress(tx, func(addr types.UnlockHash, uc types.UnlockConditions) {
+		if height < uc.Timelock {
+			locked[addr] = uc.Timelock
+		}
+	})
+	if err != nil {
+		return nil, errors.AddContext(err, "failed to read time-locked addresses")
+	}
+	return locked, nil
+}
+
+// AddTimelockedAddress adds unlock conditions with a timelock whose keys
+// belong to the wallet, e.g. the conditions of a vesting payment which were
+// created by the sender using a public key of the wallet. The outputs of the
+// address are tracked as pending unlock and spent like any other output once
+// the timelock has passed. If unused is false, the wallet rescans the
+// blockchain.
+func (w *Wallet) AddTimelockedAddress(uc types.UnlockConditions, unused bool) error {
+	if err := w.tg.Add(); err != nil {
+		return modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	if uc.Timelock == 0 {
+		return errNoTimelock
+	}
+	if uc.SignaturesRequired == 0 || uc.SignaturesRequired > uint64(len(uc.PublicKeys)) {
+		return errInvalidSignaturesRequired
+	}
+
+	err := func() error {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if !w.unlocked {
+			return modules.ErrLockedWallet
+		}
+		sk, ok := w.unlockConditionsKey(uc)
+		if !ok {
+			return errInsufficientWalletKeys
+		}
+		if err := dbPutTimelockedAddress(w.dbTx, uc); err != nil {
+			return errors.AddContext(err, "failed to store time-locked address")
+		}
+		w.keys[uc.UnlockHash()] = sk
+
+		if !unused {
+			// prepare to rescan
+			if err := w.dbTx.DeleteBucket(bucketProcessedTransactions); err != nil {
+				return err
+			}
+			if _, err := w.dbTx.CreateBucket(bucketProcessedTransactions); err != nil {
+				return err
+			}
+			w.unconfirmedProcessedTransactions = nil
+			if err := dbPutConsensusChangeID(w.dbTx, modules.ConsensusChangeBeginning); err != nil {
+				return err
+			}
+			if err := dbPutConsensusHeight(w.dbTx, 0); err != nil {
+				return err
+			}
+		}
+		return w.syncDB()
+	}()
+	if err != nil {
+		return err
+	}
+	w.log.Printf("Added time-locked address %v which unlocks at height %v", uc.UnlockHash(), uc.Timelock)
+
+	if !unused {
+		// rescan the blockchain
+		w.cs.Unsubscribe(w)
+		w.tpool.Unsubscribe(w)
+
+		done := make(chan struct{})
+		go w.rescanMessage(done)
+		defer close(done)
+		if err := w.cs.ConsensusSetSubscribe(w, modules.ConsensusChangeBeginning, w.tg.StopChan()); err != nil {
+			return err
+		}
+		w.tpool.TransactionPoolSubscribe(w)
+	}
+	return nil
+}
+
+// TimelockedAddress returns a new address of the primary seed whose outputs
+// can't be spent before the unlock height.
+func (w *Wallet) TimelockedAddress(unlockHeight types.BlockHeight) (types.UnlockConditions, error) {
+	if err := w.tg.Add(); err != nil {
+		return types.UnlockConditions{}, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.unlocked {
+		return types.UnlockConditions{}, modules.ErrLockedWallet
+	}
+	height, err := dbGetConsensusHeight(w.dbTx)
+	if err != nil {
+		return types.UnlockConditions{}, err
+	}
+	if unlockHeight <= height {
+		return types.UnlockConditions{}, errUnlockHeightReached
+	}
+	base, err := w.nextPrimarySeedAddress(w.dbTx)
+	if err != nil {
+		return types.UnlockConditions{}, err
+	}
+	uc := types.UnlockConditions{
+		Timelock:           unlockHeight,
+		PublicKeys:         base.PublicKeys,
+		SignaturesRequired: base.SignaturesRequired,
+	}
+	if err := dbPutTimelockedAddress(w.dbTx, uc); err != nil {
+		w.markAddressUnused(base)
+		return types.UnlockConditions{}, errors.AddContext(err, "failed to store time-locked address")
+	}
+	w.keys[uc.UnlockHash()] = spendableKey{
+		UnlockConditions: uc,
+		SecretKeys:       w.keys[base.UnlockHash()].SecretKeys,
+	}
+	return uc, w.syncDB()
+}
+
+// TimelockedAddresses returns the unlock conditions of the wallet's
+// time-locked addresses sorted by unlock height.
+func (w *Wallet) TimelockedAddresses() ([]types.UnlockConditions, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	ucs := []types.UnlockConditions{}
+	err := dbForEachTimelockedAddress(w.dbTx, func(_ types.UnlockHash, uc types.UnlockConditions) {
+		ucs = append(ucs, uc)
+	})
+	if err != nil {
+		return nil, errors.AddContext(err, "failed to read time-locked addresses")
+	}
+	sort.Slice(ucs, func(i, j int) bool {
+		if ucs[i].Timelock != ucs[j].Timelock {
+			return ucs[i].Timelock < ucs[j].Timelock
+		}
+		uhi, uhj := ucs[i].UnlockHash(), ucs[j].UnlockHash()
+		return bytes.Compare(uhi[:], uhj[:]) < 0
+	})
+	return ucs, nil
+}
+
+// TimelockedOutputs returns the confirmed outputs of the wallet whose unlock
+// height hasn't been reached yet, sorted by unlock height.
+func (w *Wallet) TimelockedOutputs() ([]modules.TimelockedOutput, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	locked, err := lockedAddresses(w.dbTx)
+	if err != nil {
+		return nil, err
+	}
+	outputs := []modules.TimelockedOutput{}
+	err = dbForEachSiacoinOutput(w.dbTx, func(id types.SiacoinOutputID, sco types.SiacoinOutput) {
+		if height, ok := locked[sco.UnlockHash]; ok {
+			outputs = append(outputs, modules.TimelockedOutput{
+				ID:           types.OutputID(id),
+				FundType:     types.SpecifierSiacoinOutput,
+				UnlockHash:   sco.UnlockHash,
+				Value:        sco.Value,
+				UnlockHeight: height,
+			})
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = dbForEachSiafundOutput(w.dbTx, func(id types.SiafundOutputID, sfo types.SiafundOutput) {
+		if height, ok := locked[sfo.UnlockHash]; ok {
+			outputs = append(outputs, modules.TimelockedOutput{
+				ID:           types.OutputID(id),
+				FundType:     types.SpecifierSiafundOutput,
+				UnlockHash:   sfo.UnlockHash,
+				Value:        sfo.Value,
+				UnlockHeight: height,
+			})
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(outputs, func(i, j int) bool {
+		if outputs[i].UnlockHeight != outputs[j].UnlockHeight {
+			return outputs[i].UnlockHeight < outputs[j].UnlockHeight
+		}
+		return bytes.Compare(outputs[i].ID[:], outputs[j].ID[:]) < 0
+	})
+	return outputs, nil
+}
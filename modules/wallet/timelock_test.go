@@ -0,0 +1,188 @@
+package wallet
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// TestTimelockedAddress probes receiving siacoins on a time-locked address and
+// spending them once the timelock has passed.
+func TestTimelockedAddress(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := wt.closeWt(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Invalid time-locked addresses should be rejected.
+	height, err := wt.wallet.Height()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.wallet.TimelockedAddress(height); !errors.Contains(err, errUnlockHeightReached) {
+		t.Fatal("expected errUnlockHeightReached, got", err)
+	}
+	uc, err := wt.wallet.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.wallet.AddTimelockedAddress(uc, true); !errors.Contains(err, errNoTimelock) {
+		t.Fatal("expected errNoTimelock, got", err)
+	}
+	_, pk := crypto.GenerateKeyPair()
+	foreign := types.UnlockConditions{
+		Timelock:           height + 10,
+		PublicKeys:         []types.SiaPublicKey{types.Ed25519PublicKey(pk)},
+		SignaturesRequired: 1,
+	}
+	if err := wt.wallet.AddTimelockedAddress(foreign, true); !errors.Contains(err, errInsufficientWalletKeys) {
+		t.Fatal("expected errInsufficientWalletKeys, got", err)
+	}
+
+	// Let the pending miner payouts mature, so that the confirmed balance only
+	// changes by the siacoins sent below.
+	for i := types.BlockHeight(0); i < types.MaturityDelay; i++ {
+		if err := wt.addBlockNoPayout(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if height, err = wt.wallet.Height(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Send siacoins to an address which unlocks in a few blocks.
+	unlockHeight := height + 3
+	tuc, err := wt.wallet.TimelockedAddress(unlockHeight)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tuc.Timelock != unlockHeight {
+		t.Fatal("wrong timelock", tuc.Timelock)
+	}
+	balanceBefore, _, _, err := wt.wallet.ConfirmedBalance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	amount := types.SiacoinPrecision.Mul64(100)
+	txns, err := wt.wallet.SendSiacoins(amount, tuc.UnlockHash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fees types.Currency
+	for _, txn := range txns {
+		for _, fee := range txn.MinerFees {
+			fees = fees.Add(fee)
+		}
+	}
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+	// The transaction pool is updated asynchronously, so wait for it to drop
+	// the confirmed transactions before the miner uses it for the next block.
+	err = build.Retry(50, 100*time.Millisecond, func() error {
+		if n := len(wt.tpool.TransactionList()); n != 0 {
+			return fmt.Errorf("%v transactions in the pool", n)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The output should be pending unlock and not part of the balance.
+	outputs, err := wt.wallet.TimelockedOutputs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(outputs) != 1 || !outputs[0].Value.Equals(amount) || outputs[0].UnlockHeight != unlockHeight {
+		t.Fatal("wrong time-locked outputs", outputs)
+	}
+	// The blocks are mined without payouts, so the balance only changes by
+	// the sent amount and the fees.
+	balance, _, _, err := wt.wallet.ConfirmedBalance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := balanceBefore.Sub(amount).Sub(fees); !balance.Equals(expected) {
+		t.Fatalf("expected balance %v, got %v", expected, balance)
+	}
+	_, err = wt.wallet.SendSiacoinsCoinControl(types.SiacoinPrecision, types.UnlockHash{}, false, modules.CoinControl{
+		Include: []types.SiacoinOutputID{types.SiacoinOutputID(outputs[0].ID)},
+	})
+	if err == nil {
+		t.Fatal("time-locked output was spent before its unlock height")
+	}
+
+	// Once the unlock height is reached, the output should be spendable.
+	for height < unlockHeight {
+		if err := wt.addBlockNoPayout(); err != nil {
+			t.Fatal(err)
+		}
+		if height, err = wt.wallet.Height(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	outputs, err = wt.wallet.TimelockedOutputs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(outputs) != 0 {
+		t.Fatal("output is still locked", outputs)
+	}
+	balance, _, _, err = wt.wallet.ConfirmedBalance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := balanceBefore.Sub(fees); !balance.Equals(expected) {
+		t.Fatalf("expected balance %v, got %v", expected, balance)
+	}
+	unspent, err := wt.wallet.UnspentOutputs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var id types.SiacoinOutputID
+	for _, uo := range unspent {
+		if uo.UnlockHash == tuc.UnlockHash() {
+			id = types.SiacoinOutputID(uo.ID)
+		}
+	}
+	_, err = wt.wallet.SendSiacoinsCoinControl(types.SiacoinPrecision, types.UnlockHash{}, false, modules.CoinControl{
+		Include: []types.SiacoinOutputID{id},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The address should be restored after locking and unlocking the wallet.
+	ucs, err := wt.wallet.TimelockedAddresses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ucs) != 1 || ucs[0].UnlockHash() != tuc.UnlockHash() {
+		t.Fatal("wrong time-locked addresses", ucs)
+	}
+	if err := wt.wallet.Lock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.wallet.Unlock(wt.walletMasterKey); err != nil {
+		t.Fatal(err)
+	}
+	if !wt.wallet.managedCanSpendUnlockHash(tuc.UnlockHash()) {
+		t.Fatal("time-locked address isn't spendable after unlocking")
+	}
+}
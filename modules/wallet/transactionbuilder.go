@@ -2,7 +2,7 @@ package wallet
 
 import (
 	"bytes"
-	"sort"
+	"fmt"
 
 	"gitlab.com/NebulousLabs/bolt"
 	"gitlab.com/NebulousLabs/errors"
@@ -108,8 +108,8 @@ func (w *Wallet) checkOutput(tx *bolt.Tx, currentHeight types.BlockHeight, id ty
 			return errSpendHeightTooHigh
 		}
 	}
-	outputUnlockConditions := w.keys[output.UnlockHash].UnlockConditions
-	if currentHeight < outputUnlockConditions.Timelock {
+	key, _ := w.signingKey(output.UnlockHash)
+	if currentHeight < key.UnlockConditions.Timelock {
 		return errOutputTimelock
 	}
 
@@ -190,10 +190,21 @@ func (tb *transactionBuilder) MarkWalletInputs() bool {
 // transaction. A parent transaction may be needed to achieve an input with the
 // correct value. The siacoin input will not be signed until 'Sign' is called
 // on the transaction builder.
-func (tb *transactionBuilder) FundSiacoins(amount types.Currency) (err error) {
+func (tb *transactionBuilder) FundSiacoins(amount types.Currency) error {
+	return tb.FundSiacoinsCoinControl(amount, modules.CoinControl{})
+}
+
+// FundSiacoinsCoinControl is like FundSiacoins but only selects siacoin
+// outputs which are allowed by the provided CoinControl. The change is sent to
+// the change address of the CoinControl if it is set, or else to a new address
+// of the selected account.
+func (tb *transactionBuilder) FundSiacoinsCoinControl(amount types.Currency, cc modules.CoinControl) (err error) {
 	if amount.IsZero() {
 		return nil
 	}
+	if err := cc.Validate(); err != nil {
+		return err
+	}
 	// dustThreshold has to be obtained separate from the lock
 	dustThreshold, err := tb.wallet.DustThreshold()
 	if err != nil {
@@ -208,7 +219,18 @@ func (tb *transactionBuilder) FundSiacoins(amount types.Currency) (err error) {
 		return err
 	}
 
-	// Collect a value-sorted set of siacoin outputs.
+	// Outputs of accounts are only spent if the account was selected.
+	if cc.Account != "" {
+		if err := checkAccount(tb.wallet.dbTx, cc.Account); err != nil {
+			return err
+		}
+	}
+	accountAddrs, err := accountAddresses(tb.wallet.dbTx)
+	if err != nil {
+		return err
+	}
+
+	// Collect the siacoin outputs of the wallet.
 	var so sortedOutputs
 	err = dbForEachSiacoinOutput(tb.wallet.dbTx, func(scoid types.SiacoinOutputID, sco types.SiacoinOutput) {
 		so.ids = append(so.ids, scoid)
@@ -229,44 +251,79 @@ func (tb *transactionBuilder) FundSiacoins(amount types.Currency) (err error) {
 			so.outputs = append(so.outputs, sco)
 		}
 	}
-	sort.Sort(sort.Reverse(so))
 
-	// Create and fund a parent transaction that will add the correct amount of
-	// siacoins to the transaction.
-	var fund types.Currency
+	// Filter the outputs which can be spent according to the coin control.
 	// potentialFund tracks the balance of the wallet including outputs that
 	// have been spent in other unconfirmed transactions recently. This is to
 	// provide the user with a more useful error message in the event that they
 	// are overspending.
+	included := make(map[types.SiacoinOutputID]struct{}, len(cc.Include))
+	for _, scoid := range cc.Include {
+		included[scoid] = struct{}{}
+	}
+	excluded := make(map[types.SiacoinOutputID]struct{}, len(cc.Exclude))
+	for _, scoid := range cc.Exclude {
+		excluded[scoid] = struct{}{}
+	}
+	found := make(map[types.SiacoinOutputID]struct{}, len(cc.Include))
+	var spendable sortedOutputs
 	var potentialFund types.Currency
-	parentTxn := types.Transaction{}
-	var spentScoids []types.SiacoinOutputID
 	for i := range so.ids {
 		scoid := so.ids[i]
 		sco := so.outputs[i]
+		if _, exists := excluded[scoid]; exists {
+			continue
+		}
+		_, isIncluded := included[scoid]
+		if len(cc.Include) > 0 && !isIncluded {
+			continue
+		}
+		if accountAddrs[sco.UnlockHash] != cc.Account {
+			if isIncluded {
+				return fmt.Errorf("included output %v doesn't belong to the selected account", scoid)
+			}
+			continue
+		}
+		// Outputs of multisig addresses are only spent if they were included
+		// and the wallet owns enough keys to sign them.
+		if _, isMultisig := tb.wallet.multisigAddrs[sco.UnlockHash]; isMultisig {
+			if !isIncluded {
+				continue
+			}
+			if _, ok := tb.wallet.signingKey(sco.UnlockHash); !ok {
+				return errors.AddContext(errInsufficientWalletKeys, fmt.Sprintf("included output %v can't be spent", scoid))
+			}
+		}
 		// Check that the output can be spent.
 		if err := tb.wallet.checkOutput(tb.wallet.dbTx, consensusHeight, scoid, sco, dustThreshold); err != nil {
+			if isIncluded {
+				return errors.AddContext(err, fmt.Sprintf("included output %v can't be spent", scoid))
+			}
 			if errors.Contains(err, errSpendHeightTooHigh) {
 				potentialFund = potentialFund.Add(sco.Value)
 			}
 			continue
 		}
-
-		// Add a siacoin input for this output.
-		sci := types.SiacoinInput{
-			ParentID:         scoid,
-			UnlockConditions: tb.wallet.keys[sco.UnlockHash].UnlockConditions,
-		}
-		parentTxn.SiacoinInputs = append(parentTxn.SiacoinInputs, sci)
-		spentScoids = append(spentScoids, scoid)
-
-		// Add the output to the total fund
-		fund = fund.Add(sco.Value)
+		found[scoid] = struct{}{}
+		spendable.ids = append(spendable.ids, scoid)
+		spendable.outputs = append(spendable.outputs, sco)
 		potentialFund = potentialFund.Add(sco.Value)
-		if fund.Cmp(amount) >= 0 {
-			break
+	}
+	for _, scoid := range cc.Include {
+		if _, exists := found[scoid]; !exists {
+			return fmt.Errorf("included output %v doesn't belong to the wallet", scoid)
 		}
 	}
+
+	// Select the outputs to spend. If outputs were included, all of them are
+	// spent.
+	var selected sortedOutputs
+	var fund types.Currency
+	if len(cc.Include) > 0 {
+		selected, fund = selectSiacoinOutputs(spendable, spendable.total(), cc.Strategy)
+	} else {
+		selected, fund = selectSiacoinOutputs(spendable, amount, cc.Strategy)
+	}
 	if potentialFund.Cmp(amount) >= 0 && fund.Cmp(amount) < 0 {
 		return modules.ErrIncompleteTransactions
 	}
@@ -274,9 +331,24 @@ func (tb *transactionBuilder) FundSiacoins(amount types.Currency) (err error) {
 		return modules.ErrLowBalance
 	}
 
+	// Create a parent transaction that will add the correct amount of
+	// siacoins to the transaction.
+	parentTxn := types.Transaction{}
+	selectedKeys := make([]spendableKey, len(selected.ids))
+	for i := range selected.ids {
+		selectedKeys[i], _ = tb.wallet.signingKey(selected.outputs[i].UnlockHash)
+	}
+	for i, scoid := range selected.ids {
+		sci := types.SiacoinInput{
+			ParentID:         scoid,
+			UnlockConditions: selectedKeys[i].UnlockConditions,
+		}
+		parentTxn.SiacoinInputs = append(parentTxn.SiacoinInputs, sci)
+	}
+
 	// Create and add the output that will be used to fund the standard
 	// transaction.
-	parentUnlockConditions, err := tb.wallet.nextPrimarySeedAddress(tb.wallet.dbTx)
+	parentUnlockConditions, err := tb.wallet.nextAccountAddress(tb.wallet.dbTx, cc.Account)
 	if err != nil {
 		return err
 	}
@@ -294,25 +366,29 @@ func (tb *transactionBuilder) FundSiacoins(amount types.Currency) (err error) {
 
 	// Create a refund output if needed.
 	if !amount.Equals(fund) {
-		refundUnlockConditions, err := tb.wallet.nextPrimarySeedAddress(tb.wallet.dbTx)
-		if err != nil {
-			return err
-		}
-		defer func() {
+		refundAddress := cc.ChangeAddress
+		if refundAddress == (types.UnlockHash{}) {
+			refundUnlockConditions, err := tb.wallet.nextAccountAddress(tb.wallet.dbTx, cc.Account)
 			if err != nil {
-				tb.wallet.managedMarkAddressUnused(refundUnlockConditions)
+				return err
 			}
-		}()
+			defer func() {
+				if err != nil {
+					tb.wallet.managedMarkAddressUnused(refundUnlockConditions)
+				}
+			}()
+			refundAddress = refundUnlockConditions.UnlockHash()
+		}
 		refundOutput := types.SiacoinOutput{
 			Value:      fund.Sub(amount),
-			UnlockHash: refundUnlockConditions.UnlockHash(),
+			UnlockHash: refundAddress,
 		}
 		parentTxn.SiacoinOutputs = append(parentTxn.SiacoinOutputs, refundOutput)
 	}
 
 	// Sign all of the inputs to the parent transaction.
-	for _, sci := range parentTxn.SiacoinInputs {
-		addSignatures(&parentTxn, types.FullCoveredFields, sci.UnlockConditions, crypto.Hash(sci.ParentID), tb.wallet.keys[sci.UnlockConditions.UnlockHash()], consensusHeight)
+	for i, sci := range parentTxn.SiacoinInputs {
+		addSignatures(&parentTxn, types.FullCoveredFields, sci.UnlockConditions, crypto.Hash(sci.ParentID), selectedKeys[i], consensusHeight)
 	}
 	// Mark the parent output as spent. Must be done after the transaction is
 	// finished because otherwise the txid and output id will change.
@@ -332,7 +408,7 @@ func (tb *transactionBuilder) FundSiacoins(amount types.Currency) (err error) {
 	tb.transaction.SiacoinInputs = append(tb.transaction.SiacoinInputs, newInput)
 
 	// Mark all outputs that were spent as spent.
-	for _, scoid := range spentScoids {
+	for _, scoid := range selected.ids {
 		err = dbPutSpentOutput(tb.wallet.dbTx, types.OutputID(scoid), consensusHeight)
 		if err != nil {
 			return err
@@ -374,6 +450,11 @@ func (tb *transactionBuilder) FundSiafunds(amount types.Currency) (err error) {
 			return err
 		}
 
+		// Outputs of multisig addresses are never used for funding.
+		if _, isMultisig := tb.wallet.multisigAddrs[sfo.UnlockHash]; isMultisig {
+			continue
+		}
+
 		// Check that this output has not recently been spent by the wallet.
 		spendHeight, err := dbGetSpentOutput(tb.wallet.dbTx, types.OutputID(sfoid))
 		if err != nil {
@@ -69,11 +69,13 @@ func (w *Wallet) advanceSeedLookahead(index uint64) (bool, error) {
 }
 
 // isWalletAddress is a helper function that checks if an UnlockHash is
-// derived from one of the wallet's spendable keys or is being explicitly watched.
+// derived from one of the wallet's spendable keys, is being explicitly watched
+// or is a multisig address of the wallet.
 func (w *Wallet) isWalletAddress(uh types.UnlockHash) bool {
 	_, spendable := w.keys[uh]
 	_, watchonly := w.watchedAddrs[uh]
-	return spendable || watchonly
+	_, multisig := w.multisigAddrs[uh]
+	return spendable || watchonly || multisig
 }
 
 // updateLookahead uses a consensus change to update the seed progress if one of the outputs
@@ -549,6 +551,7 @@ func (w *Wallet) ProcessConsensusChange(cc modules.ConsensusChange) {
 
 	if cc.Synced {
 		go w.threadedDefragWallet()
+		go w.threadedSendScheduledPayments()
 	}
 }
 
@@ -80,6 +80,11 @@ type Wallet struct {
 	lookahead    map[types.UnlockHash]uint64
 	watchedAddrs map[types.UnlockHash]struct{}
 
+	// multisigAddrs contains the unlock conditions of the multisig addresses
+	// which contain a key of the wallet. Their outputs are tracked separately
+	// from the wallet's balance.
+	multisigAddrs map[types.UnlockHash]types.UnlockConditions
+
 	// unconfirmedProcessedTransactions tracks unconfirmed transactions.
 	//
 	// TODO: Replace this field with a linked list. Currently when a new
@@ -116,6 +121,14 @@ type Wallet struct {
 	// defragDisabled determines if the wallet is set to defrag outputs once it
 	// reaches a certain threshold
 	defragDisabled bool
+
+	// scheduleMu serializes changes to the scheduled payments and the
+	// sending of due payments.
+	scheduleMu sync.Mutex
+
+	// staticAlerter registers the wallet's alerts, such as failed scheduled
+	// payments.
+	staticAlerter *modules.GenericAlerter
 }
 
 // Height return the internal processed consensus height of the wallet
@@ -206,11 +219,15 @@ func NewCustomWallet(cs modules.ConsensusSet, tpool modules.TransactionPool, per
 		unusedKeys:   make(map[types.UnlockHash]types.UnlockConditions),
 		watchedAddrs: make(map[types.UnlockHash]struct{}),
 
+		multisigAddrs: make(map[types.UnlockHash]types.UnlockConditions),
+
 		unconfirmedSets: make(map[modules.TransactionSetID][]types.TransactionID),
 
 		persistDir: persistDir,
 
 		deps: deps,
+
+		staticAlerter: modules.NewAlerter("wallet"),
 	}
 	err := w.initPersist()
 	if err != nil {
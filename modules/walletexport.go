@@ -0,0 +1,118 @@
+package modules
+
+import (
+	"math/big"
+
+	"go.sia.tech/siad/types"
+)
+
+const (
+	// ExportFormatCSV exports the wallet history as CSV with one row per
+	// transaction. Currency values are in hastings.
+	ExportFormatCSV = "csv"
+
+	// ExportFormatJSON exports the wallet history as JSON.
+	ExportFormatJSON = "json"
+
+	// ExportFormatOFX exports the wallet history as an OFX 2.2 bank statement.
+	// Amounts are in siacoins with the currency code XSC.
+	ExportFormatOFX = "ofx"
+)
+
+type (
+	// ExportTransaction is a confirmed wallet transaction prepared for
+	// exporting. Only inputs and outputs which belong to the wallet's
+	// addresses are included. NetSiacoins is the signed difference between
+	// the received and the sent siacoins in hastings.
+	ExportTransaction struct {
+		TransactionID         types.TransactionID `json:"transactionid"`
+		ConfirmationHeight    types.BlockHeight   `json:"confirmationheight"`
+		ConfirmationTimestamp types.Timestamp     `json:"confirmationtimestamp"`
+
+		Inputs  []ProcessedInput  `json:"inputs"`
+		Outputs []ProcessedOutput `json:"outputs"`
+
+		SiacoinsIn  types.Currency `json:"siacoinsin"`
+		SiacoinsOut types.Currency `json:"siacoinsout"`
+		SiafundsIn  types.Currency `json:"siafundsin"`
+		SiafundsOut types.Currency `json:"siafundsout"`
+		MinerFees   types.Currency `json:"minerfees"`
+		NetSiacoins string         `json:"netsiacoins"`
+
+		// FileContractIDs are the ids of the file contracts which are
+		// formed, revised or proven by the transaction.
+		FileContractIDs []types.FileContractID `json:"filecontractids"`
+	}
+)
+
+// NewExportTransaction prepares a processed transaction for exporting.
+func NewExportTransaction(pt ProcessedTransaction) ExportTransaction {
+	et := ExportTransaction{
+		TransactionID:         pt.TransactionID,
+		ConfirmationHeight:    pt.ConfirmationHeight,
+		ConfirmationTimestamp: pt.ConfirmationTimestamp,
+		Inputs:                []ProcessedInput{},
+		Outputs:               []ProcessedOutput{},
+		FileContractIDs:       []types.FileContractID{},
+	}
+	for _, input := range pt.Inputs {
+		if !input.WalletAddress {
+			continue
+		}
+		et.Inputs = append(et.Inputs, input)
+		switch input.FundType {
+		case types.SpecifierSiacoinInput:
+			et.SiacoinsOut = et.SiacoinsOut.Add(input.Value)
+		case types.SpecifierSiafundInput:
+			et.SiafundsOut = et.SiafundsOut.Add(input.Value)
+		}
+	}
+	var fees types.Currency
+	for _, output := range pt.Outputs {
+		if output.FundType == types.SpecifierMinerFee {
+			fees = fees.Add(output.Value)
+		}
+		if !output.WalletAddress {
+			continue
+		}
+		et.Outputs = append(et.Outputs, output)
+		switch output.FundType {
+		case types.SpecifierSiacoinOutput, types.SpecifierMinerPayout, types.SpecifierClaimOutput:
+			et.SiacoinsIn = et.SiacoinsIn.Add(output.Value)
+		case types.SpecifierSiafundOutput:
+			et.SiafundsIn = et.SiafundsIn.Add(output.Value)
+		}
+	}
+	// Miner fees don't belong to an address. The wallet pays the share of the
+	// fees which matches its share of the siacoin inputs.
+	var totalIn types.Currency
+	for _, input := range pt.Inputs {
+		if input.FundType == types.SpecifierSiacoinInput {
+			totalIn = totalIn.Add(input.Value)
+		}
+	}
+	if !totalIn.IsZero() {
+		et.MinerFees = fees.Mul(et.SiacoinsOut).Div(totalIn)
+	}
+
+	net := new(big.Int).Sub(et.SiacoinsIn.Big(), et.SiacoinsOut.Big())
+	et.NetSiacoins = net.String()
+
+	seen := make(map[types.FileContractID]struct{})
+	addContract := func(id types.FileContractID) {
+		if _, exists := seen[id]; !exists {
+			seen[id] = struct{}{}
+			et.FileContractIDs = append(et.FileContractIDs, id)
+		}
+	}
+	for i := range pt.Transaction.FileContracts {
+		addContract(pt.Transaction.FileContractID(uint64(i)))
+	}
+	for _, fcr := range pt.Transaction.FileContractRevisions {
+		addContract(fcr.ParentID)
+	}
+	for _, sp := range pt.Transaction.StorageProofs {
+		addContract(sp.ParentID)
+	}
+	return et
+}
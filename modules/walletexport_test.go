@@ -0,0 +1,58 @@
+package modules
+
+import (
+	"testing"
+
+	"go.sia.tech/siad/types"
+)
+
+// TestExportTransaction tests preparing a transaction for exporting.
+func TestExportTransaction(t *testing.T) {
+	ours, theirs := types.UnlockHash{1}, types.UnlockHash{2}
+	txn := types.Transaction{
+		FileContracts: []types.FileContract{{}},
+	}
+	pt := ProcessedTransaction{
+		Transaction:           txn,
+		TransactionID:         txn.ID(),
+		ConfirmationHeight:    10,
+		ConfirmationTimestamp: 1614600000,
+		Inputs: []ProcessedInput{
+			{FundType: types.SpecifierSiacoinInput, WalletAddress: true, RelatedAddress: ours, Value: types.SiacoinPrecision.Mul64(10)},
+			{FundType: types.SpecifierSiacoinInput, WalletAddress: false, RelatedAddress: theirs, Value: types.SiacoinPrecision.Mul64(5)},
+		},
+		Outputs: []ProcessedOutput{
+			{FundType: types.SpecifierSiacoinOutput, WalletAddress: true, RelatedAddress: ours, Value: types.SiacoinPrecision.Mul64(3)},
+			{FundType: types.SpecifierSiacoinOutput, WalletAddress: false, RelatedAddress: theirs, Value: types.SiacoinPrecision.Mul64(11)},
+			{FundType: types.SpecifierMinerFee, Value: types.SiacoinPrecision.Div64(2)},
+		},
+	}
+	et := NewExportTransaction(pt)
+	if len(et.Inputs) != 1 || len(et.Outputs) != 1 {
+		t.Fatal("only our inputs and outputs should be exported", et.Inputs, et.Outputs)
+	}
+	if !et.SiacoinsIn.Equals(types.SiacoinPrecision.Mul64(3)) || !et.SiacoinsOut.Equals(types.SiacoinPrecision.Mul64(10)) {
+		t.Fatal("wrong values", et.SiacoinsIn, et.SiacoinsOut)
+	}
+	// The wallet funded 10 of the 15 SC of inputs, so it pays 2/3 of the fees.
+	if !et.MinerFees.Equals(types.SiacoinPrecision.Div64(2).Mul64(10).Div64(15)) {
+		t.Fatal("wrong miner fees", et.MinerFees)
+	}
+	if et.NetSiacoins != "-"+types.SiacoinPrecision.Mul64(7).String() {
+		t.Fatal("wrong net value", et.NetSiacoins)
+	}
+	if len(et.FileContractIDs) != 1 || et.FileContractIDs[0] != txn.FileContractID(0) {
+		t.Fatal("wrong file contracts", et.FileContractIDs)
+	}
+
+	// Miner payouts are received siacoins without miner fees.
+	reward := NewExportTransaction(ProcessedTransaction{
+		Outputs: []ProcessedOutput{
+			{FundType: types.SpecifierMinerPayout, WalletAddress: true, RelatedAddress: ours, Value: types.SiacoinPrecision},
+			{FundType: types.SpecifierMinerFee, Value: types.SiacoinPrecision},
+		},
+	})
+	if !reward.SiacoinsIn.Equals(types.SiacoinPrecision) || !reward.MinerFees.IsZero() || reward.NetSiacoins != types.SiacoinPrecision.String() {
+		t.Fatal("wrong block reward", reward)
+	}
+}
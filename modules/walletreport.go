@@ -0,0 +1,167 @@
+package modules
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/types"
+)
+
+const (
+	// ReportPeriodDay groups a fiat report by UTC day.
+	ReportPeriodDay ReportPeriod = "day"
+
+	// ReportPeriodMonth groups a fiat report by UTC month. This is the
+	// default period.
+	ReportPeriodMonth ReportPeriod = "month"
+
+	// ReportPeriodYear groups a fiat report by UTC year.
+	ReportPeriodYear ReportPeriod = "year"
+)
+
+var (
+	// ErrUnknownReportPeriod is returned if a fiat report is requested for an
+	// unknown period.
+	ErrUnknownReportPeriod = errors.New("unknown report period")
+)
+
+type (
+	// ReportPeriod is the length of the periods a fiat report is summarized
+	// by.
+	ReportPeriod string
+
+	// ExchangeRatePoint is the exchange rate of a currency at a point in
+	// time. The wallet values a transaction using the most recent point at or
+	// before its confirmation.
+	ExchangeRatePoint struct {
+		Timestamp types.Timestamp    `json:"timestamp"`
+		Rate      types.ExchangeRate `json:"rate"`
+	}
+
+	// FiatTransaction is the fiat value of a confirmed wallet transaction.
+	// Received and Sent are the net siacoins the wallet received or sent, at
+	// most one of them is non-zero. Fiat values are decimal strings which are
+	// empty if no exchange rate is known for the confirmation time.
+	FiatTransaction struct {
+		TransactionID         types.TransactionID `json:"transactionid"`
+		ConfirmationHeight    types.BlockHeight   `json:"confirmationheight"`
+		ConfirmationTimestamp types.Timestamp     `json:"confirmationtimestamp"`
+
+		Received types.Currency      `json:"received"`
+		Sent     types.Currency      `json:"sent"`
+		Rate     *types.ExchangeRate `json:"rate"`
+
+		FiatReceived string `json:"fiatreceived"`
+		FiatSent     string `json:"fiatsent"`
+
+		// RealizedGain is the fiat value of the sent siacoins minus the fiat
+		// value at which they were received. Siacoins are matched
+		// first-in-first-out. Losses are negative.
+		RealizedGain string `json:"realizedgain"`
+	}
+
+	// FiatPeriodSummary summarizes the fiat values of the transactions of a
+	// period.
+	FiatPeriodSummary struct {
+		Period       string         `json:"period"`
+		Transactions uint64         `json:"transactions"`
+		Received     types.Currency `json:"received"`
+		Sent         types.Currency `json:"sent"`
+		FiatReceived string         `json:"fiatreceived"`
+		FiatSent     string         `json:"fiatsent"`
+		RealizedGain string         `json:"realizedgain"`
+	}
+
+	// FiatReport values the confirmed transactions of the wallet which were
+	// confirmed between Start and End in a fiat currency.
+	FiatReport struct {
+		Symbol string          `json:"symbol"`
+		Period ReportPeriod    `json:"period"`
+		Start  types.Timestamp `json:"start"`
+		End    types.Timestamp `json:"end"`
+
+		Transactions []FiatTransaction   `json:"transactions"`
+		Periods      []FiatPeriodSummary `json:"periods"`
+		Total        FiatPeriodSummary   `json:"total"`
+
+		// MissingRates is the number of transactions, including transactions
+		// before Start which determine the cost basis, for which no exchange
+		// rate was known. Siacoins received without a known rate have a cost
+		// basis of zero.
+		MissingRates uint64 `json:"missingrates"`
+	}
+)
+
+// Key returns the name of the period which contains the timestamp, e.g.
+// "2021-03" for ReportPeriodMonth.
+func (p ReportPeriod) Key(ts types.Timestamp) (string, error) {
+	t := time.Unix(int64(ts), 0).UTC()
+	switch p {
+	case ReportPeriodDay:
+		return t.Format("2006-01-02"), nil
+	case ReportPeriodMonth:
+		return t.Format("2006-01"), nil
+	case ReportPeriodYear:
+		return t.Format("2006"), nil
+	default:
+		return "", ErrUnknownReportPeriod
+	}
+}
+
+// ParseReportTime parses a unix timestamp, a date in the form 2006-01-02 or an
+// RFC 3339 time. Dates are interpreted as midnight UTC.
+func ParseReportTime(s string) (types.Timestamp, error) {
+	s = strings.TrimSpace(s)
+	if unix, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return types.Timestamp(unix), nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return types.Timestamp(t.Unix()), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected a unix timestamp, 2006-01-02 or RFC 3339", s)
+	}
+	return types.Timestamp(t.Unix()), nil
+}
+
+// ParseExchangeRateCSV reads exchange rate points from CSV records of the form
+// 'time,rate'. The time is parsed by ParseReportTime and the rate is the value
+// of one siacoin in the currency with the given symbol. A header row is
+// skipped.
+func ParseExchangeRateCSV(r io.Reader, symbol string) ([]ExchangeRatePoint, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = 2
+	cr.TrimLeadingSpace = true
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, errors.AddContext(err, "failed to read csv")
+	}
+	points := make([]ExchangeRatePoint, 0, len(records))
+	for i, record := range records {
+		ts, err := ParseReportTime(record[0])
+		if err != nil && i == 0 {
+			// Skip the header.
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("line %v: %v", i+1, err)
+		}
+		rate, err := types.ParseExchangeRate(strings.TrimSpace(record[1]) + " " + symbol)
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %v", i+1, err)
+		} else if rate == nil {
+			return nil, fmt.Errorf("line %v: missing rate", i+1)
+		}
+		points = append(points, ExchangeRatePoint{
+			Timestamp: ts,
+			Rate:      *rate,
+		})
+	}
+	return points, nil
+}
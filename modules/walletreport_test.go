@@ -0,0 +1,66 @@
+package modules
+
+import (
+	"strings"
+	"testing"
+
+	"go.sia.tech/siad/types"
+)
+
+// TestParseExchangeRateCSV tests parsing exchange rate points from CSV.
+func TestParseExchangeRateCSV(t *testing.T) {
+	csv := `date,price
+2021-01-01,0.01
+1612137600, 0.02
+2021-03-01T12:00:00Z,0.035
+`
+	points, err := ParseExchangeRateCSV(strings.NewReader(csv), "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []struct {
+		ts   types.Timestamp
+		rate string
+	}{
+		{1609459200, "0.01 USD"},
+		{1612137600, "0.02 USD"},
+		{1614600000, "0.035 USD"},
+	}
+	if len(points) != len(expected) {
+		t.Fatal("wrong number of points", len(points))
+	}
+	for i, e := range expected {
+		if points[i].Timestamp != e.ts || points[i].Rate.String() != e.rate {
+			t.Errorf("point %v: expected %v %v, got %v %v", i, e.ts, e.rate, points[i].Timestamp, points[i].Rate)
+		}
+	}
+
+	// Invalid rows after the header are rejected.
+	for _, invalid := range []string{
+		"2021-01-01,0.01\nyesterday,0.01",
+		"2021-01-01,abc",
+		"2021-01-01,0",
+		"2021-01-01,0.01,extra",
+	} {
+		if _, err := ParseExchangeRateCSV(strings.NewReader(invalid), "USD"); err == nil {
+			t.Errorf("%q: expected an error", invalid)
+		}
+	}
+}
+
+// TestReportPeriodKey tests naming the periods of a fiat report.
+func TestReportPeriodKey(t *testing.T) {
+	const ts = 1614600000 // 2021-03-01T12:00:00Z
+	for period, key := range map[ReportPeriod]string{
+		ReportPeriodDay:   "2021-03-01",
+		ReportPeriodMonth: "2021-03",
+		ReportPeriodYear:  "2021",
+	} {
+		if k, err := period.Key(ts); err != nil || k != key {
+			t.Errorf("%v: expected %v, got %v %v", period, key, k, err)
+		}
+	}
+	if _, err := ReportPeriod("week").Key(ts); err != ErrUnknownReportPeriod {
+		t.Fatal("expected ErrUnknownReportPeriod, got", err)
+	}
+}
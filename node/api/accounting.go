@@ -0,0 +1,66 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/julienschmidt/httprouter"
+
+	"go.sia.tech/siad/modules"
+)
+
+type (
+	// AccountingGET contains the information that is returned after a GET
+	// request to /accounting. The entries are sorted by timestamp from oldest
+	// to newest.
+	AccountingGET []modules.AccountingInfo
+)
+
+// accountingHandlerGET handles the API call to /accounting. It returns the
+// persisted accounting information within the requested time range. If the
+// range includes the current time, the current accounting information is
+// appended as the last entry.
+func (api *API) accountingHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	// Parse the start and end timestamps.
+	start := int64(0)
+	end := int64(math.MaxInt64)
+	startStr, endStr := req.FormValue("start"), req.FormValue("end")
+	if startStr != "" {
+		var err error
+		start, err = strconv.ParseInt(startStr, 10, 64)
+		if err != nil {
+			WriteError(w, Error{"parsing integer value for parameter `start` failed: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	if endStr != "" {
+		var err error
+		end, err = strconv.ParseInt(endStr, 10, 64)
+		if err != nil {
+			WriteError(w, Error{"parsing integer value for parameter `end` failed: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Grab the persisted history.
+	history, err := api.accounting.History(start, end)
+	if err != nil {
+		WriteError(w, Error{"unable to get accounting history: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	// Add the current accounting information if it falls within the range.
+	ai, err := api.accounting.Accounting()
+	if err != nil {
+		WriteError(w, Error{"unable to get accounting information: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	if ai.Timestamp >= start && ai.Timestamp <= end {
+		history = append(history, ai)
+	}
+	if history == nil {
+		history = []modules.AccountingInfo{}
+	}
+	WriteJSON(w, AccountingGET(history))
+}
@@ -0,0 +1,320 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/threadgroup"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/modules"
+)
+
+const (
+	// AlertEventRegistered is the type of the event which is sent when an
+	// alert is registered.
+	AlertEventRegistered = "registered"
+
+	// AlertEventUnregistered is the type of the event which is sent when an
+	// alert is unregistered.
+	AlertEventUnregistered = "unregistered"
+)
+
+var (
+	// alertPollInterval is the interval at which the alert bus checks the
+	// modules for registered and unregistered alerts.
+	alertPollInterval = build.Select(build.Var{
+		Dev:      time.Second,
+		Standard: 5 * time.Second,
+		Testing:  100 * time.Millisecond,
+	}).(time.Duration)
+
+	// alertWebhookAttempts is the number of times the delivery of an event to
+	// a webhook is attempted before it is dropped.
+	alertWebhookAttempts = 3
+
+	// alertWebhookRetryInterval is the time between two delivery attempts of
+	// an event to a webhook.
+	alertWebhookRetryInterval = build.Select(build.Var{
+		Dev:      5 * time.Second,
+		Standard: 10 * time.Second,
+		Testing:  100 * time.Millisecond,
+	}).(time.Duration)
+
+	// alertWebhookTimeout is the timeout of a single webhook request.
+	alertWebhookTimeout = build.Select(build.Var{
+		Dev:      30 * time.Second,
+		Standard: 30 * time.Second,
+		Testing:  5 * time.Second,
+	}).(time.Duration)
+
+	// alertStreamBufferSize is the number of events which are buffered for a
+	// subscriber of the alert stream. Subscribers which fall behind lose
+	// events.
+	alertStreamBufferSize = 100
+
+	// alertStreamKeepAliveInterval is the interval at which an alert stream
+	// sends keepalive comments to prevent idle connections from being closed
+	// while no alerts are registered or unregistered.
+	alertStreamKeepAliveInterval = build.Select(build.Var{
+		Dev:      10 * time.Second,
+		Standard: 30 * time.Second,
+		Testing:  time.Second,
+	}).(time.Duration)
+)
+
+type (
+	// DaemonAlertEvent is the event which is sent to the alert stream and the
+	// alert webhooks when an alert is registered or unregistered.
+	DaemonAlertEvent struct {
+		modules.Alert
+		Event     string    `json:"event"`
+		Timestamp time.Time `json:"timestamp"`
+	}
+
+	// DaemonAlertWebhooksGet contains the urls of the alert webhooks.
+	DaemonAlertWebhooksGet struct {
+		Webhooks []string `json:"webhooks"`
+	}
+
+	// alertBus periodically checks the alerts of the loaded modules and
+	// notifies the subscribers of the alert stream and the webhooks about
+	// registered and unregistered alerts.
+	alertBus struct {
+		alerters    []modules.Alerter
+		known       map[modules.Alert]struct{}
+		subscribers map[chan DaemonAlertEvent]struct{}
+		mu          sync.Mutex
+
+		staticConfig *modules.SiadConfig
+		staticTG     threadgroup.ThreadGroup
+	}
+)
+
+// newAlertBus creates a new alertBus and starts watching the alerts of the
+// provided alerters.
+func newAlertBus(cfg *modules.SiadConfig, alerters []modules.Alerter) *alertBus {
+	ab := &alertBus{
+		alerters:     alerters,
+		known:        make(map[modules.Alert]struct{}),
+		subscribers:  make(map[chan DaemonAlertEvent]struct{}),
+		staticConfig: cfg,
+	}
+	go ab.threadedWatchAlerts()
+	return ab
+}
+
+// managedClose stops the alertBus.
+func (ab *alertBus) managedClose() error {
+	return ab.staticTG.Stop()
+}
+
+// managedSetAlerters replaces the alerters which are watched by the bus.
+func (ab *alertBus) managedSetAlerters(alerters []modules.Alerter) {
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+	ab.alerters = alerters
+}
+
+// managedSubscribe adds a subscriber to the bus. The returned function removes
+// the subscriber again.
+func (ab *alertBus) managedSubscribe() (<-chan DaemonAlertEvent, func()) {
+	c := make(chan DaemonAlertEvent, alertStreamBufferSize)
+	ab.mu.Lock()
+	ab.subscribers[c] = struct{}{}
+	ab.mu.Unlock()
+	return c, func() {
+		ab.mu.Lock()
+		delete(ab.subscribers, c)
+		ab.mu.Unlock()
+	}
+}
+
+// managedUpdate compares the current alerts to the known alerts, notifies the
+// subscribers about the differences and returns them.
+func (ab *alertBus) managedUpdate() []DaemonAlertEvent {
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+
+	crit, err, warn := collectAlerts(ab.alerters)
+	current := make(map[modules.Alert]struct{})
+	for _, alerts := range [][]modules.Alert{crit, err, warn} {
+		for _, alert := range alerts {
+			current[alert] = struct{}{}
+		}
+	}
+	var events []DaemonAlertEvent
+	now := time.Now()
+	for alert := range current {
+		if _, exists := ab.known[alert]; !exists {
+			events = append(events, DaemonAlertEvent{Alert: alert, Event: AlertEventRegistered, Timestamp: now})
+		}
+	}
+	for alert := range ab.known {
+		if _, exists := current[alert]; !exists {
+			events = append(events, DaemonAlertEvent{Alert: alert, Event: AlertEventUnregistered, Timestamp: now})
+		}
+	}
+	ab.known = current
+
+	for c := range ab.subscribers {
+		for _, event := range events {
+			select {
+			case c <- event:
+			default:
+			}
+		}
+	}
+	return events
+}
+
+// threadedWatchAlerts periodically checks for registered and unregistered
+// alerts and delivers them to the webhooks.
+func (ab *alertBus) threadedWatchAlerts() {
+	if err := ab.staticTG.Add(); err != nil {
+		return
+	}
+	defer ab.staticTG.Done()
+
+	ticker := time.NewTicker(alertPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ab.staticTG.StopChan():
+			return
+		case <-ticker.C:
+		}
+		events := ab.managedUpdate()
+		if len(events) == 0 {
+			continue
+		}
+		for _, webhook := range ab.staticConfig.AlertWebhooks() {
+			go ab.threadedDeliverWebhook(webhook, events)
+		}
+	}
+}
+
+// threadedDeliverWebhook delivers the events to a webhook. Every event is sent
+// as a separate json encoded POST request.
+func (ab *alertBus) threadedDeliverWebhook(webhook string, events []DaemonAlertEvent) {
+	if err := ab.staticTG.Add(); err != nil {
+		return
+	}
+	defer ab.staticTG.Done()
+
+	client := &http.Client{Timeout: alertWebhookTimeout}
+	for _, event := range events {
+		for attempt := 1; attempt <= alertWebhookAttempts; attempt++ {
+			err := deliverAlertEvent(client, webhook, event)
+			if err == nil {
+				break
+			}
+			select {
+			case <-ab.staticTG.StopChan():
+				return
+			case <-time.After(alertWebhookRetryInterval):
+			}
+		}
+	}
+}
+
+// deliverAlertEvent sends a single event to a webhook.
+func deliverAlertEvent(client *http.Client, webhook string, event DaemonAlertEvent) error {
+	b, err := json.Marshal(event)
+	if err != nil {
+		return errors.AddContext(err, "failed to marshal event")
+	}
+	req, err := http.NewRequest("POST", webhook, bytes.NewReader(b))
+	if err != nil {
+		return errors.AddContext(err, "failed to create request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Sia-Agent")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %v", resp.StatusCode)
+	}
+	return nil
+}
+
+// daemonAlertsStreamHandlerGET handles the API call that streams registered
+// and unregistered alerts as server-sent events.
+func (api *API) daemonAlertsStreamHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		WriteError(w, Error{"streaming is not supported by the connection"}, http.StatusInternalServerError)
+		return
+	}
+	events, unsubscribe := api.staticAlertBus.managedSubscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := time.NewTicker(alertStreamKeepAliveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case <-api.staticAlertBus.staticTG.StopChan():
+			return
+		case event := <-events:
+			writeServerSentEvent(w, flusher, event.Event, event)
+		case <-ticker.C:
+			_, _ = fmt.Fprint(w, ": keepalive\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// daemonAlertsWebhooksHandlerGET handles the API call that returns the alert
+// webhooks.
+func (api *API) daemonAlertsWebhooksHandlerGET(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	WriteJSON(w, DaemonAlertWebhooksGet{
+		Webhooks: api.siadConfig.AlertWebhooks(),
+	})
+}
+
+// daemonAlertsWebhooksAddHandlerPOST handles the API call that adds an alert
+// webhook.
+func (api *API) daemonAlertsWebhooksAddHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	webhook := req.FormValue("url")
+	if webhook == "" {
+		WriteError(w, Error{"url is required"}, http.StatusBadRequest)
+		return
+	}
+	if err := api.siadConfig.AddAlertWebhook(webhook); err != nil {
+		WriteError(w, Error{"unable to add webhook: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// daemonAlertsWebhooksRemoveHandlerPOST handles the API call that removes an
+// alert webhook.
+func (api *API) daemonAlertsWebhooksRemoveHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	webhook := req.FormValue("url")
+	if webhook == "" {
+		WriteError(w, Error{"url is required"}, http.StatusBadRequest)
+		return
+	}
+	if err := api.siadConfig.RemoveAlertWebhook(webhook); err != nil {
+		WriteError(w, Error{"unable to remove webhook: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/modules"
+)
+
+// TestAlertBus tests that the alertBus notifies subscribers and webhooks about
+// registered and unregistered alerts.
+func TestAlertBus(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	dir := build.TempDir("api", t.Name())
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := modules.NewConfig(filepath.Join(dir, modules.ConfigName))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a webhook which forwards the received events.
+	received := make(chan DaemonAlertEvent, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var event DaemonAlertEvent
+		if err := json.NewDecoder(req.Body).Decode(&event); err != nil {
+			t.Error(err)
+		}
+		received <- event
+	}))
+	defer server.Close()
+	if err := cfg.AddAlertWebhook(server.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a bus with an alerter. The alerter's test alerts are registered
+	// before the bus is created so they should be reported first.
+	alerter := modules.NewAlerter("test")
+	ab := newAlertBus(cfg, []modules.Alerter{alerter})
+	defer func() {
+		if err := ab.managedClose(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	events, unsubscribe := ab.managedSubscribe()
+	defer unsubscribe()
+	crit, errs, warn := alerter.Alerts()
+	numTestAlerts := len(crit) + len(errs) + len(warn)
+
+	// next returns the next event of a channel.
+	next := func(c <-chan DaemonAlertEvent) DaemonAlertEvent {
+		select {
+		case event := <-c:
+			return event
+		case <-time.After(10 * time.Second):
+			t.Fatal("no event received")
+		}
+		return DaemonAlertEvent{}
+	}
+	for i := 0; i < numTestAlerts; i++ {
+		if event := next(events); event.Event != AlertEventRegistered {
+			t.Fatal("expected registered event", event)
+		}
+		if event := next(received); event.Event != AlertEventRegistered {
+			t.Fatal("expected registered event", event)
+		}
+	}
+
+	// Register an alert.
+	alerter.RegisterAlert("storage-folder", "msg", "cause", modules.SeverityCritical)
+	expected := modules.Alert{
+		Cause:    "cause",
+		Module:   "test",
+		Msg:      "msg",
+		Severity: modules.SeverityCritical,
+	}
+	for _, c := range []<-chan DaemonAlertEvent{events, received} {
+		event := next(c)
+		if event.Event != AlertEventRegistered || event.Alert != expected {
+			t.Fatal("wrong event", event)
+		}
+	}
+
+	// Unregister it again.
+	alerter.UnregisterAlert("storage-folder")
+	for _, c := range []<-chan DaemonAlertEvent{events, received} {
+		event := next(c)
+		if event.Event != AlertEventUnregistered || event.Alert != expected {
+			t.Fatal("wrong event", event)
+		}
+	}
+
+	// After unsubscribing no more events should be sent to the subscriber.
+	unsubscribe()
+	alerter.RegisterAlert("storage-folder", "msg", "cause", modules.SeverityCritical)
+	next(received)
+	select {
+	case event := <-events:
+		t.Fatal("unexpected event", event)
+	default:
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/threadgroup"
+
 	"go.sia.tech/siad/build"
 	"go.sia.tech/siad/modules"
 )
@@ -126,10 +128,13 @@ type (
 
 		requiredUserAgent string
 		requiredPassword  string
+		metricsEnabled    bool
 		Shutdown          func() error
 		siadConfig        *modules.SiadConfig
 
+		staticAlertBus  *alertBus
 		staticStartTime time.Time
+		staticTG        threadgroup.ThreadGroup
 
 		staticDeps modules.Dependencies
 	}
@@ -182,6 +187,21 @@ func (api *API) SetModules(acc modules.Accounting, cs modules.ConsensusSet, e mo
 		Wallet:          api.wallet != nil,
 	}
 	api.modulesSet = true
+	api.staticAlertBus.managedSetAlerters(api.alerters())
+	api.buildHTTPRoutes()
+}
+
+// Close stops the background threads and long-lived streams of the API. It
+// doesn't close the modules.
+func (api *API) Close() error {
+	return errors.Compose(api.staticAlertBus.managedClose(), api.staticTG.Stop())
+}
+
+// EnableMetrics registers the /metrics endpoint which exposes metrics of the
+// loaded modules in the Prometheus text format. It needs to be called before
+// the API starts serving requests.
+func (api *API) EnableMetrics() {
+	api.metricsEnabled = true
 	api.buildHTTPRoutes()
 }
 
@@ -221,6 +241,7 @@ func NewCustom(cfg *modules.SiadConfig, requiredUserAgent string, requiredPasswo
 		staticDeps:      deps,
 		staticStartTime: time.Now(),
 	}
+	api.staticAlertBus = newAlertBus(cfg, api.alerters())
 
 	// Register API handlers
 	api.buildHTTPRoutes()
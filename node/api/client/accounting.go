@@ -0,0 +1,18 @@
+package client
+
+import (
+	"fmt"
+	"net/url"
+
+	"go.sia.tech/siad/node/api"
+)
+
+// AccountingGet requests the /accounting resource for the accounting
+// information recorded between the start and end Unix timestamps.
+func (c *Client) AccountingGet(start, end int64) (ag api.AccountingGET, err error) {
+	values := url.Values{}
+	values.Set("start", fmt.Sprint(start))
+	values.Set("end", fmt.Sprint(end))
+	err = c.get("/accounting?"+values.Encode(), &ag)
+	return
+}
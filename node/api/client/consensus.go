@@ -124,3 +124,31 @@ func (c *Client) ConsensusSetSubscribe(subscriber modules.ConsensusSetSubscriber
 	}()
 	return ch, func() { close(unsub) }
 }
+
+// ConsensusChangeStream is a stream of consensus changes returned by
+// ConsensusStream.
+type ConsensusChangeStream struct {
+	staticStream *eventStream
+}
+
+// ConsensusStream requests the /consensus/stream/:id resource which streams
+// all consensus changes after the provided change. The returned stream needs
+// to be closed by the caller.
+func (c *Client) ConsensusStream(ccid modules.ConsensusChangeID) (*ConsensusChangeStream, error) {
+	stream, err := c.newEventStream(fmt.Sprintf("/consensus/stream/%s", ccid))
+	if err != nil {
+		return nil, err
+	}
+	return &ConsensusChangeStream{staticStream: stream}, nil
+}
+
+// Close closes the consensus change stream.
+func (ccs *ConsensusChangeStream) Close() error {
+	return ccs.staticStream.Close()
+}
+
+// Next blocks until the next consensus change is received and returns it.
+func (ccs *ConsensusChangeStream) Next() (cce api.ConsensusChangeEvent, err error) {
+	_, err = ccs.staticStream.next(&cce)
+	return
+}
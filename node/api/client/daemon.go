@@ -74,3 +74,53 @@ func (c *Client) DaemonUpdatePost() (err error) {
 	err = c.post("/daemon/update", "", nil)
 	return
 }
+
+// AlertStream is a stream of alert events returned by DaemonAlertsStream.
+type AlertStream struct {
+	staticStream *eventStream
+}
+
+// DaemonAlertsStream requests the /daemon/alerts/stream resource. The
+// returned stream needs to be closed by the caller.
+func (c *Client) DaemonAlertsStream() (*AlertStream, error) {
+	stream, err := c.newEventStream("/daemon/alerts/stream")
+	if err != nil {
+		return nil, err
+	}
+	return &AlertStream{staticStream: stream}, nil
+}
+
+// Close closes the alert stream.
+func (as *AlertStream) Close() error {
+	return as.staticStream.Close()
+}
+
+// Next blocks until the next alert event is received and returns it.
+func (as *AlertStream) Next() (event api.DaemonAlertEvent, err error) {
+	_, err = as.staticStream.next(&event)
+	return
+}
+
+// DaemonAlertWebhooksGet requests the /daemon/alerts/webhooks resource.
+func (c *Client) DaemonAlertWebhooksGet() (dawg api.DaemonAlertWebhooksGet, err error) {
+	err = c.get("/daemon/alerts/webhooks", &dawg)
+	return
+}
+
+// DaemonAlertWebhookAddPost uses the /daemon/alerts/webhooks/add endpoint to
+// add an alert webhook.
+func (c *Client) DaemonAlertWebhookAddPost(webhook string) (err error) {
+	values := url.Values{}
+	values.Set("url", webhook)
+	err = c.post("/daemon/alerts/webhooks/add", values.Encode(), nil)
+	return
+}
+
+// DaemonAlertWebhookRemovePost uses the /daemon/alerts/webhooks/remove
+// endpoint to remove an alert webhook.
+func (c *Client) DaemonAlertWebhookRemovePost(webhook string) (err error) {
+	values := url.Values{}
+	values.Set("url", webhook)
+	err = c.post("/daemon/alerts/webhooks/remove", values.Encode(), nil)
+	return
+}
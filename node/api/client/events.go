@@ -0,0 +1,74 @@
+package client
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/node/api"
+)
+
+// maxEventSize is the maximum size of a single server-sent event. Consensus
+// changes can contain multiple blocks so this needs to be fairly large.
+const maxEventSize = 1 << 26 // 64 MiB
+
+// eventStream reads server-sent events from a long-lived response.
+type eventStream struct {
+	staticBody    io.ReadCloser
+	staticScanner *bufio.Scanner
+}
+
+// newEventStream requests the specified resource and returns a stream of its
+// events. The returned stream needs to be closed by the caller.
+func (c *Client) newEventStream(resource string) (*eventStream, error) {
+	_, body, err := c.getReaderResponse(resource)
+	if err != nil {
+		return nil, err
+	}
+	if body == nil {
+		return nil, errors.New("no stream was returned")
+	}
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(nil, maxEventSize)
+	return &eventStream{
+		staticBody:    body,
+		staticScanner: scanner,
+	}, nil
+}
+
+// Close closes the stream.
+func (es *eventStream) Close() error {
+	return es.staticBody.Close()
+}
+
+// next blocks until the next event is received and decodes its data into
+// obj. "error" events are returned as an api.Error.
+func (es *eventStream) next(obj interface{}) (event string, err error) {
+	for es.staticScanner.Scan() {
+		line := es.staticScanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: ") && event == "error":
+			var apiErr api.Error
+			err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &apiErr)
+			if err != nil {
+				return event, errors.AddContext(err, "failed to decode error")
+			}
+			return event, apiErr
+		case strings.HasPrefix(line, "data: "):
+			err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), obj)
+			if err != nil {
+				return event, errors.AddContext(err, "failed to decode "+event)
+			}
+			return event, nil
+		}
+	}
+	if err := es.staticScanner.Err(); err != nil {
+		return "", errors.AddContext(err, "failed to read from stream")
+	}
+	return "", io.EOF
+}
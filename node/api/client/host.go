@@ -1,6 +1,7 @@
 package client
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"strconv"
@@ -123,6 +124,57 @@ func (c *Client) HostBandwidthGet() (gbg api.GatewayBandwidthGET, err error) {
 	return
 }
 
+// HostDrainGet requests the /host/drain GET endpoint
+func (c *Client) HostDrainGet() (hds modules.HostDrainStatus, err error) {
+	err = c.get("/host/drain", &hds)
+	return
+}
+
+// HostDrainPost requests the /host/drain POST endpoint
+func (c *Client) HostDrainPost(draining, rejectDeposits bool) (err error) {
+	values := url.Values{}
+	values.Set("draining", strconv.FormatBool(draining))
+	values.Set("rejectdeposits", strconv.FormatBool(rejectDeposits))
+	err = c.post("/host/drain", values.Encode(), nil)
+	return
+}
+
+// HostPricingGet requests the /host/pricing GET endpoint
+func (c *Client) HostPricingGet() (hps modules.HostPricingStatus, err error) {
+	err = c.get("/host/pricing", &hps)
+	return
+}
+
+// HostPricingPost requests the /host/pricing POST endpoint
+func (c *Client) HostPricingPost(policy modules.HostPricingPolicy) (err error) {
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return err
+	}
+	err = c.post("/host/pricing", string(data), nil)
+	return
+}
+
+// HostRenterFilterGet requests the /host/renters/filter GET endpoint
+func (c *Client) HostRenterFilterGet() (hrfg api.HostRenterFilterGET, err error) {
+	err = c.get("/host/renters/filter", &hrfg)
+	return
+}
+
+// HostRenterFilterPost requests the /host/renters/filter POST endpoint
+func (c *Client) HostRenterFilterPost(fm modules.FilterMode, renters []types.SiaPublicKey) (err error) {
+	hrfp := api.HostRenterFilterPOST{
+		FilterMode: fm.String(),
+		Renters:    renters,
+	}
+	data, err := json.Marshal(hrfp)
+	if err != nil {
+		return err
+	}
+	err = c.post("/host/renters/filter", string(data), nil)
+	return
+}
+
 // HostStorageFoldersAddPost uses the /host/storage/folders/add api endpoint to
 // add a storage folder to a host
 func (c *Client) HostStorageFoldersAddPost(path string, size uint64) (err error) {
@@ -133,6 +185,20 @@ func (c *Client) HostStorageFoldersAddPost(path string, size uint64) (err error)
 	return
 }
 
+// HostStorageFoldersMigratePost uses the /host/storage/folders/migrate api
+// endpoint to migrate sectors from one storage folder to another. If
+// numSectors is 0, all sectors of the source folder are migrated.
+func (c *Client) HostStorageFoldersMigratePost(from, to string, numSectors uint64) (err error) {
+	values := url.Values{}
+	values.Set("from", from)
+	values.Set("to", to)
+	if numSectors > 0 {
+		values.Set("sectors", strconv.FormatUint(numSectors, 10))
+	}
+	err = c.post("/host/storage/folders/migrate", values.Encode(), nil)
+	return
+}
+
 // HostStorageFoldersRemovePost uses the /host/storage/folders/remove api
 // endpoint to remove a storage folder from a host.
 func (c *Client) HostStorageFoldersRemovePost(path string, force bool) (err error) {
@@ -159,6 +225,57 @@ func (c *Client) HostStorageGet() (sg api.StorageGET, err error) {
 	return
 }
 
+// HostStorageMigrationGet requests the /host/storage/migration endpoint.
+func (c *Client) HostStorageMigrationGet() (sms modules.StorageMigrationStatus, err error) {
+	err = c.get("/host/storage/migration", &sms)
+	return
+}
+
+// HostStorageMigrationPost uses the /host/storage/migration endpoint to
+// enable or disable the rebalance policy and set the rate limit of the sector
+// migrator in bytes per second.
+func (c *Client) HostStorageMigrationPost(rebalance bool, rateLimit uint64) (err error) {
+	values := url.Values{}
+	values.Set("rebalance", strconv.FormatBool(rebalance))
+	values.Set("ratelimit", strconv.FormatUint(rateLimit, 10))
+	err = c.post("/host/storage/migration", values.Encode(), nil)
+	return
+}
+
+// HostStorageMigrationCancelPost uses the /host/storage/migration endpoint to
+// cancel all queued sector migrations.
+func (c *Client) HostStorageMigrationCancelPost() (err error) {
+	values := url.Values{}
+	values.Set("cancel", "true")
+	err = c.post("/host/storage/migration", values.Encode(), nil)
+	return
+}
+
+// HostStorageScrubGet requests the /host/storage/scrub endpoint.
+func (c *Client) HostStorageScrubGet() (ssg api.StorageScrubGET, err error) {
+	err = c.get("/host/storage/scrub", &ssg)
+	return
+}
+
+// HostStorageScrubPost uses the /host/storage/scrub endpoint to enable or
+// disable the sector scrubber and set its rate limit in bytes per second.
+func (c *Client) HostStorageScrubPost(enabled bool, rateLimit uint64) (err error) {
+	values := url.Values{}
+	values.Set("enabled", strconv.FormatBool(enabled))
+	values.Set("ratelimit", strconv.FormatUint(rateLimit, 10))
+	err = c.post("/host/storage/scrub", values.Encode(), nil)
+	return
+}
+
+// HostStorageScrubStartPost uses the /host/storage/scrub endpoint to start
+// scrubbing all storage folders immediately.
+func (c *Client) HostStorageScrubStartPost() (err error) {
+	values := url.Values{}
+	values.Set("start", "true")
+	err = c.post("/host/storage/scrub", values.Encode(), nil)
+	return
+}
+
 // HostStorageSectorsDeletePost uses the /host/storage/sectors/delete endpoint
 // to delete a sector from the host.
 func (c *Client) HostStorageSectorsDeletePost(root crypto.Hash) (err error) {
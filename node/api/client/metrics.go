@@ -0,0 +1,8 @@
+package client
+
+// MetricsGet requests the /metrics resource and returns the metrics in the
+// Prometheus text format.
+func (c *Client) MetricsGet() (string, error) {
+	_, metrics, err := c.getRawResponse("/metrics")
+	return string(metrics), err
+}
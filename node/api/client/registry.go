@@ -0,0 +1,111 @@
+package client
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/node/api"
+	"go.sia.tech/siad/types"
+)
+
+// RegistrySubscription is a stream of updates for a registry entry returned
+// by RenterRegistrySubscribe.
+type RegistrySubscription struct {
+	staticStream *eventStream
+}
+
+// decodeRegistryValue decodes a registry value returned by the API.
+func decodeRegistryValue(dataKey crypto.Hash, rhg api.RegistryHandlerGET) (modules.SignedRegistryValue, error) {
+	data, err := hex.DecodeString(rhg.Data)
+	if err != nil {
+		return modules.SignedRegistryValue{}, errors.AddContext(err, "failed to decode data")
+	}
+	sigBytes, err := hex.DecodeString(rhg.Signature)
+	if err != nil {
+		return modules.SignedRegistryValue{}, errors.AddContext(err, "failed to decode signature")
+	}
+	var sig crypto.Signature
+	if len(sigBytes) != len(sig) {
+		return modules.SignedRegistryValue{}, fmt.Errorf("unexpected signature length %v", len(sigBytes))
+	}
+	copy(sig[:], sigBytes)
+	return modules.NewSignedRegistryValue(dataKey, data, rhg.Revision, sig), nil
+}
+
+// RenterRegistryRead queries the /renter/registry [GET] endpoint.
+func (c *Client) RenterRegistryRead(spk types.SiaPublicKey, dataKey crypto.Hash) (modules.SignedRegistryValue, error) {
+	return c.RenterRegistryReadWithTimeout(spk, dataKey, 0)
+}
+
+// RenterRegistryReadWithTimeout queries the /renter/registry [GET] endpoint
+// with the specified timeout. A timeout of 0 uses the daemon's default
+// timeout.
+func (c *Client) RenterRegistryReadWithTimeout(spk types.SiaPublicKey, dataKey crypto.Hash, timeout time.Duration) (modules.SignedRegistryValue, error) {
+	values := url.Values{}
+	values.Set("publickey", spk.String())
+	values.Set("datakey", dataKey.String())
+	if timeout > 0 {
+		values.Set("timeout", fmt.Sprint(int(timeout.Seconds())))
+	}
+
+	// Send the request.
+	var rhg api.RegistryHandlerGET
+	err := c.get("/renter/registry?"+values.Encode(), &rhg)
+	if err != nil {
+		return modules.SignedRegistryValue{}, err
+	}
+	return decodeRegistryValue(dataKey, rhg)
+}
+
+// RenterRegistrySubscribe queries the /renter/registry/subscribe [GET]
+// endpoint. The returned subscription needs to be closed by the caller.
+func (c *Client) RenterRegistrySubscribe(spk types.SiaPublicKey, dataKey crypto.Hash) (*RegistrySubscription, error) {
+	values := url.Values{}
+	values.Set("publickey", spk.String())
+	values.Set("datakey", dataKey.String())
+	stream, err := c.newEventStream("/renter/registry/subscribe?" + values.Encode())
+	if err != nil {
+		return nil, err
+	}
+	return &RegistrySubscription{staticStream: stream}, nil
+}
+
+// Close closes the subscription stream.
+func (rs *RegistrySubscription) Close() error {
+	return rs.staticStream.Close()
+}
+
+// Next blocks until the next update of the subscribed entry is received and
+// returns it.
+func (rs *RegistrySubscription) Next() (modules.SignedRegistryValue, error) {
+	var rse api.RegistrySubscriptionEvent
+	if _, err := rs.staticStream.next(&rse); err != nil {
+		return modules.SignedRegistryValue{}, err
+	}
+	return decodeRegistryValue(rse.DataKey, rse.RegistryHandlerGET)
+}
+
+// RenterRegistryUpdate queries the /renter/registry [POST] endpoint.
+func (c *Client) RenterRegistryUpdate(spk types.SiaPublicKey, srv modules.SignedRegistryValue) error {
+	req := api.RegistryHandlerRequestPOST{
+		PublicKey: spk,
+		DataKey:   srv.Tweak,
+		Revision:  srv.Revision,
+		Signature: hex.EncodeToString(srv.Signature[:]),
+		Data:      hex.EncodeToString(srv.Data),
+	}
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return errors.AddContext(err, "failed to marshal request")
+	}
+	_, _, err = c.postRawResponse("/renter/registry", bytes.NewReader(reqBytes))
+	return err
+}
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"math"
@@ -87,6 +88,12 @@ func (a *AllowanceRequestPost) WithMaxPeriodChurn(maxPeriodChurn uint64) *Allowa
 	return a
 }
 
+// WithFundingAccount adds the fundingaccount field to the request.
+func (a *AllowanceRequestPost) WithFundingAccount(account string) *AllowanceRequestPost {
+	a.values.Set("fundingaccount", account)
+	return a
+}
+
 // WithMaxRPCPrice adds the maxrpcprice field to the request.
 func (a *AllowanceRequestPost) WithMaxRPCPrice(price types.Currency) *AllowanceRequestPost {
 	a.values.Set("maxrpcprice", price.String())
@@ -586,6 +593,43 @@ func (c *Client) RenterUploadForcePost(path string, siaPath modules.SiaPath, dat
 	return
 }
 
+// RenterImportPost uses the /renter/import endpoint to import a file which was
+// shared by another renter.
+func (c *Client) RenterImportPost(siaPath modules.SiaPath, descriptor, sharingKey string, force bool) (err error) {
+	sp := escapeSiaPath(siaPath)
+	values := url.Values{}
+	values.Set("descriptor", descriptor)
+	values.Set("sharingkey", sharingKey)
+	values.Set("force", strconv.FormatBool(force))
+	err = c.post(fmt.Sprintf("/renter/import/%s", sp), values.Encode(), nil)
+	return
+}
+
+// RenterShareGet uses the /renter/share endpoint to create a share descriptor
+// for a file.
+func (c *Client) RenterShareGet(siaPath modules.SiaPath) (rsg api.RenterShareGET, err error) {
+	sp := escapeSiaPath(siaPath)
+	err = c.get(fmt.Sprintf("/renter/share/%s", sp), &rsg)
+	return
+}
+
+// RenterUploadPackedPost uses the /renter/uploadpacked endpoint to pack
+// multiple small files into shared chunks and upload them.
+func (c *Client) RenterUploadPackedPost(files []api.RenterUploadPackedFile, dataPieces, parityPieces uint64, force bool) (err error) {
+	rupp := api.RenterUploadPackedPOST{
+		Files:        files,
+		DataPieces:   dataPieces,
+		ParityPieces: parityPieces,
+		Force:        force,
+	}
+	data, err := json.Marshal(rupp)
+	if err != nil {
+		return err
+	}
+	err = c.post("/renter/uploadpacked", string(data), nil)
+	return
+}
+
 // RenterUploadDefaultPost uses the /renter/upload endpoint with default
 // redundancy settings to upload a file.
 func (c *Client) RenterUploadDefaultPost(path string, siaPath modules.SiaPath) (err error) {
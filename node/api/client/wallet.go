@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/url"
 	"strconv"
+	"strings"
 
 	mnemonics "gitlab.com/NebulousLabs/entropy-mnemonics"
 	"gitlab.com/NebulousLabs/errors"
@@ -14,6 +15,90 @@ import (
 	"go.sia.tech/siad/types"
 )
 
+// WalletAccountAddressGet requests a new address of an account from the
+// /wallet/accounts/:name/address endpoint.
+func (c *Client) WalletAccountAddressGet(name string) (wag api.WalletAddressGET, err error) {
+	err = c.get("/wallet/accounts/"+url.PathEscape(name)+"/address", &wag)
+	return
+}
+
+// WalletAccountGet requests an account and its addresses from the
+// /wallet/accounts/:name endpoint.
+func (c *Client) WalletAccountGet(name string) (wag api.WalletAccountGET, err error) {
+	err = c.get("/wallet/accounts/"+url.PathEscape(name), &wag)
+	return
+}
+
+// WalletAccountAssignPost uses the /wallet/accounts/:name endpoint to assign
+// addresses of the wallet to an account.
+func (c *Client) WalletAccountAssignPost(name string, addrs []types.UnlockHash) error {
+	json, err := json.Marshal(api.WalletAccountPOST{
+		Addresses: addrs,
+	})
+	if err != nil {
+		return err
+	}
+	return c.post("/wallet/accounts/"+url.PathEscape(name), string(json), nil)
+}
+
+// WalletAccountSeedPost uses the /wallet/accounts/:name endpoint to assign
+// the addresses of a loaded auxiliary seed to an account.
+func (c *Client) WalletAccountSeedPost(name, seed string) error {
+	json, err := json.Marshal(api.WalletAccountPOST{
+		Seed: seed,
+	})
+	if err != nil {
+		return err
+	}
+	return c.post("/wallet/accounts/"+url.PathEscape(name), string(json), nil)
+}
+
+// WalletAccountUnassignPost uses the /wallet/accounts/:name endpoint to
+// remove addresses from an account.
+func (c *Client) WalletAccountUnassignPost(name string, addrs []types.UnlockHash) error {
+	json, err := json.Marshal(api.WalletAccountPOST{
+		Addresses: addrs,
+		Remove:    true,
+	})
+	if err != nil {
+		return err
+	}
+	return c.post("/wallet/accounts/"+url.PathEscape(name), string(json), nil)
+}
+
+// WalletAccountsGet requests the accounts of the wallet from the
+// /wallet/accounts endpoint.
+func (c *Client) WalletAccountsGet() (wag api.WalletAccountsGET, err error) {
+	err = c.get("/wallet/accounts", &wag)
+	return
+}
+
+// WalletAccountsAddPost uses the /wallet/accounts endpoint to create an
+// account.
+func (c *Client) WalletAccountsAddPost(name string) error {
+	json, err := json.Marshal(api.WalletAccountsPOST{
+		Name:   name,
+		Remove: false,
+	})
+	if err != nil {
+		return err
+	}
+	return c.post("/wallet/accounts", string(json), nil)
+}
+
+// WalletAccountsRemovePost uses the /wallet/accounts endpoint to remove an
+// account.
+func (c *Client) WalletAccountsRemovePost(name string) error {
+	json, err := json.Marshal(api.WalletAccountsPOST{
+		Name:   name,
+		Remove: true,
+	})
+	if err != nil {
+		return err
+	}
+	return c.post("/wallet/accounts", string(json), nil)
+}
+
 // WalletAddressGet requests a new address from the /wallet/address endpoint
 func (c *Client) WalletAddressGet() (wag api.WalletAddressGET, err error) {
 	err = c.get("/wallet/address", &wag)
@@ -27,6 +112,21 @@ func (c *Client) WalletAddressesGet() (wag api.WalletAddressesGET, err error) {
 	return
 }
 
+// WalletBumpPost uses the /wallet/bump/:txid endpoint to raise the fee of the
+// unconfirmed transaction set containing the transaction. An empty method and
+// a zero fee let the wallet choose.
+func (c *Client) WalletBumpPost(txid types.TransactionID, method modules.FeeBumpMethod, feePerByte types.Currency) (wbp api.WalletBumpPOST, err error) {
+	values := url.Values{}
+	if method != "" {
+		values.Set("method", string(method))
+	}
+	if !feePerByte.IsZero() {
+		values.Set("feeperbyte", feePerByte.String())
+	}
+	err = c.post(fmt.Sprintf("/wallet/bump/%s", txid), values.Encode(), &wbp)
+	return
+}
+
 // WalletChangePasswordPost uses the /wallet/changepassword endpoint to change
 // the wallet's password.
 func (c *Client) WalletChangePasswordPost(currentPassword, newPassword string) (err error) {
@@ -97,6 +197,81 @@ func (c *Client) WalletGet() (wg api.WalletGET, err error) {
 	return
 }
 
+// WalletExchangeRatesGet requests the /wallet/exchangerates endpoint to get
+// the stored exchange rates of a currency. An empty symbol returns the rates of
+// all currencies.
+func (c *Client) WalletExchangeRatesGet(symbol string) (werg api.WalletExchangeRatesGET, err error) {
+	values := url.Values{}
+	values.Set("symbol", symbol)
+	err = c.get("/wallet/exchangerates?"+values.Encode(), &werg)
+	return
+}
+
+// WalletExchangeRatesPost uses the /wallet/exchangerates endpoint to store
+// historical exchange rates.
+func (c *Client) WalletExchangeRatesPost(points []modules.ExchangeRatePoint) error {
+	json, err := json.Marshal(api.WalletExchangeRatesPOST{
+		ExchangeRates: points,
+	})
+	if err != nil {
+		return err
+	}
+	return c.post("/wallet/exchangerates", string(json), nil)
+}
+
+// WalletExchangeRatesCSVPost uses the /wallet/exchangerates endpoint to import
+// historical exchange rates of a currency from CSV records of the form
+// 'time,rate'.
+func (c *Client) WalletExchangeRatesCSVPost(csv, symbol string) error {
+	json, err := json.Marshal(api.WalletExchangeRatesPOST{
+		CSV:    csv,
+		Symbol: symbol,
+	})
+	if err != nil {
+		return err
+	}
+	return c.post("/wallet/exchangerates", string(json), nil)
+}
+
+// WalletExportGet requests the /wallet/export endpoint to get the confirmed
+// transactions between startHeight and endHeight prepared for exporting.
+func (c *Client) WalletExportGet(startHeight, endHeight types.BlockHeight) (weg api.WalletExportGET, err error) {
+	err = c.get(fmt.Sprintf("/wallet/export?startheight=%v&endheight=%v", startHeight, endHeight), &weg)
+	return
+}
+
+// WalletExportRawGet requests the /wallet/export endpoint to export the
+// confirmed transactions between startHeight and endHeight in the given
+// format.
+func (c *Client) WalletExportRawGet(startHeight, endHeight types.BlockHeight, format string) ([]byte, error) {
+	values := url.Values{}
+	values.Set("startheight", fmt.Sprint(startHeight))
+	values.Set("endheight", fmt.Sprint(endHeight))
+	values.Set("format", format)
+	_, data, err := c.getRawResponse("/wallet/export?" + values.Encode())
+	return data, err
+}
+
+// WalletLabelsGet requests the /wallet/labels endpoint to get the address
+// and transaction labels of the wallet.
+func (c *Client) WalletLabelsGet() (wlg api.WalletLabelsGET, err error) {
+	err = c.get("/wallet/labels", &wlg)
+	return
+}
+
+// WalletLabelsPost uses the /wallet/labels endpoint to set address and
+// transaction labels.
+func (c *Client) WalletLabelsPost(addrLabels []modules.AddressLabel, txnLabels []modules.TransactionLabel) error {
+	json, err := json.Marshal(api.WalletLabelsPOST{
+		AddressLabels:     addrLabels,
+		TransactionLabels: txnLabels,
+	})
+	if err != nil {
+		return err
+	}
+	return c.post("/wallet/labels", string(json), nil)
+}
+
 // WalletLastAddressesGet returns the count last addresses generated by the
 // wallet in reverse order. That means the last generated address will be the
 // first one in the slice.
@@ -121,6 +296,65 @@ func (c *Client) WalletSeedPost(seed, password string) (err error) {
 	return
 }
 
+// WalletReportGet requests the /wallet/report endpoint to get a fiat report of
+// the transactions confirmed between start and end.
+func (c *Client) WalletReportGet(symbol string, start, end types.Timestamp, period modules.ReportPeriod) (wrg api.WalletReportGET, err error) {
+	values := url.Values{}
+	values.Set("symbol", symbol)
+	values.Set("start", fmt.Sprint(start))
+	values.Set("end", fmt.Sprint(end))
+	values.Set("period", string(period))
+	err = c.get("/wallet/report?"+values.Encode(), &wrg)
+	return
+}
+
+// WalletScheduleGet requests the /wallet/schedule endpoint to get the
+// scheduled payments of the wallet.
+func (c *Client) WalletScheduleGet() (wsg api.WalletScheduleGET, err error) {
+	err = c.get("/wallet/schedule", &wsg)
+	return
+}
+
+// WalletSchedulePost uses the /wallet/schedule endpoint to schedule a payment
+// at a height. If interval is not 0, the payment is repeated every interval
+// blocks.
+func (c *Client) WalletSchedulePost(amount types.Currency, dest types.UnlockHash, height, interval types.BlockHeight) (wsp api.WalletSchedulePOST, err error) {
+	values := url.Values{}
+	values.Set("amount", amount.String())
+	values.Set("destination", dest.String())
+	values.Set("height", fmt.Sprint(height))
+	values.Set("interval", fmt.Sprint(interval))
+	err = c.post("/wallet/schedule", values.Encode(), &wsp)
+	return
+}
+
+// WalletScheduleIDGet requests the /wallet/schedule/:id endpoint to get a
+// scheduled payment.
+func (c *Client) WalletScheduleIDGet(id modules.ScheduledPaymentID) (wsg api.WalletScheduleGETid, err error) {
+	err = c.get("/wallet/schedule/"+string(id), &wsg)
+	return
+}
+
+// WalletScheduleIDPost uses the /wallet/schedule/:id endpoint to update the
+// amount, destination, height and interval of a scheduled payment.
+func (c *Client) WalletScheduleIDPost(sp modules.ScheduledPayment) (wsp api.WalletSchedulePOST, err error) {
+	values := url.Values{}
+	values.Set("amount", sp.Amount.String())
+	values.Set("destination", sp.Destination.String())
+	values.Set("height", fmt.Sprint(sp.NextHeight))
+	values.Set("interval", fmt.Sprint(sp.Interval))
+	err = c.post("/wallet/schedule/"+string(sp.ID), values.Encode(), &wsp)
+	return
+}
+
+// WalletScheduleIDRemovePost uses the /wallet/schedule/:id endpoint to remove
+// a scheduled payment.
+func (c *Client) WalletScheduleIDRemovePost(id modules.ScheduledPaymentID) error {
+	values := url.Values{}
+	values.Set("remove", "true")
+	return c.post("/wallet/schedule/"+string(id), values.Encode(), nil)
+}
+
 // WalletSeedsGet uses the /wallet/seeds endpoint to return the wallet's
 // current seeds.
 func (c *Client) WalletSeedsGet() (wsg api.WalletSeedsGET, err error) {
@@ -128,6 +362,95 @@ func (c *Client) WalletSeedsGet() (wsg api.WalletSeedsGET, err error) {
 	return
 }
 
+// WalletMultisigGet requests the /wallet/multisig endpoint to get the multisig
+// addresses of the wallet.
+func (c *Client) WalletMultisigGet() (wmg api.WalletMultisigGET, err error) {
+	err = c.get("/wallet/multisig", &wmg)
+	return
+}
+
+// WalletMultisigPost uses the /wallet/multisig endpoint to create a multisig
+// address which requires 'signaturesRequired' of the public keys to sign.
+func (c *Client) WalletMultisigPost(publicKeys []types.SiaPublicKey, signaturesRequired uint64, unused bool) (wmp api.WalletMultisigPOST, err error) {
+	json, err := json.Marshal(api.WalletMultisigPOSTParams{
+		PublicKeys:         publicKeys,
+		SignaturesRequired: signaturesRequired,
+		Unused:             unused,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/multisig", string(json), &wmp)
+	return
+}
+
+// WalletPartialCreatePost uses the /wallet/partial/create endpoint to create a
+// partially signed transaction.
+func (c *Client) WalletPartialCreatePost(txn types.Transaction, parents []types.Transaction) (wpp api.WalletPartialPOST, err error) {
+	json, err := json.Marshal(api.WalletPartialCreatePOSTParams{
+		Transaction: txn,
+		Parents:     parents,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/partial/create", string(json), &wpp)
+	return
+}
+
+// WalletPartialFinalizePost uses the /wallet/partial/finalize endpoint to
+// finalize a partially signed transaction and optionally broadcast it.
+func (c *Client) WalletPartialFinalizePost(pst modules.PartiallySignedTransaction, broadcast bool) (wpfp api.WalletPartialFinalizePOST, err error) {
+	json, err := json.Marshal(api.WalletPartialFinalizePOSTParams{
+		PartialTransaction: pst,
+		Broadcast:          broadcast,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/partial/finalize", string(json), &wpfp)
+	return
+}
+
+// WalletPartialMergePost uses the /wallet/partial/merge endpoint to merge the
+// signatures of partially signed transactions.
+func (c *Client) WalletPartialMergePost(psts ...modules.PartiallySignedTransaction) (wpp api.WalletPartialPOST, err error) {
+	json, err := json.Marshal(api.WalletPartialMergePOSTParams{
+		PartialTransactions: psts,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/partial/merge", string(json), &wpp)
+	return
+}
+
+// WalletPartialSignPost uses the /wallet/partial/sign endpoint to add the
+// signatures the wallet can create to a partially signed transaction.
+func (c *Client) WalletPartialSignPost(pst modules.PartiallySignedTransaction) (wpp api.WalletPartialPOST, err error) {
+	json, err := json.Marshal(api.WalletPartialPOSTParams{
+		PartialTransaction: pst,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/partial/sign", string(json), &wpp)
+	return
+}
+
+// WalletPartialStatusPost uses the /wallet/partial/status endpoint to get the
+// signing status of a partially signed transaction.
+func (c *Client) WalletPartialStatusPost(pst modules.PartiallySignedTransaction) (wpp api.WalletPartialPOST, err error) {
+	json, err := json.Marshal(api.WalletPartialPOSTParams{
+		PartialTransaction: pst,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/partial/status", string(json), &wpp)
+	return
+}
+
 // WalletSiacoinsMultiPost uses the /wallet/siacoin api endpoint to send money
 // to multiple addresses at once
 func (c *Client) WalletSiacoinsMultiPost(outputs []types.SiacoinOutput) (wsp api.WalletSiacoinsPOST, err error) {
@@ -152,6 +475,71 @@ func (c *Client) WalletSiacoinsPost(amount types.Currency, destination types.Unl
 	return
 }
 
+// WalletSiacoinsCoinControlPost uses the /wallet/siacoins api endpoint to send
+// money to a single address using only the outputs allowed by the coin
+// control.
+func (c *Client) WalletSiacoinsCoinControlPost(amount types.Currency, destination types.UnlockHash, feeIncluded bool, cc modules.CoinControl) (wsp api.WalletSiacoinsPOST, err error) {
+	values := coinControlValues(cc)
+	values.Set("amount", amount.String())
+	values.Set("destination", destination.String())
+	values.Set("feeIncluded", strconv.FormatBool(feeIncluded))
+	err = c.post("/wallet/siacoins", values.Encode(), &wsp)
+	return
+}
+
+// WalletSiacoinsTimelockPost uses the /wallet/siacoins api endpoint to send
+// money to a public key. The output can't be spent before the unlock height.
+func (c *Client) WalletSiacoinsTimelockPost(amount types.Currency, publicKey types.SiaPublicKey, unlockHeight types.BlockHeight, cc modules.CoinControl) (wsp api.WalletSiacoinsPOST, err error) {
+	values := coinControlValues(cc)
+	values.Set("amount", amount.String())
+	values.Set("publickey", publicKey.String())
+	values.Set("timelock", fmt.Sprint(unlockHeight))
+	err = c.post("/wallet/siacoins", values.Encode(), &wsp)
+	return
+}
+
+// WalletSiacoinsMultiCoinControlPost uses the /wallet/siacoins api endpoint to
+// send money to multiple addresses using only the outputs allowed by the coin
+// control.
+func (c *Client) WalletSiacoinsMultiCoinControlPost(outputs []types.SiacoinOutput, cc modules.CoinControl) (wsp api.WalletSiacoinsPOST, err error) {
+	values := coinControlValues(cc)
+	marshaledOutputs, err := json.Marshal(outputs)
+	if err != nil {
+		return api.WalletSiacoinsPOST{}, err
+	}
+	values.Set("outputs", string(marshaledOutputs))
+	err = c.post("/wallet/siacoins", values.Encode(), &wsp)
+	return
+}
+
+// coinControlValues encodes the coin control as /wallet/siacoins parameters.
+func coinControlValues(cc modules.CoinControl) url.Values {
+	values := url.Values{}
+	var include, exclude []string
+	for _, id := range cc.Include {
+		include = append(include, id.String())
+	}
+	for _, id := range cc.Exclude {
+		exclude = append(exclude, id.String())
+	}
+	if len(include) > 0 {
+		values.Set("include", strings.Join(include, ","))
+	}
+	if len(exclude) > 0 {
+		values.Set("exclude", strings.Join(exclude, ","))
+	}
+	if cc.Strategy != "" {
+		values.Set("strategy", string(cc.Strategy))
+	}
+	if cc.ChangeAddress != (types.UnlockHash{}) {
+		values.Set("changeaddress", cc.ChangeAddress.String())
+	}
+	if cc.Account != "" {
+		values.Set("account", cc.Account)
+	}
+	return values
+}
+
 // WalletSignPost uses the /wallet/sign api endpoint to sign a transaction.
 func (c *Client) WalletSignPost(txn types.Transaction, toSign []crypto.Hash) (wspr api.WalletSignPOSTResp, err error) {
 	json, err := json.Marshal(api.WalletSignPOSTParams{
@@ -194,6 +582,41 @@ func (c *Client) WalletSweepPost(seed string) (wsp api.WalletSweepPOST, err erro
 	return
 }
 
+// WalletTimelockGet requests the /wallet/timelock endpoint to get the
+// time-locked addresses of the wallet and the outputs which are pending
+// unlock.
+func (c *Client) WalletTimelockGet() (wtg api.WalletTimelockGET, err error) {
+	err = c.get("/wallet/timelock", &wtg)
+	return
+}
+
+// WalletTimelockAddPost uses the /wallet/timelock endpoint to add the unlock
+// conditions of an existing time-locked address to the wallet.
+func (c *Client) WalletTimelockAddPost(uc types.UnlockConditions, unused bool) (wta api.WalletTimelockAddress, err error) {
+	json, err := json.Marshal(api.WalletTimelockPOSTParams{
+		UnlockConditions: &uc,
+		Unused:           unused,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/timelock", string(json), &wta)
+	return
+}
+
+// WalletTimelockPost uses the /wallet/timelock endpoint to create a new
+// address which can't be spent from before the unlock height.
+func (c *Client) WalletTimelockPost(unlockHeight types.BlockHeight) (wta api.WalletTimelockAddress, err error) {
+	json, err := json.Marshal(api.WalletTimelockPOSTParams{
+		UnlockHeight: unlockHeight,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/timelock", string(json), &wta)
+	return
+}
+
 // WalletTransactionsGet requests the/wallet/transactions api resource for a
 // certain startheight and endheight
 func (c *Client) WalletTransactionsGet(startHeight types.BlockHeight, endHeight types.BlockHeight) (wtg api.WalletTransactionsGET, err error) {
@@ -202,10 +625,46 @@ func (c *Client) WalletTransactionsGet(startHeight types.BlockHeight, endHeight
 	return
 }
 
+// WalletTransactionsAccountGet requests the /wallet/transactions api resource
+// for a certain startheight and endheight and only returns the transactions
+// which involve an address of the account.
+func (c *Client) WalletTransactionsAccountGet(startHeight types.BlockHeight, endHeight types.BlockHeight, account string) (wtg api.WalletTransactionsGET, err error) {
+	values := url.Values{}
+	values.Set("startheight", fmt.Sprint(startHeight))
+	values.Set("endheight", fmt.Sprint(endHeight))
+	values.Set("account", account)
+	err = c.get("/wallet/transactions?"+values.Encode(), &wtg)
+	return
+}
+
+// WalletTransactionsLabelGet requests the /wallet/transactions api resource
+// for a certain startheight and endheight and only returns the transactions
+// with the label.
+func (c *Client) WalletTransactionsLabelGet(startHeight types.BlockHeight, endHeight types.BlockHeight, label string) (wtg api.WalletTransactionsGET, err error) {
+	values := url.Values{}
+	values.Set("startheight", fmt.Sprint(startHeight))
+	values.Set("endheight", fmt.Sprint(endHeight))
+	values.Set("label", label)
+	err = c.get("/wallet/transactions?"+values.Encode(), &wtg)
+	return
+}
+
+// WalletTransactionsFiatGet requests the /wallet/transactions api resource
+// for a certain startheight and endheight and adds the fiat values of the
+// confirmed transactions in the currency with the given symbol.
+func (c *Client) WalletTransactionsFiatGet(startHeight types.BlockHeight, endHeight types.BlockHeight, symbol string) (wtg api.WalletTransactionsGET, err error) {
+	values := url.Values{}
+	values.Set("startheight", fmt.Sprint(startHeight))
+	values.Set("endheight", fmt.Sprint(endHeight))
+	values.Set("symbol", symbol)
+	err = c.get("/wallet/transactions?"+values.Encode(), &wtg)
+	return
+}
+
 // WalletTransactionGet requests the /wallet/transaction/:id api resource for a
 // certain TransactionID.
 func (c *Client) WalletTransactionGet(id types.TransactionID) (wtg api.WalletTransactionGETid, err error) {
-	err = c.get("/wallet/transaction/"+id.String(), wtg)
+	err = c.get("/wallet/transaction/"+id.String(), &wtg)
 	return
 }
 
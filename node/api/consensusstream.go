@@ -0,0 +1,197 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+var (
+	// consensusStreamBufferSize is the number of consensus changes which are
+	// buffered for a consensus change stream. If a client falls further
+	// behind, the stream unsubscribes from the consensus set until the buffer
+	// is drained and then resubscribes from the last buffered change.
+	consensusStreamBufferSize = build.Select(build.Var{
+		Dev:      16,
+		Standard: 16,
+		Testing:  2,
+	}).(int)
+
+	// consensusStreamKeepAliveInterval is the interval at which a consensus
+	// change stream sends keepalive comments to prevent idle connections from
+	// being closed while no blocks are found.
+	consensusStreamKeepAliveInterval = build.Select(build.Var{
+		Dev:      10 * time.Second,
+		Standard: 30 * time.Second,
+		Testing:  time.Second,
+	}).(time.Duration)
+)
+
+type (
+	// ConsensusChangeEvent is the json representation of a
+	// modules.ConsensusChange which is sent by the consensus change stream.
+	ConsensusChangeEvent struct {
+		ID                         crypto.Hash                    `json:"id"`
+		RevertedBlocks             []types.Block                  `json:"revertedblocks"`
+		AppliedBlocks              []types.Block                  `json:"appliedblocks"`
+		RevertedDiffs              []modules.ConsensusChangeDiffs `json:"reverteddiffs"`
+		AppliedDiffs               []modules.ConsensusChangeDiffs `json:"applieddiffs"`
+		ChildTarget                types.Target                   `json:"childtarget"`
+		MinimumValidChildTimestamp types.Timestamp                `json:"minimumvalidchildtimestamp"`
+		Synced                     bool                           `json:"synced"`
+	}
+
+	// consensusChangeStream is a consensus set subscriber which buffers
+	// changes for a stream. It never blocks the consensus set. Instead it
+	// signals an overflow and drops all changes until it is reset.
+	consensusChangeStream struct {
+		changes    chan modules.ConsensusChange
+		lastID     modules.ConsensusChangeID
+		overflow   chan struct{}
+		overflowed bool
+		mu         sync.Mutex
+	}
+)
+
+// newConsensusChangeStream creates a new stream which starts after the
+// provided change.
+func newConsensusChangeStream(start modules.ConsensusChangeID) *consensusChangeStream {
+	return &consensusChangeStream{
+		changes:  make(chan modules.ConsensusChange, consensusStreamBufferSize),
+		lastID:   start,
+		overflow: make(chan struct{}, 1),
+	}
+}
+
+// ProcessConsensusChange implements modules.ConsensusSetSubscriber.
+func (ccs *consensusChangeStream) ProcessConsensusChange(cc modules.ConsensusChange) {
+	ccs.mu.Lock()
+	defer ccs.mu.Unlock()
+	if ccs.overflowed {
+		return
+	}
+	select {
+	case ccs.changes <- cc:
+		ccs.lastID = cc.ID
+	default:
+		ccs.overflowed = true
+		ccs.overflow <- struct{}{}
+	}
+}
+
+// managedReset clears the overflow and returns the id of the last buffered
+// change to resubscribe from.
+func (ccs *consensusChangeStream) managedReset() modules.ConsensusChangeID {
+	ccs.mu.Lock()
+	defer ccs.mu.Unlock()
+	ccs.overflowed = false
+	return ccs.lastID
+}
+
+// consensusChangeEvent converts a consensus change to its json representation.
+func consensusChangeEvent(cc modules.ConsensusChange) ConsensusChangeEvent {
+	return ConsensusChangeEvent{
+		ID:                         crypto.Hash(cc.ID),
+		RevertedBlocks:             cc.RevertedBlocks,
+		AppliedBlocks:              cc.AppliedBlocks,
+		RevertedDiffs:              cc.RevertedDiffs,
+		AppliedDiffs:               cc.AppliedDiffs,
+		ChildTarget:                cc.ChildTarget,
+		MinimumValidChildTimestamp: cc.MinimumValidChildTimestamp,
+		Synced:                     cc.Synced,
+	}
+}
+
+// consensusStreamHandlerGET handles the API call that streams consensus
+// changes after the provided change as server-sent events.
+func (api *API) consensusStreamHandlerGET(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	var ccid modules.ConsensusChangeID
+	if err := (*crypto.Hash)(&ccid).LoadString(ps.ByName("id")); err != nil {
+		WriteError(w, Error{"could not decode ID: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		WriteError(w, Error{"streaming is not supported by the connection"}, http.StatusInternalServerError)
+		return
+	}
+
+	// Subscribe the stream in a separate goroutine since the initial changes
+	// are sent while subscribing.
+	ccs := newConsensusChangeStream(ccid)
+	var cancel chan struct{}
+	var subscribeErr chan error
+	subscribe := func(start modules.ConsensusChangeID) {
+		cancel = make(chan struct{})
+		subscribeErr = make(chan error, 1)
+		go func(cancel <-chan struct{}, errChan chan<- error) {
+			errChan <- api.cs.ConsensusSetSubscribe(ccs, start, cancel)
+		}(cancel, subscribeErr)
+	}
+	// unsubscribe cancels the subscription and removes the stream from the
+	// subscribers. subscribeErr is nil if the subscription already returned.
+	unsubscribe := func() {
+		close(cancel)
+		if subscribeErr != nil {
+			<-subscribeErr
+			subscribeErr = nil
+		}
+		api.cs.Unsubscribe(ccs)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	subscribe(ccid)
+	subscribed := true
+	defer func() {
+		if subscribed {
+			unsubscribe()
+		}
+	}()
+
+	ticker := time.NewTicker(consensusStreamKeepAliveInterval)
+	defer ticker.Stop()
+	for {
+		// Resubscribe after an overflow once all buffered changes were sent.
+		if !subscribed && len(ccs.changes) == 0 {
+			subscribe(ccs.managedReset())
+			subscribed = true
+		}
+		select {
+		case <-req.Context().Done():
+			return
+		case <-api.staticTG.StopChan():
+			return
+		case err := <-subscribeErr:
+			// The initial changes were sent and the stream will receive new
+			// changes from now on unless subscribing failed.
+			subscribeErr = nil
+			if err != nil {
+				writeServerSentEvent(w, flusher, "error", Error{"failed to subscribe to consensus set: " + err.Error()})
+				return
+			}
+		case <-ccs.overflow:
+			// The client is too slow. Unsubscribe until the buffer is
+			// drained to avoid blocking the consensus set.
+			unsubscribe()
+			subscribed = false
+		case cc := <-ccs.changes:
+			writeServerSentEvent(w, flusher, "change", consensusChangeEvent(cc))
+		case <-ticker.C:
+			_, _ = fmt.Fprint(w, ": keepalive\n\n")
+			flusher.Flush()
+		}
+	}
+}
@@ -299,49 +299,54 @@ func updateToRelease(version string) (err error) {
 	return nil
 }
 
-// daemonAlertsHandlerGET handles the API call that returns the alerts of all
-// loaded modules.
-func (api *API) daemonAlertsHandlerGET(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
-	// initialize slices to avoid "null" in response.
-	crit := make([]modules.Alert, 0, 6)
-	err := make([]modules.Alert, 0, 6)
-	warn := make([]modules.Alert, 0, 6)
+// alerters returns the alerters of all loaded modules.
+func (api *API) alerters() []modules.Alerter {
+	alerters := []modules.Alerter{}
 	if api.gateway != nil {
-		c, e, w := api.gateway.Alerts()
-		crit = append(crit, c...)
-		err = append(err, e...)
-		warn = append(warn, w...)
+		alerters = append(alerters, api.gateway)
 	}
 	if api.cs != nil {
-		c, e, w := api.cs.Alerts()
-		crit = append(crit, c...)
-		err = append(err, e...)
-		warn = append(warn, w...)
+		alerters = append(alerters, api.cs)
 	}
 	if api.tpool != nil {
-		c, e, w := api.tpool.Alerts()
-		crit = append(crit, c...)
-		err = append(err, e...)
-		warn = append(warn, w...)
+		alerters = append(alerters, api.tpool)
 	}
 	if api.wallet != nil {
-		c, e, w := api.wallet.Alerts()
-		crit = append(crit, c...)
-		err = append(err, e...)
-		warn = append(warn, w...)
+		alerters = append(alerters, api.wallet)
 	}
 	if api.renter != nil {
-		c, e, w := api.renter.Alerts()
-		crit = append(crit, c...)
-		err = append(err, e...)
-		warn = append(warn, w...)
+		alerters = append(alerters, api.renter)
 	}
 	if api.host != nil {
-		c, e, w := api.host.Alerts()
+		alerters = append(alerters, api.host)
+	}
+	return alerters
+}
+
+// alerts returns the alerts of all loaded modules.
+func (api *API) alerts() (crit, err, warn []modules.Alert) {
+	return collectAlerts(api.alerters())
+}
+
+// collectAlerts returns the alerts of the provided alerters.
+func collectAlerts(alerters []modules.Alerter) (crit, err, warn []modules.Alert) {
+	// initialize slices to avoid "null" in response.
+	crit = make([]modules.Alert, 0, 6)
+	err = make([]modules.Alert, 0, 6)
+	warn = make([]modules.Alert, 0, 6)
+	for _, alerter := range alerters {
+		c, e, w := alerter.Alerts()
 		crit = append(crit, c...)
 		err = append(err, e...)
 		warn = append(warn, w...)
 	}
+	return
+}
+
+// daemonAlertsHandlerGET handles the API call that returns the alerts of all
+// loaded modules.
+func (api *API) daemonAlertsHandlerGET(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	crit, err, warn := api.alerts()
 	// Sort alerts by severity. Critical first, then Error and finally Warning.
 	alerts := append(crit, append(err, warn...)...)
 	WriteJSON(w, DaemonAlertsGet{
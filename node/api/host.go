@@ -2,9 +2,11 @@ package api
 
 import (
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/julienschmidt/httprouter"
@@ -67,12 +69,34 @@ type (
 		ConversionRate float64        `json:"conversionrate"`
 	}
 
+	// HostRenterFilterGET contains the information about the host's renter
+	// filter.
+	HostRenterFilterGET struct {
+		FilterMode string   `json:"filtermode"`
+		Renters    []string `json:"renters"`
+	}
+
+	// HostRenterFilterPOST contains the information needed to set the host's
+	// renter filter.
+	HostRenterFilterPOST struct {
+		FilterMode string               `json:"filtermode"`
+		Renters    []types.SiaPublicKey `json:"renters"`
+	}
+
 	// StorageGET contains the information that is returned after a GET request
 	// to /host/storage - a bunch of information about the status of storage
 	// management on the host.
 	StorageGET struct {
 		Folders []modules.StorageFolderMetadata `json:"folders"`
 	}
+
+	// StorageScrubGET contains the information that is returned after a GET
+	// request to /host/storage/scrub - the settings and progress of the sector
+	// scrubber and the corrupt sectors it found.
+	StorageScrubGET struct {
+		modules.StorageScrubStatus
+		CorruptSectors []modules.CorruptSector `json:"corruptsectors"`
+	}
 )
 
 // RegisterRoutesHost is a helper function to register all host routes.
@@ -96,6 +120,24 @@ func RegisterRoutesHost(router *httprouter.Router, h modules.Host, deps modules.
 	router.GET("/host/bandwidth", func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		hostBandwidthHandlerGET(h, w, req, ps)
 	})
+	router.GET("/host/drain", func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		hostDrainHandlerGET(h, w, req, ps)
+	})
+	router.POST("/host/drain", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		hostDrainHandlerPOST(h, w, req, ps)
+	}, requiredPassword))
+	router.GET("/host/pricing", func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		hostPricingHandlerGET(h, w, req, ps)
+	})
+	router.POST("/host/pricing", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		hostPricingHandlerPOST(h, w, req, ps)
+	}, requiredPassword))
+	router.GET("/host/renters/filter", func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		hostRenterFilterHandlerGET(h, w, req, ps)
+	})
+	router.POST("/host/renters/filter", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		hostRenterFilterHandlerPOST(h, w, req, ps)
+	}, requiredPassword))
 
 	// Calls pertaining to the storage manager that the host uses.
 	router.GET("/host/storage", func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
@@ -104,12 +146,27 @@ func RegisterRoutesHost(router *httprouter.Router, h modules.Host, deps modules.
 	router.POST("/host/storage/folders/add", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		storageFoldersAddHandler(h, w, req, ps)
 	}, requiredPassword))
+	router.POST("/host/storage/folders/migrate", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		storageFoldersMigrateHandler(h, w, req, ps)
+	}, requiredPassword))
 	router.POST("/host/storage/folders/remove", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		storageFoldersRemoveHandler(h, w, req, ps)
 	}, requiredPassword))
 	router.POST("/host/storage/folders/resize", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		storageFoldersResizeHandler(h, w, req, ps)
 	}, requiredPassword))
+	router.GET("/host/storage/migration", func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		storageMigrationHandlerGET(h, w, req, ps)
+	})
+	router.POST("/host/storage/migration", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		storageMigrationHandlerPOST(h, w, req, ps)
+	}, requiredPassword))
+	router.GET("/host/storage/scrub", func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		storageScrubHandlerGET(h, w, req, ps)
+	})
+	router.POST("/host/storage/scrub", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		storageScrubHandlerPOST(h, w, req, ps)
+	}, requiredPassword))
 	router.POST("/host/storage/sectors/delete/:merkleroot", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		storageSectorsDeleteHandler(h, w, req, ps)
 	}, requiredPassword))
@@ -474,6 +531,92 @@ func hostAnnounceHandler(host modules.Host, w http.ResponseWriter, req *http.Req
 	WriteSuccess(w)
 }
 
+// hostDrainHandlerGET handles the API call to get the state of the host's
+// drain mode.
+func hostDrainHandlerGET(host modules.Host, w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	WriteJSON(w, host.DrainStatus())
+}
+
+// hostDrainHandlerPOST handles the API call to enable or disable the host's
+// drain mode.
+func hostDrainHandlerPOST(host modules.Host, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	draining, err := strconv.ParseBool(req.FormValue("draining"))
+	if err != nil {
+		WriteError(w, Error{"unable to parse draining: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	var rejectDeposits bool
+	if req.FormValue("rejectdeposits") != "" {
+		rejectDeposits, err = strconv.ParseBool(req.FormValue("rejectdeposits"))
+		if err != nil {
+			WriteError(w, Error{"unable to parse rejectdeposits: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	if err := host.SetDrain(draining, rejectDeposits); err != nil {
+		WriteError(w, Error{"failed to set the drain mode: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// hostPricingHandlerGET handles the API call to get the host's pricing policy
+// and the inputs of its most recent price adjustment.
+func hostPricingHandlerGET(host modules.Host, w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	WriteJSON(w, host.PricingStatus())
+}
+
+// hostPricingHandlerPOST handles the API call to set the host's pricing
+// policy.
+func hostPricingHandlerPOST(host modules.Host, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var policy modules.HostPricingPolicy
+	err := json.NewDecoder(req.Body).Decode(&policy)
+	if err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if err := host.SetPricingPolicy(policy); err != nil {
+		WriteError(w, Error{"failed to set the pricing policy: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// hostRenterFilterHandlerGET handles the API call to get the host's renter
+// filter.
+func hostRenterFilterHandlerGET(host modules.Host, w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	fm, renters := host.RenterFilter()
+	keys := make([]string, 0, len(renters))
+	for _, renter := range renters {
+		keys = append(keys, renter.String())
+	}
+	WriteJSON(w, HostRenterFilterGET{
+		FilterMode: fm.String(),
+		Renters:    keys,
+	})
+}
+
+// hostRenterFilterHandlerPOST handles the API call to set the host's renter
+// filter.
+func hostRenterFilterHandlerPOST(host modules.Host, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params HostRenterFilterPOST
+	err := json.NewDecoder(req.Body).Decode(&params)
+	if err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	var fm modules.FilterMode
+	if err := fm.FromString(params.FilterMode); err != nil {
+		WriteError(w, Error{"unable to load filter mode from string: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if err := host.SetRenterFilter(fm, params.Renters); err != nil {
+		WriteError(w, Error{"failed to set the renter filter: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
 // storageHandler returns a bunch of information about storage management on
 // the host.
 func storageHandler(host modules.Host, w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
@@ -528,6 +671,46 @@ func storageFoldersResizeHandler(host modules.Host, w http.ResponseWriter, req *
 	WriteSuccess(w)
 }
 
+// storageFoldersMigrateHandler queues a migration of sectors from one storage
+// folder to another.
+func storageFoldersMigrateHandler(host modules.Host, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	fromPath, toPath := req.FormValue("from"), req.FormValue("to")
+	if fromPath == "" || toPath == "" {
+		WriteError(w, Error{"from and to parameters are required"}, http.StatusBadRequest)
+		return
+	}
+	storageFolders := host.StorageFolders()
+	fromIndex, err := folderIndex(fromPath, storageFolders)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	toIndex, err := folderIndex(toPath, storageFolders)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	var numSectors uint64
+	if req.FormValue("sectors") != "" {
+		_, err := fmt.Sscan(req.FormValue("sectors"), &numSectors)
+		if err != nil {
+			WriteError(w, Error{"unable to parse sectors: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		if numSectors == 0 {
+			WriteError(w, Error{"sectors must be greater than 0"}, http.StatusBadRequest)
+			return
+		}
+	}
+	err = host.MigrateSectors(uint16(fromIndex), uint16(toIndex), numSectors)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
 // storageFoldersRemoveHandler removes a storage folder from the storage
 // manager.
 func storageFoldersRemoveHandler(host modules.Host, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
@@ -568,3 +751,117 @@ func storageSectorsDeleteHandler(host modules.Host, w http.ResponseWriter, _ *ht
 	}
 	WriteSuccess(w)
 }
+
+// storageScrubHandlerGET returns the settings and progress of the sector
+// scrubber and the corrupt sectors it found.
+func storageScrubHandlerGET(host modules.Host, w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	sectors, err := host.CorruptSectors()
+	if err != nil {
+		WriteError(w, Error{"unable to get corrupt sectors: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	WriteJSON(w, StorageScrubGET{
+		StorageScrubStatus: host.ScrubStatus(),
+		CorruptSectors:     sectors,
+	})
+}
+
+// storageScrubHandlerPOST changes the settings of the sector scrubber and
+// optionally starts scrubbing all storage folders.
+func storageScrubHandlerPOST(host modules.Host, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	status := host.ScrubStatus()
+	enabled, rateLimit := status.Enabled, status.RateLimit
+	if req.FormValue("enabled") != "" {
+		var err error
+		enabled, err = strconv.ParseBool(req.FormValue("enabled"))
+		if err != nil {
+			WriteError(w, Error{"unable to parse enabled: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	if req.FormValue("ratelimit") != "" {
+		_, err := fmt.Sscan(req.FormValue("ratelimit"), &rateLimit)
+		if err != nil {
+			WriteError(w, Error{"unable to parse ratelimit: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		if rateLimit == 0 {
+			WriteError(w, Error{"ratelimit must be greater than 0"}, http.StatusBadRequest)
+			return
+		}
+	}
+	var start bool
+	if req.FormValue("start") != "" {
+		var err error
+		start, err = strconv.ParseBool(req.FormValue("start"))
+		if err != nil {
+			WriteError(w, Error{"unable to parse start: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := host.SetScrubSettings(enabled, rateLimit); err != nil {
+		WriteError(w, Error{"unable to set scrub settings: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if start {
+		if err := host.StartScrub(); err != nil {
+			WriteError(w, Error{"unable to start scrub: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	WriteSuccess(w)
+}
+
+// storageMigrationHandlerGET returns the settings of the sector migrator and
+// the queued migrations.
+func storageMigrationHandlerGET(host modules.Host, w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	WriteJSON(w, host.MigrationStatus())
+}
+
+// storageMigrationHandlerPOST changes the settings of the sector migrator and
+// optionally cancels the queued migrations.
+func storageMigrationHandlerPOST(host modules.Host, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	status := host.MigrationStatus()
+	rebalance, rateLimit := status.Rebalance, status.RateLimit
+	if req.FormValue("rebalance") != "" {
+		var err error
+		rebalance, err = strconv.ParseBool(req.FormValue("rebalance"))
+		if err != nil {
+			WriteError(w, Error{"unable to parse rebalance: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	if req.FormValue("ratelimit") != "" {
+		_, err := fmt.Sscan(req.FormValue("ratelimit"), &rateLimit)
+		if err != nil {
+			WriteError(w, Error{"unable to parse ratelimit: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		if rateLimit == 0 {
+			WriteError(w, Error{"ratelimit must be greater than 0"}, http.StatusBadRequest)
+			return
+		}
+	}
+	var cancel bool
+	if req.FormValue("cancel") != "" {
+		var err error
+		cancel, err = strconv.ParseBool(req.FormValue("cancel"))
+		if err != nil {
+			WriteError(w, Error{"unable to parse cancel: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+
+	if cancel {
+		if err := host.CancelSectorMigrations(); err != nil {
+			WriteError(w, Error{"unable to cancel migrations: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	if err := host.SetMigrationSettings(rebalance, rateLimit); err != nil {
+		WriteError(w, Error{"unable to set migration settings: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
@@ -0,0 +1,270 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// metricsContentType is the content type of the Prometheus text format.
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+type (
+	// metricsWriter writes metrics in the Prometheus text format.
+	metricsWriter struct {
+		buf bytes.Buffer
+	}
+
+	// metricSample is a single sample of a metric.
+	metricSample struct {
+		labels map[string]string
+		value  interface{}
+	}
+)
+
+// labelEscaper escapes label values according to the Prometheus text format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// sample creates a sample from a value and optional pairs of label names and
+// label values.
+func sample(value interface{}, labels ...string) metricSample {
+	s := metricSample{
+		labels: make(map[string]string, len(labels)/2),
+		value:  value,
+	}
+	for i := 0; i+1 < len(labels); i += 2 {
+		s.labels[labels[i]] = labels[i+1]
+	}
+	return s
+}
+
+// formatMetricValue formats a value as a Prometheus sample value. It returns
+// false if the type of the value isn't supported.
+func formatMetricValue(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return "1", true
+		}
+		return "0", true
+	case int:
+		return strconv.Itoa(v), true
+	case uint64:
+		return strconv.FormatUint(v, 10), true
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), true
+	case types.BlockHeight:
+		return strconv.FormatUint(uint64(v), 10), true
+	case types.Currency:
+		return v.String(), true
+	default:
+		return "", false
+	}
+}
+
+// add writes a metric with the provided samples to the writer.
+func (mw *metricsWriter) add(name, typ, help string, samples ...metricSample) {
+	fmt.Fprintf(&mw.buf, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(&mw.buf, "# TYPE %s %s\n", name, typ)
+	for _, s := range samples {
+		value, ok := formatMetricValue(s.value)
+		if !ok {
+			build.Critical(fmt.Sprintf("unsupported value type %T of metric %v", s.value, name))
+			continue
+		}
+		mw.buf.WriteString(name)
+		if len(s.labels) > 0 {
+			names := make([]string, 0, len(s.labels))
+			for name := range s.labels {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			pairs := make([]string, 0, len(names))
+			for _, name := range names {
+				pairs = append(pairs, fmt.Sprintf(`%s="%s"`, name, labelEscaper.Replace(s.labels[name])))
+			}
+			mw.buf.WriteString("{" + strings.Join(pairs, ",") + "}")
+		}
+		mw.buf.WriteString(" " + value + "\n")
+	}
+}
+
+// metricsHandlerGET handles the API call that returns the metrics of all
+// loaded modules in the Prometheus text format.
+func (api *API) metricsHandlerGET(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	var mw metricsWriter
+	if err := api.writeMetrics(&mw); err != nil {
+		WriteError(w, Error{"failed to collect metrics: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", metricsContentType)
+	w.Write(mw.buf.Bytes())
+}
+
+// writeMetrics writes the metrics of all loaded modules to the writer.
+func (api *API) writeMetrics(mw *metricsWriter) error {
+	// Alerts
+	crit, errs, warn := api.alerts()
+	mw.add("sia_alerts", "gauge", "Number of active alerts by severity.",
+		sample(len(crit), "severity", "critical"),
+		sample(len(errs), "severity", "error"),
+		sample(len(warn), "severity", "warning"))
+
+	// Consensus
+	if api.cs != nil {
+		mw.add("sia_consensus_height", "gauge", "Current block height.", sample(api.cs.Height()))
+		mw.add("sia_consensus_synced", "gauge", "Whether the consensus set is synced.", sample(api.cs.Synced()))
+	}
+
+	// Gateway
+	if api.gateway != nil {
+		var inbound, outbound int
+		for _, p := range api.gateway.Peers() {
+			if p.Inbound {
+				inbound++
+			} else {
+				outbound++
+			}
+		}
+		mw.add("sia_gateway_peers", "gauge", "Number of connected peers.",
+			sample(inbound, "direction", "inbound"),
+			sample(outbound, "direction", "outbound"))
+		upload, download, _, err := api.gateway.BandwidthCounters()
+		if err != nil {
+			return errors.AddContext(err, "failed to get gateway bandwidth counters")
+		}
+		mw.add("sia_gateway_upload_bytes_total", "counter", "Bytes uploaded by the gateway since startup.", sample(upload))
+		mw.add("sia_gateway_download_bytes_total", "counter", "Bytes downloaded by the gateway since startup.", sample(download))
+	}
+
+	// Wallet
+	if api.wallet != nil {
+		unlocked, err := api.wallet.Unlocked()
+		if err != nil {
+			return errors.AddContext(err, "failed to check if wallet is unlocked")
+		}
+		mw.add("sia_wallet_unlocked", "gauge", "Whether the wallet is unlocked.", sample(unlocked))
+		if unlocked {
+			siacoins, siafunds, claims, err := api.wallet.ConfirmedBalance()
+			if err != nil {
+				return errors.AddContext(err, "failed to get confirmed balance")
+			}
+			outgoing, incoming, err := api.wallet.UnconfirmedBalance()
+			if err != nil {
+				return errors.AddContext(err, "failed to get unconfirmed balance")
+			}
+			mw.add("sia_wallet_siacoins_hastings", "gauge", "Siacoin balance of the wallet in hastings.",
+				sample(siacoins, "type", "confirmed"),
+				sample(outgoing, "type", "unconfirmed_outgoing"),
+				sample(incoming, "type", "unconfirmed_incoming"))
+			mw.add("sia_wallet_siafunds", "gauge", "Siafund balance of the wallet.", sample(siafunds))
+			mw.add("sia_wallet_siafund_claim_hastings", "gauge", "Siacoin claim balance of the wallet's siafunds in hastings.", sample(claims))
+		}
+	}
+
+	// Host
+	if api.host != nil {
+		writeHostMetrics(mw, api.host)
+	}
+
+	// Renter
+	if api.renter != nil {
+		if err := writeRenterMetrics(mw, api.renter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeHostMetrics writes the financial and storage metrics of the host.
+func writeHostMetrics(mw *metricsWriter, host modules.Host) {
+	fm := host.FinancialMetrics()
+	mw.add("sia_host_contracts", "gauge", "Number of active storage obligations.", sample(fm.ContractCount))
+	financials := []struct {
+		name  string
+		value types.Currency
+	}{
+		{"account_funding", fm.AccountFunding},
+		{"contract_compensation", fm.ContractCompensation},
+		{"download_bandwidth_revenue", fm.DownloadBandwidthRevenue},
+		{"locked_storage_collateral", fm.LockedStorageCollateral},
+		{"lost_revenue", fm.LostRevenue},
+		{"lost_storage_collateral", fm.LostStorageCollateral},
+		{"potential_account_funding", fm.PotentialAccountFunding},
+		{"potential_contract_compensation", fm.PotentialContractCompensation},
+		{"potential_download_bandwidth_revenue", fm.PotentialDownloadBandwidthRevenue},
+		{"potential_storage_revenue", fm.PotentialStorageRevenue},
+		{"potential_upload_bandwidth_revenue", fm.PotentialUploadBandwidthRevenue},
+		{"risked_storage_collateral", fm.RiskedStorageCollateral},
+		{"storage_revenue", fm.StorageRevenue},
+		{"transaction_fee_expenses", fm.TransactionFeeExpenses},
+		{"upload_bandwidth_revenue", fm.UploadBandwidthRevenue},
+	}
+	for _, f := range financials {
+		mw.add("sia_host_"+f.name+"_hastings", "gauge", "Host "+strings.Replace(f.name, "_", " ", -1)+" in hastings.", sample(f.value))
+	}
+
+	folders := host.StorageFolders()
+	capacity := make([]metricSample, 0, len(folders))
+	remaining := make([]metricSample, 0, len(folders))
+	failedReads := make([]metricSample, 0, len(folders))
+	failedWrites := make([]metricSample, 0, len(folders))
+	for _, sf := range folders {
+		capacity = append(capacity, sample(sf.Capacity, "path", sf.Path))
+		remaining = append(remaining, sample(sf.CapacityRemaining, "path", sf.Path))
+		failedReads = append(failedReads, sample(sf.FailedReads, "path", sf.Path))
+		failedWrites = append(failedWrites, sample(sf.FailedWrites, "path", sf.Path))
+	}
+	mw.add("sia_host_storage_folder_capacity_bytes", "gauge", "Capacity of the storage folder.", capacity...)
+	mw.add("sia_host_storage_folder_remaining_bytes", "gauge", "Unused capacity of the storage folder.", remaining...)
+	mw.add("sia_host_storage_folder_failed_reads_total", "counter", "Failed reads of the storage folder.", failedReads...)
+	mw.add("sia_host_storage_folder_failed_writes_total", "counter", "Failed writes of the storage folder.", failedWrites...)
+}
+
+// writeRenterMetrics writes the worker pool and memory metrics of the renter.
+func writeRenterMetrics(mw *metricsWriter, renter modules.Renter) error {
+	wps, err := renter.WorkerPoolStatus()
+	if err != nil {
+		return errors.AddContext(err, "failed to get worker pool status")
+	}
+	mw.add("sia_renter_workers", "gauge", "Number of workers in the worker pool.", sample(wps.NumWorkers))
+	mw.add("sia_renter_workers_cooldown", "gauge", "Number of workers on cooldown.",
+		sample(wps.TotalDownloadCoolDown, "type", "download"),
+		sample(wps.TotalMaintenanceCoolDown, "type", "maintenance"),
+		sample(wps.TotalUploadCoolDown, "type", "upload"))
+
+	ms, err := renter.MemoryStatus()
+	if err != nil {
+		return errors.AddContext(err, "failed to get memory status")
+	}
+	managers := []struct {
+		name   string
+		status modules.MemoryManagerStatus
+	}{
+		{"total", ms.MemoryManagerStatus},
+		{"registry", ms.Registry},
+		{"system", ms.System},
+		{"userdownload", ms.UserDownload},
+		{"userupload", ms.UserUpload},
+	}
+	var available, base, requested []metricSample
+	for _, m := range managers {
+		available = append(available, sample(m.status.Available, "manager", m.name))
+		base = append(base, sample(m.status.Base, "manager", m.name))
+		requested = append(requested, sample(m.status.Requested, "manager", m.name))
+	}
+	mw.add("sia_renter_memory_available_bytes", "gauge", "Available memory of the memory manager.", available...)
+	mw.add("sia_renter_memory_base_bytes", "gauge", "Total memory of the memory manager.", base...)
+	mw.add("sia_renter_memory_requested_bytes", "gauge", "Memory requested from the memory manager which is not yet available.", requested...)
+	return nil
+}
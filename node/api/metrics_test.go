@@ -0,0 +1,83 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// TestMetricsWriter tests writing metrics in the Prometheus text format.
+func TestMetricsWriter(t *testing.T) {
+	var mw metricsWriter
+	mw.add("sia_test", "gauge", "Test metric.",
+		sample(uint64(1)),
+		sample(true, "path", `C:\sia "data"`+"\n", "a", "b"),
+		sample(types.SiacoinPrecision))
+	expected := `# HELP sia_test Test metric.
+# TYPE sia_test gauge
+sia_test 1
+sia_test{a="b",path="C:\\sia \"data\"\n"} 1
+sia_test 1000000000000000000000000
+`
+	if mw.buf.String() != expected {
+		t.Fatalf("expected\n%v\nbut got\n%v", expected, mw.buf.String())
+	}
+}
+
+// TestMetricsUserAgent tests that /metrics only skips the user agent check if
+// the API requires a password.
+func TestMetricsUserAgent(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	dir := build.TempDir("api", t.Name())
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := modules.NewConfig(filepath.Join(dir, modules.ConfigName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	get := func(api *API, password string) int {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		req.Header.Set("User-Agent", "Prometheus/2.0")
+		if password != "" {
+			req.SetBasicAuth("", password)
+		}
+		w := httptest.NewRecorder()
+		api.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// With a password, Prometheus can scrape the metrics if it authenticates.
+	api := New(cfg, "Sia-Agent", "password", nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	api.EnableMetrics()
+	if code := get(api, "password"); code != http.StatusOK {
+		t.Fatal("expected authenticated request to succeed", code)
+	}
+	if code := get(api, ""); code != http.StatusUnauthorized {
+		t.Fatal("expected unauthenticated request to fail", code)
+	}
+	if err := api.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a password, the user agent is required.
+	api = New(cfg, "Sia-Agent", "", nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	api.EnableMetrics()
+	if code := get(api, ""); code != http.StatusBadRequest {
+		t.Fatal("expected request without user agent to fail", code)
+	}
+	if err := api.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,256 @@
+package api
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/modules/renter"
+	"go.sia.tech/siad/types"
+)
+
+var (
+	// registrySubscriptionKeepAliveInterval is the interval at which a
+	// keepalive comment is written to idle registry subscription streams to
+	// prevent proxies from closing them.
+	registrySubscriptionKeepAliveInterval = build.Select(build.Var{
+		Dev:      10 * time.Second,
+		Standard: 30 * time.Second,
+		Testing:  time.Second,
+	}).(time.Duration)
+)
+
+type (
+	// RegistryHandlerGET is the response returned by the /renter/registry
+	// [GET] endpoint.
+	RegistryHandlerGET struct {
+		Data      string `json:"data"`
+		Revision  uint64 `json:"revision"`
+		Signature string `json:"signature"`
+	}
+
+	// RegistrySubscriptionEvent is the data of a single event sent by the
+	// /renter/registry/subscribe [GET] endpoint.
+	RegistrySubscriptionEvent struct {
+		PublicKey types.SiaPublicKey `json:"publickey"`
+		DataKey   crypto.Hash        `json:"datakey"`
+		RegistryHandlerGET
+	}
+
+	// RegistryHandlerRequestPOST is the expected format of the json request
+	// for the /renter/registry [POST] endpoint.
+	RegistryHandlerRequestPOST struct {
+		PublicKey types.SiaPublicKey `json:"publickey"`
+		DataKey   crypto.Hash        `json:"datakey"`
+		Revision  uint64             `json:"revision"`
+		Signature string             `json:"signature"`
+		Data      string             `json:"data"`
+	}
+)
+
+// parseRegistryKey parses the publickey and datakey query string parameters of
+// a registry request.
+func parseRegistryKey(req *http.Request) (types.SiaPublicKey, crypto.Hash, error) {
+	var spk types.SiaPublicKey
+	err := spk.LoadString(req.FormValue("publickey"))
+	if err != nil {
+		return types.SiaPublicKey{}, crypto.Hash{}, errors.AddContext(err, "unable to parse publickey param")
+	}
+	var dataKey crypto.Hash
+	err = dataKey.LoadString(req.FormValue("datakey"))
+	if err != nil {
+		return types.SiaPublicKey{}, crypto.Hash{}, errors.AddContext(err, "unable to parse datakey param")
+	}
+	return spk, dataKey, nil
+}
+
+// parseRegistryTimeout parses the timeout query string parameter of a registry
+// request. If no timeout is provided, MaxRegistryReadTimeout is returned.
+func parseRegistryTimeout(req *http.Request) (time.Duration, error) {
+	timeoutStr := req.FormValue("timeout")
+	if timeoutStr == "" {
+		return renter.MaxRegistryReadTimeout, nil
+	}
+	timeoutInt, err := strconv.Atoi(timeoutStr)
+	if err != nil {
+		return 0, errors.AddContext(err, "unable to parse timeout")
+	}
+	timeout := time.Duration(timeoutInt) * time.Second
+	if timeout <= 0 || timeout > renter.MaxRegistryReadTimeout {
+		return 0, fmt.Errorf("timeout must be between 1s and %ds", uint64(renter.MaxRegistryReadTimeout.Seconds()))
+	}
+	return timeout, nil
+}
+
+// renterRegistryHandlerGET handles the GET calls to /renter/registry.
+func (api *API) renterRegistryHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	spk, dataKey, err := parseRegistryKey(req)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	timeout, err := parseRegistryTimeout(req)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	// Read the registry.
+	srv, err := api.renter.ReadRegistry(spk, dataKey, timeout)
+	if errors.Contains(err, renter.ErrRegistryEntryNotFound) || errors.Contains(err, renter.ErrRegistryLookupTimeout) {
+		WriteError(w, Error{err.Error()}, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		WriteError(w, Error{fmt.Sprintf("failed to read registry entry: %v", err)}, http.StatusInternalServerError)
+		return
+	}
+	WriteJSON(w, RegistryHandlerGET{
+		Data:      hex.EncodeToString(srv.Data),
+		Revision:  srv.Revision,
+		Signature: hex.EncodeToString(srv.Signature[:]),
+	})
+}
+
+// renterRegistryHandlerPOST handles the POST calls to /renter/registry.
+func (api *API) renterRegistryHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	// Decode the request.
+	var rhp RegistryHandlerRequestPOST
+	err := json.NewDecoder(req.Body).Decode(&rhp)
+	if err != nil {
+		WriteError(w, Error{"Failed to decode request: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	data, err := hex.DecodeString(rhp.Data)
+	if err != nil {
+		WriteError(w, Error{"Failed to decode data: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if len(data) > modules.RegistryDataSize {
+		WriteError(w, Error{fmt.Sprintf("data can't be larger than %v bytes", modules.RegistryDataSize)}, http.StatusBadRequest)
+		return
+	}
+	sigBytes, err := hex.DecodeString(rhp.Signature)
+	if err != nil {
+		WriteError(w, Error{"Failed to decode signature: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	var sig crypto.Signature
+	if len(sigBytes) != len(sig) {
+		WriteError(w, Error{fmt.Sprintf("signature must be %v bytes", len(sig))}, http.StatusBadRequest)
+		return
+	}
+	copy(sig[:], sigBytes)
+
+	// Verify the entry before sending it to the hosts.
+	srv := modules.NewSignedRegistryValue(rhp.DataKey, data, rhp.Revision, sig)
+	err = srv.Verify(rhp.PublicKey.ToPublicKey())
+	if err != nil {
+		WriteError(w, Error{"Failed to verify signature: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	// Update the registry.
+	err = api.renter.UpdateRegistry(rhp.PublicKey, srv, renter.DefaultRegistryUpdateTimeout)
+	if err != nil {
+		WriteError(w, Error{"Unable to update the registry: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// renterRegistrySubscribeHandlerGET handles the GET calls to
+// /renter/registry/subscribe. It keeps the connection open and streams every
+// update of the entry to the caller as a server-sent event.
+func (api *API) renterRegistrySubscribeHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	spk, dataKey, err := parseRegistryKey(req)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		WriteError(w, Error{"streaming is not supported by the connection"}, http.StatusInternalServerError)
+		return
+	}
+
+	// Create a subscriber which forwards the updates to this thread until the
+	// request is done.
+	ctx := req.Context()
+	updates := make(chan modules.RPCRegistrySubscriptionNotificationEntryUpdate)
+	subscriber, err := api.renter.NewRegistrySubscriber(func(update modules.RPCRegistrySubscriptionNotificationEntryUpdate) {
+		select {
+		case updates <- update:
+		case <-ctx.Done():
+		}
+	})
+	if err != nil {
+		WriteError(w, Error{"failed to create registry subscriber: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	defer subscriber.Close()
+
+	// Send the headers before subscribing since the initial value is already
+	// sent as an event.
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- subscriber.Subscribe(modules.RPCRegistrySubscriptionRequest{
+			PubKey: spk,
+			Tweak:  dataKey,
+		})
+	}()
+
+	ticker := time.NewTicker(registrySubscriptionKeepAliveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err := <-errChan:
+			if err != nil {
+				writeServerSentEvent(w, flusher, "error", Error{"failed to subscribe to registry entry: " + err.Error()})
+				return
+			}
+		case update := <-updates:
+			writeServerSentEvent(w, flusher, "update", RegistrySubscriptionEvent{
+				PublicKey: update.PubKey,
+				DataKey:   update.Entry.Tweak,
+				RegistryHandlerGET: RegistryHandlerGET{
+					Data:      hex.EncodeToString(update.Entry.Data),
+					Revision:  update.Entry.Revision,
+					Signature: hex.EncodeToString(update.Entry.Signature[:]),
+				},
+			})
+		case <-ticker.C:
+			_, _ = fmt.Fprint(w, ": keepalive\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// writeServerSentEvent writes a server-sent event with the provided name and
+// json encoded data to the stream.
+func writeServerSentEvent(w http.ResponseWriter, flusher http.Flusher, event string, data interface{}) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		build.Critical("failed to marshal event", err)
+		return
+	}
+	_, _ = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, b)
+	flusher.Flush()
+}
@@ -1,6 +1,9 @@
 package api
 
 import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -23,6 +26,8 @@ import (
 	"go.sia.tech/siad/modules"
 	"go.sia.tech/siad/modules/renter"
 	"go.sia.tech/siad/modules/renter/contractor"
+	"go.sia.tech/siad/modules/renter/filesystem"
+	"go.sia.tech/siad/modules/renter/filesystem/siafile"
 	"go.sia.tech/siad/persist"
 	"go.sia.tech/siad/types"
 )
@@ -238,6 +243,27 @@ type (
 		ParityPieces int `json:"paritypieces"`
 	}
 
+	// RenterShareGET contains the share descriptor of a file and the key to
+	// decrypt it.
+	RenterShareGET struct {
+		Descriptor string `json:"descriptor"`
+		SharingKey string `json:"sharingkey"`
+	}
+
+	// RenterUploadPackedPOST contains the request body of a packed upload.
+	RenterUploadPackedPOST struct {
+		Files        []RenterUploadPackedFile `json:"files"`
+		DataPieces   uint64                   `json:"datapieces"`
+		ParityPieces uint64                   `json:"paritypieces"`
+		Force        bool                     `json:"force"`
+	}
+
+	// RenterUploadPackedFile is a single file of a packed upload.
+	RenterUploadPackedFile struct {
+		Source  string `json:"source"`
+		SiaPath string `json:"siapath"`
+	}
+
 	// DownloadInfo contains all client-facing information of a file.
 	DownloadInfo struct {
 		Destination     string          `json:"destination"`     // The destination of the download.
@@ -781,6 +807,16 @@ func (api *API) renterHandlerPOST(w http.ResponseWriter, req *http.Request, _ ht
 		settings.Allowance.MaxPeriodChurn = maxPeriodChurn
 		maxPeriodChurnSet = true
 	}
+	if values, ok := req.Form["fundingaccount"]; ok {
+		// An empty account resets the funding account.
+		if account := values[0]; account != "" {
+			if _, err := api.wallet.AccountAddresses(account); err != nil {
+				WriteError(w, Error{"unable to use funding account: " + err.Error()}, http.StatusBadRequest)
+				return
+			}
+		}
+		settings.Allowance.FundingAccount = values[0]
+	}
 	if str := req.FormValue("maxrpcprice"); str != "" {
 		price, ok := scanAmount(str)
 		if !ok {
@@ -1374,7 +1410,9 @@ func (api *API) renterFuseMountHandlerPOST(w http.ResponseWriter, req *http.Requ
 	}
 
 	mount := req.FormValue("mount")
-	var opts modules.MountOptions
+	opts := modules.MountOptions{
+		ReadOnly: true,
+	}
 	if req.FormValue("readonly") != "" {
 		readOnly, err := scanBool(req.FormValue("readonly"))
 		if err != nil {
@@ -1974,6 +2012,141 @@ func (api *API) renterUploadHandler(w http.ResponseWriter, req *http.Request, ps
 	WriteSuccess(w)
 }
 
+// renterImportHandler handles the API call to import a file which was shared
+// by another renter.
+func (api *API) renterImportHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	// Parse the descriptor and the sharing key.
+	descriptor, err := base64.StdEncoding.DecodeString(req.FormValue("descriptor"))
+	if err != nil {
+		WriteError(w, Error{"unable to decode 'descriptor' parameter: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	sharingKey, err := hex.DecodeString(req.FormValue("sharingkey"))
+	if err != nil {
+		WriteError(w, Error{"unable to decode 'sharingkey' parameter: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	// Check whether existing file should be overwritten
+	force := false
+	if f := req.FormValue("force"); f != "" {
+		force, err = strconv.ParseBool(f)
+		if err != nil {
+			WriteError(w, Error{"unable to parse 'force' parameter: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	siaPath, err := modules.NewSiaPath(ps.ByName("siapath"))
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	siaPath, err = rebaseInputSiaPath(siaPath)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	err = api.renter.ImportSharedFile(siaPath, descriptor, sharingKey, force)
+	if errors.Contains(err, siafile.ErrInvalidShareDescriptor) {
+		WriteError(w, Error{"import failed: " + err.Error()}, http.StatusBadRequest)
+		return
+	} else if err != nil {
+		WriteError(w, Error{"import failed: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// renterShareHandlerGET handles the API call to create a share descriptor for a
+// file.
+func (api *API) renterShareHandlerGET(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	siaPath, err := modules.NewSiaPath(ps.ByName("siapath"))
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	// Determine whether the user is requesting a user siapath, or a root siapath.
+	root, err := isCalledWithRootFlag(req)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if !root {
+		siaPath, err = rebaseInputSiaPath(siaPath)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	descriptor, sharingKey, err := api.renter.ShareFile(siaPath)
+	if errors.Contains(err, filesystem.ErrNotExist) {
+		WriteError(w, Error{err.Error()}, http.StatusNotFound)
+		return
+	} else if err != nil {
+		WriteError(w, Error{"unable to share file: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	WriteJSON(w, RenterShareGET{
+		Descriptor: base64.StdEncoding.EncodeToString(descriptor),
+		SharingKey: hex.EncodeToString(sharingKey),
+	})
+}
+
+// renterUploadPackedHandler handles the API call to pack multiple small files
+// into shared chunks and upload them.
+func (api *API) renterUploadPackedHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params RenterUploadPackedPOST
+	if err := json.NewDecoder(req.Body).Decode(&params); err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if len(params.Files) == 0 {
+		WriteError(w, Error{"no files provided"}, http.StatusBadRequest)
+		return
+	}
+	// Parse the erasure coder.
+	ec, err := parseErasureCodingParameters(fmt.Sprint(params.DataPieces), fmt.Sprint(params.ParityPieces))
+	if err != nil {
+		WriteError(w, Error{"unable to parse erasure code settings: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	// Parse the files.
+	files := make([]modules.PackedFileParams, 0, len(params.Files))
+	for _, file := range params.Files {
+		// Source must be absolute path.
+		if !filepath.IsAbs(file.Source) {
+			WriteError(w, Error{"source must be an absolute path: " + file.Source}, http.StatusBadRequest)
+			return
+		}
+		siaPath, err := modules.NewSiaPath(file.SiaPath)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+		siaPath, err = rebaseInputSiaPath(siaPath)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+		files = append(files, modules.PackedFileParams{
+			Source:  file.Source,
+			SiaPath: siaPath,
+		})
+	}
+	err = api.renter.UploadPacked(modules.PackedUploadParams{
+		Files:       files,
+		ErasureCode: ec,
+		Force:       params.Force,
+
+		// NOTE: can make this an optional param.
+		CipherType: crypto.TypeDefaultRenter,
+	})
+	if err != nil {
+		WriteError(w, Error{"packed upload failed: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	WriteSuccess(w)
+}
+
 // renterUploadReadyHandler handles the API call to check whether or not the
 // renter is ready to upload files
 func (api *API) renterUploadReadyHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
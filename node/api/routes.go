@@ -33,8 +33,17 @@ func (api *API) buildHTTPRoutes() {
 	router.NotFound = http.HandlerFunc(api.UnrecognizedCallHandler)
 	router.RedirectTrailingSlash = false
 
+	// Accounting API Calls
+	if api.accounting != nil {
+		router.GET("/accounting", RequirePassword(api.accountingHandlerGET, requiredPassword))
+	}
+
 	// Daemon API Calls
 	router.GET("/daemon/alerts", api.daemonAlertsHandlerGET)
+	router.GET("/daemon/alerts/stream", api.daemonAlertsStreamHandlerGET)
+	router.GET("/daemon/alerts/webhooks", RequirePassword(api.daemonAlertsWebhooksHandlerGET, requiredPassword))
+	router.POST("/daemon/alerts/webhooks/add", RequirePassword(api.daemonAlertsWebhooksAddHandlerPOST, requiredPassword))
+	router.POST("/daemon/alerts/webhooks/remove", RequirePassword(api.daemonAlertsWebhooksRemoveHandlerPOST, requiredPassword))
 	router.GET("/daemon/constants", api.daemonConstantsHandler)
 	router.GET("/daemon/settings", api.daemonSettingsHandlerGET)
 	router.POST("/daemon/settings", api.daemonSettingsHandlerPOST)
@@ -46,9 +55,18 @@ func (api *API) buildHTTPRoutes() {
 	router.POST("/daemon/update", api.daemonUpdateHandlerPOST)
 	router.GET("/daemon/version", api.daemonVersionHandler)
 
+	// Metrics API Calls
+	if api.metricsEnabled {
+		router.GET("/metrics", RequirePassword(api.metricsHandlerGET, requiredPassword))
+	}
+
 	// Consensus API Calls
 	if api.cs != nil {
 		RegisterRoutesConsensus(router, api.cs)
+
+		// Register the stream separately since it needs to be closed with
+		// the API.
+		router.GET("/consensus/stream/:id", api.consensusStreamHandlerGET)
 	}
 
 	// Explorer API Calls
@@ -97,6 +115,9 @@ func (api *API) buildHTTPRoutes() {
 		router.POST("/renter/file/*siapath", RequirePassword(api.renterFileHandlerPOST, requiredPassword))
 		router.GET("/renter/prices", api.renterPricesHandler)
 		router.POST("/renter/recoveryscan", RequirePassword(api.renterRecoveryScanHandlerPOST, requiredPassword))
+		router.GET("/renter/registry", RequirePassword(api.renterRegistryHandlerGET, requiredPassword))
+		router.POST("/renter/registry", RequirePassword(api.renterRegistryHandlerPOST, requiredPassword))
+		router.GET("/renter/registry/subscribe", RequirePassword(api.renterRegistrySubscribeHandlerGET, requiredPassword))
 		router.GET("/renter/recoveryscan", api.renterRecoveryScanHandlerGET)
 		router.GET("/renter/fuse", api.renterFuseHandlerGET)
 		router.POST("/renter/fuse/mount", RequirePassword(api.renterFuseMountHandlerPOST, requiredPassword))
@@ -106,9 +127,12 @@ func (api *API) buildHTTPRoutes() {
 		router.GET("/renter/download/*siapath", RequirePassword(api.renterDownloadHandler, requiredPassword))
 		router.POST("/renter/download/cancel", RequirePassword(api.renterCancelDownloadHandler, requiredPassword))
 		router.GET("/renter/downloadasync/*siapath", RequirePassword(api.renterDownloadAsyncHandler, requiredPassword))
+		router.POST("/renter/import/*siapath", RequirePassword(api.renterImportHandler, requiredPassword))
 		router.POST("/renter/rename/*siapath", RequirePassword(api.renterRenameHandler, requiredPassword))
+		router.GET("/renter/share/*siapath", RequirePassword(api.renterShareHandlerGET, requiredPassword))
 		router.GET("/renter/stream/*siapath", api.renterStreamHandler)
 		router.POST("/renter/upload/*siapath", RequirePassword(api.renterUploadHandler, requiredPassword))
+		router.POST("/renter/uploadpacked", RequirePassword(api.renterUploadPackedHandler, requiredPassword))
 		router.GET("/renter/uploadready", api.renterUploadReadyHandler)
 		router.POST("/renter/uploads/pause", RequirePassword(api.renterUploadsPauseHandler, requiredPassword))
 		router.POST("/renter/uploads/resume", RequirePassword(api.renterUploadsResumeHandler, requiredPassword))
@@ -144,6 +168,12 @@ func (api *API) buildHTTPRoutes() {
 	// Wallet API Calls
 	if api.wallet != nil {
 		RegisterRoutesWallet(router, api.wallet, requiredPassword)
+
+		// Finalizing partially signed transactions requires the transaction
+		// pool to broadcast them.
+		if api.tpool != nil {
+			router.POST("/wallet/partial/finalize", RequirePassword(api.walletPartialFinalizeHandler, requiredPassword))
+		}
 	}
 
 	// Apply UserAgent middleware and return the Router
@@ -152,8 +182,31 @@ func (api *API) buildHTTPRoutes() {
 	if err != nil {
 		build.Critical("marshalling error on object that should be safe to marshal:", err)
 	}
+	handler := RequireUserAgent(router, requiredUserAgent)
+	if api.metricsEnabled && requiredPassword != "" {
+		// Prometheus can't set a custom user agent, so /metrics skips the
+		// user agent check. This is only done if the endpoint requires the
+		// API password, otherwise any website could read the metrics.
+		uaHandler := handler
+		handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.URL.Path == "/metrics" {
+				router.ServeHTTP(w, req)
+				return
+			}
+			uaHandler.ServeHTTP(w, req)
+		})
+	}
+	timeoutHandler := http.TimeoutHandler(handler, httpServerTimeout, string(jsonErr))
 	api.routerMu.Lock()
-	api.router = http.TimeoutHandler(RequireUserAgent(router, requiredUserAgent), httpServerTimeout, string(jsonErr))
+	api.router = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// The TimeoutHandler buffers the whole response which doesn't work
+		// for long-lived streams.
+		if isLongLivedStream(req) {
+			handler.ServeHTTP(w, req)
+			return
+		}
+		timeoutHandler.ServeHTTP(w, req)
+	})
 	api.routerMu.Unlock()
 	return
 }
@@ -193,3 +246,11 @@ func RequirePassword(h httprouter.Handle, password string) httprouter.Handle {
 func isUnrestricted(req *http.Request) bool {
 	return strings.HasPrefix(req.URL.Path, "/renter/stream/")
 }
+
+// isLongLivedStream checks if a request is for an endpoint that keeps the
+// connection open indefinitely and therefore can't be subject to the
+// httpServerTimeout.
+func isLongLivedStream(req *http.Request) bool {
+	return req.URL.Path == "/renter/registry/subscribe" || req.URL.Path == "/daemon/alerts/stream" ||
+		strings.HasPrefix(req.URL.Path, "/consensus/stream/")
+}
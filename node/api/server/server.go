@@ -60,8 +60,10 @@ func (srv *Server) Close() error {
 	defer close(srv.closeChan)
 	srv.closeMu.Lock()
 	defer srv.closeMu.Unlock()
-	// Stop accepting API requests.
-	err := srv.apiServer.Shutdown(context.Background())
+	// Stop the background threads of the API first to close long-lived
+	// streams. Then stop accepting API requests.
+	err := srv.api.Close()
+	err = errors.Compose(err, srv.apiServer.Shutdown(context.Background()))
 	// Wait for serve() to return and capture its error.
 	<-srv.serveChan
 	if !errors.Contains(srv.serveErr, http.ErrServerClosed) {
@@ -176,6 +178,9 @@ func NewAsync(APIaddr string, requiredUserAgent string, requiredPassword string,
 
 		// Create the api for the server.
 		api := api.New(cfg, requiredUserAgent, requiredPassword, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+		if nodeParams.EnableMetrics {
+			api.EnableMetrics()
+		}
 		srv := &Server{
 			api: api,
 			apiServer: &http.Server{
@@ -59,6 +59,7 @@ func (srv *Server) panicClose() {
 func (srv *Server) Close() error {
 	err := srv.listener.Close()
 	err = errors.Extend(err, srv.tg.Stop())
+	err = errors.Extend(err, srv.api.Close())
 
 	// Safely close each module.
 	mods := []struct {
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"math"
@@ -33,9 +34,43 @@ type (
 		SiacoinClaimBalance types.Currency `json:"siacoinclaimbalance"`
 		SiafundBalance      types.Currency `json:"siafundbalance"`
 
+		PendingUnlockSiacoins types.Currency `json:"pendingunlocksiacoins"`
+		PendingUnlockSiafunds types.Currency `json:"pendingunlocksiafunds"`
+
+		MultisigSiacoins types.Currency `json:"multisigsiacoins"`
+		MultisigSiafunds types.Currency `json:"multisigsiafunds"`
+
 		DustThreshold types.Currency `json:"dustthreshold"`
 	}
 
+	// WalletAccountGET contains an account of the wallet and the addresses
+	// assigned to it.
+	WalletAccountGET struct {
+		modules.WalletAccount
+		Addresses []types.UnlockHash `json:"addresses"`
+	}
+
+	// WalletAccountPOST contains the addresses to assign to or remove from an
+	// account. Instead of addresses, the seed of an auxiliary seed can be
+	// provided to assign all of its addresses.
+	WalletAccountPOST struct {
+		Addresses  []types.UnlockHash `json:"addresses"`
+		Seed       string             `json:"seed"`
+		Dictionary string             `json:"dictionary"`
+		Remove     bool               `json:"remove"`
+	}
+
+	// WalletAccountsGET contains the accounts of the wallet.
+	WalletAccountsGET struct {
+		Accounts []modules.WalletAccount `json:"accounts"`
+	}
+
+	// WalletAccountsPOST contains the name of an account to create or remove.
+	WalletAccountsPOST struct {
+		Name   string `json:"name"`
+		Remove bool   `json:"remove"`
+	}
+
 	// WalletAddressGET contains an address returned by a GET call to
 	// /wallet/address.
 	WalletAddressGET struct {
@@ -48,17 +83,100 @@ type (
 		Addresses []types.UnlockHash `json:"addresses"`
 	}
 
+	// WalletBumpPOST contains the transactions created by a POST call to
+	// /wallet/bump/:txid and the method used to bump the fee.
+	WalletBumpPOST struct {
+		Method         modules.FeeBumpMethod `json:"method"`
+		Transactions   []types.Transaction   `json:"transactions"`
+		TransactionIDs []types.TransactionID `json:"transactionids"`
+	}
+
 	// WalletInitPOST contains the primary seed that gets generated during a
 	// POST call to /wallet/init.
 	WalletInitPOST struct {
 		PrimarySeed string `json:"primaryseed"`
 	}
 
+	// WalletMultisigAddress contains a multisig address of the wallet and its
+	// confirmed balance.
+	WalletMultisigAddress struct {
+		Address          types.UnlockHash       `json:"address"`
+		UnlockConditions types.UnlockConditions `json:"unlockconditions"`
+		SiacoinBalance   types.Currency         `json:"siacoinbalance"`
+		SiafundBalance   types.Currency         `json:"siafundbalance"`
+	}
+
+	// WalletMultisigGET contains the multisig addresses tracked by the
+	// wallet.
+	WalletMultisigGET struct {
+		Addresses []WalletMultisigAddress `json:"addresses"`
+	}
+
+	// WalletMultisigPOST contains the multisig address created by a POST
+	// call to /wallet/multisig.
+	WalletMultisigPOST struct {
+		Address          types.UnlockHash       `json:"address"`
+		UnlockConditions types.UnlockConditions `json:"unlockconditions"`
+	}
+
+	// WalletMultisigPOSTParams contains the public keys and threshold of a
+	// multisig address.
+	WalletMultisigPOSTParams struct {
+		PublicKeys         []types.SiaPublicKey `json:"publickeys"`
+		SignaturesRequired uint64               `json:"signaturesrequired"`
+		Unused             bool                 `json:"unused"`
+	}
+
+	// WalletPartialPOST contains a partially signed transaction and its
+	// signing status.
+	WalletPartialPOST struct {
+		PartialTransaction modules.PartiallySignedTransaction   `json:"partialtransaction"`
+		Inputs             []modules.PartiallySignedInputStatus `json:"inputs"`
+		Complete           bool                                 `json:"complete"`
+	}
+
+	// WalletPartialCreatePOSTParams contains the transaction and parents
+	// used to create a partially signed transaction.
+	WalletPartialCreatePOSTParams struct {
+		Transaction types.Transaction   `json:"transaction"`
+		Parents     []types.Transaction `json:"parents"`
+	}
+
+	// WalletPartialFinalizePOST contains the transactions of a finalized
+	// partially signed transaction.
+	WalletPartialFinalizePOST struct {
+		Transactions   []types.Transaction   `json:"transactions"`
+		TransactionIDs []types.TransactionID `json:"transactionids"`
+		Broadcast      bool                  `json:"broadcast"`
+	}
+
+	// WalletPartialFinalizePOSTParams contains the partially signed
+	// transaction to finalize and whether it should be broadcast.
+	WalletPartialFinalizePOSTParams struct {
+		PartialTransaction modules.PartiallySignedTransaction `json:"partialtransaction"`
+		Broadcast          bool                               `json:"broadcast"`
+	}
+
+	// WalletPartialMergePOSTParams contains the partially signed transactions
+	// to merge.
+	WalletPartialMergePOSTParams struct {
+		PartialTransactions []modules.PartiallySignedTransaction `json:"partialtransactions"`
+	}
+
+	// WalletPartialPOSTParams contains a partially signed transaction.
+	WalletPartialPOSTParams struct {
+		PartialTransaction modules.PartiallySignedTransaction `json:"partialtransaction"`
+	}
+
 	// WalletSiacoinsPOST contains the transaction sent in the POST call to
 	// /wallet/siacoins.
 	WalletSiacoinsPOST struct {
 		Transactions   []types.Transaction   `json:"transactions"`
 		TransactionIDs []types.TransactionID `json:"transactionids"`
+
+		// UnlockConditions are the conditions of the time-locked destination
+		// if the siacoins were sent to a public key with a timelock.
+		UnlockConditions *types.UnlockConditions `json:"unlockconditions,omitempty"`
 	}
 
 	// WalletSiafundsPOST contains the transaction sent in the POST call to
@@ -94,6 +212,28 @@ type (
 		Funds types.Currency `json:"funds"`
 	}
 
+	// WalletTimelockAddress contains a time-locked address of the wallet
+	// and its unlock conditions.
+	WalletTimelockAddress struct {
+		Address          types.UnlockHash       `json:"address"`
+		UnlockConditions types.UnlockConditions `json:"unlockconditions"`
+	}
+
+	// WalletTimelockGET contains the time-locked addresses of the wallet and
+	// the outputs which are pending unlock.
+	WalletTimelockGET struct {
+		Addresses []WalletTimelockAddress    `json:"addresses"`
+		Outputs   []modules.TimelockedOutput `json:"outputs"`
+	}
+
+	// WalletTimelockPOSTParams contains either the unlock height of a new
+	// time-locked address or the unlock conditions of an existing one.
+	WalletTimelockPOSTParams struct {
+		UnlockHeight     types.BlockHeight       `json:"unlockheight"`
+		UnlockConditions *types.UnlockConditions `json:"unlockconditions"`
+		Unused           bool                    `json:"unused"`
+	}
+
 	// WalletTransactionGETid contains the transaction returned by a call to
 	// /wallet/transaction/:id
 	WalletTransactionGETid struct {
@@ -105,6 +245,16 @@ type (
 	WalletTransactionsGET struct {
 		ConfirmedTransactions   []modules.ProcessedTransaction `json:"confirmedtransactions"`
 		UnconfirmedTransactions []modules.ProcessedTransaction `json:"unconfirmedtransactions"`
+
+		// AddressLabels and TransactionLabels contain the labels of the
+		// addresses and transactions that appear in the returned
+		// transactions.
+		AddressLabels     []modules.AddressLabel     `json:"addresslabels"`
+		TransactionLabels []modules.TransactionLabel `json:"transactionlabels"`
+
+		// FiatTransactions contains the fiat values of the returned confirmed
+		// transactions if a currency symbol was provided.
+		FiatTransactions []modules.FiatTransaction `json:"fiattransactions,omitempty"`
 	}
 
 	// WalletTransactionsGETaddr contains the set of wallet transactions
@@ -145,6 +295,62 @@ type (
 		Valid bool `json:"valid"`
 	}
 
+	// WalletScheduleGET contains the scheduled payments of the wallet.
+	WalletScheduleGET struct {
+		ScheduledPayments []modules.ScheduledPayment `json:"scheduledpayments"`
+	}
+
+	// WalletScheduleGETid contains a single scheduled payment.
+	WalletScheduleGETid struct {
+		ScheduledPayment modules.ScheduledPayment `json:"scheduledpayment"`
+	}
+
+	// WalletSchedulePOST contains the created or updated scheduled payment.
+	WalletSchedulePOST struct {
+		ScheduledPayment modules.ScheduledPayment `json:"scheduledpayment"`
+	}
+
+	// WalletExchangeRatesGET contains the stored historical exchange rates.
+	WalletExchangeRatesGET struct {
+		ExchangeRates []modules.ExchangeRatePoint `json:"exchangerates"`
+	}
+
+	// WalletExchangeRatesPOST contains historical exchange rates to store.
+	// The rates can be provided as exchange rate points or as CSV records of
+	// the form 'time,rate' with the currency symbol of the rates.
+	WalletExchangeRatesPOST struct {
+		ExchangeRates []modules.ExchangeRatePoint `json:"exchangerates"`
+		CSV           string                      `json:"csv"`
+		Symbol        string                      `json:"symbol"`
+	}
+
+	// WalletExportGET contains the confirmed wallet transactions of a height
+	// range prepared for exporting.
+	WalletExportGET struct {
+		StartHeight  types.BlockHeight           `json:"startheight"`
+		EndHeight    types.BlockHeight           `json:"endheight"`
+		Transactions []modules.ExportTransaction `json:"transactions"`
+	}
+
+	// WalletReportGET contains a fiat report of the wallet's transactions.
+	WalletReportGET struct {
+		modules.FiatReport
+	}
+
+	// WalletLabelsGET contains the labels of the wallet's address book and
+	// transactions.
+	WalletLabelsGET struct {
+		AddressLabels     []modules.AddressLabel     `json:"addresslabels"`
+		TransactionLabels []modules.TransactionLabel `json:"transactionlabels"`
+	}
+
+	// WalletLabelsPOST contains the address and transaction labels to set.
+	// Labels with an empty name, or without note and tags, are removed.
+	WalletLabelsPOST struct {
+		AddressLabels     []modules.AddressLabel     `json:"addresslabels"`
+		TransactionLabels []modules.TransactionLabel `json:"transactionlabels"`
+	}
+
 	// WalletWatchPOST contains the set of addresses to add or remove from the
 	// watch set.
 	WalletWatchPOST struct {
@@ -168,6 +374,21 @@ func RegisterRoutesWallet(router *httprouter.Router, wallet modules.Wallet, requ
 	router.POST("/wallet/033x", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		wallet033xHandler(wallet, w, req, ps)
 	}, requiredPassword))
+	router.GET("/wallet/accounts", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		walletAccountsHandlerGET(wallet, w, req, ps)
+	}, requiredPassword))
+	router.POST("/wallet/accounts", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		walletAccountsHandlerPOST(wallet, w, req, ps)
+	}, requiredPassword))
+	router.GET("/wallet/accounts/:name", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		walletAccountHandlerGET(wallet, w, req, ps)
+	}, requiredPassword))
+	router.POST("/wallet/accounts/:name", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		walletAccountHandlerPOST(wallet, w, req, ps)
+	}, requiredPassword))
+	router.GET("/wallet/accounts/:name/address", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		walletAccountAddressHandler(wallet, w, req, ps)
+	}, requiredPassword))
 	router.GET("/wallet/address", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		walletAddressHandler(wallet, w, req, ps)
 	}, requiredPassword))
@@ -180,15 +401,66 @@ func RegisterRoutesWallet(router *httprouter.Router, wallet modules.Wallet, requ
 	router.GET("/wallet/backup", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		walletBackupHandler(wallet, w, req, ps)
 	}, requiredPassword))
+	router.POST("/wallet/bump/:txid", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		walletBumpHandler(wallet, w, req, ps)
+	}, requiredPassword))
+	router.GET("/wallet/exchangerates", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		walletExchangeRatesHandlerGET(wallet, w, req, ps)
+	}, requiredPassword))
+	router.POST("/wallet/exchangerates", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		walletExchangeRatesHandlerPOST(wallet, w, req, ps)
+	}, requiredPassword))
+	router.GET("/wallet/export", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		walletExportHandler(wallet, w, req, ps)
+	}, requiredPassword))
 	router.POST("/wallet/init", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		walletInitHandler(wallet, w, req, ps)
 	}, requiredPassword))
 	router.POST("/wallet/init/seed", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		walletInitSeedHandler(wallet, w, req, ps)
 	}, requiredPassword))
+	router.GET("/wallet/labels", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		walletLabelsHandlerGET(wallet, w, req, ps)
+	}, requiredPassword))
+	router.POST("/wallet/labels", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		walletLabelsHandlerPOST(wallet, w, req, ps)
+	}, requiredPassword))
 	router.POST("/wallet/lock", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		walletLockHandler(wallet, w, req, ps)
 	}, requiredPassword))
+	router.GET("/wallet/multisig", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		walletMultisigHandlerGET(wallet, w, req, ps)
+	}, requiredPassword))
+	router.POST("/wallet/multisig", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		walletMultisigHandlerPOST(wallet, w, req, ps)
+	}, requiredPassword))
+	router.POST("/wallet/partial/create", func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		walletPartialCreateHandler(w, req, ps)
+	})
+	router.POST("/wallet/partial/merge", func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		walletPartialMergeHandler(wallet, w, req, ps)
+	})
+	router.POST("/wallet/partial/sign", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		walletPartialSignHandler(wallet, w, req, ps)
+	}, requiredPassword))
+	router.POST("/wallet/partial/status", func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		walletPartialStatusHandler(w, req, ps)
+	})
+	router.GET("/wallet/report", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		walletReportHandler(wallet, w, req, ps)
+	}, requiredPassword))
+	router.GET("/wallet/schedule", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		walletScheduleHandlerGET(wallet, w, req, ps)
+	}, requiredPassword))
+	router.POST("/wallet/schedule", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		walletScheduleHandlerPOST(wallet, w, req, ps)
+	}, requiredPassword))
+	router.GET("/wallet/schedule/:id", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		walletScheduleIDHandlerGET(wallet, w, req, ps)
+	}, requiredPassword))
+	router.POST("/wallet/schedule/:id", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		walletScheduleIDHandlerPOST(wallet, w, req, ps)
+	}, requiredPassword))
 	router.POST("/wallet/seed", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		walletSeedHandler(wallet, w, req, ps)
 	}, requiredPassword))
@@ -207,6 +479,12 @@ func RegisterRoutesWallet(router *httprouter.Router, wallet modules.Wallet, requ
 	router.POST("/wallet/sweep/seed", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		walletSweepSeedHandler(wallet, w, req, ps)
 	}, requiredPassword))
+	router.GET("/wallet/timelock", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		walletTimelockHandlerGET(wallet, w, req, ps)
+	}, requiredPassword))
+	router.POST("/wallet/timelock", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		walletTimelockHandlerPOST(wallet, w, req, ps)
+	}, requiredPassword))
 	router.GET("/wallet/transaction/:id", func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		walletTransactionHandler(wallet, w, req, ps)
 	})
@@ -301,6 +579,25 @@ func walletHandler(wallet modules.Wallet, w http.ResponseWriter, _ *http.Request
 		WriteError(w, Error{fmt.Sprintf("Error when calling /wallet: %v", err)}, http.StatusBadRequest)
 		return
 	}
+	locked, err := wallet.TimelockedOutputs()
+	if err != nil {
+		WriteError(w, Error{fmt.Sprintf("Error when calling /wallet: %v", err)}, http.StatusBadRequest)
+		return
+	}
+	multisigSiacoins, multisigSiafunds, err := wallet.MultisigBalance()
+	if err != nil {
+		WriteError(w, Error{fmt.Sprintf("Error when calling /wallet: %v", err)}, http.StatusBadRequest)
+		return
+	}
+	var lockedSiacoins, lockedSiafunds types.Currency
+	for _, to := range locked {
+		switch to.FundType {
+		case types.SpecifierSiacoinOutput:
+			lockedSiacoins = lockedSiacoins.Add(to.Value)
+		case types.SpecifierSiafundOutput:
+			lockedSiafunds = lockedSiafunds.Add(to.Value)
+		}
+	}
 	WriteJSON(w, WalletGET{
 		Encrypted:  encrypted,
 		Unlocked:   unlocked,
@@ -314,6 +611,12 @@ func walletHandler(wallet modules.Wallet, w http.ResponseWriter, _ *http.Request
 		SiafundBalance:      siafundBal,
 		SiacoinClaimBalance: siaclaimBal,
 
+		PendingUnlockSiacoins: lockedSiacoins,
+		PendingUnlockSiafunds: lockedSiafunds,
+
+		MultisigSiacoins: multisigSiacoins,
+		MultisigSiafunds: multisigSiafunds,
+
 		DustThreshold: dustThreshold,
 	})
 }
@@ -353,6 +656,130 @@ func walletAddressHandler(wallet modules.Wallet, w http.ResponseWriter, _ *http.
 	})
 }
 
+// walletAccountAddressHandler handles API calls to
+// /wallet/accounts/:name/address.
+func walletAccountAddressHandler(wallet modules.Wallet, w http.ResponseWriter, _ *http.Request, ps httprouter.Params) {
+	uc, err := wallet.AccountAddress(ps.ByName("name"))
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/accounts/:name/address: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletAddressGET{
+		Address: uc.UnlockHash(),
+	})
+}
+
+// walletAccountHandlerGET handles GET calls to /wallet/accounts/:name.
+func walletAccountHandlerGET(wallet modules.Wallet, w http.ResponseWriter, _ *http.Request, ps httprouter.Params) {
+	name := ps.ByName("name")
+	addrs, err := wallet.AccountAddresses(name)
+	if err != nil {
+		WriteError(w, Error{"failed to get account addresses: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	accounts, err := wallet.Accounts()
+	if err != nil {
+		WriteError(w, Error{"failed to get accounts: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	for _, account := range accounts {
+		if account.Name == name {
+			WriteJSON(w, WalletAccountGET{
+				WalletAccount: account,
+				Addresses:     addrs,
+			})
+			return
+		}
+	}
+	WriteError(w, Error{"account doesn't exist"}, http.StatusBadRequest)
+}
+
+// walletAccountHandlerPOST handles POST calls to /wallet/accounts/:name.
+func walletAccountHandlerPOST(wallet modules.Wallet, w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	var wap WalletAccountPOST
+	err := json.NewDecoder(req.Body).Decode(&wap)
+	if err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	name := ps.ByName("name")
+	if wap.Seed != "" {
+		if wap.Remove {
+			WriteError(w, Error{"seeds can't be removed from an account"}, http.StatusBadRequest)
+			return
+		}
+		dictID := mnemonics.DictionaryID(wap.Dictionary)
+		if dictID == "" {
+			dictID = "english"
+		}
+		seed, err := modules.StringToSeed(wap.Seed, dictID)
+		if err != nil {
+			WriteError(w, Error{"invalid seed: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		if err := wallet.AssignAccountSeed(name, seed); err != nil {
+			WriteError(w, Error{"failed to assign seed: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	if wap.Remove {
+		// Only remove addresses which are assigned to the account.
+		assigned, err := wallet.AccountAddresses(name)
+		if err != nil {
+			WriteError(w, Error{"failed to get account addresses: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		isAssigned := make(map[types.UnlockHash]struct{}, len(assigned))
+		for _, addr := range assigned {
+			isAssigned[addr] = struct{}{}
+		}
+		for _, addr := range wap.Addresses {
+			if _, exists := isAssigned[addr]; !exists {
+				WriteError(w, Error{fmt.Sprintf("address %v isn't assigned to the account", addr)}, http.StatusBadRequest)
+				return
+			}
+		}
+		name = ""
+	}
+	if err := wallet.AssignAccountAddresses(name, wap.Addresses); err != nil {
+		WriteError(w, Error{"failed to assign addresses: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// walletAccountsHandlerGET handles GET calls to /wallet/accounts.
+func walletAccountsHandlerGET(wallet modules.Wallet, w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	accounts, err := wallet.Accounts()
+	if err != nil {
+		WriteError(w, Error{"failed to get accounts: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletAccountsGET{
+		Accounts: accounts,
+	})
+}
+
+// walletAccountsHandlerPOST handles POST calls to /wallet/accounts.
+func walletAccountsHandlerPOST(wallet modules.Wallet, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var wap WalletAccountsPOST
+	err := json.NewDecoder(req.Body).Decode(&wap)
+	if err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if wap.Remove {
+		err = wallet.RemoveAccount(wap.Name)
+	} else {
+		err = wallet.AddAccount(wap.Name)
+	}
+	if err != nil {
+		WriteError(w, Error{"failed to update accounts: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
 // walletSeedAddressesHandler handles the requests to /wallet/seedaddrs.
 func walletSeedAddressesHandler(wallet modules.Wallet, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	// Parse the count argument. If it isn't specified we return as many
@@ -579,12 +1006,268 @@ func walletSeedsHandler(wallet modules.Wallet, w http.ResponseWriter, req *http.
 	})
 }
 
+// scanOutputIDs scans a comma separated list of siacoin output ids.
+func scanOutputIDs(param string) ([]types.SiacoinOutputID, error) {
+	if param == "" {
+		return nil, nil
+	}
+	var ids []types.SiacoinOutputID
+	for _, s := range strings.Split(param, ",") {
+		h, err := scanHash(strings.TrimSpace(s))
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, types.SiacoinOutputID(h))
+	}
+	return ids, nil
+}
+
+// scanCoinControl scans the coin control parameters of a request to
+// /wallet/siacoins.
+func scanCoinControl(req *http.Request) (cc modules.CoinControl, err error) {
+	cc.Include, err = scanOutputIDs(req.FormValue("include"))
+	if err != nil {
+		return modules.CoinControl{}, errors.AddContext(err, "could not read include")
+	}
+	cc.Exclude, err = scanOutputIDs(req.FormValue("exclude"))
+	if err != nil {
+		return modules.CoinControl{}, errors.AddContext(err, "could not read exclude")
+	}
+	cc.Strategy = modules.CoinSelectionStrategy(req.FormValue("strategy"))
+	cc.Account = req.FormValue("account")
+	if change := req.FormValue("changeaddress"); change != "" {
+		cc.ChangeAddress, err = scanAddress(change)
+		if err != nil {
+			return modules.CoinControl{}, errors.AddContext(err, "could not read changeaddress")
+		}
+	}
+	return cc, cc.Validate()
+}
+
+// walletBumpHandler handles API calls to /wallet/bump/:txid.
+func walletBumpHandler(wallet modules.Wallet, w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	var txid types.TransactionID
+	if err := txid.UnmarshalJSON([]byte("\"" + ps.ByName("txid") + "\"")); err != nil {
+		WriteError(w, Error{"could not read txid from POST call to /wallet/bump: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	var feePerByte types.Currency
+	if fpb := req.FormValue("feeperbyte"); fpb != "" {
+		var ok bool
+		feePerByte, ok = scanAmount(fpb)
+		if !ok {
+			WriteError(w, Error{"could not read feeperbyte from POST call to /wallet/bump"}, http.StatusBadRequest)
+			return
+		}
+	}
+	method := modules.FeeBumpMethod(req.FormValue("method"))
+
+	txns, method, err := wallet.BumpTransaction(txid, method, feePerByte)
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/bump: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	var txids []types.TransactionID
+	for _, txn := range txns {
+		txids = append(txids, txn.ID())
+	}
+	WriteJSON(w, WalletBumpPOST{
+		Method:         method,
+		Transactions:   txns,
+		TransactionIDs: txids,
+	})
+}
+
+// walletMultisigHandlerGET handles GET calls to /wallet/multisig.
+func walletMultisigHandlerGET(wallet modules.Wallet, w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	ucs, err := wallet.MultisigAddresses()
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/multisig: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	outputs, err := wallet.UnspentOutputs()
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/multisig: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	addrs := make([]WalletMultisigAddress, 0, len(ucs))
+	indices := make(map[types.UnlockHash]int)
+	for _, uc := range ucs {
+		indices[uc.UnlockHash()] = len(addrs)
+		addrs = append(addrs, WalletMultisigAddress{
+			Address:          uc.UnlockHash(),
+			UnlockConditions: uc,
+		})
+	}
+	for _, uo := range outputs {
+		i, exists := indices[uo.UnlockHash]
+		if !exists || uo.ConfirmationHeight == types.BlockHeight(math.MaxUint64) {
+			continue
+		}
+		switch uo.FundType {
+		case types.SpecifierSiacoinOutput:
+			addrs[i].SiacoinBalance = addrs[i].SiacoinBalance.Add(uo.Value)
+		case types.SpecifierSiafundOutput:
+			addrs[i].SiafundBalance = addrs[i].SiafundBalance.Add(uo.Value)
+		}
+	}
+	WriteJSON(w, WalletMultisigGET{
+		Addresses: addrs,
+	})
+}
+
+// walletMultisigHandlerPOST handles POST calls to /wallet/multisig.
+func walletMultisigHandlerPOST(wallet modules.Wallet, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletMultisigPOSTParams
+	if err := json.NewDecoder(req.Body).Decode(&params); err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	uc, err := wallet.AddMultisigAddress(params.PublicKeys, params.SignaturesRequired, params.Unused)
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/multisig: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletMultisigPOST{
+		Address:          uc.UnlockHash(),
+		UnlockConditions: uc,
+	})
+}
+
+// writePartialTransaction writes a partially signed transaction and its
+// signing status.
+func writePartialTransaction(w http.ResponseWriter, pst modules.PartiallySignedTransaction) {
+	WriteJSON(w, WalletPartialPOST{
+		PartialTransaction: pst,
+		Inputs:             pst.Status(),
+		Complete:           pst.Complete(),
+	})
+}
+
+// walletPartialCreateHandler handles API calls to /wallet/partial/create.
+func walletPartialCreateHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletPartialCreatePOSTParams
+	if err := json.NewDecoder(req.Body).Decode(&params); err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	pst, err := modules.NewPartiallySignedTransaction(params.Transaction, params.Parents)
+	if err != nil {
+		WriteError(w, Error{"failed to create partially signed transaction: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	writePartialTransaction(w, pst)
+}
+
+// walletPartialMergeHandler handles API calls to /wallet/partial/merge. The
+// merged signatures are verified at the current height.
+func walletPartialMergeHandler(wallet modules.Wallet, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletPartialMergePOSTParams
+	if err := json.NewDecoder(req.Body).Decode(&params); err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if len(params.PartialTransactions) == 0 {
+		WriteError(w, Error{"no partially signed transactions provided"}, http.StatusBadRequest)
+		return
+	}
+	height, err := wallet.Height()
+	if err != nil {
+		WriteError(w, Error{"failed to get consensus height: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	pst := params.PartialTransactions[0]
+	for _, other := range params.PartialTransactions[1:] {
+		if err := pst.Merge(other, height); err != nil {
+			WriteError(w, Error{"failed to merge partially signed transactions: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	writePartialTransaction(w, pst)
+}
+
+// walletPartialSignHandler handles API calls to /wallet/partial/sign.
+func walletPartialSignHandler(wallet modules.Wallet, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletPartialPOSTParams
+	if err := json.NewDecoder(req.Body).Decode(&params); err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	height, err := wallet.Height()
+	if err != nil {
+		WriteError(w, Error{"failed to get consensus height: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	pst := params.PartialTransaction
+	if _, err := pst.Sign(wallet.SignTransaction, height); err != nil {
+		WriteError(w, Error{"failed to sign partially signed transaction: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	writePartialTransaction(w, pst)
+}
+
+// walletPartialStatusHandler handles API calls to /wallet/partial/status.
+func walletPartialStatusHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletPartialPOSTParams
+	if err := json.NewDecoder(req.Body).Decode(&params); err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	writePartialTransaction(w, params.PartialTransaction)
+}
+
+// walletPartialFinalizeHandler handles API calls to /wallet/partial/finalize.
+// The finalized transaction is checked for validity and optionally broadcast
+// using the transaction pool.
+func (api *API) walletPartialFinalizeHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletPartialFinalizePOSTParams
+	if err := json.NewDecoder(req.Body).Decode(&params); err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	height, err := api.wallet.Height()
+	if err != nil {
+		WriteError(w, Error{"failed to get consensus height: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	txns, err := params.PartialTransaction.Finalize(height)
+	if err != nil {
+		WriteError(w, Error{"failed to finalize partially signed transaction: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if err := txns[len(txns)-1].StandaloneValid(height); err != nil {
+		WriteError(w, Error{"finalized transaction is invalid: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if params.Broadcast {
+		if err := api.tpool.AcceptTransactionSet(txns); err != nil {
+			WriteError(w, Error{"failed to broadcast transaction: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	var txids []types.TransactionID
+	for _, txn := range txns {
+		txids = append(txids, txn.ID())
+	}
+	WriteJSON(w, WalletPartialFinalizePOST{
+		Transactions:   txns,
+		TransactionIDs: txids,
+		Broadcast:      params.Broadcast,
+	})
+}
+
 // walletSiacoinsHandler handles API calls to /wallet/siacoins.
 func walletSiacoinsHandler(wallet modules.Wallet, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	cc, err := scanCoinControl(req)
+	if err != nil {
+		WriteError(w, Error{"invalid coin control: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
 	var txns []types.Transaction
+	var timelocked *types.UnlockConditions
 	if req.FormValue("outputs") != "" {
 		// multiple amounts + destinations
-		if req.FormValue("amount") != "" || req.FormValue("destination") != "" || req.FormValue("feeIncluded") != "" {
+		if req.FormValue("amount") != "" || req.FormValue("destination") != "" || req.FormValue("feeIncluded") != "" || req.FormValue("publickey") != "" {
 			WriteError(w, Error{"cannot supply both 'outputs' and single amount+destination pair and/or feeIncluded parameter"}, http.StatusInternalServerError)
 			return
 		}
@@ -595,7 +1278,7 @@ func walletSiacoinsHandler(wallet modules.Wallet, w http.ResponseWriter, req *ht
 			WriteError(w, Error{"could not decode outputs: " + err.Error()}, http.StatusInternalServerError)
 			return
 		}
-		txns, err = wallet.SendSiacoinsMulti(outputs)
+		txns, err = wallet.SendSiacoinsMultiCoinControl(outputs, cc)
 		if err != nil {
 			WriteError(w, Error{"error when calling /wallet/siacoins: " + err.Error()}, http.StatusInternalServerError)
 			return
@@ -607,10 +1290,35 @@ func walletSiacoinsHandler(wallet modules.Wallet, w http.ResponseWriter, req *ht
 			WriteError(w, Error{"could not read amount from POST call to /wallet/siacoins"}, http.StatusBadRequest)
 			return
 		}
-		dest, err := scanAddress(req.FormValue("destination"))
-		if err != nil {
-			WriteError(w, Error{"could not read address from POST call to /wallet/siacoins"}, http.StatusBadRequest)
-			return
+		var dest types.UnlockHash
+		if req.FormValue("publickey") != "" {
+			// time-locked destination
+			if req.FormValue("destination") != "" {
+				WriteError(w, Error{"cannot supply both 'destination' and 'publickey'"}, http.StatusBadRequest)
+				return
+			}
+			var pk types.SiaPublicKey
+			if err := pk.LoadString(req.FormValue("publickey")); err != nil {
+				WriteError(w, Error{"could not read publickey from POST call to /wallet/siacoins: " + err.Error()}, http.StatusBadRequest)
+				return
+			}
+			var unlockHeight types.BlockHeight
+			if _, err := fmt.Sscan(req.FormValue("timelock"), &unlockHeight); err != nil || unlockHeight == 0 {
+				WriteError(w, Error{"could not read timelock from POST call to /wallet/siacoins"}, http.StatusBadRequest)
+				return
+			}
+			timelocked = &types.UnlockConditions{
+				Timelock:           unlockHeight,
+				PublicKeys:         []types.SiaPublicKey{pk},
+				SignaturesRequired: 1,
+			}
+			dest = timelocked.UnlockHash()
+		} else {
+			dest, err = scanAddress(req.FormValue("destination"))
+			if err != nil {
+				WriteError(w, Error{"could not read address from POST call to /wallet/siacoins"}, http.StatusBadRequest)
+				return
+			}
 		}
 		feeIncluded, err := scanBool(req.FormValue("feeIncluded"))
 		if err != nil {
@@ -618,11 +1326,7 @@ func walletSiacoinsHandler(wallet modules.Wallet, w http.ResponseWriter, req *ht
 			return
 		}
 
-		if feeIncluded {
-			txns, err = wallet.SendSiacoinsFeeIncluded(amount, dest)
-		} else {
-			txns, err = wallet.SendSiacoins(amount, dest)
-		}
+		txns, err = wallet.SendSiacoinsCoinControl(amount, dest, feeIncluded, cc)
 		if err != nil {
 			WriteError(w, Error{"error when calling /wallet/siacoins: " + err.Error()}, http.StatusInternalServerError)
 			return
@@ -634,8 +1338,9 @@ func walletSiacoinsHandler(wallet modules.Wallet, w http.ResponseWriter, req *ht
 		txids = append(txids, txn.ID())
 	}
 	WriteJSON(w, WalletSiacoinsPOST{
-		Transactions:   txns,
-		TransactionIDs: txids,
+		Transactions:     txns,
+		TransactionIDs:   txids,
+		UnlockConditions: timelocked,
 	})
 }
 
@@ -691,6 +1396,60 @@ func walletSweepSeedHandler(wallet modules.Wallet, w http.ResponseWriter, req *h
 	})
 }
 
+// walletTimelockHandlerGET handles GET calls to /wallet/timelock.
+func walletTimelockHandlerGET(wallet modules.Wallet, w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	ucs, err := wallet.TimelockedAddresses()
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/timelock: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	outputs, err := wallet.TimelockedOutputs()
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/timelock: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	addrs := make([]WalletTimelockAddress, 0, len(ucs))
+	for _, uc := range ucs {
+		addrs = append(addrs, WalletTimelockAddress{
+			Address:          uc.UnlockHash(),
+			UnlockConditions: uc,
+		})
+	}
+	WriteJSON(w, WalletTimelockGET{
+		Addresses: addrs,
+		Outputs:   outputs,
+	})
+}
+
+// walletTimelockHandlerPOST handles POST calls to /wallet/timelock.
+func walletTimelockHandlerPOST(wallet modules.Wallet, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletTimelockPOSTParams
+	if err := json.NewDecoder(req.Body).Decode(&params); err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	var uc types.UnlockConditions
+	var err error
+	if params.UnlockConditions != nil {
+		if params.UnlockHeight != 0 {
+			WriteError(w, Error{"cannot supply both 'unlockheight' and 'unlockconditions'"}, http.StatusBadRequest)
+			return
+		}
+		uc = *params.UnlockConditions
+		err = wallet.AddTimelockedAddress(uc, params.Unused)
+	} else {
+		uc, err = wallet.TimelockedAddress(params.UnlockHeight)
+	}
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/timelock: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletTimelockAddress{
+		Address:          uc.UnlockHash(),
+		UnlockConditions: uc,
+	})
+}
+
 // walletTransactionHandler handles API calls to /wallet/transaction/:id.
 func walletTransactionHandler(wallet modules.Wallet, w http.ResponseWriter, _ *http.Request, ps httprouter.Params) {
 	// Parse the id from the url.
@@ -752,10 +1511,119 @@ func walletTransactionsHandler(wallet modules.Wallet, w http.ResponseWriter, req
 		return
 	}
 
-	WriteJSON(w, WalletTransactionsGET{
+	addrLabels, err := wallet.AddressLabels()
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/transactions: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	txnLabels, err := wallet.TransactionLabels()
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/transactions: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	names := make(map[types.UnlockHash]modules.AddressLabel, len(addrLabels))
+	for _, al := range addrLabels {
+		names[al.Address] = al
+	}
+	labels := make(map[types.TransactionID]modules.TransactionLabel, len(txnLabels))
+	for _, tl := range txnLabels {
+		labels[tl.TransactionID] = tl
+	}
+
+	// If a label was provided, only return transactions with that tag or
+	// which involve an address with that name.
+	if label := req.FormValue("label"); label != "" {
+		matches := func(pt modules.ProcessedTransaction) bool {
+			if labels[pt.TransactionID].HasTag(label) {
+				return true
+			}
+			for _, addr := range pt.Addresses() {
+				if al, exists := names[addr]; exists && al.Name == label {
+					return true
+				}
+			}
+			return false
+		}
+		filter := func(pts []modules.ProcessedTransaction) (filtered []modules.ProcessedTransaction) {
+			for _, pt := range pts {
+				if matches(pt) {
+					filtered = append(filtered, pt)
+				}
+			}
+			return filtered
+		}
+		confirmedTxns = filter(confirmedTxns)
+		unconfirmedTxns = filter(unconfirmedTxns)
+	}
+
+	// If an account was provided, only return transactions which involve an
+	// address of the account.
+	if account := req.FormValue("account"); account != "" {
+		addrs, err := wallet.AccountAddresses(account)
+		if err != nil {
+			WriteError(w, Error{"error when calling /wallet/transactions: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		isAccount := make(map[types.UnlockHash]struct{}, len(addrs))
+		for _, addr := range addrs {
+			isAccount[addr] = struct{}{}
+		}
+		filter := func(pts []modules.ProcessedTransaction) (filtered []modules.ProcessedTransaction) {
+			for _, pt := range pts {
+				for _, addr := range pt.Addresses() {
+					if _, exists := isAccount[addr]; exists {
+						filtered = append(filtered, pt)
+						break
+					}
+				}
+			}
+			return filtered
+		}
+		confirmedTxns = filter(confirmedTxns)
+		unconfirmedTxns = filter(unconfirmedTxns)
+	}
+
+	// Collect the labels of the returned transactions.
+	wtg := WalletTransactionsGET{
 		ConfirmedTransactions:   confirmedTxns,
 		UnconfirmedTransactions: unconfirmedTxns,
-	})
+	}
+	seen := make(map[types.UnlockHash]struct{})
+	for _, pt := range append(append([]modules.ProcessedTransaction(nil), confirmedTxns...), unconfirmedTxns...) {
+		if tl, exists := labels[pt.TransactionID]; exists {
+			wtg.TransactionLabels = append(wtg.TransactionLabels, tl)
+		}
+		for _, addr := range pt.Addresses() {
+			if _, exists := seen[addr]; exists {
+				continue
+			}
+			seen[addr] = struct{}{}
+			if al, exists := names[addr]; exists {
+				wtg.AddressLabels = append(wtg.AddressLabels, al)
+			}
+		}
+	}
+
+	// If a currency symbol was provided, add the fiat values of the confirmed
+	// transactions.
+	if symbol := req.FormValue("symbol"); symbol != "" {
+		report, err := wallet.FiatReport(symbol, 0, math.MaxUint64, modules.ReportPeriodMonth)
+		if err != nil {
+			WriteError(w, Error{"error when calling /wallet/transactions: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		fiat := make(map[types.TransactionID]modules.FiatTransaction, len(report.Transactions))
+		for _, ft := range report.Transactions {
+			fiat[ft.TransactionID] = ft
+		}
+		wtg.FiatTransactions = []modules.FiatTransaction{}
+		for _, pt := range confirmedTxns {
+			if ft, exists := fiat[pt.TransactionID]; exists {
+				wtg.FiatTransactions = append(wtg.FiatTransactions, ft)
+			}
+		}
+	}
+	WriteJSON(w, wtg)
 }
 
 // walletTransactionsAddrHandler handles API calls to
@@ -939,6 +1807,330 @@ func walletWatchHandlerGET(wallet modules.Wallet, w http.ResponseWriter, _ *http
 	})
 }
 
+// scanScheduledPayment overwrites the fields of the scheduled payment with
+// the amount, destination, height and interval parameters of the request if
+// they are set.
+func scanScheduledPayment(req *http.Request, sp *modules.ScheduledPayment) error {
+	if amountStr := req.FormValue("amount"); amountStr != "" {
+		amount, ok := scanAmount(amountStr)
+		if !ok {
+			return errors.New("could not read amount")
+		}
+		sp.Amount = amount
+	}
+	if destStr := req.FormValue("destination"); destStr != "" {
+		dest, err := scanAddress(destStr)
+		if err != nil {
+			return errors.AddContext(err, "could not read destination")
+		}
+		sp.Destination = dest
+	}
+	if heightStr := req.FormValue("height"); heightStr != "" {
+		height, err := strconv.ParseUint(heightStr, 10, 64)
+		if err != nil {
+			return errors.AddContext(err, "could not read height")
+		}
+		sp.NextHeight = types.BlockHeight(height)
+	}
+	if intervalStr := req.FormValue("interval"); intervalStr != "" {
+		interval, err := strconv.ParseUint(intervalStr, 10, 64)
+		if err != nil {
+			return errors.AddContext(err, "could not read interval")
+		}
+		sp.Interval = types.BlockHeight(interval)
+	}
+	return nil
+}
+
+// walletScheduleHandlerGET handles GET calls to /wallet/schedule.
+func walletScheduleHandlerGET(wallet modules.Wallet, w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	sps, err := wallet.ScheduledPayments()
+	if err != nil {
+		WriteError(w, Error{"failed to get scheduled payments: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletScheduleGET{
+		ScheduledPayments: sps,
+	})
+}
+
+// walletScheduleHandlerPOST handles POST calls to /wallet/schedule.
+func walletScheduleHandlerPOST(wallet modules.Wallet, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	if req.FormValue("height") == "" {
+		WriteError(w, Error{"height must be provided to a /wallet/schedule call"}, http.StatusBadRequest)
+		return
+	}
+	var sp modules.ScheduledPayment
+	if err := scanScheduledPayment(req, &sp); err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	sp, err := wallet.AddScheduledPayment(sp)
+	if err != nil {
+		WriteError(w, Error{"failed to schedule payment: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletSchedulePOST{
+		ScheduledPayment: sp,
+	})
+}
+
+// walletScheduleIDHandlerGET handles GET calls to /wallet/schedule/:id.
+func walletScheduleIDHandlerGET(wallet modules.Wallet, w http.ResponseWriter, _ *http.Request, ps httprouter.Params) {
+	sp, err := wallet.ScheduledPayment(modules.ScheduledPaymentID(ps.ByName("id")))
+	if errors.Contains(err, modules.ErrUnknownScheduledPayment) {
+		WriteError(w, Error{err.Error()}, http.StatusNotFound)
+		return
+	} else if err != nil {
+		WriteError(w, Error{"failed to get scheduled payment: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletScheduleGETid{
+		ScheduledPayment: sp,
+	})
+}
+
+// walletScheduleIDHandlerPOST handles POST calls to /wallet/schedule/:id. It
+// either updates or removes the scheduled payment.
+func walletScheduleIDHandlerPOST(wallet modules.Wallet, w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	id := modules.ScheduledPaymentID(ps.ByName("id"))
+	var remove bool
+	if removeStr := req.FormValue("remove"); removeStr != "" {
+		var err error
+		remove, err = scanBool(removeStr)
+		if err != nil {
+			WriteError(w, Error{"invalid parameter 'remove': " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	if remove {
+		err := wallet.RemoveScheduledPayment(id)
+		if errors.Contains(err, modules.ErrUnknownScheduledPayment) {
+			WriteError(w, Error{err.Error()}, http.StatusNotFound)
+			return
+		} else if err != nil {
+			WriteError(w, Error{"failed to remove scheduled payment: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		WriteSuccess(w)
+		return
+	}
+
+	sp, err := wallet.ScheduledPayment(id)
+	if errors.Contains(err, modules.ErrUnknownScheduledPayment) {
+		WriteError(w, Error{err.Error()}, http.StatusNotFound)
+		return
+	} else if err != nil {
+		WriteError(w, Error{"failed to get scheduled payment: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if err := scanScheduledPayment(req, &sp); err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	sp, err = wallet.UpdateScheduledPayment(sp)
+	if err != nil {
+		WriteError(w, Error{"failed to update scheduled payment: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletSchedulePOST{
+		ScheduledPayment: sp,
+	})
+}
+
+// walletExchangeRatesHandlerGET handles GET calls to /wallet/exchangerates.
+func walletExchangeRatesHandlerGET(wallet modules.Wallet, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	points, err := wallet.ExchangeRates(req.FormValue("symbol"))
+	if err != nil {
+		WriteError(w, Error{"failed to get exchange rates: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if points == nil {
+		points = []modules.ExchangeRatePoint{}
+	}
+	WriteJSON(w, WalletExchangeRatesGET{
+		ExchangeRates: points,
+	})
+}
+
+// walletExchangeRatesHandlerPOST handles POST calls to /wallet/exchangerates.
+func walletExchangeRatesHandlerPOST(wallet modules.Wallet, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var werp WalletExchangeRatesPOST
+	err := json.NewDecoder(req.Body).Decode(&werp)
+	if err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	points := werp.ExchangeRates
+	if werp.CSV != "" {
+		if werp.Symbol == "" {
+			WriteError(w, Error{"a symbol must be provided with csv exchange rates"}, http.StatusBadRequest)
+			return
+		}
+		csvPoints, err := modules.ParseExchangeRateCSV(strings.NewReader(werp.CSV), werp.Symbol)
+		if err != nil {
+			WriteError(w, Error{"failed to parse csv: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		points = append(points, csvPoints...)
+	}
+	if err := wallet.AddExchangeRates(points); err != nil {
+		WriteError(w, Error{"failed to add exchange rates: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// walletExportHandler handles API calls to /wallet/export.
+func walletExportHandler(wallet modules.Wallet, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	height, err := wallet.Height()
+	if err != nil {
+		WriteError(w, Error{"failed to get wallet height: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	var start uint64
+	if startStr := req.FormValue("startheight"); startStr != "" {
+		start, err = strconv.ParseUint(startStr, 10, 64)
+		if err != nil {
+			WriteError(w, Error{"parsing integer value for parameter `startheight` failed: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	end := uint64(height)
+	if endStr := req.FormValue("endheight"); endStr != "" && endStr != "-1" {
+		end, err = strconv.ParseUint(endStr, 10, 64)
+		if err != nil {
+			WriteError(w, Error{"parsing integer value for parameter `endheight` failed: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	if end > uint64(height) {
+		end = uint64(height)
+	}
+	if start > end {
+		WriteError(w, Error{"startheight must not be greater than endheight or the wallet height"}, http.StatusBadRequest)
+		return
+	}
+	pts, err := wallet.Transactions(types.BlockHeight(start), types.BlockHeight(end))
+	if err != nil {
+		WriteError(w, Error{"failed to get transactions: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	txns := make([]modules.ExportTransaction, 0, len(pts))
+	for _, pt := range pts {
+		txns = append(txns, modules.NewExportTransaction(pt))
+	}
+
+	var buf bytes.Buffer
+	var contentType string
+	switch format := req.FormValue("format"); format {
+	case "", modules.ExportFormatJSON:
+		WriteJSON(w, WalletExportGET{
+			StartHeight:  types.BlockHeight(start),
+			EndHeight:    types.BlockHeight(end),
+			Transactions: txns,
+		})
+		return
+	case modules.ExportFormatCSV:
+		contentType = "text/csv; charset=utf-8"
+		err = WriteExportCSV(&buf, txns)
+	case modules.ExportFormatOFX:
+		contentType = "application/x-ofx"
+		var balance types.Currency
+		balance, _, _, err = wallet.ConfirmedBalance()
+		if err == nil {
+			err = WriteExportOFX(&buf, txns, balance, types.CurrentTimestamp())
+		}
+	default:
+		WriteError(w, Error{fmt.Sprintf("unknown format %q, must be one of %v, %v or %v", format, modules.ExportFormatJSON, modules.ExportFormatCSV, modules.ExportFormatOFX)}, http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		WriteError(w, Error{"failed to export transactions: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write(buf.Bytes())
+}
+
+// walletReportHandler handles API calls to /wallet/report.
+func walletReportHandler(wallet modules.Wallet, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	symbol := req.FormValue("symbol")
+	if symbol == "" {
+		WriteError(w, Error{"symbol must be provided to a /wallet/report call"}, http.StatusBadRequest)
+		return
+	}
+	var start types.Timestamp
+	if startStr := req.FormValue("start"); startStr != "" {
+		var err error
+		start, err = modules.ParseReportTime(startStr)
+		if err != nil {
+			WriteError(w, Error{"unable to parse start: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	end := types.CurrentTimestamp()
+	if endStr := req.FormValue("end"); endStr != "" {
+		var err error
+		end, err = modules.ParseReportTime(endStr)
+		if err != nil {
+			WriteError(w, Error{"unable to parse end: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	period := modules.ReportPeriodMonth
+	if periodStr := req.FormValue("period"); periodStr != "" {
+		period = modules.ReportPeriod(periodStr)
+	}
+	report, err := wallet.FiatReport(symbol, start, end, period)
+	if err != nil {
+		WriteError(w, Error{"failed to create report: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletReportGET{report})
+}
+
+// walletLabelsHandlerGET handles GET calls to /wallet/labels.
+func walletLabelsHandlerGET(wallet modules.Wallet, w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	addrLabels, err := wallet.AddressLabels()
+	if err != nil {
+		WriteError(w, Error{"failed to get address labels: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	txnLabels, err := wallet.TransactionLabels()
+	if err != nil {
+		WriteError(w, Error{"failed to get transaction labels: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletLabelsGET{
+		AddressLabels:     addrLabels,
+		TransactionLabels: txnLabels,
+	})
+}
+
+// walletLabelsHandlerPOST handles POST calls to /wallet/labels.
+func walletLabelsHandlerPOST(wallet modules.Wallet, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var wlp WalletLabelsPOST
+	err := json.NewDecoder(req.Body).Decode(&wlp)
+	if err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	for _, al := range wlp.AddressLabels {
+		if err := wallet.SetAddressLabel(al.Address, al.Name); err != nil {
+			WriteError(w, Error{"failed to set address label: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	for _, tl := range wlp.TransactionLabels {
+		if err := wallet.SetTransactionLabel(tl); err != nil {
+			WriteError(w, Error{"failed to set transaction label: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	WriteSuccess(w)
+}
+
 // walletWatchHandlerPOST handles POST calls to /wallet/watch.
 func walletWatchHandlerPOST(wallet modules.Wallet, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	var wwpp WalletWatchPOST
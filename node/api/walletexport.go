@@ -0,0 +1,192 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"time"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+const (
+	// ofxTimeFormat is the datetime format of OFX files.
+	ofxTimeFormat = "20060102150405"
+)
+
+type (
+	// ofxDocument is the root of an OFX 2.2 bank statement.
+	ofxDocument struct {
+		XMLName xml.Name `xml:"OFX"`
+		SignOn  struct {
+			Response struct {
+				Status   ofxStatus `xml:"STATUS"`
+				DTServer string    `xml:"DTSERVER"`
+				Language string    `xml:"LANGUAGE"`
+			} `xml:"SONRS"`
+		} `xml:"SIGNONMSGSRSV1"`
+		Bank struct {
+			Transaction struct {
+				TrnUID    string       `xml:"TRNUID"`
+				Status    ofxStatus    `xml:"STATUS"`
+				Statement ofxStatement `xml:"STMTRS"`
+			} `xml:"STMTTRNRS"`
+		} `xml:"BANKMSGSRSV1"`
+	}
+
+	// ofxStatus is the status aggregate of an OFX response.
+	ofxStatus struct {
+		Code     int    `xml:"CODE"`
+		Severity string `xml:"SEVERITY"`
+	}
+
+	// ofxStatement is the statement of an OFX bank account.
+	ofxStatement struct {
+		Currency string `xml:"CURDEF"`
+		Account  struct {
+			BankID   string `xml:"BANKID"`
+			AcctID   string `xml:"ACCTID"`
+			AcctType string `xml:"ACCTTYPE"`
+		} `xml:"BANKACCTFROM"`
+		TransactionList struct {
+			Start        string           `xml:"DTSTART"`
+			End          string           `xml:"DTEND"`
+			Transactions []ofxTransaction `xml:"STMTTRN"`
+		} `xml:"BANKTRANLIST"`
+		LedgerBalance struct {
+			Amount string `xml:"BALAMT"`
+			AsOf   string `xml:"DTASOF"`
+		} `xml:"LEDGERBAL"`
+	}
+
+	// ofxTransaction is a transaction of an OFX statement.
+	ofxTransaction struct {
+		Type   string `xml:"TRNTYPE"`
+		Posted string `xml:"DTPOSTED"`
+		Amount string `xml:"TRNAMT"`
+		FITID  string `xml:"FITID"`
+		Name   string `xml:"NAME"`
+		Memo   string `xml:"MEMO,omitempty"`
+	}
+)
+
+// siacoinString formats an amount of hastings as a decimal number of
+// siacoins without trailing zeros.
+func siacoinString(hastings *big.Int) string {
+	s := new(big.Rat).SetFrac(hastings, types.SiacoinPrecision.Big()).FloatString(24)
+	return strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+}
+
+// WriteExportCSV writes the transactions as CSV with a header row.
+func WriteExportCSV(w io.Writer, txns []modules.ExportTransaction) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write([]string{"height", "time", "transaction id", "siacoins in", "siacoins out", "miner fees", "net siacoins", "siafunds in", "siafunds out", "addresses", "file contracts"})
+	if err != nil {
+		return err
+	}
+	for _, et := range txns {
+		var addrs []string
+		seen := make(map[types.UnlockHash]struct{})
+		addAddr := func(addr types.UnlockHash) {
+			if _, exists := seen[addr]; !exists {
+				seen[addr] = struct{}{}
+				addrs = append(addrs, addr.String())
+			}
+		}
+		for _, input := range et.Inputs {
+			addAddr(input.RelatedAddress)
+		}
+		for _, output := range et.Outputs {
+			addAddr(output.RelatedAddress)
+		}
+		contracts := make([]string, 0, len(et.FileContractIDs))
+		for _, id := range et.FileContractIDs {
+			contracts = append(contracts, id.String())
+		}
+		err = cw.Write([]string{
+			fmt.Sprint(et.ConfirmationHeight),
+			time.Unix(int64(et.ConfirmationTimestamp), 0).UTC().Format(time.RFC3339),
+			et.TransactionID.String(),
+			et.SiacoinsIn.String(),
+			et.SiacoinsOut.String(),
+			et.MinerFees.String(),
+			et.NetSiacoins,
+			et.SiafundsIn.String(),
+			et.SiafundsOut.String(),
+			strings.Join(addrs, ";"),
+			strings.Join(contracts, ";"),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteExportOFX writes the transactions as an OFX 2.2 bank statement of the
+// wallet. The statement covers the confirmation times of the transactions and
+// its ledger balance is the confirmed siacoin balance at balanceTime.
+func WriteExportOFX(w io.Writer, txns []modules.ExportTransaction, balance types.Currency, balanceTime types.Timestamp) error {
+	ofxTime := func(ts types.Timestamp) string {
+		return time.Unix(int64(ts), 0).UTC().Format(ofxTimeFormat)
+	}
+	var doc ofxDocument
+	doc.SignOn.Response.Status = ofxStatus{Code: 0, Severity: "INFO"}
+	doc.SignOn.Response.DTServer = ofxTime(balanceTime)
+	doc.SignOn.Response.Language = "ENG"
+	doc.Bank.Transaction.TrnUID = "0"
+	doc.Bank.Transaction.Status = ofxStatus{Code: 0, Severity: "INFO"}
+
+	stmt := &doc.Bank.Transaction.Statement
+	stmt.Currency = "XSC"
+	stmt.Account.BankID = "SIA"
+	stmt.Account.AcctID = "wallet"
+	stmt.Account.AcctType = "CHECKING"
+	stmt.TransactionList.Start = ofxTime(balanceTime)
+	stmt.TransactionList.End = ofxTime(balanceTime)
+	if len(txns) > 0 {
+		stmt.TransactionList.Start = ofxTime(txns[0].ConfirmationTimestamp)
+		stmt.TransactionList.End = ofxTime(txns[len(txns)-1].ConfirmationTimestamp)
+	}
+	for _, et := range txns {
+		net, _ := new(big.Int).SetString(et.NetSiacoins, 10)
+		trnType := "CREDIT"
+		if net.Sign() < 0 {
+			trnType = "DEBIT"
+		}
+		var memo []string
+		if !et.MinerFees.IsZero() {
+			memo = append(memo, fmt.Sprintf("miner fees %v SC", siacoinString(et.MinerFees.Big())))
+		}
+		if len(et.FileContractIDs) > 0 {
+			memo = append(memo, fmt.Sprintf("%v file contracts", len(et.FileContractIDs)))
+		}
+		stmt.TransactionList.Transactions = append(stmt.TransactionList.Transactions, ofxTransaction{
+			Type:   trnType,
+			Posted: ofxTime(et.ConfirmationTimestamp),
+			Amount: siacoinString(net),
+			FITID:  et.TransactionID.String(),
+			Name:   fmt.Sprintf("Sia transaction at height %v", et.ConfirmationHeight),
+			Memo:   strings.Join(memo, ", "),
+		})
+	}
+	stmt.LedgerBalance.Amount = siacoinString(balance.Big())
+	stmt.LedgerBalance.AsOf = ofxTime(balanceTime)
+
+	_, err := io.WriteString(w, xml.Header+`<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>`+"\n")
+	if err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
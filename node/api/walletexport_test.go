@@ -0,0 +1,75 @@
+package api
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// TestWriteExport tests writing exported transactions as CSV and OFX.
+func TestWriteExport(t *testing.T) {
+	ours, theirs := types.UnlockHash{1}, types.UnlockHash{2}
+	txn := types.Transaction{
+		FileContracts: []types.FileContract{{}},
+	}
+	et := modules.NewExportTransaction(modules.ProcessedTransaction{
+		Transaction:           txn,
+		TransactionID:         txn.ID(),
+		ConfirmationHeight:    10,
+		ConfirmationTimestamp: 1614600000,
+		Inputs: []modules.ProcessedInput{
+			{FundType: types.SpecifierSiacoinInput, WalletAddress: true, RelatedAddress: ours, Value: types.SiacoinPrecision.Mul64(10)},
+		},
+		Outputs: []modules.ProcessedOutput{
+			{FundType: types.SpecifierSiacoinOutput, WalletAddress: true, RelatedAddress: ours, Value: types.SiacoinPrecision.Mul64(3)},
+			{FundType: types.SpecifierSiacoinOutput, WalletAddress: false, RelatedAddress: theirs, Value: types.SiacoinPrecision.Mul64(6)},
+			{FundType: types.SpecifierMinerFee, Value: types.SiacoinPrecision},
+		},
+	})
+	reward := modules.NewExportTransaction(modules.ProcessedTransaction{
+		Outputs: []modules.ProcessedOutput{
+			{FundType: types.SpecifierMinerPayout, WalletAddress: true, RelatedAddress: ours, Value: types.SiacoinPrecision},
+		},
+	})
+
+	// CSV
+	var buf bytes.Buffer
+	if err := WriteExportCSV(&buf, []modules.ExportTransaction{et, reward}); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 || records[1][0] != "10" || records[1][1] != "2021-03-01T12:00:00Z" || records[1][6] != et.NetSiacoins || records[1][9] != ours.String() {
+		t.Fatal("wrong csv", records)
+	}
+
+	// OFX
+	buf.Reset()
+	if err := WriteExportOFX(&buf, []modules.ExportTransaction{et, reward}, types.SiacoinPrecision.Mul64(42), 1614700000); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `<?OFX OFXHEADER="200" VERSION="220"`) {
+		t.Fatal("missing OFX header", buf.String())
+	}
+	var doc ofxDocument
+	if err := xml.NewDecoder(&buf).Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	stmt := doc.Bank.Transaction.Statement
+	if stmt.LedgerBalance.Amount != "42" || len(stmt.TransactionList.Transactions) != 2 {
+		t.Fatal("wrong statement", stmt)
+	}
+	if trn := stmt.TransactionList.Transactions[0]; trn.Type != "DEBIT" || trn.Amount != "-7" || trn.Posted != "20210301120000" || trn.FITID != et.TransactionID.String() || !strings.Contains(trn.Memo, "miner fees 1 SC") {
+		t.Fatal("wrong transaction", trn)
+	}
+	if trn := stmt.TransactionList.Transactions[1]; trn.Type != "CREDIT" || trn.Amount != "1" {
+		t.Fatal("wrong transaction", trn)
+	}
+}
@@ -107,6 +107,9 @@ type NodeParams struct {
 	HostStorage uint64
 	RPCAddress  string
 
+	// EnableMetrics enables the /metrics endpoint of the API server.
+	EnableMetrics bool
+
 	// Initialize node from existing seed.
 	PrimarySeed string
 
@@ -0,0 +1,62 @@
+package accounting
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"go.sia.tech/siad/node"
+	"go.sia.tech/siad/siatest"
+)
+
+// TestAccountingEndpoint probes the /accounting endpoint.
+func TestAccountingEndpoint(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	// Create a node with the accounting module.
+	n, err := siatest.NewNode(node.Accounting(accountingTestDir(t.Name())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := n.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// The full range should contain at least the current accounting
+	// information as the last entry.
+	start := time.Now().Unix()
+	ag, err := n.AccountingGet(0, math.MaxInt64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ag) == 0 {
+		t.Fatal("expected at least one accounting entry")
+	}
+	last := ag[len(ag)-1]
+	if last.Timestamp < start {
+		t.Fatalf("last entry should be the current accounting information, got timestamp %v < %v", last.Timestamp, start)
+	}
+	if last.Wallet.ConfirmedSiacoinBalance.IsZero() {
+		t.Error("expected a non zero wallet balance")
+	}
+
+	// A range in the past should be empty.
+	ag, err = n.AccountingGet(0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ag) != 0 {
+		t.Fatalf("expected no entries, got %v", len(ag))
+	}
+
+	// An invalid range should return an error.
+	_, err = n.AccountingGet(1, 0)
+	if err == nil {
+		t.Fatal("expected an error for an invalid range")
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"gitlab.com/NebulousLabs/encoding"
+	"gitlab.com/NebulousLabs/fastrand"
 	"go.sia.tech/siad/build"
 	"go.sia.tech/siad/crypto"
 	"go.sia.tech/siad/modules"
@@ -293,6 +294,96 @@ func TestConsensusSubscribe(t *testing.T) {
 	}
 }
 
+// TestConsensusStream tests the /consensus/stream/:id endpoint.
+func TestConsensusStream(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	// Create a testgroup
+	groupParams := siatest.GroupParams{
+		Miners: 1,
+	}
+	tg, err := siatest.NewGroupFromTemplate(consensusTestDir(t.Name()), groupParams)
+	if err != nil {
+		t.Fatal("Failed to create group: ", err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	testNode := tg.Miners()[0]
+
+	// An invalid change id should return an error event.
+	var invalidID modules.ConsensusChangeID
+	fastrand.Read(invalidID[:])
+	stream, err := testNode.ConsensusStream(invalidID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = stream.Next()
+	if err == nil || !strings.Contains(err.Error(), modules.ErrInvalidConsensusChangeID.Error()) {
+		t.Fatal("expected invalid consensus change id error, got", err)
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Stream from the beginning. The stream's buffer is smaller than the
+	// number of existing changes which forces it to resubscribe while
+	// catching up.
+	stream, err = testNode.ConsensusStream(modules.ConsensusChangeBeginning)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := stream.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Mine a few more blocks while the stream is open. They should be
+	// delivered after the existing ones without gaps.
+	for i := 0; i < 5; i++ {
+		if err := testNode.MineBlock(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cg, err := testNode.ConsensusGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Read the changes slowly and verify that the blocks are applied in order.
+	var height types.BlockHeight
+	var parentID types.BlockID
+	ids := make(map[crypto.Hash]struct{})
+	for height <= cg.Height {
+		time.Sleep(10 * time.Millisecond)
+		cce, err := stream.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, exists := ids[cce.ID]; exists {
+			t.Fatal("received change twice", cce.ID)
+		}
+		ids[cce.ID] = struct{}{}
+		if len(cce.RevertedBlocks) != 0 {
+			t.Fatal("unexpected reverted blocks")
+		}
+		for _, b := range cce.AppliedBlocks {
+			if height != 0 && b.ParentID != parentID {
+				t.Fatalf("block at height %v doesn't extend the previous block", height)
+			}
+			parentID = b.ID()
+			height++
+		}
+	}
+	if parentID != cg.CurrentBlock {
+		t.Fatal("stream didn't end at the current block", parentID, cg.CurrentBlock)
+	}
+}
+
 // TestFoundationHardfork tests the foundation hardfork, ensuring that upgraded
 // nodes have the ability to follow the hardfork, and ensuring that the
 // mechanisms for spending the foundation coins are functional.
@@ -2,8 +2,12 @@ package daemon
 
 import (
 	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -13,10 +17,13 @@ import (
 	"go.sia.tech/siad/build"
 	"go.sia.tech/siad/crypto"
 	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/modules/gateway"
 	"go.sia.tech/siad/node"
+	"go.sia.tech/siad/node/api"
 	"go.sia.tech/siad/node/api/client"
 	"go.sia.tech/siad/profile"
 	"go.sia.tech/siad/siatest"
+	"go.sia.tech/siad/siatest/dependencies"
 )
 
 // TestDaemonAPIPassword makes sure that the daemon rejects requests with the
@@ -222,8 +229,8 @@ func TestAlertFields(t *testing.T) {
 	}
 
 	// Check alerts field
-	if len(dag.Alerts) != 12 {
-		t.Fatal("number of alerts is not 12")
+	if len(dag.Alerts) != 15 {
+		t.Fatal("number of alerts is not 15")
 	}
 
 	// Check criticalalerts field severity and total count
@@ -232,8 +239,8 @@ func TestAlertFields(t *testing.T) {
 			t.Fatal("criticalalerts field contains alert which has not critical severity")
 		}
 	}
-	if len(dag.CriticalAlerts) != 4 {
-		t.Fatal("number of critical alerts is not 4")
+	if len(dag.CriticalAlerts) != 5 {
+		t.Fatal("number of critical alerts is not 5")
 	}
 
 	// Check erroralerts field severity and total count
@@ -242,8 +249,8 @@ func TestAlertFields(t *testing.T) {
 			t.Fatal("erroralerts field contains alert which has not error severity")
 		}
 	}
-	if len(dag.ErrorAlerts) != 4 {
-		t.Fatal("number of error alerts is not 4")
+	if len(dag.ErrorAlerts) != 5 {
+		t.Fatal("number of error alerts is not 5")
 	}
 
 	// Check warningalerts field severity and total count
@@ -252,8 +259,8 @@ func TestAlertFields(t *testing.T) {
 			t.Fatal("warningalerts field contains alert which has not warning severity")
 		}
 	}
-	if len(dag.WarningAlerts) != 4 {
-		t.Fatal("number of warning alerts is not 4")
+	if len(dag.WarningAlerts) != 5 {
+		t.Fatal("number of warning alerts is not 5")
 	}
 }
 
@@ -437,3 +444,192 @@ func TestDaemonProfile(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// TestDaemonMetrics tests the /metrics endpoint.
+func TestDaemonMetrics(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	testDir := daemonTestDir(t.Name())
+
+	// The endpoint should be disabled by default.
+	gateway, err := siatest.NewCleanNode(node.Gateway(filepath.Join(testDir, "gateway")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := gateway.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	if _, err := gateway.MetricsGet(); err == nil {
+		t.Fatal("expected metrics to be disabled by default")
+	}
+
+	// Create a host with metrics enabled.
+	params := node.Host(filepath.Join(testDir, "host"))
+	params.EnableMetrics = true
+	host, err := siatest.NewCleanNode(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := host.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Requests without the API password should fail.
+	opts, err := client.DefaultOptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.Address = host.Server.APIAddress()
+	if _, err := client.New(opts).MetricsGet(); err == nil {
+		t.Fatal("expected unauthenticated request to fail")
+	}
+
+	// The metrics should contain the loaded modules only.
+	metrics, err := host.MetricsGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cg, err := host.ConsensusGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"# TYPE sia_consensus_height gauge",
+		"sia_consensus_synced 1",
+		"sia_gateway_peers{direction=\"inbound\"} 0",
+		"sia_wallet_unlocked 1",
+		"sia_host_contracts 0",
+		"sia_alerts{severity=\"critical\"}",
+	}
+	for _, e := range expected {
+		if !strings.Contains(metrics, e) {
+			t.Errorf("metrics don't contain %q:\n%v", e, metrics)
+		}
+	}
+	if !strings.Contains(metrics, "sia_consensus_height "+strconv.FormatUint(uint64(cg.Height), 10)) {
+		t.Errorf("metrics contain wrong height, expected %v:\n%v", cg.Height, metrics)
+	}
+	if strings.Contains(metrics, "sia_renter_") {
+		t.Error("metrics shouldn't contain renter metrics")
+	}
+}
+
+// TestDaemonAlertsStream tests the /daemon/alerts/stream endpoint and the alert
+// webhooks.
+func TestDaemonAlertsStream(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	testDir := daemonTestDir(t.Name())
+
+	// Create a gateway which registers an alert when it's offline.
+	params := node.Gateway(testDir)
+	params.GatewayDeps = &dependencies.DependencyDisableAutoOnline{}
+	testNode, err := siatest.NewCleanNode(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := testNode.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Invalid webhooks should be rejected.
+	if err := testNode.DaemonAlertWebhookAddPost("ftp://example.com"); err == nil {
+		t.Fatal("expected invalid webhook to be rejected")
+	}
+
+	// Add a webhook.
+	received := make(chan api.DaemonAlertEvent, 100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var event api.DaemonAlertEvent
+		if err := json.NewDecoder(req.Body).Decode(&event); err != nil {
+			t.Error(err)
+		}
+		received <- event
+	}))
+	defer server.Close()
+	if err := testNode.DaemonAlertWebhookAddPost(server.URL); err != nil {
+		t.Fatal(err)
+	}
+	if err := testNode.DaemonAlertWebhookAddPost(server.URL); err == nil {
+		t.Fatal("expected duplicate webhook to be rejected")
+	}
+
+	// Subscribe to the stream.
+	stream, err := testNode.DaemonAlertsStream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := stream.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	streamed := make(chan api.DaemonAlertEvent, 100)
+	go func() {
+		for {
+			event, err := stream.Next()
+			if err != nil {
+				close(streamed)
+				return
+			}
+			streamed <- event
+		}
+	}()
+
+	// Querying the gateway registers the offline alert.
+	if _, err := testNode.GatewayGet(); err != nil {
+		t.Fatal(err)
+	}
+	isOfflineAlert := func(event api.DaemonAlertEvent) bool {
+		return event.Event == api.AlertEventRegistered && event.Module == "gateway" &&
+			event.Msg == gateway.AlertMSGGatewayOffline && event.Severity == modules.SeverityWarning
+	}
+	for name, c := range map[string]chan api.DaemonAlertEvent{"stream": streamed, "webhook": received} {
+		timeout := time.After(10 * time.Second)
+	LOOP:
+		for {
+			select {
+			case event := <-c:
+				if isOfflineAlert(event) {
+					break LOOP
+				}
+			case <-timeout:
+				t.Fatalf("%v didn't receive offline alert", name)
+			}
+		}
+	}
+
+	// The webhook should be persisted.
+	if err := testNode.RestartNode(); err != nil {
+		t.Fatal(err)
+	}
+	dawg, err := testNode.DaemonAlertWebhooksGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dawg.Webhooks) != 1 || dawg.Webhooks[0] != server.URL {
+		t.Fatal("webhook wasn't persisted", dawg.Webhooks)
+	}
+
+	// Remove it again.
+	if err := testNode.DaemonAlertWebhookRemovePost(server.URL); err != nil {
+		t.Fatal(err)
+	}
+	dawg, err = testNode.DaemonAlertWebhooksGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dawg.Webhooks) != 0 {
+		t.Fatal("webhook wasn't removed", dawg.Webhooks)
+	}
+}
@@ -3,12 +3,16 @@ package host
 import (
 	"bytes"
 	"errors"
+	"fmt"
+	"os"
 	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
 	"time"
 
+	"gitlab.com/NebulousLabs/fastrand"
+
 	"go.sia.tech/siad/build"
 	"go.sia.tech/siad/modules"
 	"go.sia.tech/siad/modules/host"
@@ -698,3 +702,534 @@ func TestHostGetPriceTable(t *testing.T) {
 		t.Fatal("wrong subscription notification cost")
 	}
 }
+
+// TestHostStorageScrub verifies that the sector scrubber finds sectors which
+// were corrupted on disk and reports the affected storage obligations.
+func TestHostStorageScrub(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	gp := siatest.GroupParams{
+		Hosts:   1,
+		Renters: 1,
+		Miners:  1,
+	}
+	tg, err := siatest.NewGroupFromTemplate(hostTestDir(t.Name()), gp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	hostNode := tg.Hosts()[0]
+	renterNode := tg.Renters()[0]
+
+	// The scrubber should be enabled by default and the new storage folder
+	// shouldn't contain corrupt sectors.
+	ssg, err := hostNode.HostStorageScrubGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ssg.Enabled || ssg.RateLimit == 0 || len(ssg.Folders) != 1 || len(ssg.CorruptSectors) != 0 {
+		t.Fatal("unexpected scrub status", ssg)
+	}
+
+	// Upload a file. It will only reach 50% progress.
+	_, rf, err := renterNode.UploadNewFile(4096, 1, 1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = renterNode.WaitForUploadProgress(rf, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hc, err := hostNode.HostContractInfoGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hc.Contracts) != 1 {
+		t.Fatal("expected 1 contract, got", len(hc.Contracts))
+	}
+	fcid := hc.Contracts[0].ObligationId
+
+	// Overwrite the sectors of the storage folder on disk.
+	f, err := os.OpenFile(filepath.Join(ssg.Folders[0].Path, "siahostdata.dat"), os.O_RDWR, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteAt(fastrand.Bytes(int(fi.Size())), 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Scrubbing shouldn't be possible while the scrubber is disabled.
+	if err := hostNode.HostStorageScrubPost(false, ssg.RateLimit); err != nil {
+		t.Fatal(err)
+	}
+	if err := hostNode.HostStorageScrubStartPost(); err == nil {
+		t.Fatal("scrub was started while the scrubber is disabled")
+	}
+	if err := hostNode.HostStorageScrubPost(true, ssg.RateLimit); err != nil {
+		t.Fatal(err)
+	}
+
+	// Scrub the storage folder and wait for the corrupt sector.
+	if err := hostNode.HostStorageScrubStartPost(); err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		ssg, err = hostNode.HostStorageScrubGet()
+		if err != nil {
+			return err
+		}
+		if len(ssg.CorruptSectors) != 1 {
+			return fmt.Errorf("expected 1 corrupt sector, got %v", len(ssg.CorruptSectors))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := ssg.CorruptSectors[0]
+	if len(cs.Obligations) != 1 || cs.Obligations[0] != fcid {
+		t.Fatal("wrong obligations", cs.Obligations)
+	}
+	if ssg.Folders[0].CorruptSectors != 1 {
+		t.Fatal("expected 1 corrupt sector in the folder, got", ssg.Folders[0].CorruptSectors)
+	}
+
+	// The host should register an alert listing the obligation.
+	alert := modules.Alert{
+		Cause:    fmt.Sprintf("1 corrupt sectors affect 1 storage obligations: %v", fcid),
+		Module:   "host",
+		Msg:      host.AlertMSGHostCorruptSectors,
+		Severity: modules.SeverityCritical,
+	}
+	err = build.Retry(10, time.Second, func() error {
+		return hostNode.IsAlertRegistered(alert)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestHostStorageMigration tests migrating sectors between storage folders
+// through the API.
+func TestHostStorageMigration(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	gp := siatest.GroupParams{
+		Hosts:   1,
+		Renters: 1,
+		Miners:  1,
+	}
+	tg, err := siatest.NewGroupFromTemplate(hostTestDir(t.Name()), gp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	hostNode := tg.Hosts()[0]
+	renterNode := tg.Renters()[0]
+
+	// Rebalancing should be disabled by default.
+	sms, err := hostNode.HostStorageMigrationGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sms.Rebalance || sms.RateLimit == 0 || len(sms.Migrations) != 0 {
+		t.Fatal("unexpected migration status", sms)
+	}
+
+	// Upload a file. It will only reach 50% progress.
+	_, rf, err := renterNode.UploadNewFile(4096, 1, 1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = renterNode.WaitForUploadProgress(rf, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Add a second storage folder.
+	sg, err := hostNode.HostStorageGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sg.Folders) != 1 {
+		t.Fatal("expected 1 storage folder, got", len(sg.Folders))
+	}
+	from := sg.Folders[0]
+	to := filepath.Join(hostNode.Dir, "folder2")
+	if err := os.MkdirAll(to, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := hostNode.HostStorageFoldersAddPost(to, from.Capacity); err != nil {
+		t.Fatal(err)
+	}
+
+	// Migrating to the same folder should fail.
+	if err := hostNode.HostStorageFoldersMigratePost(from.Path, from.Path, 0); err == nil {
+		t.Fatal("migrating to the same folder should fail")
+	}
+
+	// Migrate all sectors to the new folder.
+	if err := hostNode.HostStorageFoldersMigratePost(from.Path, to, 0); err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		sms, err := hostNode.HostStorageMigrationGet()
+		if err != nil {
+			return err
+		}
+		if len(sms.Migrations) != 0 {
+			return errors.New("migration hasn't finished yet")
+		}
+		sg, err := hostNode.HostStorageGet()
+		if err != nil {
+			return err
+		}
+		for _, sf := range sg.Folders {
+			if sf.Path == from.Path && sf.CapacityRemaining != sf.Capacity {
+				return errors.New("source folder isn't empty")
+			}
+			if sf.Path == to && sf.CapacityRemaining == sf.Capacity {
+				return errors.New("destination folder is empty")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The file should still be downloadable.
+	if _, _, err := renterNode.DownloadByStream(rf); err != nil {
+		t.Fatal(err)
+	}
+
+	// Update the settings of the migrator.
+	if err := hostNode.HostStorageMigrationPost(true, 1<<20); err != nil {
+		t.Fatal(err)
+	}
+	if err := hostNode.HostStorageMigrationCancelPost(); err != nil {
+		t.Fatal(err)
+	}
+	sms, err = hostNode.HostStorageMigrationGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sms.Rebalance || sms.RateLimit != 1<<20 {
+		t.Fatal("migration settings weren't updated", sms)
+	}
+}
+
+// TestHostRenterFilter checks that the host's renter filter can be set through
+// the API, that filtered renters can't download from or form contracts with the
+// host and that whitelisted renters can renew their contracts.
+func TestHostRenterFilter(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	gp := siatest.GroupParams{
+		Hosts:   1,
+		Renters: 1,
+		Miners:  1,
+	}
+	tg, err := siatest.NewGroupFromTemplate(hostTestDir(t.Name()), gp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	hostNode := tg.Hosts()[0]
+	renterNode := tg.Renters()[0]
+
+	// The renter filter should be disabled by default.
+	hrfg, err := hostNode.HostRenterFilterGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hrfg.FilterMode != modules.HostDBDisableFilter.String() || len(hrfg.Renters) != 0 {
+		t.Fatal("unexpected renter filter", hrfg)
+	}
+
+	// Upload a file. It will only reach 50% progress.
+	_, rf, err := renterNode.UploadNewFile(4096, 1, 1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = renterNode.WaitForUploadProgress(rf, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The host should report the public key of the renter.
+	cig, err := hostNode.HostContractInfoGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cig.Contracts) != 1 || cig.Contracts[0].RenterPublicKey.Key == nil {
+		t.Fatal("expected a contract with a renter public key", cig.Contracts)
+	}
+	renterPK := cig.Contracts[0].RenterPublicKey
+
+	// Enabling the whitelist without renters should fail.
+	if err := hostNode.HostRenterFilterPost(modules.HostDBActiveWhitelist, nil); err == nil {
+		t.Fatal("enabling the whitelist without renters should fail")
+	}
+
+	// Blacklist the renter. Downloads should fail.
+	if err := hostNode.HostRenterFilterPost(modules.HostDBActivateBlacklist, []types.SiaPublicKey{renterPK}); err != nil {
+		t.Fatal(err)
+	}
+	hrfg, err = hostNode.HostRenterFilterGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hrfg.FilterMode != modules.HostDBActivateBlacklist.String() || len(hrfg.Renters) != 1 || hrfg.Renters[0] != renterPK.String() {
+		t.Fatal("renter filter wasn't updated", hrfg)
+	}
+	if _, _, err := renterNode.DownloadByStream(rf); err == nil {
+		t.Fatal("blacklisted renter shouldn't be able to download")
+	}
+
+	// Whitelist the renter. Downloads should succeed again.
+	if err := hostNode.HostRenterFilterPost(modules.HostDBActiveWhitelist, []types.SiaPublicKey{renterPK}); err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(60, time.Second, func() error {
+		_, _, err := renterNode.DownloadByStream(rf)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A new renter derives a new key for its contract, so it shouldn't be able
+	// to form a contract while the whitelist is active.
+	renterParams := node.RenterTemplate
+	renterParams.SkipSetAllowance = true
+	nodes, err := tg.AddNodes(renterParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newRenter := nodes[0]
+	if err := newRenter.RenterPostAllowance(siatest.DefaultAllowance); err != nil {
+		t.Fatal(err)
+	}
+	if err := tg.Miners()[0].MineBlock(); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Second)
+	rc, err := newRenter.RenterContractsGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rc.ActiveContracts) != 0 {
+		t.Fatal("renter which isn't whitelisted formed a contract", rc.ActiveContracts)
+	}
+
+	// Renew the contract of the whitelisted renter. The renewal is checked
+	// against the key of the old contract and the key of the new contract
+	// should be added to the whitelist.
+	if err := siatest.RenewContractsByRenewWindow(renterNode, tg); err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(60, time.Second, func() error {
+		hrfg, err := hostNode.HostRenterFilterGet()
+		if err != nil {
+			return err
+		}
+		if len(hrfg.Renters) != 2 {
+			return fmt.Errorf("expected 2 whitelisted renters, got %v", len(hrfg.Renters))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The renter should still be able to download using the renewed contract.
+	err = build.Retry(60, time.Second, func() error {
+		_, _, err := renterNode.DownloadByStream(rf)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestHostPricingPolicy checks that the host's pricing policy can be set
+// through the API and that it adjusts the host's prices.
+func TestHostPricingPolicy(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	gp := siatest.GroupParams{
+		Hosts:  1,
+		Miners: 1,
+	}
+	tg, err := siatest.NewGroupFromTemplate(hostTestDir(t.Name()), gp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	hostNode := tg.Hosts()[0]
+
+	// The pricing policy should be disabled by default.
+	hps, err := hostNode.HostPricingGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hps.Policy.Enabled || hps.Policy.ExchangeRate != nil {
+		t.Fatal("unexpected pricing policy", hps.Policy)
+	}
+
+	// Enabling the policy without an exchange rate should fail.
+	policy := hps.Policy
+	policy.Enabled = true
+	if err := hostNode.HostPricingPost(policy); err == nil {
+		t.Fatal("enabling the pricing policy without an exchange rate should fail")
+	}
+
+	// Enable the policy with a storage price target of 1 USD/TB/month at 0.01
+	// USD/SC.
+	policy.ExchangeRate, err = types.ParseExchangeRate("0.01USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy.Deviation = 0
+	policy.Smoothing = 1
+	policy.StoragePrice.Target = 1
+	if err := hostNode.HostPricingPost(policy); err != nil {
+		t.Fatal(err)
+	}
+	expected := types.SiacoinPrecision.Mul64(100).Div(modules.BlockBytesPerMonthTerabyte)
+	hg, err := hostNode.HostGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hg.InternalSettings.MinStoragePrice.Equals(expected) || !hg.ExternalSettings.StoragePrice.Equals(expected) {
+		t.Fatal("wrong storage price", hg.InternalSettings.MinStoragePrice, hg.ExternalSettings.StoragePrice)
+	}
+
+	// The policy should be returned by the API.
+	hps, err = hostNode.HostPricingGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hps.Policy.Enabled || hps.Policy.ExchangeRate.String() != policy.ExchangeRate.String() || hps.Policy.StoragePrice.Target != 1 || hps.LastAdjustment.IsZero() {
+		t.Fatal("unexpected pricing status", hps)
+	}
+}
+
+// TestHostDrain checks that the host's drain mode can be set through the API
+// and that it is advertised in the host's external settings.
+func TestHostDrain(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	gp := siatest.GroupParams{
+		Hosts:  1,
+		Miners: 1,
+	}
+	tg, err := siatest.NewGroupFromTemplate(hostTestDir(t.Name()), gp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	hostNode := tg.Hosts()[0]
+
+	// The host shouldn't be draining by default.
+	hds, err := hostNode.HostDrainGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hds.Draining || hds.Drained {
+		t.Fatal("unexpected drain status", hds)
+	}
+	hg, err := hostNode.HostGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hg.ExternalSettings.AcceptingContracts || hg.ExternalSettings.Draining {
+		t.Fatal("unexpected external settings", hg.ExternalSettings)
+	}
+
+	// Start draining.
+	if err := hostNode.HostDrainPost(true, true); err != nil {
+		t.Fatal(err)
+	}
+	hds, err = hostNode.HostDrainGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hds.Draining || !hds.RejectDeposits || !hds.Drained {
+		t.Fatal("unexpected drain status", hds)
+	}
+	hg, err = hostNode.HostGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hg.ExternalSettings.AcceptingContracts || !hg.ExternalSettings.Draining || !hg.PriceTable.Draining {
+		t.Fatal("host should advertise the drain mode", hg.ExternalSettings, hg.PriceTable)
+	}
+
+	// The drain mode should survive a restart.
+	if err := hostNode.RestartNode(); err != nil {
+		t.Fatal(err)
+	}
+	hds, err = hostNode.HostDrainGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hds.Draining || !hds.RejectDeposits {
+		t.Fatal("drain mode wasn't persisted", hds)
+	}
+
+	// Stop draining.
+	if err := hostNode.HostDrainPost(false, false); err != nil {
+		t.Fatal(err)
+	}
+	hg, err = hostNode.HostGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hg.ExternalSettings.AcceptingContracts || hg.ExternalSettings.Draining {
+		t.Fatal("unexpected external settings", hg.ExternalSettings)
+	}
+}
@@ -138,8 +138,8 @@ func testContractorIncompleteMaintenanceAlert(t *testing.T, tg *siatest.TestGrou
 	if err != nil {
 		t.Fatal(err)
 	}
-	if len(dag.Alerts) != 12 {
-		t.Fatal("number of alerts is not 12")
+	if len(dag.Alerts) != 15 {
+		t.Fatal("number of alerts is not 15")
 	}
 	// Save the seed for later.
 	wsg, err := r.WalletSeedsGet()
@@ -2259,7 +2259,7 @@ func TestFailedContractRenewalAlert(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	if len(dag.Alerts) != 12 {
+	if len(dag.Alerts) != 15 {
 		t.Fatal("number of alerts is not 0", len(dag.Alerts))
 	}
 
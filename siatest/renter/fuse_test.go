@@ -17,6 +17,7 @@ import (
 
 	"go.sia.tech/siad/build"
 	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/modules/renter/filesystem"
 	"go.sia.tech/siad/persist"
 	"go.sia.tech/siad/siatest"
 
@@ -732,9 +733,153 @@ func TestFuse(t *testing.T) {
 		t.Fatal("should not be able to make a directory in a read-only fuse system")
 	}
 
-	// TODO: When read-write fuse is supported, switch over to read-write mode
-	// here and begin testing the write features. Extend the concurrency test to
-	// probe write features as well, probably by adding more phases.
+	// Mount a writable fuse system and test the write features.
+	writableMount := filepath.Join(testDir, "writableMount")
+	err = os.MkdirAll(writableMount, persist.DefaultDiskPermissionsTest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writableDir := modules.RandomSiaPath()
+	err = r.RenterDirCreatePost(writableDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writableOpts := modules.MountOptions{
+		ReadOnly:   false,
+		AllowOther: false,
+	}
+	err = r.RenterFuseMount(writableMount, writableDir, writableOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// checkWritableFile checks that a file written through the writable mount
+	// has the expected contents on the Sia network. Files are uploaded in the
+	// background after they are closed, so this waits for the upload.
+	checkWritableFile := func(siaPath modules.SiaPath, expected []byte) {
+		t.Helper()
+		rf, err := r.RenterFileGet(siaPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rf.File.Filesize != uint64(len(expected)) {
+			t.Fatalf("expected size %v but got %v", len(expected), rf.File.Filesize)
+		}
+		if len(expected) == 0 {
+			return
+		}
+		err = build.Retry(100, 100*time.Millisecond, func() error {
+			rf, err := r.RenterFileGet(siaPath)
+			if err != nil {
+				return err
+			}
+			if !rf.File.Available {
+				return errors.New("file isn't available yet")
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, data, err := r.RenterDownloadHTTPResponseGet(siaPath, 0, uint64(len(expected)), true, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, expected) {
+			t.Fatal("data written through fuse doesn't match")
+		}
+	}
+
+	// Create a directory and a file within it.
+	err = os.Mkdir(filepath.Join(writableMount, "dir"), persist.DefaultDiskPermissionsTest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePath := filepath.Join(writableMount, "dir", "file")
+	writeData = fastrand.Bytes(int(modules.SectorSize) + siatest.Fuzz())
+	err = ioutil.WriteFile(writePath, writeData, persist.DefaultDiskPermissionsTest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeSiaPath, err := writableDir.Join("dir/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkWritableFile(writeSiaPath, writeData)
+	readData, err := ioutil.ReadFile(writePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(readData, writeData) {
+		t.Fatal("data read from fuse doesn't match data written to fuse")
+	}
+
+	// Append to the file.
+	writeFile, err := os.OpenFile(writePath, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendData := fastrand.Bytes(100)
+	_, err = writeFile.Write(appendData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = writeFile.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeData = append(writeData, appendData...)
+	checkWritableFile(writeSiaPath, writeData)
+
+	// Truncate the file.
+	err = os.Truncate(writePath, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeData = writeData[:50]
+	checkWritableFile(writeSiaPath, writeData)
+
+	// Rename the file, replacing an existing file.
+	existingPath := filepath.Join(writableMount, "existing")
+	err = ioutil.WriteFile(existingPath, fastrand.Bytes(10), persist.DefaultDiskPermissionsTest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Rename(writePath, existingPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	existingSiaPath, err := writableDir.Join("existing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkWritableFile(existingSiaPath, writeData)
+	_, err = r.RenterFileGet(writeSiaPath)
+	if err == nil || !strings.Contains(err.Error(), filesystem.ErrNotExist.Error()) {
+		t.Fatal("file should have been renamed", err)
+	}
+
+	// Delete the file.
+	err = os.Remove(existingPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = r.RenterFileGet(existingSiaPath)
+	if err == nil || !strings.Contains(err.Error(), filesystem.ErrNotExist.Error()) {
+		t.Fatal("file should have been deleted", err)
+	}
+
+	// No local copies of the written files should be left behind.
+	buffers, err := ioutil.ReadDir(filepath.Join(r.RenterDir(), "fusebuffers"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buffers) != 0 {
+		t.Fatal("write buffers weren't removed", len(buffers))
+	}
+	err = r.RenterFuseUnmount(writableMount)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	// Inode check. Mount the root siafile to a special inode mountpoint then
 	// open several files and directoriesk. Grab their inodes. Keep the folder
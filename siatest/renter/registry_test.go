@@ -0,0 +1,130 @@
+package renter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gitlab.com/NebulousLabs/fastrand"
+
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/modules/renter"
+	"go.sia.tech/siad/siatest"
+	"go.sia.tech/siad/types"
+)
+
+// TestRenterRegistry tests reading and updating registry entries through the
+// /renter/registry endpoints.
+func TestRenterRegistry(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	// Create a group for the test.
+	groupParams := siatest.GroupParams{
+		Hosts:   renter.MinUpdateRegistrySuccesses,
+		Renters: 1,
+		Miners:  1,
+	}
+	tg, err := siatest.NewGroupFromTemplate(renterTestDir(t.Name()), groupParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	r := tg.Renters()[0]
+
+	// Create a random entry.
+	sk, pk := crypto.GenerateKeyPair()
+	spk := types.Ed25519PublicKey(pk)
+	var dataKey crypto.Hash
+	fastrand.Read(dataKey[:])
+	data := fastrand.Bytes(modules.RegistryDataSize)
+	srv := modules.NewRegistryValue(dataKey, data, 0).Sign(sk)
+
+	// Reading the entry before it exists should fail.
+	_, err = r.RenterRegistryRead(spk, dataKey)
+	if err == nil || !strings.Contains(err.Error(), renter.ErrRegistryEntryNotFound.Error()) {
+		t.Fatal("expected entry to not be found", err)
+	}
+
+	// Update the entry.
+	err = r.RenterRegistryUpdate(spk, srv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Read it again.
+	readSRV, err := r.RenterRegistryRead(spk, dataKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if readSRV.Revision != srv.Revision || readSRV.Tweak != srv.Tweak || string(readSRV.Data) != string(srv.Data) || readSRV.Signature != srv.Signature {
+		t.Fatal("entries don't match", readSRV, srv)
+	}
+
+	// Updating with the same revision should fail.
+	err = r.RenterRegistryUpdate(spk, srv)
+	if err == nil {
+		t.Fatal("expected update with same revision to fail")
+	}
+
+	// Updating with an invalid signature should fail.
+	invalid := srv
+	invalid.Revision++
+	err = r.RenterRegistryUpdate(spk, invalid)
+	if err == nil {
+		t.Fatal("expected update with invalid signature to fail")
+	}
+
+	// Updating with a higher revision should succeed.
+	srv = modules.NewRegistryValue(dataKey, fastrand.Bytes(10), 1).Sign(sk)
+	err = r.RenterRegistryUpdate(spk, srv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	readSRV, err = r.RenterRegistryRead(spk, dataKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if readSRV.Revision != 1 || string(readSRV.Data) != string(srv.Data) {
+		t.Fatal("entry wasn't updated", readSRV)
+	}
+
+	// Subscribe to the entry. The latest value should be sent right away.
+	sub, err := r.RenterRegistrySubscribe(spk, dataKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := sub.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	subSRV, err := sub.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(subSRV, srv) {
+		t.Fatal("wrong initial value", subSRV, srv)
+	}
+
+	// Update the entry again. The subscription should receive the update.
+	srv = modules.NewRegistryValue(dataKey, fastrand.Bytes(10), 2).Sign(sk)
+	err = r.RenterRegistryUpdate(spk, srv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	subSRV, err = sub.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(subSRV, srv) {
+		t.Fatal("wrong update", subSRV, srv)
+	}
+}
@@ -0,0 +1,99 @@
+package renter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/siatest"
+)
+
+// TestRenterShareFile tests sharing a file between two renters using the
+// /renter/share and /renter/import endpoints.
+func TestRenterShareFile(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	// Create a group for the test.
+	groupParams := siatest.GroupParams{
+		Hosts:   3,
+		Renters: 2,
+		Miners:  1,
+	}
+	tg, err := siatest.NewGroupFromTemplate(renterTestDir(t.Name()), groupParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	sharer, importer := tg.Renters()[0], tg.Renters()[1]
+
+	// Upload a file which spans multiple chunks.
+	size := int(2*modules.SectorSize) + siatest.Fuzz()
+	lf, rf, err := sharer.UploadNewFileBlocking(size, 1, 2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := lf.Data()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Sharing a file that doesn't exist should fail with a 404.
+	_, err = sharer.RenterShareGet(modules.RandomSiaPath())
+	if err == nil || !strings.Contains(err.Error(), "path does not exist") {
+		t.Fatal("expected sharing a missing file to fail", err)
+	}
+
+	// Share the file.
+	rsg, err := sharer.RenterShareGet(rf.SiaPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Importing the file with the wrong key should fail.
+	siaPath := modules.RandomSiaPath()
+	wrongKey := strings.Repeat("00", len(rsg.SharingKey)/2)
+	err = importer.RenterImportPost(siaPath, rsg.Descriptor, wrongKey, false)
+	if err == nil || !strings.Contains(err.Error(), "invalid share descriptor") {
+		t.Fatal("expected import with wrong key to fail", err)
+	}
+
+	// Import the file.
+	err = importer.RenterImportPost(siaPath, rsg.Descriptor, rsg.SharingKey, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := importer.RenterFileGet(siaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.File.Filesize != uint64(size) {
+		t.Fatalf("expected size %v but got %v", size, file.File.Filesize)
+	}
+
+	// Importing it again should fail unless force is set.
+	err = importer.RenterImportPost(siaPath, rsg.Descriptor, rsg.SharingKey, false)
+	if err == nil {
+		t.Fatal("expected import to fail since file exists")
+	}
+	err = importer.RenterImportPost(siaPath, rsg.Descriptor, rsg.SharingKey, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The importer should be able to download the file.
+	_, data, err := importer.RenterDownloadHTTPResponseGet(siaPath, 0, uint64(size), true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, expected) {
+		t.Fatal("downloaded data doesn't match uploaded data")
+	}
+}
@@ -0,0 +1,150 @@
+package renter
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"gitlab.com/NebulousLabs/fastrand"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/node/api"
+	"go.sia.tech/siad/siatest"
+)
+
+// TestRenterUploadPacked tests packing small files into shared chunks through
+// the /renter/uploadpacked endpoint.
+func TestRenterUploadPacked(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	// Create a group for the test.
+	groupParams := siatest.GroupParams{
+		Hosts:   3,
+		Renters: 1,
+		Miners:  1,
+	}
+	tg, err := siatest.NewGroupFromTemplate(renterTestDir(t.Name()), groupParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	r := tg.Renters()[0]
+
+	// Create some small files and an empty one. With 2 data pieces every pack
+	// holds 2 sectors worth of files which should result in multiple packs.
+	numFiles := 20
+	var localFiles []*siatest.LocalFile
+	var files []api.RenterUploadPackedFile
+	for i := 0; i <= numFiles; i++ {
+		size := 100 + fastrand.Intn(1000)
+		if i == numFiles {
+			size = 0
+		}
+		lf, err := r.FilesDir().NewFile(size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		localFiles = append(localFiles, lf)
+		files = append(files, api.RenterUploadPackedFile{
+			Source:  lf.Path(),
+			SiaPath: fmt.Sprintf("packed/%v", lf.FileName()),
+		})
+	}
+
+	// Upload the files.
+	err = r.RenterUploadPackedPost(files, 2, 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Uploading them again should fail unless force is set.
+	err = r.RenterUploadPackedPost(files[:1], 2, 1, false)
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatal("expected upload to fail", err)
+	}
+	err = r.RenterUploadPackedPost(files[:1], 2, 1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// There should be multiple packs.
+	rd, err := r.RenterDirRootGet(modules.PackFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rd.Files) < 3 {
+		t.Fatal("expected multiple packs but got", len(rd.Files))
+	}
+
+	// Every file should be downloadable on its own.
+	for i, lf := range localFiles {
+		siaPath, err := modules.NewSiaPath(files[i].SiaPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rf, err := r.RenterFileGet(siaPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rf.File.Filesize != uint64(lf.Size()) {
+			t.Fatalf("expected size %v but got %v", lf.Size(), rf.File.Filesize)
+		}
+		if lf.Size() == 0 {
+			continue
+		}
+		expected, err := lf.Data()
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, data, err := r.RenterDownloadHTTPResponseGet(siaPath, 0, uint64(lf.Size()), true, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, expected) {
+			t.Fatal("downloaded data doesn't match", i)
+		}
+
+		// Download a range of the file.
+		_, data, err = r.RenterDownloadHTTPResponseGet(siaPath, 10, 50, true, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, expected[10:60]) {
+			t.Fatal("downloaded range doesn't match", i)
+		}
+
+		// Download the file from disk.
+		_, data, err = r.RenterDownloadHTTPResponseGet(siaPath, 10, 50, false, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, expected[10:60]) {
+			t.Fatal("range downloaded from disk doesn't match", i)
+		}
+	}
+
+	// Deleting all the packed files should delete the packs.
+	dir, err := modules.NewSiaPath("packed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.RenterDirDeletePost(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rd, err = r.RenterDirRootGet(modules.PackFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rd.Files) != 0 {
+		t.Fatal("expected packs to be deleted but got", len(rd.Files))
+	}
+}
@@ -737,6 +737,304 @@ func TestWalletSend(t *testing.T) {
 	}
 }
 
+// TestWalletCoinControl tests sending siacoins with coin control through the
+// API.
+func TestWalletCoinControl(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+
+	// Create a testgroup
+	groupParams := siatest.GroupParams{
+		Miners: 1,
+	}
+	tg, err := siatest.NewGroupFromTemplate(walletTestDir(t.Name()), groupParams)
+	if err != nil {
+		t.Fatal("Failed to create group: ", err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	miner := tg.Miners()[0]
+
+	// Pick a siacoin output of the wallet.
+	wug, err := miner.WalletUnspentGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var output modules.UnspentOutput
+	for _, uo := range wug.Outputs {
+		if uo.FundType == types.SpecifierSiacoinOutput && uo.ConfirmationHeight > 0 {
+			output = uo
+			break
+		}
+	}
+	if output.Value.IsZero() {
+		t.Fatal("no siacoin output found")
+	}
+
+	// An unknown strategy should be rejected.
+	dest := types.UnlockHash{1}
+	_, err = miner.WalletSiacoinsCoinControlPost(types.SiacoinPrecision, dest, false, modules.CoinControl{Strategy: "unknown"})
+	if err == nil || !strings.Contains(err.Error(), modules.ErrUnknownCoinSelectionStrategy.Error()) {
+		t.Fatal("expected unknown strategy error, got", err)
+	}
+
+	// Send coins using only the picked output and send the change to a
+	// separate address.
+	change := types.UnlockHash{2}
+	cc := modules.CoinControl{
+		Include:       []types.SiacoinOutputID{types.SiacoinOutputID(output.ID)},
+		ChangeAddress: change,
+	}
+	wsp, err := miner.WalletSiacoinsCoinControlPost(types.SiacoinPrecision, dest, false, cc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent := wsp.Transactions[0]
+	if len(parent.SiacoinInputs) != 1 || parent.SiacoinInputs[0].ParentID != types.SiacoinOutputID(output.ID) {
+		t.Fatal("transaction wasn't funded by the included output")
+	}
+	if len(parent.SiacoinOutputs) != 2 || parent.SiacoinOutputs[1].UnlockHash != change {
+		t.Fatal("change wasn't sent to the change address")
+	}
+
+	// The output is spent now and can't be included again.
+	_, err = miner.WalletSiacoinsCoinControlPost(types.SiacoinPrecision, dest, false, cc)
+	if err == nil {
+		t.Fatal("spent output was included")
+	}
+}
+
+// TestWalletBump tests bumping the fee of unconfirmed transactions using the
+// /wallet/bump/:txid endpoint.
+func TestWalletBump(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+
+	// Create a testgroup
+	groupParams := siatest.GroupParams{
+		Miners: 1,
+	}
+	tg, err := siatest.NewGroupFromTemplate(walletTestDir(t.Name()), groupParams)
+	if err != nil {
+		t.Fatal("Failed to create group: ", err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	miner := tg.Miners()[0]
+	tfg, err := miner.TransactionPoolFeeGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	feePerByte := tfg.Maximum.Mul64(10)
+
+	// inPool returns whether the transaction is in the transaction pool.
+	inPool := func(txid types.TransactionID) bool {
+		tptg, err := miner.TransactionPoolTransactionsGet()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, txn := range tptg.Transactions {
+			if txn.ID() == txid {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Unknown transactions can't be bumped.
+	if _, err := miner.WalletBumpPost(types.TransactionID{1}, "", feePerByte); err == nil {
+		t.Fatal("unknown transaction was bumped")
+	}
+
+	// Bump a transaction using the default method.
+	wsp, err := miner.WalletSiacoinsPost(types.SiacoinPrecision, types.UnlockHash{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wbp, err := miner.WalletBumpPost(wsp.TransactionIDs[len(wsp.TransactionIDs)-1], "", feePerByte)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wbp.Method != modules.FeeBumpCPFP {
+		t.Fatal("expected cpfp, got", wbp.Method)
+	}
+	child := wbp.TransactionIDs[len(wbp.TransactionIDs)-1]
+	if !inPool(child) {
+		t.Fatal("child isn't in the transaction pool")
+	}
+	if err := miner.MineBlock(); err != nil {
+		t.Fatal(err)
+	}
+	if inPool(child) {
+		t.Fatal("child wasn't confirmed")
+	}
+
+	// Bump a transaction by replacing it.
+	wsp, err = miner.WalletSiacoinsPost(types.SiacoinPrecision, types.UnlockHash{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := wsp.TransactionIDs[len(wsp.TransactionIDs)-1]
+	wbp, err = miner.WalletBumpPost(original, modules.FeeBumpReplace, feePerByte)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wbp.Method != modules.FeeBumpReplace {
+		t.Fatal("expected replace, got", wbp.Method)
+	}
+	if inPool(original) {
+		t.Fatal("original transaction is still in the transaction pool")
+	}
+	if !inPool(wbp.TransactionIDs[len(wbp.TransactionIDs)-1]) {
+		t.Fatal("replacement isn't in the transaction pool")
+	}
+}
+
+// TestWalletPartialTransaction tests signing a transaction which spends the
+// outputs of two wallets using partially signed transactions.
+func TestWalletPartialTransaction(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+
+	// Create a testgroup
+	groupParams := siatest.GroupParams{
+		Miners: 2,
+	}
+	tg, err := siatest.NewGroupFromTemplate(walletTestDir(t.Name()), groupParams)
+	if err != nil {
+		t.Fatal("Failed to create group: ", err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	alice, bob := tg.Miners()[0], tg.Miners()[1]
+
+	// Give bob an output.
+	wag, err := bob.WalletAddressGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := alice.WalletSiacoinsPost(types.SiacoinPrecision.Mul64(100), wag.Address, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := alice.MineBlock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tg.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	// input returns a confirmed siacoin output of the node as an input.
+	input := func(tn *siatest.TestNode) (types.SiacoinInput, types.Currency) {
+		wug, err := tn.WalletUnspentGet()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, uo := range wug.Outputs {
+			if uo.FundType != types.SpecifierSiacoinOutput || uo.ConfirmationHeight == 0 {
+				continue
+			}
+			wucg, err := tn.WalletUnlockConditionsGet(uo.UnlockHash)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return types.SiacoinInput{
+				ParentID:         types.SiacoinOutputID(uo.ID),
+				UnlockConditions: wucg.UnlockConditions,
+			}, uo.Value
+		}
+		t.Fatal("no siacoin output found")
+		return types.SiacoinInput{}, types.ZeroCurrency
+	}
+	aliceInput, aliceValue := input(alice)
+	bobInput, bobValue := input(bob)
+	fee := types.SiacoinPrecision
+	dest := types.UnlockHash{1}
+	txn := types.Transaction{
+		SiacoinInputs: []types.SiacoinInput{aliceInput, bobInput},
+		SiacoinOutputs: []types.SiacoinOutput{{
+			Value:      aliceValue.Add(bobValue).Sub(fee),
+			UnlockHash: dest,
+		}},
+		MinerFees: []types.Currency{fee},
+	}
+
+	// Both parties sign their own copy.
+	wpp, err := alice.WalletPartialCreatePost(txn, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wpp.Complete || len(wpp.Inputs) != 2 {
+		t.Fatal("unexpected status", wpp.Complete, len(wpp.Inputs))
+	}
+	pst := wpp.PartialTransaction
+	aliceSigned, err := alice.WalletPartialSignPost(pst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bobSigned, err := bob.WalletPartialSignPost(pst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if aliceSigned.Complete || bobSigned.Complete {
+		t.Fatal("transaction shouldn't be complete yet")
+	}
+	if aliceSigned.Inputs[0].Collected != 1 || aliceSigned.Inputs[1].Collected != 0 {
+		t.Fatal("alice signed the wrong inputs", aliceSigned.Inputs)
+	}
+
+	// An incomplete transaction can't be finalized.
+	if _, err := alice.WalletPartialFinalizePost(aliceSigned.PartialTransaction, true); err == nil || !strings.Contains(err.Error(), modules.ErrIncompleteTransaction.Error()) {
+		t.Fatal("expected ErrIncompleteTransaction, got", err)
+	}
+
+	// Merge the signatures and broadcast the transaction.
+	merged, err := alice.WalletPartialMergePost(aliceSigned.PartialTransaction, bobSigned.PartialTransaction)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, err := alice.WalletPartialStatusPost(merged.PartialTransaction)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Complete {
+		t.Fatal("merged transaction should be complete")
+	}
+	wpfp, err := bob.WalletPartialFinalizePost(merged.PartialTransaction, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wpfp.TransactionIDs) != 1 || wpfp.TransactionIDs[0] != txn.ID() {
+		t.Fatal("wrong transaction was finalized")
+	}
+	if err := bob.MineBlock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tg.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	tptg, err := alice.TransactionPoolTransactionsGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ptxn := range tptg.Transactions {
+		if ptxn.ID() == txn.ID() {
+			t.Fatal("transaction wasn't confirmed")
+		}
+	}
+}
+
 // TestWalletSendUnsynced confirms that the wallet will return an error when
 // trying to send siacoins or siafunds if the consensus is not fully synced
 func TestWalletSendUnsynced(t *testing.T) {
@@ -969,3 +1267,736 @@ func TestWalletVerifyPassword(t *testing.T) {
 		t.Error("Password should not be valid")
 	}
 }
+
+// TestWalletMultisig tests creating a 2-of-2 multisig address on two nodes and
+// spending from it.
+func TestWalletMultisig(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+
+	// Create a testgroup
+	groupParams := siatest.GroupParams{
+		Miners: 2,
+	}
+	tg, err := siatest.NewGroupFromTemplate(walletTestDir(t.Name()), groupParams)
+	if err != nil {
+		t.Fatal("Failed to create group: ", err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	alice, bob := tg.Miners()[0], tg.Miners()[1]
+
+	// publicKey returns a new public key of the node.
+	publicKey := func(tn *siatest.TestNode) types.SiaPublicKey {
+		wag, err := tn.WalletAddressGet()
+		if err != nil {
+			t.Fatal(err)
+		}
+		wucg, err := tn.WalletUnlockConditionsGet(wag.Address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return wucg.UnlockConditions.PublicKeys[0]
+	}
+	pks := []types.SiaPublicKey{publicKey(alice), publicKey(bob)}
+
+	// Both parties create the same address.
+	aliceAddr, err := alice.WalletMultisigPost(pks, 2, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bobAddr, err := bob.WalletMultisigPost(pks, 2, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if aliceAddr.Address != bobAddr.Address {
+		t.Fatal("parties created different addresses", aliceAddr.Address, bobAddr.Address)
+	}
+
+	// Fund the address.
+	funds := types.SiacoinPrecision.Mul64(100)
+	wsp, err := alice.WalletSiacoinsPost(funds, aliceAddr.Address, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := alice.MineBlock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tg.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	for _, tn := range []*siatest.TestNode{alice, bob} {
+		wmg, err := tn.WalletMultisigGet()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(wmg.Addresses) != 1 || !wmg.Addresses[0].SiacoinBalance.Equals(funds) {
+			t.Fatal("wrong multisig balance", wmg.Addresses)
+		}
+	}
+
+	// Find the funding output.
+	var parentID types.SiacoinOutputID
+	found := false
+	for _, txnID := range wsp.TransactionIDs {
+		wtg, err := alice.WalletTransactionGet(txnID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, sco := range wtg.Transaction.Transaction.SiacoinOutputs {
+			if sco.UnlockHash == aliceAddr.Address {
+				parentID = wtg.Transaction.Transaction.SiacoinOutputID(uint64(i))
+				found = true
+			}
+		}
+	}
+	if !found {
+		t.Fatal("funding output not found")
+	}
+
+	// Spend from the address by signing with both wallets.
+	fee := types.SiacoinPrecision
+	txn := types.Transaction{
+		SiacoinInputs: []types.SiacoinInput{{
+			ParentID:         parentID,
+			UnlockConditions: aliceAddr.UnlockConditions,
+		}},
+		SiacoinOutputs: []types.SiacoinOutput{{
+			Value:      funds.Sub(fee),
+			UnlockHash: types.UnlockHash{1},
+		}},
+		MinerFees: []types.Currency{fee},
+	}
+	wpp, err := alice.WalletPartialCreatePost(txn, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aliceSigned, err := alice.WalletPartialSignPost(wpp.PartialTransaction)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bobSigned, err := bob.WalletPartialSignPost(aliceSigned.PartialTransaction)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bobSigned.Complete {
+		t.Fatal("transaction should be complete", bobSigned.Inputs)
+	}
+	if _, err := bob.WalletPartialFinalizePost(bobSigned.PartialTransaction, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := bob.MineBlock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tg.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	wmg, err := alice.WalletMultisigGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wmg.Addresses) != 1 || !wmg.Addresses[0].SiacoinBalance.IsZero() {
+		t.Fatal("multisig address should be empty", wmg.Addresses)
+	}
+}
+
+// TestWalletLabels tests labelling addresses and transactions and filtering
+// the transaction history by label.
+func TestWalletLabels(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+
+	// Create a testgroup
+	groupParams := siatest.GroupParams{
+		Miners: 1,
+	}
+	tg, err := siatest.NewGroupFromTemplate(walletTestDir(t.Name()), groupParams)
+	if err != nil {
+		t.Fatal("Failed to create group: ", err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	miner := tg.Miners()[0]
+
+	// Pay a counterparty and a second address and label the payments.
+	landlord, grocer := types.UnlockHash{1}, types.UnlockHash{2}
+	rent, err := miner.WalletSiacoinsPost(types.SiacoinPrecision.Mul64(100), landlord, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := miner.WalletSiacoinsPost(types.SiacoinPrecision.Mul64(10), grocer, false); err != nil {
+		t.Fatal(err)
+	}
+	rentID := rent.TransactionIDs[len(rent.TransactionIDs)-1]
+	err = miner.WalletLabelsPost([]modules.AddressLabel{{Address: landlord, Name: "landlord"}}, []modules.TransactionLabel{{
+		TransactionID: rentID,
+		Note:          "rent for march",
+		Tags:          []string{"housing"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := miner.MineBlock(); err != nil {
+		t.Fatal(err)
+	}
+	wlg, err := miner.WalletLabelsGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wlg.AddressLabels) != 1 || len(wlg.TransactionLabels) != 1 {
+		t.Fatal("wrong labels", wlg)
+	}
+
+	// Filter by tag and by address name.
+	for _, label := range []string{"housing", "landlord"} {
+		wtg, err := miner.WalletTransactionsLabelGet(0, math.MaxUint64, label)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(wtg.ConfirmedTransactions) != 1 || wtg.ConfirmedTransactions[0].TransactionID != rentID {
+			t.Fatalf("expected only the rent transaction for label %v, got %v transactions", label, len(wtg.ConfirmedTransactions))
+		}
+		if len(wtg.TransactionLabels) != 1 || wtg.TransactionLabels[0].Note != "rent for march" {
+			t.Fatal("transaction label missing", wtg.TransactionLabels)
+		}
+		if len(wtg.AddressLabels) != 1 || wtg.AddressLabels[0].Name != "landlord" {
+			t.Fatal("address label missing", wtg.AddressLabels)
+		}
+	}
+
+	// Without a label all transactions are returned.
+	wtg, err := miner.WalletTransactionsGet(0, math.MaxUint64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wtg.ConfirmedTransactions) <= 1 {
+		t.Fatal("expected all transactions", len(wtg.ConfirmedTransactions))
+	}
+}
+
+// TestWalletSchedule tests scheduling, sending and removing payments via the
+// API.
+func TestWalletSchedule(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+
+	// Create a testgroup
+	groupParams := siatest.GroupParams{
+		Miners: 1,
+	}
+	tg, err := siatest.NewGroupFromTemplate(walletTestDir(t.Name()), groupParams)
+	if err != nil {
+		t.Fatal("Failed to create group: ", err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	miner := tg.Miners()[0]
+
+	// Schedule a one-time and a recurring payment for the next block.
+	cg, err := miner.ConsensusGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	amount := types.SiacoinPrecision.Mul64(10)
+	once, err := miner.WalletSchedulePost(amount, types.UnlockHash{1}, cg.Height+1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recurring, err := miner.WalletSchedulePost(amount, types.UnlockHash{2}, cg.Height+1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wsg, err := miner.WalletScheduleGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wsg.ScheduledPayments) != 2 {
+		t.Fatal("expected 2 scheduled payments, got", len(wsg.ScheduledPayments))
+	}
+
+	// Mine a block and wait for the payments to be sent.
+	if err := miner.MineBlock(); err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		wsg, err := miner.WalletScheduleIDGet(once.ScheduledPayment.ID)
+		if err != nil {
+			return err
+		}
+		if !wsg.ScheduledPayment.Completed {
+			return errors.New("one-time payment wasn't sent")
+		}
+		wsg, err = miner.WalletScheduleIDGet(recurring.ScheduledPayment.ID)
+		if err != nil {
+			return err
+		}
+		if wsg.ScheduledPayment.Payments != 1 || wsg.ScheduledPayment.NextHeight != cg.Height+11 {
+			return fmt.Errorf("recurring payment wasn't sent: %+v", wsg.ScheduledPayment)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Postpone and then remove the recurring payment.
+	sp := recurring.ScheduledPayment
+	sp.NextHeight = cg.Height + 100
+	wsp, err := miner.WalletScheduleIDPost(sp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wsp.ScheduledPayment.NextHeight != sp.NextHeight || wsp.ScheduledPayment.Payments != 1 {
+		t.Fatal("payment wasn't updated", wsp.ScheduledPayment)
+	}
+	if err := miner.WalletScheduleIDRemovePost(sp.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := miner.WalletScheduleIDGet(sp.ID); err == nil || !strings.Contains(err.Error(), modules.ErrUnknownScheduledPayment.Error()) {
+		t.Fatal("expected ErrUnknownScheduledPayment, got", err)
+	}
+}
+
+// TestWalletReport tests storing exchange rates and creating fiat reports of
+// the wallet's transactions.
+func TestWalletReport(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+
+	// Create a testgroup
+	groupParams := siatest.GroupParams{
+		Miners: 1,
+	}
+	tg, err := siatest.NewGroupFromTemplate(walletTestDir(t.Name()), groupParams)
+	if err != nil {
+		t.Fatal("Failed to create group: ", err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	miner := tg.Miners()[0]
+
+	// Without exchange rates the values are unknown.
+	wrg, err := miner.WalletReportGet("USD", 0, types.CurrentTimestamp(), modules.ReportPeriodYear)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wrg.Transactions) == 0 || wrg.MissingRates != uint64(len(wrg.Transactions)) || wrg.Total.FiatReceived != "0.00" {
+		t.Fatal("unexpected report without rates", wrg.Total, wrg.MissingRates)
+	}
+
+	// Add a rate which applies to the existing transactions and import a
+	// rate for the future.
+	rate, err := types.ParseExchangeRate("0.01 USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = miner.WalletExchangeRatesPost([]modules.ExchangeRatePoint{{Timestamp: 1, Rate: *rate}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Hour).Unix()
+	err = miner.WalletExchangeRatesCSVPost(fmt.Sprintf("time,rate\n%v,0.02\n", future), "usd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := miner.WalletExchangeRatesCSVPost("2021-01-01,0.01", ""); err == nil {
+		t.Fatal("csv without symbol should be rejected")
+	}
+	werg, err := miner.WalletExchangeRatesGet("usd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(werg.ExchangeRates) != 2 || werg.ExchangeRates[1].Rate.String() != "0.02 usd" {
+		t.Fatal("wrong exchange rates", werg.ExchangeRates)
+	}
+
+	// Send siacoins and confirm the transaction.
+	wsp, err := miner.WalletSiacoinsPost(types.SiacoinPrecision.Mul64(100), types.UnlockHash{1}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := miner.MineBlock(); err != nil {
+		t.Fatal(err)
+	}
+	sentID := wsp.TransactionIDs[len(wsp.TransactionIDs)-1]
+
+	// The report should contain the sent siacoins valued at the first rate.
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		wrg, err = miner.WalletReportGet("USD", 0, types.CurrentTimestamp(), modules.ReportPeriodDay)
+		if err != nil {
+			return err
+		}
+		for _, ft := range wrg.Transactions {
+			if ft.TransactionID == sentID {
+				return nil
+			}
+		}
+		return errors.New("sent transaction isn't part of the report")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wrg.MissingRates != 0 || wrg.Total.Sent.IsZero() || wrg.Total.FiatReceived == "0.00" {
+		t.Fatal("unexpected report", wrg.Total, wrg.MissingRates)
+	}
+	for _, ft := range wrg.Transactions {
+		if ft.TransactionID == sentID && (ft.Sent.Cmp(types.SiacoinPrecision.Mul64(100)) < 0 || ft.FiatSent != "1.00" || ft.RealizedGain == "") {
+			t.Fatal("wrong value of sent transaction", ft)
+		}
+	}
+
+	// The transactions endpoint should contain the same values.
+	wtg, err := miner.WalletTransactionsFiatGet(0, math.MaxUint64, "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wtg.FiatTransactions) != len(wrg.Transactions) {
+		t.Fatal("expected fiat values of all transactions", len(wtg.FiatTransactions), len(wrg.Transactions))
+	}
+
+	// Unknown periods are rejected.
+	if _, err := miner.WalletReportGet("USD", 0, types.CurrentTimestamp(), "week"); err == nil {
+		t.Fatal("unknown period should be rejected")
+	}
+}
+
+// TestWalletExport tests exporting the wallet history.
+func TestWalletExport(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+
+	// Create a testgroup
+	groupParams := siatest.GroupParams{
+		Miners: 1,
+	}
+	tg, err := siatest.NewGroupFromTemplate(walletTestDir(t.Name()), groupParams)
+	if err != nil {
+		t.Fatal("Failed to create group: ", err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	miner := tg.Miners()[0]
+
+	// Send siacoins and confirm the transaction.
+	amount := types.SiacoinPrecision.Mul64(100)
+	wsp, err := miner.WalletSiacoinsPost(amount, types.UnlockHash{1}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := miner.MineBlock(); err != nil {
+		t.Fatal(err)
+	}
+	sentID := wsp.TransactionIDs[len(wsp.TransactionIDs)-1]
+
+	// The export should contain the same transactions as /wallet/transactions.
+	weg, err := miner.WalletExportGet(0, math.MaxUint64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		weg, err = miner.WalletExportGet(0, math.MaxUint64)
+		if err != nil {
+			return err
+		}
+		for _, et := range weg.Transactions {
+			if et.TransactionID == sentID {
+				return nil
+			}
+		}
+		return errors.New("sent transaction wasn't exported")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wtg, err := miner.WalletTransactionsGet(0, weg.EndHeight)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(weg.Transactions) != len(wtg.ConfirmedTransactions) {
+		t.Fatalf("expected %v transactions, got %v", len(wtg.ConfirmedTransactions), len(weg.Transactions))
+	}
+	for _, et := range weg.Transactions {
+		if et.TransactionID != sentID {
+			continue
+		}
+		if et.SiacoinsOut.Cmp(amount) <= 0 || et.MinerFees.IsZero() || !strings.HasPrefix(et.NetSiacoins, "-") {
+			t.Fatal("wrong values of sent transaction", et)
+		}
+	}
+
+	// Pages should add up to the whole history.
+	var paged int
+	for start := types.BlockHeight(0); start <= weg.EndHeight; start += 10 {
+		page, err := miner.WalletExportGet(start, start+9)
+		if err != nil {
+			t.Fatal(err)
+		}
+		paged += len(page.Transactions)
+	}
+	if paged != len(weg.Transactions) {
+		t.Fatalf("expected %v paged transactions, got %v", len(weg.Transactions), paged)
+	}
+
+	// CSV and OFX
+	csv, err := miner.WalletExportRawGet(0, weg.EndHeight, modules.ExportFormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(csv), "\n"); lines != len(weg.Transactions)+1 || !strings.Contains(string(csv), sentID.String()) {
+		t.Fatal("wrong csv export", string(csv))
+	}
+	ofx, err := miner.WalletExportRawGet(0, weg.EndHeight, modules.ExportFormatOFX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(string(ofx), "<STMTTRN>") != len(weg.Transactions) || !strings.Contains(string(ofx), "<FITID>"+sentID.String()) {
+		t.Fatal("wrong ofx export", string(ofx))
+	}
+	if _, err := miner.WalletExportRawGet(0, weg.EndHeight, "xls"); err == nil {
+		t.Fatal("unknown format should be rejected")
+	}
+}
+
+// TestWalletAccounts tests funding an account and sending from it using the
+// API.
+func TestWalletAccounts(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+
+	// Create a testgroup
+	groupParams := siatest.GroupParams{
+		Miners: 1,
+	}
+	tg, err := siatest.NewGroupFromTemplate(walletTestDir(t.Name()), groupParams)
+	if err != nil {
+		t.Fatal("Failed to create group: ", err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	miner := tg.Miners()[0]
+
+	// Create an account and fund it.
+	if err := miner.WalletAccountsAddPost("ops"); err != nil {
+		t.Fatal(err)
+	}
+	wag, err := miner.WalletAccountAddressGet("ops")
+	if err != nil {
+		t.Fatal(err)
+	}
+	funding := types.SiacoinPrecision.Mul64(100)
+	wsp, err := miner.WalletSiacoinsPost(funding, wag.Address, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := miner.MineBlock(); err != nil {
+		t.Fatal(err)
+	}
+	fundingID := wsp.TransactionIDs[len(wsp.TransactionIDs)-1]
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		wag, err := miner.WalletAccountsGet()
+		if err != nil {
+			return err
+		}
+		if len(wag.Accounts) != 1 || !wag.Accounts[0].ConfirmedSiacoinBalance.Equals(funding) {
+			return fmt.Errorf("wrong accounts %v", wag.Accounts)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Send from the account.
+	amount := types.SiacoinPrecision.Mul64(30)
+	wsp, err = miner.WalletSiacoinsCoinControlPost(amount, types.UnlockHash{1}, false, modules.CoinControl{Account: "ops"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := miner.MineBlock(); err != nil {
+		t.Fatal(err)
+	}
+	sentID := wsp.TransactionIDs[len(wsp.TransactionIDs)-1]
+
+	// The history of the account should only contain its own transactions.
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		wtg, err := miner.WalletTransactionsAccountGet(0, math.MaxUint64, "ops")
+		if err != nil {
+			return err
+		}
+		found := make(map[types.TransactionID]bool)
+		for _, pt := range wtg.ConfirmedTransactions {
+			found[pt.TransactionID] = true
+		}
+		if !found[fundingID] || !found[sentID] {
+			return errors.New("account transactions are missing")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wag2, err := miner.WalletAccountGet("ops")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wag2.ConfirmedSiacoinBalance.Cmp(funding.Sub(amount)) >= 0 {
+		t.Fatal("account wasn't charged", wag2.ConfirmedSiacoinBalance)
+	}
+	if wag2.NumAddresses != uint64(len(wag2.Addresses)) || len(wag2.Addresses) < 2 {
+		t.Fatal("change wasn't sent to a new address of the account", wag2.Addresses)
+	}
+
+	// Sending more than the balance of the account should fail.
+	_, err = miner.WalletSiacoinsCoinControlPost(funding, types.UnlockHash{1}, false, modules.CoinControl{Account: "ops"})
+	if err == nil {
+		t.Fatal("account was overdrawn")
+	}
+}
+
+// TestWalletTimelock tests sending siacoins to a time-locked address of
+// another wallet and spending them once they are unlocked.
+func TestWalletTimelock(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+
+	// Create a testgroup
+	groupParams := siatest.GroupParams{
+		Miners: 2,
+	}
+	tg, err := siatest.NewGroupFromTemplate(walletTestDir(t.Name()), groupParams)
+	if err != nil {
+		t.Fatal("Failed to create group: ", err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	alice, bob := tg.Miners()[0], tg.Miners()[1]
+
+	// Alice sends a vesting payment to a public key of Bob.
+	wag, err := bob.WalletAddressGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wucg, err := bob.WalletUnlockConditionsGet(wag.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cg, err := alice.ConsensusGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlockHeight := cg.Height + 5
+	amount := types.SiacoinPrecision.Mul64(100)
+	wsp, err := alice.WalletSiacoinsTimelockPost(amount, wucg.UnlockConditions.PublicKeys[0], unlockHeight, modules.CoinControl{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wsp.UnlockConditions == nil || wsp.UnlockConditions.Timelock != unlockHeight {
+		t.Fatal("wrong unlock conditions", wsp.UnlockConditions)
+	}
+	if err := alice.MineBlock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tg.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Bob adds the unlock conditions to his wallet. The payment should be
+	// pending unlock.
+	if _, err := bob.WalletTimelockAddPost(*wsp.UnlockConditions, false); err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		wg, err := bob.WalletGet()
+		if err != nil {
+			return err
+		}
+		if !wg.PendingUnlockSiacoins.Equals(amount) {
+			return fmt.Errorf("expected %v pending unlock, got %v", amount, wg.PendingUnlockSiacoins)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wtg, err := bob.WalletTimelockGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wtg.Addresses) != 1 || wtg.Addresses[0].Address != wsp.UnlockConditions.UnlockHash() {
+		t.Fatal("wrong time-locked addresses", wtg.Addresses)
+	}
+	if len(wtg.Outputs) != 1 || wtg.Outputs[0].UnlockHeight != unlockHeight {
+		t.Fatal("wrong time-locked outputs", wtg.Outputs)
+	}
+
+	// Once the unlock height is reached, Bob should be able to spend the
+	// payment.
+	for cg.Height < unlockHeight {
+		if err := alice.MineBlock(); err != nil {
+			t.Fatal(err)
+		}
+		if cg, err = alice.ConsensusGet(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tg.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		wg, err := bob.WalletGet()
+		if err != nil {
+			return err
+		}
+		if !wg.PendingUnlockSiacoins.IsZero() {
+			return fmt.Errorf("payment is still pending unlock: %v", wg.PendingUnlockSiacoins)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wug, err := bob.WalletUnspentGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var id types.SiacoinOutputID
+	for _, uo := range wug.Outputs {
+		if uo.UnlockHash == wsp.UnlockConditions.UnlockHash() {
+			id = types.SiacoinOutputID(uo.ID)
+		}
+	}
+	_, err = bob.WalletSiacoinsCoinControlPost(types.SiacoinPrecision, types.UnlockHash{1}, false, modules.CoinControl{
+		Include: []types.SiacoinOutputID{id},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -4,10 +4,14 @@ package types
 // functions
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math/big"
 	"regexp"
+
+	"gitlab.com/NebulousLabs/encoding"
 )
 
 type (
@@ -71,12 +75,7 @@ func (r *ExchangeRate) ApplyAndFormat(c Currency) string {
 		return fmt.Sprintf("0.00 %s", r.staticSymbol)
 	}
 
-	asRatio, _ := r.staticValue.Rat(nil)
-	cRat := new(big.Rat).SetInt(c.Big())
-	precisionRat := new(big.Rat).SetInt(SiacoinPrecision.Big())
-
-	// calculate (cRat * asRatio) / precisionRat
-	resultRat := new(big.Rat).Quo(new(big.Rat).Mul(cRat, asRatio), precisionRat)
+	resultRat := r.Apply(c)
 
 	// use two digits of precision by default
 	result := resultRat.FloatString(2)
@@ -92,3 +91,83 @@ func (r *ExchangeRate) ApplyAndFormat(c Currency) string {
 	result = fmt.Sprintf("~ %s %s", result, r.staticSymbol)
 	return result
 }
+
+// Apply applies the exchange rate to a currency amount and returns the exact
+// value in the exchange rate's currency.
+func (r ExchangeRate) Apply(c Currency) *big.Rat {
+	cRat := new(big.Rat).SetInt(c.Big())
+	precisionRat := new(big.Rat).SetInt(SiacoinPrecision.Big())
+
+	// calculate (cRat * rate) / precisionRat
+	return new(big.Rat).Quo(new(big.Rat).Mul(cRat, r.Rat()), precisionRat)
+}
+
+// Rat returns the value of one siacoin in the exchange rate's currency. The
+// decimal value the rate was parsed from is returned exactly. The zero value
+// of ExchangeRate is worth nothing.
+func (r ExchangeRate) Rat() *big.Rat {
+	if r.staticValue == nil {
+		return new(big.Rat)
+	}
+	rat, ok := new(big.Rat).SetString(r.staticValue.Text('f', -1))
+	if !ok {
+		rat, _ = r.staticValue.Rat(nil)
+	}
+	return rat
+}
+
+// String returns the exchange rate in the format accepted by
+// ParseExchangeRate.
+func (r ExchangeRate) String() string {
+	if r.staticValue == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s %s", r.staticValue.Text('f', -1), r.staticSymbol)
+}
+
+// Symbol returns the symbol of the exchange rate's currency.
+func (r ExchangeRate) Symbol() string {
+	return r.staticSymbol
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (r ExchangeRate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.String())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (r *ExchangeRate) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	return r.loadString(s)
+}
+
+// MarshalSia implements the encoding.SiaMarshaler interface.
+func (r ExchangeRate) MarshalSia(w io.Writer) error {
+	return encoding.NewEncoder(w).Encode(r.String())
+}
+
+// UnmarshalSia implements the encoding.SiaUnmarshaler interface.
+func (r *ExchangeRate) UnmarshalSia(rd io.Reader) error {
+	var s string
+	if err := encoding.NewDecoder(rd, encoding.DefaultAllocLimit).Decode(&s); err != nil {
+		return err
+	}
+	return r.loadString(s)
+}
+
+// loadString parses s into the exchange rate. An empty string is decoded into
+// the zero value, which is also encoded as an empty string.
+func (r *ExchangeRate) loadString(s string) error {
+	rate, err := ParseExchangeRate(s)
+	if err != nil {
+		return err
+	} else if rate == nil {
+		*r = ExchangeRate{}
+		return nil
+	}
+	*r = *rate
+	return nil
+}
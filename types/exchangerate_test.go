@@ -1,7 +1,11 @@
 package types
 
 import (
+	"encoding/json"
+	"math/big"
 	"testing"
+
+	"gitlab.com/NebulousLabs/encoding"
 )
 
 // TestParseInvalidExchangeRate checks that invalid exchange rate
@@ -122,3 +126,61 @@ func TestApplyAndFormat(t *testing.T) {
 		}
 	}
 }
+
+// TestExchangeRateEncoding checks that exchange rates survive JSON and Sia
+// encoding and that Apply is exact.
+func TestExchangeRateEncoding(t *testing.T) {
+	rate, err := ParseExchangeRate("0.00001234USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rate.String() != "0.00001234 USD" || rate.Symbol() != "USD" {
+		t.Fatalf("wrong string %v or symbol %v", rate.String(), rate.Symbol())
+	}
+
+	// JSON
+	b, err := json.Marshal(rate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var jsonRate ExchangeRate
+	if err := json.Unmarshal(b, &jsonRate); err != nil {
+		t.Fatal(err)
+	}
+	if jsonRate.String() != rate.String() {
+		t.Fatal("JSON round trip failed", jsonRate.String())
+	}
+	if err := json.Unmarshal([]byte(`"USD"`), &jsonRate); err != ErrUnexpectedFormat {
+		t.Fatal("expected ErrUnexpectedFormat, got", err)
+	}
+
+	// The zero value should survive the round trip and be worth nothing.
+	var zero ExchangeRate
+	b, err = json.Marshal(zero)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, &jsonRate); err != nil {
+		t.Fatal(err)
+	}
+	if jsonRate.String() != "" {
+		t.Fatal("zero value JSON round trip failed", jsonRate.String())
+	}
+	if zero.Rat().Sign() != 0 || zero.Apply(SiacoinPrecision).Sign() != 0 {
+		t.Fatal("zero value should be worth nothing")
+	}
+
+	// Sia encoding
+	var siaRate ExchangeRate
+	if err := encoding.Unmarshal(encoding.Marshal(*rate), &siaRate); err != nil {
+		t.Fatal(err)
+	}
+	if siaRate.String() != rate.String() {
+		t.Fatal("Sia round trip failed", siaRate.String())
+	}
+
+	// 1000 SC at 0.00001234 USD are exactly 0.01234 USD.
+	if rate.Apply(SiacoinPrecision.Mul64(1000)).Cmp(big.NewRat(1234, 100000)) != 0 {
+		t.Fatal("Apply isn't exact", rate.Apply(SiacoinPrecision.Mul64(1000)))
+	}
+}